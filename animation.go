@@ -0,0 +1,110 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+	"github.com/gogpu/gg/recording"
+)
+
+// defaultFrameDuration is the frame duration of an Animation that does
+// not set one.
+const defaultFrameDuration = 100 * time.Millisecond
+
+// Animation is a sequence of frames written as one animated SVG by
+// WriteAnimation.
+type Animation struct {
+	// Frames are the recordings shown in turn.
+	Frames []*recording.Recording
+	// Durations holds how long each frame is shown. Frames past the end
+	// of Durations, and frames with a zero duration, are shown for
+	// FrameDuration.
+	Durations []time.Duration
+	// FrameDuration is the default frame duration, 100ms if zero.
+	FrameDuration time.Duration
+	// Repeat is the number of times the animation plays. Zero repeats it
+	// indefinitely; otherwise the last frame stays on screen at the end.
+	Repeat int
+}
+
+// WriteAnimation writes the frames of a as one self-contained animated
+// SVG. Every frame is exported with a Backend configured with opts and
+// nested in a group whose display is switched by a SMIL <animate>
+// element with discrete keyTimes, so each group is shown for its frame's
+// duration. Viewers without SMIL support show the first frame. Generated
+// IDs get an "fN-" prefix so frames do not collide.
+func WriteAnimation(w io.Writer, a Animation, opts ...Option) (int64, error) {
+	if len(a.Frames) == 0 {
+		return 0, errors.New("svg: animation has no frames")
+	}
+	frameDuration := a.FrameDuration
+	if frameDuration <= 0 {
+		frameDuration = defaultFrameDuration
+	}
+	starts := make([]time.Duration, len(a.Frames))
+	var total time.Duration
+	for i := range a.Frames {
+		starts[i] = total
+		d := frameDuration
+		if i < len(a.Durations) && a.Durations[i] > 0 {
+			d = a.Durations[i]
+		}
+		total += d
+	}
+	keyTimes := make([]string, len(starts))
+	for i, start := range starts {
+		keyTimes[i] = svgwriter.FormatNumber(float64(start)/float64(total), 4)
+	}
+	repeat := "indefinite"
+	if a.Repeat > 0 {
+		repeat = strconv.Itoa(a.Repeat)
+	}
+
+	var prolog []byte
+	root := &Element{Name: "svg"}
+	var width, height float64
+	for i, r := range a.Frames {
+		p, frame, err := exportElementTree(r, opts, fmt.Sprintf("f%d-", i+1))
+		if err != nil {
+			return 0, fmt.Errorf("svg: frame %d: %w", i+1, err)
+		}
+		if i == 0 {
+			prolog = p
+		}
+		fw, fh := pageSize(frame, r)
+		width, height = max(width, fw), max(height, fh)
+		nestDocument(root, frame)
+
+		group := &Element{Name: "g", Attrs: []Attr{{Name: "id", Value: "frame" + strconv.Itoa(i+1)}}}
+		if i > 0 {
+			group.SetAttr("display", "none")
+		}
+		if len(a.Frames) > 1 {
+			values := make([]string, len(a.Frames))
+			for j := range values {
+				values[j] = "none"
+			}
+			values[i] = "inline"
+			animate := &Element{Name: "animate", Attrs: []Attr{
+				{Name: "attributeName", Value: "display"},
+				{Name: "values", Value: strings.Join(values, ";")},
+				{Name: "keyTimes", Value: strings.Join(keyTimes, ";")},
+				{Name: "dur", Value: svgwriter.FormatNumber(total.Seconds(), 3) + "s"},
+				{Name: "calcMode", Value: "discrete"},
+				{Name: "repeatCount", Value: repeat},
+			}}
+			if a.Repeat > 0 {
+				animate.SetAttr("fill", "freeze")
+			}
+			group.Children = append(group.Children, animate)
+		}
+		group.Children = append(group.Children, frame)
+		root.Children = append(root.Children, &Element{Text: "\n"}, group)
+	}
+	return writeNested(w, prolog, root, width, height)
+}
@@ -0,0 +1,66 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func testFrames(n int) []*recording.Recording {
+	frames := make([]*recording.Recording, n)
+	for i := range frames {
+		rec := recording.NewRecorder(50, 50)
+		rec.SetFillRGBA(0, 0, 1, 1)
+		rec.DrawCircle(10+float64(i)*10, 25, 5)
+		rec.Fill()
+		frames[i] = rec.FinishRecording()
+	}
+	return frames
+}
+
+func TestWriteAnimation(t *testing.T) {
+	var s strings.Builder
+	_, err := WriteAnimation(&s, Animation{
+		Frames:    testFrames(3),
+		Durations: []time.Duration{0, 200 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := s.String()
+	for _, want := range []string{
+		`<g id="frame1"><animate attributeName="display" values="inline;none;none" keyTimes="0;0.25;0.75" dur="0.4s" calcMode="discrete" repeatCount="indefinite"/>`,
+		`<g id="frame2" display="none"><animate attributeName="display" values="none;inline;none"`,
+		`<g id="frame3" display="none">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "fill=\"freeze\"") {
+		t.Error("looping animations should not freeze")
+	}
+}
+
+func TestWriteAnimationRepeat(t *testing.T) {
+	var s strings.Builder
+	if _, err := WriteAnimation(&s, Animation{Frames: testFrames(2), Repeat: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(s.String(), `repeatCount="2" fill="freeze"`) {
+		t.Errorf("finite animations should freeze on the last frame:\n%s", s.String())
+	}
+
+	s.Reset()
+	if _, err := WriteAnimation(&s, Animation{Frames: testFrames(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(s.String(), "<animate") {
+		t.Error("a single frame needs no animation")
+	}
+	if _, err := WriteAnimation(&s, Animation{}); err == nil {
+		t.Error("an animation without frames should be an error")
+	}
+}
@@ -0,0 +1,50 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Aria holds accessibility attributes for an element.
+// Empty fields are omitted.
+type Aria struct {
+	// Role is the ARIA role, typically "img" for the root of a chart.
+	Role string
+	// Label is the accessible name (aria-label).
+	Label string
+	// LabelledBy lists IDs of elements naming this one (aria-labelledby).
+	LabelledBy string
+}
+
+// isZero reports whether no attribute is set.
+func (a Aria) isZero() bool {
+	return a.Role == "" && a.Label == "" && a.LabelledBy == ""
+}
+
+// attrs returns the attributes as an SVG attribute string with a leading space.
+func (a Aria) attrs() string {
+	var s strings.Builder
+	if a.Role != "" {
+		s.WriteString(fmt.Sprintf(` role="%s"`, escapeXML(a.Role)))
+	}
+	if a.Label != "" {
+		s.WriteString(fmt.Sprintf(` aria-label="%s"`, escapeXML(a.Label)))
+	}
+	if a.LabelledBy != "" {
+		s.WriteString(fmt.Sprintf(` aria-labelledby="%s"`, escapeXML(a.LabelledBy)))
+	}
+	return s.String()
+}
+
+// SetAria sets accessibility attributes on the root <svg> element, so
+// generated charts pass accessibility audits. Like the title, it is not
+// reset by Begin.
+func (b *Backend) SetAria(a Aria) {
+	b.rootAria = a
+}
+
+// SetNextAria sets accessibility attributes on the next drawn element.
+// It combines with SetNextAttrs and is cleared once the element is written.
+func (b *Backend) SetNextAria(a Aria) {
+	b.nextAttrs.aria = a
+}
@@ -0,0 +1,34 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestSetAria(t *testing.T) {
+	backend := NewBackend()
+	backend.SetAria(Aria{Role: "img", LabelledBy: "chart-title"})
+	if err := backend.Begin(200, 100); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	backend.SetNextAttrs("bar1")
+	backend.SetNextAria(Aria{Role: "graphics-symbol", Label: `Q1 "best"`})
+	backend.FillRect(recording.NewRect(0, 0, 10, 50), brush)
+	backend.FillRect(recording.NewRect(20, 0, 10, 50), brush)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `viewBox="0 0 200 100" role="img" aria-labelledby="chart-title">`) {
+		t.Error("Root element should carry role and aria-labelledby")
+	}
+	if !strings.Contains(svg, `<rect id="bar1" role="graphics-symbol" aria-label="Q1 &quot;best&quot;" x="0"`) {
+		t.Error("Element should carry its id and ARIA attributes")
+	}
+	if !strings.Contains(svg, `<rect x="20"`) {
+		t.Error("ARIA attributes should apply to only one element")
+	}
+}
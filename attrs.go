@@ -0,0 +1,53 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetNextAttrs tags the next drawn element (path, rect, image or text)
+// with an id and CSS classes, so exported shapes can be targeted from CSS
+// or JavaScript. Empty values are omitted. The attributes apply to exactly
+// one element and are cleared once it has been written.
+func (b *Backend) SetNextAttrs(id string, classes ...string) {
+	b.nextAttrs.id = id
+	b.nextAttrs.classes = classes
+}
+
+// elementAttrs holds user-supplied attributes for the next element.
+type elementAttrs struct {
+	id      string
+	classes []string
+	aria    Aria
+	// animation is set by SetNextAnimation.
+	animation *CSSAnimation
+}
+
+// openElement writes the start of an element tag followed by any pending
+// per-element attributes.
+func (b *Backend) openElement(tag string) {
+	b.elementStart = b.builder.Len()
+	b.mergeable = false
+	b.openElementLink()
+	b.tagStart = b.builder.Len()
+	b.builder.WriteString("<")
+	b.builder.WriteString(tag)
+	b.writeSourceOp()
+
+	attrs := b.nextAttrs
+	b.nextAttrs = elementAttrs{}
+	if attrs.id != "" {
+		b.builder.WriteString(fmt.Sprintf(` id="%s"`, escapeXML(attrs.id)))
+	}
+	classes := attrs.classes
+	b.animatedElement = attrs.animation != nil
+	if attrs.animation != nil {
+		classes = append(classes[:len(classes):len(classes)], b.animationClass(attrs.animation))
+	}
+	if len(classes) > 0 {
+		b.builder.WriteString(fmt.Sprintf(` class="%s"`, escapeXML(strings.Join(classes, " "))))
+	}
+	if !attrs.aria.isZero() {
+		b.builder.WriteString(attrs.aria.attrs())
+	}
+}
@@ -0,0 +1,46 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestSetNextAttrs(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 1, G: 0, B: 0, A: 1})
+	backend.SetNextAttrs("bar-q1", "bar", "highlight")
+	backend.FillRect(recording.NewRect(10, 10, 20, 100), brush)
+	backend.FillRect(recording.NewRect(40, 10, 20, 100), brush)
+	backend.SetNextAttrs("", "label")
+	backend.DrawText("Q1", 10, 130, nil, brush)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<rect id="bar-q1" class="bar highlight" x="10"`) {
+		t.Error("First rect should carry the id and classes")
+	}
+	if !strings.Contains(svg, `<rect x="40"`) {
+		t.Error("Attributes should apply to only one element")
+	}
+	if !strings.Contains(svg, `<text class="label" x="10"`) {
+		t.Error("Text should carry the class without an id")
+	}
+}
+
+func TestSetNextAttrsEscape(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+
+	backend.SetNextAttrs(`a"b`)
+	backend.FillRect(recording.NewRect(0, 0, 1, 1), recording.NewSolidBrush(gg.RGBA{A: 1}))
+
+	if !strings.Contains(writeSVG(t, backend), `id="a&quot;b"`) {
+		t.Error("Attribute values should be escaped")
+	}
+}
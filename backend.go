@@ -32,34 +32,50 @@ package svg
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"image"
 	"image/png"
 	"io"
 	"math"
+	"math/rand/v2"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/gogpu/gg"
+	"github.com/gogpu/gg-svg/svgwriter"
 	"github.com/gogpu/gg/recording"
 	"github.com/gogpu/gg/text"
 )
 
+// canvasClipID is the ID of the clip path used by WithHardClipToCanvas.
+const canvasClipID = "canvas-clip"
+
 // Backend implements recording.Backend for SVG output.
 // It generates SVG XML from recorded drawing commands.
+//
+// A Backend is not safe for concurrent use. Worker pools should give each
+// goroutine its own backend; the package keeps no mutable global state,
+// so backends created concurrently with NewBackend or
+// recording.NewBackend("svg") are independent.
 type Backend struct {
+	// Output settings made by options
+	settings
+
 	width  int
 	height int
 
 	// SVG content builder
-	builder strings.Builder
+	builder bytes.Buffer
 
 	// Definitions (gradients, clip paths)
-	defs strings.Builder
+	defs bytes.Buffer
 
-	// Current group nesting for Save/Restore
-	groupDepth int
+	// Open container elements, innermost last
+	containers []openContainer
 
 	// Counter for unique IDs
 	idCounter int
@@ -70,55 +86,312 @@ type Backend struct {
 	// Current graphics state
 	currentTransform recording.Matrix
 	currentClipID    string
+
+	// Attributes for the next emitted element
+	nextAttrs elementAttrs
+
+	// Open link URLs when links wrap individual elements
+	links []string
+
+	// Whether the document uses Inkscape layer attributes
+	usesInkscape bool
+
+	// Export-time rewriting hooks
+	brushRemap       func(recording.Brush) recording.Brush
+	strokeWidthRemap func(float64) float64
+	elementHook      func(tag string, attrs map[string]string) map[string]string
+
+	// Patterns defined with DefinePatternFromRecording, by element ID
+	patterns     map[string]string
+	patternOrder []string
+
+	// Color theming: CSS color to theme variable
+	themeColors map[string]string
+	themeAuto   int
+	themeVars   bool
+
+	// Text state
+	fonts            []fontText
+	fontSizeResolver func(text.Face) float64
+
+	// Output state
+	extraNamespaces []namespaceDecl
+	cutShapes       []cutShape
+	sketchPCG       *rand.PCG
+	sketchRNG       *rand.Rand
+
+	// Auto-crop
+	contentBounds bbox
+	clipBounds    *bbox
+
+	// CSS classes collected with StyleClasses
+	styleClasses map[string]string
+	styleRules   []string
+
+	// CSS animations collected with SetNextAnimation
+	keyframeNames    map[string]string
+	animationClasses map[string]string
+	animationRules   []string
+	animatedElement  bool
+
+	// Scripts added with AddScript
+	scripts []string
+
+	// Draw-on animation of strokes
+	drawOnRegistered bool
+	drawOnCount      int
+
+	// Layered content for structures other than StructureInterleaved
+	layers       []bytes.Buffer
+	elementStart int
+	tagStart     int
+	elementLayer int
+
+	// Laser classification for StructureLaser
+	laserClassifier func(LaserElement) LaserOp
+
+	// Deferred strokes for WithToolpathOrdering
+	toolpaths []toolpath
+
+	// Recording being replayed by Playback, if any
+	source *recording.Recording
+
+	// Current operation, for WithSourceMap and error reports
+	opIndex      int
+	opMethod     string
+	sourceCursor int
+
+	// Failure handling
+	errs       []error
+	failures   int
+	incomplete bool
+
+	// Transform flattening for WithFlattenTransforms
+	clipPaths map[string]clipDef
+	flatClips map[string]string
+
+	// Path merging for WithMergePaths
+	lastPath pathMerge
+	// mergeable is set for path elements whose paint and fill rule allow
+	// merging them.
+	mergeable bool
+
+	// Size limits for WithBudget
+	onExceeded func(BudgetUsage)
+	elements   int
+	overBudget bool
+
+	// Provenance for WithProvenance
+	recordingHash string
+
+	// Memory management
+	peakBytes int
+
+	// Post-write processing
+	postProcessors []func([]byte) ([]byte, error)
+	passes         []Optimizer
+	signer         Signer
+	signature      []byte
+	contentHash    string
+
+	// Document metadata
+	title       string
+	description string
+	rootAria    Aria
+	metadata    Metadata
 }
 
 // backendState stores the graphics state for Save/Restore operations.
 type backendState struct {
-	transform recording.Matrix
-	clipID    string
+	transform  recording.Matrix
+	clipID     string
+	clipBounds *bbox
+}
+
+// container identifies an open container element.
+type container int
+
+const (
+	containerGroup container = iota // <g> opened by Save
+	containerLink                   // <a> opened by BeginLink
+	containerLayer                  // <g> opened by BeginLayer
+)
+
+// endTag returns the closing tag of the container element.
+func (c container) endTag() string {
+	if c == containerLink {
+		return "</a>"
+	}
+	return "</g>"
+}
+
+// openContainer is a container element whose end tag has not been
+// written yet.
+type openContainer struct {
+	kind container
+	// start is the offset of the start tag in the builder.
+	start int
+	// children counts the elements written directly inside it.
+	children int
+	// nestedLinks counts the nested BeginLink calls left out while this
+	// was the innermost container, for their EndLink to skip.
+	nestedLinks int
+}
+
+// pushContainer writes the start tag of a container element.
+func (b *Backend) pushContainer(c container, startTag string) {
+	b.addChild(1)
+	b.lastPath = pathMerge{}
+	b.containers = append(b.containers, openContainer{kind: c, start: b.builder.Len()})
+	b.builder.WriteString(startTag)
+}
+
+// popContainer closes the innermost container element if it is of kind c.
+func (b *Backend) popContainer(c container) {
+	if len(b.containers) == 0 || b.containers[len(b.containers)-1].kind != c {
+		return
+	}
+	b.closeContainer()
+}
+
+// closeContainer writes the end tag of the innermost container and
+// returns its kind. Groups opened by Save carry no attributes, so an
+// empty one is removed and one with a single child is replaced by the
+// child.
+func (b *Backend) closeContainer() container {
+	c := b.containers[len(b.containers)-1]
+	b.containers = b.containers[:len(b.containers)-1]
+	b.lastPath = pathMerge{}
+	if c.kind == containerGroup {
+		content := c.start + len("<g>")
+		switch {
+		case b.builder.Len() == content:
+			b.builder.Truncate(c.start)
+			b.addChild(-1)
+			return c.kind
+		case c.children == 1:
+			buf := b.builder.Bytes()
+			n := copy(buf[c.start:], buf[content:])
+			b.builder.Truncate(c.start + n)
+			return c.kind
+		}
+	}
+	b.builder.WriteString(c.kind.endTag())
+	return c.kind
 }
 
-// NewBackend creates a new SVG backend.
+// addChild counts n elements written into the innermost container.
+func (b *Backend) addChild(n int) {
+	if len(b.containers) > 0 {
+		b.containers[len(b.containers)-1].children += n
+	}
+}
+
+// NewBackend creates a new SVG backend configured with the given options.
 // The backend starts in an uninitialized state. Call Begin() to initialize
 // with specific dimensions before drawing.
-func NewBackend() *Backend {
-	return &Backend{
+func NewBackend(opts ...Option) *Backend {
+	b := &Backend{
+		settings: settings{
+			trimThreshold:   DefaultTrimThreshold,
+			defaultFontSize: DefaultFontSize,
+			opacityDigits:   -1,
+			alphaOpaque:     1,
+		},
 		stateStack: make([]backendState, 0, 8),
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 // Begin initializes the backend for rendering at the given dimensions.
+//
+// A backend can be reused for any number of exports by calling Begin
+// again. Options and the settings made with ThemeColor, RemapBrush,
+// RemapStrokeWidth and DefinePatternFromRecording carry over; the drawn
+// content, definitions, graphics state and failures are discarded. The
+// buffers and slices holding them keep their capacity up to the trim
+// threshold, so exporting a sequence of similar frames allocates little
+// after the first. See Reset and WithTrimThreshold.
 func (b *Backend) Begin(width, height int) error {
 	b.width = width
 	b.height = height
+	b.recordPeak()
+	b.trimBuffers()
 	b.builder.Reset()
 	b.defs.Reset()
-	b.groupDepth = 0
+	b.presize()
+	b.resetLayers()
+	b.containers = b.containers[:0]
 	b.idCounter = 0
 	b.stateStack = b.stateStack[:0]
 	b.currentTransform = recording.Identity()
 	b.currentClipID = ""
+	b.contentBounds = emptyBBox()
+	b.fonts = b.fonts[:0]
+	b.clipBounds = nil
+	b.nextAttrs = elementAttrs{}
+	b.links = b.links[:0]
+	b.usesInkscape = false
+	b.toolpaths = b.toolpaths[:0]
+	b.opIndex = -1
+	b.sourceCursor = 0
+	b.recordingHash = ""
+	b.signature = nil
+	b.errs = b.errs[:0]
+	b.cutShapes = b.cutShapes[:0]
+	b.resetSketch()
+	b.failures = 0
+	b.incomplete = false
+	b.elements = 0
+	b.overBudget = false
+	b.lastPath = pathMerge{}
+	clear(b.clipPaths)
+	clear(b.flatClips)
+	b.contentHash = ""
+	clear(b.styleClasses)
+	b.styleRules = b.styleRules[:0]
+	clear(b.keyframeNames)
+	clear(b.animationClasses)
+	b.animationRules = b.animationRules[:0]
+	b.drawOnRegistered = false
+	b.drawOnCount = 0
+	if b.provenance && b.source != nil {
+		b.recordingHash = hashRecording(b.source)
+	}
 
 	return nil
 }
 
 // End finalizes the rendering.
 func (b *Backend) End() error {
-	return nil
+	b.flushToolpaths()
+	err := b.exportErr()
+	if b.validate && b.strictErr() == nil {
+		err = errors.Join(err, b.Validate())
+	}
+	return err
 }
 
 // Save saves the current graphics state onto a stack.
 func (b *Backend) Save() {
+	b.advanceOp("Save")
 	b.stateStack = append(b.stateStack, backendState{
-		transform: b.currentTransform,
-		clipID:    b.currentClipID,
+		transform:  b.currentTransform,
+		clipID:     b.currentClipID,
+		clipBounds: b.clipBounds,
 	})
-	b.builder.WriteString("<g>")
-	b.groupDepth++
+	if b.structure != StructureInterleaved {
+		return
+	}
+	b.pushContainer(containerGroup, "<g>")
 }
 
 // Restore restores the graphics state from the stack.
 func (b *Backend) Restore() {
+	b.advanceOp("Restore")
 	if len(b.stateStack) == 0 {
 		return
 	}
@@ -128,26 +401,52 @@ func (b *Backend) Restore() {
 
 	b.currentTransform = state.transform
 	b.currentClipID = state.clipID
+	b.clipBounds = state.clipBounds
 
-	if b.groupDepth > 0 {
-		b.builder.WriteString("</g>")
-		b.groupDepth--
+	// Close the group opened by the matching Save, along with any
+	// links and layers that were left open inside it.
+	isGroup := func(c openContainer) bool { return c.kind == containerGroup }
+	if slices.ContainsFunc(b.containers, isGroup) {
+		for b.closeContainer() != containerGroup {
+		}
 	}
 }
 
 // SetTransform sets the current transformation matrix.
 func (b *Backend) SetTransform(m recording.Matrix) {
+	b.advanceOp("SetTransform")
 	b.currentTransform = m
 }
 
 // SetClip sets the clipping region to the given path.
 func (b *Backend) SetClip(path *gg.Path, rule recording.FillRule) {
+	b.advanceOp("SetClip")
+	if b.overBudget {
+		return
+	}
 	if path == nil {
 		return
 	}
+	b.checkPath(path)
+	path = b.preparePath(path)
+
+	if b.tiny() {
+		b.fail(ErrUnsupportedFeature, errors.New("clip paths"))
+		return
+	}
 
 	clipID := b.nextID("clip")
 	b.currentClipID = clipID
+	if b.flattenTransforms {
+		if b.clipPaths == nil {
+			b.clipPaths = make(map[string]clipDef)
+		}
+		b.clipPaths[clipID] = clipDef{path: path, rule: rule}
+	}
+	if b.autoCrop {
+		clip := b.transformedBounds(pathBounds(path, 0))
+		b.clipBounds = &clip
+	}
 
 	// Write clip path definition
 	b.defs.WriteString(fmt.Sprintf(`<clipPath id="%s">`, clipID))
@@ -156,132 +455,365 @@ func (b *Backend) SetClip(path *gg.Path, rule recording.FillRule) {
 		b.defs.WriteString(` clip-rule="evenodd"`)
 	}
 	b.defs.WriteString(`/></clipPath>`)
+	b.checkBudget()
 }
 
 // ClearClip removes any clipping region.
 func (b *Backend) ClearClip() {
+	b.advanceOp("ClearClip")
 	b.currentClipID = ""
+	b.clipBounds = nil
 }
 
 // FillPath fills the given path with the brush color/pattern.
 func (b *Backend) FillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
+	b.advanceOp("FillPath")
+	if b.overBudget {
+		return
+	}
+	b.fillPath(path, brush, rule)
+}
+
+// fillPath writes a filled path element.
+func (b *Backend) fillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
 	if path == nil {
 		return
 	}
+	b.checkPath(path)
+	path = b.preparePath(path)
+	brush = b.fillBrush(brush)
+	if b.flattens(brush) {
+		path = transformPath(path, b.currentTransform)
+		defer b.flatten()()
+	}
+	if b.invisible(brush) || degenerateFill(path) || b.culledPath(path, 0) {
+		return
+	}
 
-	b.builder.WriteString("<path")
+	b.includePath(path, 0)
+	b.addCutPath(path, true, 0)
+	b.openElement("path")
+	b.mergeable = rule != recording.FillRuleEvenOdd && b.mergesPaint(brush)
 	b.writeTransform()
 	b.writeClip()
-	b.builder.WriteString(fmt.Sprintf(` d="%s"`, b.pathToD(path)))
+	b.writePathData(path)
 	b.writeFill(brush)
 	if rule == recording.FillRuleEvenOdd {
 		b.builder.WriteString(` fill-rule="evenodd"`)
 	}
 	b.builder.WriteString(` stroke="none"`)
 	b.builder.WriteString("/>")
+	b.closeElement(kindFill)
 }
 
 // StrokePath strokes the given path with the brush and stroke style.
 func (b *Backend) StrokePath(path *gg.Path, brush recording.Brush, stroke recording.Stroke) {
+	b.advanceOp("StrokePath")
+	if b.overBudget {
+		return
+	}
 	if path == nil {
 		return
 	}
+	b.checkPath(path)
+	path = b.preparePath(path)
+	brush = b.strokeBrush(brush, stroke)
+	if scale, ok := similarityScale(b.currentTransform); ok && b.flattens(brush) {
+		path = transformPath(path, b.currentTransform)
+		stroke = scaleStroke(stroke, scale)
+		defer b.flatten()()
+	}
+	if b.invisible(brush) || b.degenerateStroke(path, stroke) || b.culledPath(path, strokeExtent(stroke)) {
+		return
+	}
 
-	b.builder.WriteString("<path")
+	b.includePath(path, stroke.Width/2)
+	b.addCutPath(path, false, stroke.Width/2)
+	drawOn := b.drawsOn(stroke)
+	if drawOn {
+		classes := b.nextAttrs.classes
+		b.nextAttrs.classes = append(classes[:len(classes):len(classes)], b.drawOnClass())
+	}
+	b.openElement("path")
+	b.mergeable = b.mergesPaint(brush)
 	b.writeTransform()
 	b.writeClip()
-	b.builder.WriteString(fmt.Sprintf(` d="%s"`, b.pathToD(path)))
+	dStart := b.builder.Len()
+	b.writePathData(path)
+	dEnd := b.builder.Len()
 	b.builder.WriteString(` fill="none"`)
 	b.writeStroke(brush, stroke)
+	if drawOn {
+		b.writeDrawOn(path)
+	}
 	b.builder.WriteString("/>")
+	if b.toolpathOrdering {
+		b.deferToolpath(path, dStart, dEnd)
+		return
+	}
+	b.closeElement(kindStroke)
 }
 
 // FillRect fills an axis-aligned rectangle with the brush.
 func (b *Backend) FillRect(rect recording.Rect, brush recording.Brush) {
-	b.builder.WriteString("<rect")
+	b.advanceOp("FillRect")
+	if b.overBudget {
+		return
+	}
+	if b.sketching() {
+		b.fillPath(rectPath(rect), brush, recording.FillRuleNonZero)
+		return
+	}
+	b.checkCoords(rect.MinX, rect.MinY, rect.MaxX, rect.MaxY)
+	if b.flattens(brush) && !axisAligned(b.currentTransform) {
+		// Rotated and sheared rectangles are flattened as paths.
+		b.fillPath(rectPath(rect), brush, recording.FillRuleNonZero)
+		return
+	}
+	brush = b.fillBrush(brush)
+	if b.flattens(brush) {
+		r := transformBBox(b.currentTransform, rect.MinX, rect.MinY, rect.MaxX, rect.MaxY)
+		rect = recording.NewRectFromPoints(r.minX, r.minY, r.maxX, r.maxY)
+		defer b.flatten()()
+	}
+	if b.invisible(brush) || emptyRect(rect) || b.culled(rect.MinX, rect.MinY, rect.MaxX, rect.MaxY) {
+		return
+	}
+
+	b.includeBounds(rect.MinX, rect.MinY, rect.MaxX, rect.MaxY)
+	b.addCutRect(rect.MinX, rect.MinY, rect.MaxX, rect.MaxY)
+	b.openElement("rect")
 	b.writeTransform()
 	b.writeClip()
 	b.builder.WriteString(fmt.Sprintf(` x="%g" y="%g" width="%g" height="%g"`,
 		rect.MinX, rect.MinY, rect.Width(), rect.Height()))
+	if b.cornerRadius > 0 {
+		r := math.Min(b.cornerRadius, math.Min(math.Abs(rect.Width()), math.Abs(rect.Height()))/2)
+		b.builder.WriteString(fmt.Sprintf(` rx="%g" ry="%g"`, r, r))
+	}
 	b.writeFill(brush)
 	b.builder.WriteString(` stroke="none"`)
 	b.builder.WriteString("/>")
+	b.closeElement(kindFill)
 }
 
 // DrawImage draws an image from the source rectangle to the destination rectangle.
 func (b *Backend) DrawImage(img image.Image, src, dst recording.Rect, opts recording.ImageOptions) {
+	b.advanceOp("DrawImage")
+	if b.overBudget {
+		return
+	}
 	if img == nil {
 		return
 	}
 
-	// Encode image to PNG and then to base64 data URI
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
+	b.checkCoords(dst.MinX, dst.MinY, dst.MaxX, dst.MaxY)
+	if m := b.currentTransform; b.flattens(nil) && axisAligned(m) && m.A > 0 && m.E > 0 {
+		r := transformBBox(m, dst.MinX, dst.MinY, dst.MaxX, dst.MaxY)
+		dst = recording.NewRectFromPoints(r.minX, r.minY, r.maxX, r.maxY)
+		defer b.flatten()()
+	}
+	if b.transparent(opts.Alpha) || emptyRect(dst) || b.culled(dst.MinX, dst.MinY, dst.MaxX, dst.MaxY) {
 		return
 	}
-	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
 
-	b.builder.WriteString("<image")
+	mark, attrs := b.builder.Len(), b.nextAttrs
+	b.openElement("image")
 	b.writeTransform()
 	b.writeClip()
 	b.builder.WriteString(fmt.Sprintf(` x="%g" y="%g" width="%g" height="%g"`,
 		dst.MinX, dst.MinY, dst.Width(), dst.Height()))
-	b.builder.WriteString(fmt.Sprintf(` href="%s"`, dataURI))
 
-	if opts.Alpha < 1.0 {
-		b.builder.WriteString(fmt.Sprintf(` opacity="%g"`, opts.Alpha))
+	// The PNG is base64-encoded straight into the document, so large
+	// images are never held in memory as PNG bytes or a string.
+	b.builder.WriteString(fmt.Sprintf(` %s="data:image/png;base64,`, b.hrefAttr()))
+	enc := base64.NewEncoder(base64.StdEncoding, &b.builder)
+	err := png.Encode(enc, img)
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		// Drop the partial element; the next element keeps its attributes.
+		b.builder.Truncate(mark)
+		b.nextAttrs = attrs
+		b.fail(ErrImageEncode, err)
+		return
+	}
+	b.builder.WriteString(`"`)
+
+	b.classifyLaser(false, nil, recording.Stroke{})
+	b.includeBounds(dst.MinX, dst.MinY, dst.MaxX, dst.MaxY)
+	b.addCutRect(dst.MinX, dst.MinY, dst.MaxX, dst.MaxY)
+
+	if b.adjustAlpha(opts.Alpha) < 1.0 {
+		b.builder.WriteString(fmt.Sprintf(` opacity="%s"`, b.opacity(opts.Alpha)))
 	}
 
-	b.builder.WriteString(` preserveAspectRatio="none"`)
+	aspect := b.imageAspectRatio
+	if aspect == "" {
+		aspect = AspectNone
+	}
+	b.builder.WriteString(fmt.Sprintf(` preserveAspectRatio="%s"`, escapeXML(string(aspect))))
 	b.builder.WriteString("/>")
+	b.closeElement(kindImage)
 }
 
 // DrawText draws text at the given position with the specified font face and brush.
 func (b *Backend) DrawText(s string, x, y float64, face text.Face, brush recording.Brush) {
-	b.builder.WriteString("<text")
-	b.writeTransform()
+	b.advanceOp("DrawText")
+	if b.overBudget {
+		return
+	}
+	b.checkCoords(x, y)
+	brush = b.fillBrush(brush)
+	if b.invisible(brush) {
+		return
+	}
+	if m := b.currentTransform; b.flattens(brush) && m.A == 1 && m.B == 0 && m.D == 0 && m.E == 1 {
+		x, y = x+m.C, y+m.F
+		defer b.flatten()()
+	}
+
+	lines := b.wrapLines(s, face)
+	if b.culledText(lines, x, y, face) {
+		return
+	}
+	b.includeText(lines, x, y, face)
+	b.trackFont(s, face)
+
+	b.openElement("text")
+	b.writeTextTransform(lines, x, y, face)
 	b.writeClip()
 	b.builder.WriteString(fmt.Sprintf(` x="%g" y="%g"`, x, y))
 
 	// Font settings
-	fontSize := 12.0
-	if face != nil {
-		fontSize = face.Size()
-		if fontSize <= 0 {
-			metrics := face.Metrics()
-			fontSize = metrics.LineHeight()
-			if fontSize <= 0 {
-				fontSize = 12.0
-			}
-		}
+	b.writeFont(face)
+	b.writeBaseline(face)
+	b.writeTextDirection(s, face)
+	if len(lines) == 1 {
+		b.writeTextLength(s, face)
 	}
-	b.builder.WriteString(fmt.Sprintf(` font-size="%g"`, fontSize))
 
 	// Fill color
 	b.writeFill(brush)
 
 	b.builder.WriteString(">")
-	b.builder.WriteString(escapeXML(s))
+	if len(lines) == 1 {
+		b.builder.WriteString(escapeXML(s))
+	} else {
+		b.writeTextLines(lines, x, face)
+	}
 	b.builder.WriteString("</text>")
+	b.closeElement(kindText)
+}
+
+// buffersOutput reports whether WriteTo builds the whole document in
+// memory before writing it, to run optimizer passes, post-processors,
+// the signer or the script scrubber on it.
+func (b *Backend) buffersOutput() bool {
+	return len(b.postProcessors) > 0 || len(b.passes) > 0 || b.signer != nil || b.scriptless()
 }
 
 // WriteTo writes the SVG to the given writer.
 // This implements recording.WriterBackend.
 func (b *Backend) WriteTo(w io.Writer) (int64, error) {
+	b.contentHash = ""
+	if err := b.strictErr(); err != nil {
+		return 0, err
+	}
+	hw := newHashingWriter(w)
+	if !b.buffersOutput() {
+		n, err := b.writeDocument(hw)
+		if err != nil {
+			return n, err
+		}
+		b.contentHash = hw.sum()
+		return n, b.exportErr()
+	}
+
+	data, err := b.postProcess()
+	if err != nil {
+		return 0, err
+	}
+	if b.signer != nil {
+		if err := b.sign(data); err != nil {
+			return 0, err
+		}
+	}
+	n, err := hw.Write(data)
+	if err != nil {
+		return int64(n), err
+	}
+	b.contentHash = hw.sum()
+	return int64(n), b.exportErr()
+}
+
+// Bytes returns the SVG document as written by WriteTo, or nil if it
+// cannot be written, e.g. in strict mode after a failed operation. A
+// document with missing content is returned as written; Err reports what
+// is missing.
+func (b *Backend) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.Grow(b.builder.Len() + b.defs.Len() + 256)
+	if _, err := b.WriteTo(&buf); err != nil && buf.Len() == 0 {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// String returns the SVG document as written by WriteTo, or "" if it
+// cannot be written.
+func (b *Backend) String() string {
+	return string(b.Bytes())
+}
+
+// MarshalText returns the SVG document as written by WriteTo.
+// This implements encoding.TextMarshaler.
+func (b *Backend) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(b.builder.Len() + b.defs.Len() + 256)
+	if _, err := b.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeDocument serializes the SVG document to w.
+func (b *Backend) writeDocument(w io.Writer) (int64, error) {
+	// Strokes deferred by WithToolpathOrdering are normally written by
+	// End; flush them here so a document written without End keeps them.
+	b.flushToolpaths()
+
 	var total int64
+	background := b.backgroundRect()
 
 	// Write SVG header
-	header := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="0 0 %d %d">
-`, b.width, b.height, b.width, b.height)
-	n, err := w.Write([]byte(header))
+	n, err := w.Write([]byte(b.header()))
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+
+	// Write document title, description and metadata
+	n, err = w.Write([]byte(b.documentInfo()))
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+
+	// Write CSS classes collected with StyleClasses
+	n, err = w.Write([]byte(b.styleBlock()))
 	total += int64(n)
 	if err != nil {
 		return total, err
 	}
 
 	// Write definitions if any
-	defs := b.defs.String()
+	defs := b.patternDefs() + b.defs.String()
+	hardClip := b.hardClip && !b.tiny()
+	if hardClip {
+		defs += b.canvasClip()
+	}
 	if defs != "" {
 		n, err = w.Write([]byte("<defs>"))
 		total += int64(n)
@@ -301,20 +833,69 @@ func (b *Backend) WriteTo(w io.Writer) (int64, error) {
 	}
 
 	// Write content
-	n, err = w.Write([]byte(b.builder.String()))
+	rotation := b.documentGroup()
+	n, err = w.Write([]byte(rotation + background))
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+	if hardClip {
+		n, err = fmt.Fprintf(w, `<g clip-path="url(#%s)">`, b.idPrefix+canvasClipID)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	n, err = w.Write(b.builder.Bytes())
 	total += int64(n)
 	if err != nil {
 		return total, err
 	}
+	m, err := b.writeLayers(w)
+	total += m
+	if err != nil {
+		return total, err
+	}
+
+	// Close any unclosed groups and links
+	for i := len(b.containers) - 1; i >= 0; i-- {
+		n, err = w.Write([]byte(b.containers[i].kind.endTag()))
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
 
-	// Close any unclosed groups
-	for i := 0; i < b.groupDepth; i++ {
+	if hardClip {
 		n, err = w.Write([]byte("</g>"))
 		total += int64(n)
 		if err != nil {
 			return total, err
 		}
 	}
+	n, err = w.Write([]byte(b.cutlineGroup()))
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+	if rotation != "" {
+		n, err = w.Write([]byte("</g>"))
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	n, err = w.Write([]byte(b.cropMarksGroup()))
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
+
+	n, err = w.Write([]byte(b.scriptBlock()))
+	total += int64(n)
+	if err != nil {
+		return total, err
+	}
 
 	// Write SVG footer
 	n, err = w.Write([]byte("\n</svg>\n"))
@@ -322,69 +903,185 @@ func (b *Backend) WriteTo(w io.Writer) (int64, error) {
 	return total, err
 }
 
+// header returns the prolog and the opening <svg> tag.
+func (b *Backend) header() string {
+	var h strings.Builder
+	h.WriteString(b.prolog())
+	h.WriteString("<svg" + b.namespaceAttrs())
+	if b.tiny() {
+		h.WriteString(` version="1.2" baseProfile="tiny"`)
+	} else if b.version == SVG11 {
+		h.WriteString(` version="1.1"`)
+	}
+	h.WriteString(b.sizeAttrs())
+	if b.usesInkscape || len(b.cutShapes) > 0 || b.cropMarks || b.registersNamespace("inkscape") {
+		h.WriteString(` xmlns:inkscape="` + inkscapeNS + `"`)
+	}
+	if b.aspectRatio != "" {
+		h.WriteString(fmt.Sprintf(` preserveAspectRatio="%s"`, escapeXML(string(b.aspectRatio))))
+	}
+	if b.overflow != OverflowDefault {
+		h.WriteString(fmt.Sprintf(` overflow="%s"`, b.overflow))
+	}
+	h.WriteString(b.rootAria.attrs())
+	h.WriteString(">\n")
+	return h.String()
+}
+
 // SaveToFile saves the SVG to a file at the given path.
 // This implements recording.FileBackend.
 func (b *Backend) SaveToFile(path string) error {
+	return b.saveToFile(path, func(w io.Writer) error {
+		_, err := b.WriteTo(w)
+		return err
+	})
+}
+
+// saveToFile creates path, writes the document to it with write and saves
+// the detached signature next to it.
+func (b *Backend) saveToFile(path string, write func(io.Writer) error) error {
 	f, err := os.Create(path) //nolint:gosec // Path is provided by user code
 	if err != nil {
 		return err
 	}
 
-	_, writeErr := b.WriteTo(f)
+	writeErr := write(f)
 	closeErr := f.Close()
 
 	if writeErr != nil {
 		return writeErr
 	}
-	return closeErr
+	if closeErr != nil || b.signer == nil {
+		return closeErr
+	}
+	return os.WriteFile(path+".sig", b.signature, 0o644) //nolint:gosec // Signatures are public
 }
 
 // nextID generates a unique ID for SVG elements.
-func (b *Backend) nextID(prefix string) string {
+// IDs depend only on the order of calls since Begin.
+func (b *Backend) nextID(kind string) string {
 	b.idCounter++
-	return fmt.Sprintf("%s%d", prefix, b.idCounter)
+	return fmt.Sprintf("%s%s%d", b.idPrefix, kind, b.idCounter)
 }
 
 // pathToD converts a gg.Path to an SVG path data string.
 func (b *Backend) pathToD(path *gg.Path) string {
-	var d strings.Builder
+	d := getScratch()
+	defer putScratch(d)
+	d.Write(appendPathData(d.AvailableBuffer(), b.simplifyPath(path)))
+	return d.String()
+}
 
+// appendPathData appends the SVG path data of path to dst.
+func appendPathData(dst []byte, path *gg.Path) []byte {
 	for _, elem := range path.Elements() {
 		switch e := elem.(type) {
 		case gg.MoveTo:
-			d.WriteString(fmt.Sprintf("M%g %g", e.Point.X, e.Point.Y))
+			dst = appendPoints(append(dst, 'M'), e.Point)
 		case gg.LineTo:
-			d.WriteString(fmt.Sprintf("L%g %g", e.Point.X, e.Point.Y))
+			dst = appendPoints(append(dst, 'L'), e.Point)
 		case gg.QuadTo:
-			d.WriteString(fmt.Sprintf("Q%g %g %g %g",
-				e.Control.X, e.Control.Y, e.Point.X, e.Point.Y))
+			dst = appendPoints(append(dst, 'Q'), e.Control, e.Point)
 		case gg.CubicTo:
-			d.WriteString(fmt.Sprintf("C%g %g %g %g %g %g",
-				e.Control1.X, e.Control1.Y,
-				e.Control2.X, e.Control2.Y,
-				e.Point.X, e.Point.Y))
+			dst = appendPoints(append(dst, 'C'), e.Control1, e.Control2, e.Point)
 		case gg.Close:
-			d.WriteString("Z")
+			dst = append(dst, 'Z')
 		}
 	}
+	return dst
+}
 
-	return d.String()
+// appendPoints appends the coordinates of points, separated by spaces.
+func appendPoints(dst []byte, points ...gg.Point) []byte {
+	for i, p := range points {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = appendNumber(dst, p.X)
+		dst = append(dst, ' ')
+		dst = appendNumber(dst, p.Y)
+	}
+	return dst
+}
+
+// appendNumber appends v in the shortest form that round-trips.
+func appendNumber(dst []byte, v float64) []byte {
+	return svgwriter.AppendNumber(dst, v, -1)
+}
+
+// writePathData writes the d attribute of a path element.
+func (b *Backend) writePathData(path *gg.Path) {
+	buf := append(b.builder.AvailableBuffer(), ` d="`...)
+	buf = appendPathData(buf, b.simplifyPath(path))
+	b.builder.Write(append(buf, '"'))
+}
+
+// writeAttr writes an attribute whose value needs no escaping.
+func (b *Backend) writeAttr(name, value string) {
+	buf := append(b.builder.AvailableBuffer(), ' ')
+	buf = append(buf, name...)
+	buf = append(buf, `="`...)
+	buf = append(buf, value...)
+	b.builder.Write(append(buf, '"'))
+}
+
+// writeNumberAttr writes a numeric attribute.
+func (b *Backend) writeNumberAttr(name string, v float64) {
+	buf := append(b.builder.AvailableBuffer(), ' ')
+	buf = append(buf, name...)
+	buf = append(buf, `="`...)
+	buf = appendNumber(buf, v)
+	b.builder.Write(append(buf, '"'))
+}
+
+// writeURLAttr writes a paint or clip attribute referencing the element
+// with the given ID.
+func (b *Backend) writeURLAttr(name, id string) {
+	buf := append(b.builder.AvailableBuffer(), ' ')
+	buf = append(buf, name...)
+	buf = append(buf, `="url(#`...)
+	buf = append(buf, id...)
+	b.builder.Write(append(buf, `)"`...))
 }
 
 // writeTransform writes the transform attribute if not identity.
 func (b *Backend) writeTransform() {
-	m := b.currentTransform
-	if m.IsIdentity() {
+	if b.currentTransform.IsIdentity() {
 		return
 	}
-	b.builder.WriteString(fmt.Sprintf(` transform="matrix(%g,%g,%g,%g,%g,%g)"`,
-		m.A, m.B, m.D, m.E, m.C, m.F))
+	b.writeMatrixAttr(b.currentTransform)
+}
+
+// writeMatrixAttr writes m as a transform attribute.
+func (b *Backend) writeMatrixAttr(m recording.Matrix) {
+	buf := append(b.builder.AvailableBuffer(), ` transform="`...)
+	buf = appendMatrix(buf, m)
+	b.builder.Write(append(buf, '"'))
+}
+
+// matrixValue formats m as an SVG matrix() transform.
+func matrixValue(m recording.Matrix) string {
+	return string(appendMatrix(nil, m))
+}
+
+// appendMatrix appends m as an SVG matrix() transform.
+func appendMatrix(dst []byte, m recording.Matrix) []byte {
+	// gg maps x' = A*x + B*y + C, y' = D*x + E*y + F, while SVG's
+	// matrix(a,b,c,d,e,f) maps x' = a*x + c*y + e, y' = b*x + d*y + f.
+	dst = append(dst, "matrix("...)
+	for i, v := range [6]float64{m.A, m.D, m.B, m.E, m.C, m.F} {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendNumber(dst, v)
+	}
+	return append(dst, ')')
 }
 
 // writeClip writes the clip-path attribute if set.
 func (b *Backend) writeClip() {
 	if b.currentClipID != "" {
-		b.builder.WriteString(fmt.Sprintf(` clip-path="url(#%s)"`, b.currentClipID))
+		b.writeURLAttr("clip-path", b.currentClipID)
 	}
 }
 
@@ -392,29 +1089,34 @@ func (b *Backend) writeClip() {
 func (b *Backend) writeFill(brush recording.Brush) {
 	switch br := brush.(type) {
 	case recording.SolidBrush:
-		b.builder.WriteString(fmt.Sprintf(` fill="%s"`, colorToCSS(br.Color)))
-		if br.Color.A < 1.0 {
-			b.builder.WriteString(fmt.Sprintf(` fill-opacity="%g"`, br.Color.A))
+		b.writeAttr("fill", b.paintColor(br.Color))
+		if br.Color.A < 1.0 && !b.paintsAlpha(br.Color) {
+			b.writeAttr("fill-opacity", b.opacity(br.Color.A))
 		}
 
+	case PatternBrush:
+		b.writeURLAttr("fill", b.patternID(br.Name))
+
 	case *recording.LinearGradientBrush:
 		gradID := b.addLinearGradient(br)
-		b.builder.WriteString(fmt.Sprintf(` fill="url(#%s)"`, gradID))
+		b.writeURLAttr("fill", gradID)
 
 	case *recording.RadialGradientBrush:
 		gradID := b.addRadialGradient(br)
-		b.builder.WriteString(fmt.Sprintf(` fill="url(#%s)"`, gradID))
+		b.writeURLAttr("fill", gradID)
 
 	case *recording.SweepGradientBrush:
 		// SVG doesn't support sweep gradients directly
 		// Fallback to first stop color
+		b.unsupportedBrush(br)
 		if len(br.Stops) > 0 {
-			b.builder.WriteString(fmt.Sprintf(` fill="%s"`, colorToCSS(br.Stops[0].Color)))
+			b.writeAttr("fill", b.paintColor(br.Stops[0].Color))
 		} else {
 			b.builder.WriteString(` fill="black"`)
 		}
 
 	default:
+		b.unsupportedBrush(br)
 		b.builder.WriteString(` fill="black"`)
 	}
 }
@@ -424,25 +1126,30 @@ func (b *Backend) writeStroke(brush recording.Brush, stroke recording.Stroke) {
 	// Stroke color
 	switch br := brush.(type) {
 	case recording.SolidBrush:
-		b.builder.WriteString(fmt.Sprintf(` stroke="%s"`, colorToCSS(br.Color)))
-		if br.Color.A < 1.0 {
-			b.builder.WriteString(fmt.Sprintf(` stroke-opacity="%g"`, br.Color.A))
+		b.writeAttr("stroke", b.paintColor(br.Color))
+		if br.Color.A < 1.0 && !b.paintsAlpha(br.Color) {
+			b.writeAttr("stroke-opacity", b.opacity(br.Color.A))
 		}
 
+	case PatternBrush:
+		b.writeURLAttr("stroke", b.patternID(br.Name))
+
 	case *recording.LinearGradientBrush:
 		gradID := b.addLinearGradient(br)
-		b.builder.WriteString(fmt.Sprintf(` stroke="url(#%s)"`, gradID))
+		b.writeURLAttr("stroke", gradID)
 
 	case *recording.RadialGradientBrush:
 		gradID := b.addRadialGradient(br)
-		b.builder.WriteString(fmt.Sprintf(` stroke="url(#%s)"`, gradID))
+		b.writeURLAttr("stroke", gradID)
 
 	default:
+		b.unsupportedBrush(br)
 		b.builder.WriteString(` stroke="black"`)
 	}
 
 	// Stroke width
-	b.builder.WriteString(fmt.Sprintf(` stroke-width="%g"`, stroke.Width))
+	width := b.remapStrokeWidth(stroke.Width)
+	b.writeNumberAttr("stroke-width", width)
 
 	// Line cap
 	switch stroke.Cap {
@@ -463,19 +1170,26 @@ func (b *Backend) writeStroke(brush recording.Brush, stroke recording.Stroke) {
 	default:
 		b.builder.WriteString(` stroke-linejoin="miter"`)
 		if stroke.MiterLimit > 0 {
-			b.builder.WriteString(fmt.Sprintf(` stroke-miterlimit="%g"`, stroke.MiterLimit))
+			b.writeNumberAttr("stroke-miterlimit", stroke.MiterLimit)
 		}
 	}
 
 	// Dash pattern
 	if len(stroke.DashPattern) > 0 {
-		dashStrs := make([]string, len(stroke.DashPattern))
+		scale := 1.0
+		if b.dashUnits == DashStrokeWidths {
+			scale = width
+		}
+		buf := append(b.builder.AvailableBuffer(), ` stroke-dasharray="`...)
 		for i, v := range stroke.DashPattern {
-			dashStrs[i] = fmt.Sprintf("%g", v)
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = appendNumber(buf, v*scale)
 		}
-		b.builder.WriteString(fmt.Sprintf(` stroke-dasharray="%s"`, strings.Join(dashStrs, " ")))
+		b.builder.Write(append(buf, '"'))
 		if stroke.DashOffset != 0 {
-			b.builder.WriteString(fmt.Sprintf(` stroke-dashoffset="%g"`, stroke.DashOffset))
+			b.writeNumberAttr("stroke-dashoffset", stroke.DashOffset*scale)
 		}
 	}
 }
@@ -491,25 +1205,23 @@ func (b *Backend) addLinearGradient(br *recording.LinearGradientBrush) string {
 
 	// Use userSpaceOnUse for absolute coordinates
 	b.defs.WriteString(fmt.Sprintf(
-		`<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%g" y1="%g" x2="%g" y2="%g">`,
+		`<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%g" y1="%g" x2="%g" y2="%g"`,
 		gradID, br.Start.X, br.Start.Y, br.End.X, br.End.Y))
 
 	// Handle spread mode
 	if length > 0 {
-		switch br.Extend {
-		case recording.ExtendRepeat:
-			b.defs.WriteString(` spreadMethod="repeat"`)
-		case recording.ExtendReflect:
-			b.defs.WriteString(` spreadMethod="reflect"`)
-		}
+		b.writeSpreadMethod(br.Extend)
 	}
+	b.defs.WriteString(">")
 
 	for _, stop := range br.Stops {
+		c := stop.Color
+		c.A = b.adjustAlpha(c.A)
 		b.defs.WriteString(fmt.Sprintf(
 			`<stop offset="%g" stop-color="%s"`,
-			stop.Offset, colorToCSS(stop.Color)))
-		if stop.Color.A < 1.0 {
-			b.defs.WriteString(fmt.Sprintf(` stop-opacity="%g"`, stop.Color.A))
+			stop.Offset, b.formatColor(c)))
+		if c.A < 1.0 && !b.alphaInColor(c) {
+			b.defs.WriteString(fmt.Sprintf(` stop-opacity="%s"`, b.opacity(c.A)))
 		}
 		b.defs.WriteString(`/>`)
 	}
@@ -518,28 +1230,55 @@ func (b *Backend) addLinearGradient(br *recording.LinearGradientBrush) string {
 	return gradID
 }
 
+// writeSpreadMethod writes the spreadMethod attribute of a gradient
+// definition for non-default extend modes.
+func (b *Backend) writeSpreadMethod(extend recording.ExtendMode) {
+	if b.tiny() {
+		return
+	}
+	switch extend {
+	case recording.ExtendRepeat:
+		b.defs.WriteString(` spreadMethod="repeat"`)
+	case recording.ExtendReflect:
+		b.defs.WriteString(` spreadMethod="reflect"`)
+	}
+}
+
 // addRadialGradient adds a radial gradient definition and returns its ID.
 func (b *Backend) addRadialGradient(br *recording.RadialGradientBrush) string {
 	gradID := b.nextID("rg")
 
 	b.defs.WriteString(fmt.Sprintf(
-		`<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%g" cy="%g" r="%g" fx="%g" fy="%g">`,
-		gradID, br.Center.X, br.Center.Y, br.EndRadius, br.Focus.X, br.Focus.Y))
+		`<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%g" cy="%g" r="%g"`,
+		gradID, br.Center.X, br.Center.Y, br.EndRadius))
+	if !b.tiny() {
+		b.defs.WriteString(fmt.Sprintf(` fx="%g" fy="%g"`, br.Focus.X, br.Focus.Y))
+	}
+	// Without fr, the gradient starts at the center; move the stops out
+	// to where they fall between the start and end radius.
+	offset := func(o float64) float64 { return o }
+	if br.StartRadius > 0 && br.EndRadius > 0 {
+		if b.svg2() {
+			b.defs.WriteString(fmt.Sprintf(` fr="%g"`, br.StartRadius))
+		} else {
+			offset = func(o float64) float64 {
+				return (br.StartRadius + o*(br.EndRadius-br.StartRadius)) / br.EndRadius
+			}
+		}
+	}
 
 	// Handle spread mode
-	switch br.Extend {
-	case recording.ExtendRepeat:
-		b.defs.WriteString(` spreadMethod="repeat"`)
-	case recording.ExtendReflect:
-		b.defs.WriteString(` spreadMethod="reflect"`)
-	}
+	b.writeSpreadMethod(br.Extend)
+	b.defs.WriteString(">")
 
 	for _, stop := range br.Stops {
+		c := stop.Color
+		c.A = b.adjustAlpha(c.A)
 		b.defs.WriteString(fmt.Sprintf(
 			`<stop offset="%g" stop-color="%s"`,
-			stop.Offset, colorToCSS(stop.Color)))
-		if stop.Color.A < 1.0 {
-			b.defs.WriteString(fmt.Sprintf(` stop-opacity="%g"`, stop.Color.A))
+			offset(stop.Offset), b.formatColor(c)))
+		if c.A < 1.0 && !b.alphaInColor(c) {
+			b.defs.WriteString(fmt.Sprintf(` stop-opacity="%s"`, b.opacity(c.A)))
 		}
 		b.defs.WriteString(`/>`)
 	}
@@ -548,23 +1287,39 @@ func (b *Backend) addRadialGradient(br *recording.RadialGradientBrush) string {
 	return gradID
 }
 
+// fontSize returns the font size to emit for a face.
+// The resolver installed with WithFontSizeResolver is consulted first.
+// Otherwise it falls back to the line height and then to the default
+// size when the face does not report a usable size.
+func (b *Backend) fontSize(face text.Face) float64 {
+	if b.fontSizeResolver != nil {
+		if size := b.fontSizeResolver(face); size > 0 {
+			return size
+		}
+	}
+	if face != nil {
+		if s := face.Size(); s > 0 {
+			return s
+		}
+		if s := face.Metrics().LineHeight(); s > 0 {
+			return s
+		}
+	}
+	if c, ok := b.sourceText(); ok && c.FontSize > 0 {
+		return c.FontSize
+	}
+	return b.defaultFontSize
+}
+
 // colorToCSS converts an RGBA color to CSS color string.
 // gg.RGBA uses float64 values in the range [0, 1].
 func colorToCSS(c gg.RGBA) string {
-	r := int(c.R * 255)
-	g := int(c.G * 255)
-	b := int(c.B * 255)
-	return fmt.Sprintf("rgb(%d,%d,%d)", r, g, b)
+	return string(appendRGB(make([]byte, 0, len("rgb(255,255,255)")), channelByte(c.R), channelByte(c.G), channelByte(c.B)))
 }
 
 // escapeXML escapes special XML characters.
 func escapeXML(s string) string {
-	s = strings.ReplaceAll(s, "&", "&amp;")
-	s = strings.ReplaceAll(s, "<", "&lt;")
-	s = strings.ReplaceAll(s, ">", "&gt;")
-	s = strings.ReplaceAll(s, "\"", "&quot;")
-	s = strings.ReplaceAll(s, "'", "&apos;")
-	return s
+	return svgwriter.Escape(s)
 }
 
 // Ensure Backend implements the required interfaces.
@@ -572,4 +1327,6 @@ var (
 	_ recording.Backend       = (*Backend)(nil)
 	_ recording.WriterBackend = (*Backend)(nil)
 	_ recording.FileBackend   = (*Backend)(nil)
+	_ encoding.TextMarshaler  = (*Backend)(nil)
+	_ fmt.Stringer            = (*Backend)(nil)
 )
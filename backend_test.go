@@ -2,6 +2,10 @@ package svg
 
 import (
 	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
@@ -85,6 +89,53 @@ func TestBackendSaveRestore(t *testing.T) {
 	_ = backend.End()
 }
 
+func TestBackendSaveRestoreGroups(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	brush := recording.NewSolidBrush(gg.Red)
+	rect := func(x float64) { backend.FillRect(recording.NewRect(x, 0, 1, 1), brush) }
+
+	// Empty, including nested empty groups.
+	backend.Save()
+	backend.Save()
+	backend.Restore()
+	backend.Restore()
+
+	// A single child, through a nested group.
+	backend.Save()
+	backend.Save()
+	rect(1)
+	backend.Restore()
+	backend.Restore()
+
+	// Two children are kept in their group.
+	backend.Save()
+	rect(2)
+	backend.Save()
+	rect(3)
+	backend.Restore()
+	backend.Restore()
+
+	// A raw fragment may hold several elements.
+	backend.Save()
+	_ = backend.WriteRaw(`<circle r="1"/><circle r="2"/>`)
+	backend.Restore()
+
+	out := backend.String()
+	if strings.Count(out, "<g>") != 2 || strings.Count(out, "</g>") != 2 {
+		t.Errorf("Only groups with several children should be kept, got:\n%s", out)
+	}
+	if !strings.Contains(out, "\n"+`<rect x="1"`) {
+		t.Errorf("A single child should replace its group, got:\n%s", out)
+	}
+	if !strings.Contains(out, `<g><rect x="2" y="0" width="1" height="1" fill="rgb(255,0,0)" stroke="none"/><rect x="3"`) {
+		t.Errorf("The collapsed inner group should stay in order, got:\n%s", out)
+	}
+	if !strings.Contains(out, `<g><circle r="1"/><circle r="2"/></g>`) {
+		t.Errorf("Groups around raw fragments should be kept, got:\n%s", out)
+	}
+}
+
 func TestBackendFillPath(t *testing.T) {
 	backend := NewBackend()
 	err := backend.Begin(400, 300)
@@ -390,6 +441,26 @@ func TestBackendTransform(t *testing.T) {
 	}
 }
 
+func TestBackendTransformOrder(t *testing.T) {
+	// A shear has different B and D, so swapped matrix entries show.
+	m := recording.Matrix{A: 1, B: 0.5, C: 10, D: 0.25, E: 1, F: 20}
+
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.SetTransform(m)
+	backend.FillRect(recording.Rect{MaxX: 1, MaxY: 1}, recording.NewSolidBrush(gg.Black))
+
+	// SVG's matrix(a,b,c,d,e,f) maps (x, y) to (a*x+c*y+e, b*x+d*y+f).
+	x, y := m.TransformPoint(2, 3)
+	a, b, c, d, e, f := 1.0, 0.25, 0.5, 1.0, 10.0, 20.0
+	if x != a*2+c*3+e || y != b*2+d*3+f {
+		t.Fatalf("Test matrix does not match gg's convention")
+	}
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `transform="matrix(1,0.25,0.5,1,10,20)"`) {
+		t.Errorf("Transform should be written in SVG order, got:\n%s", svg)
+	}
+}
+
 func TestBackendSaveToFile(t *testing.T) {
 	backend := NewBackend()
 	err := backend.Begin(400, 300)
@@ -569,7 +640,8 @@ func TestColorToCSS(t *testing.T) {
 		{gg.RGBA{R: 1, G: 0, B: 0, A: 1}, "rgb(255,0,0)"},
 		{gg.RGBA{R: 0, G: 1, B: 0, A: 1}, "rgb(0,255,0)"},
 		{gg.RGBA{R: 0, G: 0, B: 1, A: 1}, "rgb(0,0,255)"},
-		{gg.RGBA{R: 0.5, G: 0.5, B: 0.5, A: 1}, "rgb(127,127,127)"},
+		{gg.RGBA{R: 0.5, G: 0.5, B: 0.5, A: 1}, "rgb(128,128,128)"},
+		{gg.RGBA{R: 1.2, G: -0.1, B: 0.999, A: 1}, "rgb(255,0,255)"},
 	}
 
 	for _, tt := range tests {
@@ -666,3 +738,117 @@ func BenchmarkBackendStrokePath(b *testing.B) {
 		backend.StrokePath(path, brush, stroke)
 	}
 }
+
+// writeSVG finalizes the backend and returns the written document.
+func writeSVG(t *testing.T, backend *Backend) string {
+	t.Helper()
+
+	if err := backend.End(); err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := backend.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestBackendBytes(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(10, 10)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+
+	expected := writeSVG(t, backend)
+	if got := string(backend.Bytes()); got != expected {
+		t.Errorf("Bytes() = %s, expected %s", got, expected)
+	}
+	if got := backend.String(); got != expected {
+		t.Errorf("String() = %s, expected %s", got, expected)
+	}
+	if got, err := backend.MarshalText(); err != nil || string(got) != expected {
+		t.Errorf("MarshalText() = %s, %v", got, err)
+	}
+
+	strict := NewBackend(WithStrict(true))
+	_ = strict.Begin(10, 10)
+	strict.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSweepGradientBrush(5, 5, 0))
+	if _, err := strict.MarshalText(); err == nil {
+		t.Error("MarshalText() should report failed operations in strict mode")
+	}
+	if strict.Bytes() != nil {
+		t.Error("Bytes() should be nil when the document cannot be written")
+	}
+}
+
+func TestFillPathAllocations(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(800, 600)
+	path := gg.NewPath()
+	path.MoveTo(0.5, -0.0)
+	path.CubicTo(10, 20, 30, 40, 50, 60)
+	path.Close()
+	brush := recording.NewSolidBrush(gg.RGBA{R: 1, G: 0.5, B: 0, A: 0.5})
+	stroke := recording.DefaultStroke()
+	stroke.DashPattern = []float64{4, 2}
+	backend.FillPath(path, brush, recording.FillRuleNonZero)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		backend.FillPath(path, brush, recording.FillRuleNonZero)
+		backend.StrokePath(path, brush, stroke)
+	})
+	if allocs > 8 {
+		t.Errorf("FillPath and StrokePath allocate %v times, expected at most 8", allocs)
+	}
+
+	out := backend.String()
+	if !strings.Contains(out, `d="M0.5 0C10 20 30 40 50 60Z"`) || !strings.Contains(out, `stroke-dasharray="4 2"`) {
+		t.Errorf("Unexpected path output: %s", out)
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.DrawImage(img, recording.Rect{}, recording.NewRect(10, 10, 30, 20), recording.ImageOptions{Alpha: 1})
+	out := backend.String()
+
+	const prefix = `href="data:image/png;base64,`
+	start := strings.Index(out, prefix)
+	if start < 0 {
+		t.Fatalf("Image should be embedded as a data URI: %s", out)
+	}
+	data := out[start+len(prefix):]
+	data = data[:strings.IndexByte(data, '"')]
+	decoded, err := png.Decode(base64.NewDecoder(base64.StdEncoding, strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("Embedded image does not decode: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() || color.NRGBAModel.Convert(decoded.At(123, 45)) != img.At(123, 45) {
+		t.Error("Embedded image should match the drawn image")
+	}
+}
+
+func TestDrawImageEncodeFailure(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.SetNextAttrs("target")
+	backend.DrawImage(hugeImage{image.NewRGBA(image.Rect(0, 0, 1, 1))},
+		recording.Rect{}, recording.NewRect(0, 0, 1, 1), recording.ImageOptions{Alpha: 1})
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+
+	out := backend.String()
+	if strings.Contains(out, "<image") || strings.Contains(out, "base64") {
+		t.Errorf("A failed image should leave no partial element: %s", out)
+	}
+	if !strings.Contains(out, `<rect id="target"`) {
+		t.Errorf("The next element should keep the pending attributes: %s", out)
+	}
+	if backend.Err() == nil {
+		t.Error("The encoding failure should be reported")
+	}
+}
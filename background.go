@@ -0,0 +1,55 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogpu/gg"
+)
+
+// WithBackground fills the canvas with c before any drawn content, as the
+// raster backends do when they clear to a color. Without it exports have
+// a transparent background.
+//
+// The background is written as a <rect> covering the viewBox, ahead of
+// all structure groups and layers, and is not passed to element hooks.
+// Theme mappings apply to its color.
+func WithBackground(c gg.RGBA) Option {
+	return func(b *Backend) {
+		b.background, b.hasBackground = c, true
+	}
+}
+
+// backgroundRect returns the background element, or "" without a
+// background.
+func (b *Backend) backgroundRect() string {
+	if !b.hasBackground {
+		return ""
+	}
+	c := b.background
+	c.A = b.adjustAlpha(c.A)
+
+	var r strings.Builder
+	x, y, w, h := b.viewBox()
+	if b.cropMarks {
+		// The background runs into the bleed.
+		x, y, w, h = x-b.bleed, y-b.bleed, w+2*b.bleed, h+2*b.bleed
+	}
+	if x != 0 || y != 0 {
+		r.WriteString(fmt.Sprintf(`<rect x="%g" y="%g" width="%g" height="%g"`, x, y, w, h))
+	} else {
+		r.WriteString(fmt.Sprintf(`<rect width="%g" height="%g"`, w, h))
+	}
+	fill := b.paintColor(c)
+	if strings.HasPrefix(fill, "var(") {
+		// CSS variables are not resolved in presentation attributes.
+		r.WriteString(fmt.Sprintf(` style="fill:%s"`, fill))
+	} else {
+		r.WriteString(fmt.Sprintf(` fill="%s"`, fill))
+	}
+	if c.A < 1.0 && !b.paintsAlpha(c) {
+		r.WriteString(fmt.Sprintf(` fill-opacity="%s"`, b.opacity(c.A)))
+	}
+	r.WriteString("/>")
+	return r.String()
+}
@@ -0,0 +1,40 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithBackground(t *testing.T) {
+	backend := NewBackend(WithBackground(gg.RGBA{R: 1, G: 1, B: 1, A: 1}), WithStructure(StructureByOp))
+	_ = backend.Begin(100, 50)
+	backend.FillRect(recording.Rect{MinX: 10, MinY: 10, MaxX: 20, MaxY: 20}, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<rect width="100" height="50" fill="rgb(255,255,255)"/><g id="fills">`) {
+		t.Errorf("Background should precede all content, got:\n%s", svg)
+	}
+}
+
+func TestWithBackgroundOpacityAndTheme(t *testing.T) {
+	white := gg.RGBA{R: 1, G: 1, B: 1, A: 0.5}
+	backend := NewBackend(WithBackground(white))
+	backend.ThemeColor(white, "page")
+	_ = backend.Begin(10, 10)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<rect width="10" height="10" style="fill:var(--page,rgb(255,255,255))" fill-opacity="0.5"/>`) {
+		t.Errorf("Themed background should use a style attribute, got:\n%s", svg)
+	}
+}
+
+func TestNoBackground(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(10, 10)
+	if svg := writeSVG(t, backend); strings.Contains(svg, "<rect") {
+		t.Errorf("Default export should be transparent, got:\n%s", svg)
+	}
+}
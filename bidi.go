@@ -0,0 +1,86 @@
+package svg
+
+import (
+	"unicode"
+
+	"github.com/gogpu/gg/text"
+)
+
+// bidiClass describes the directionality of a text run.
+type bidiClass int
+
+const (
+	// bidiLTR is text with no right-to-left characters.
+	bidiLTR bidiClass = iota
+	// bidiRTL is text whose strong characters are all right-to-left.
+	bidiRTL
+	// bidiMixed is text containing both left-to-right and right-to-left characters.
+	bidiMixed
+)
+
+// rtlScripts lists the scripts written right-to-left.
+var rtlScripts = []*unicode.RangeTable{
+	unicode.Arabic,
+	unicode.Hebrew,
+	unicode.Syriac,
+	unicode.Thaana,
+	unicode.Nko,
+	unicode.Samaritan,
+	unicode.Mandaic,
+}
+
+// classifyBidi scans s for strong directional characters.
+// The face direction is used for strings without any strong characters.
+func classifyBidi(s string, face text.Face) bidiClass {
+	hasLTR, hasRTL := false, false
+	for _, r := range s {
+		switch {
+		case unicode.In(r, rtlScripts...):
+			hasRTL = true
+		case unicode.IsLetter(r):
+			hasLTR = true
+		}
+	}
+
+	switch {
+	case hasRTL && hasLTR:
+		return bidiMixed
+	case hasRTL:
+		return bidiRTL
+	case !hasLTR && face != nil && face.Direction() == text.DirectionRTL:
+		return bidiRTL
+	default:
+		return bidiLTR
+	}
+}
+
+// baseIsRTL reports whether the first strong character of s is right-to-left.
+func baseIsRTL(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, rtlScripts...) {
+			return true
+		}
+		if unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return false
+}
+
+// writeTextDirection writes direction attributes for right-to-left and
+// mixed-direction text.
+//
+// The recorded x coordinate is the left edge of the run (as in the raster
+// backends), so right-to-left text is anchored at its end, which is the
+// left edge in an rtl context.
+func (b *Backend) writeTextDirection(s string, face text.Face) {
+	switch classifyBidi(s, face) {
+	case bidiRTL:
+		b.builder.WriteString(` direction="rtl" text-anchor="end"`)
+	case bidiMixed:
+		if baseIsRTL(s) {
+			b.builder.WriteString(` direction="rtl" text-anchor="end"`)
+		}
+		b.builder.WriteString(` unicode-bidi="embed"`)
+	}
+}
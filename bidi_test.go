@@ -0,0 +1,51 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestClassifyBidi(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bidiClass
+	}{
+		{"Hello", bidiLTR},
+		{"123", bidiLTR},
+		{"שלום", bidiRTL},
+		{"مرحبا 42", bidiRTL},
+		{"Hello שלום", bidiMixed},
+	}
+
+	for _, tt := range tests {
+		if got := classifyBidi(tt.input, nil); got != tt.expected {
+			t.Errorf("classifyBidi(%q) = %d, expected %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestBackendTextRTL(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 0, G: 0, B: 0, A: 1})
+	backend.DrawText("שלום", 100, 100, nil, brush)
+	backend.DrawText("Total: 42 ₪ שקל", 100, 150, nil, brush)
+	backend.DrawText("Plain", 100, 200, nil, brush)
+
+	svg := writeSVG(t, backend)
+	if strings.Count(svg, `direction="rtl"`) != 1 {
+		t.Error("Only the RTL-based string should get direction=\"rtl\"")
+	}
+	if !strings.Contains(svg, `unicode-bidi="embed"`) {
+		t.Error("Mixed-direction text should get unicode-bidi")
+	}
+	if !strings.Contains(svg, `<text x="100" y="200" font-size="12" fill=`) {
+		t.Error("LTR text should not get direction attributes")
+	}
+}
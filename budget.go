@@ -0,0 +1,84 @@
+package svg
+
+import "fmt"
+
+// Budget limits the size of an export, so services generating SVGs from
+// user input can fail fast instead of shipping huge documents.
+type Budget struct {
+	// MaxBytes limits the size of the drawn content and definitions, in
+	// bytes. Zero means no limit.
+	MaxBytes int
+	// MaxElements limits the number of drawn elements. Zero means no
+	// limit.
+	MaxElements int
+	// OnExceeded, if set, is called once per export when a limit is
+	// first exceeded, with the usage including the offending element.
+	OnExceeded func(BudgetUsage)
+}
+
+// BudgetUsage is the size of an export when its budget was exceeded.
+type BudgetUsage struct {
+	Bytes    int
+	Elements int
+}
+
+// WithBudget limits the size of every export. The element that exceeds a
+// limit and everything drawn after it are dropped, and the failure is
+// recorded as ErrBudgetExceeded. Once the budget is exceeded, drawing
+// calls return without writing anything, definitions included, and
+// WriteRaw and DrawForeignObject return ErrBudgetExceeded. Unlike other
+// failures it is returned by End and WriteTo even without WithStrict, and
+// WriteTo writes nothing, so a truncated document is never shipped by
+// accident.
+//
+// Sizes are measured before WithPostProcessor functions run. They include
+// the gradient and clip path definitions and WriteRaw fragments, but not
+// the document header and footer or the patterns defined with
+// DefinePatternFromRecording.
+func WithBudget(budget Budget) Option {
+	return func(b *Backend) {
+		b.maxBytes, b.maxElements = budget.MaxBytes, budget.MaxElements
+		b.onExceeded = budget.OnExceeded
+	}
+}
+
+// withinBudget counts the element started by the last openElement call
+// and reports whether it fits the budget. Once the budget is exceeded the
+// element is removed and false is returned for every later element.
+func (b *Backend) withinBudget() bool {
+	if b.maxBytes <= 0 && b.maxElements <= 0 {
+		return true
+	}
+	if !b.overBudget {
+		b.elements++
+	}
+	if !b.checkBudget() {
+		b.builder.Truncate(b.elementStart)
+		return false
+	}
+	return true
+}
+
+// checkBudget reports whether the content written so far, definitions
+// included, fits the budget, recording the failure when it is first
+// exceeded. Drawing calls return early once it is.
+func (b *Backend) checkBudget() bool {
+	if b.overBudget {
+		return false
+	}
+	usage := BudgetUsage{Bytes: b.documentLen(), Elements: b.elements}
+	switch {
+	case b.maxElements > 0 && usage.Elements > b.maxElements:
+		b.fail(ErrBudgetExceeded, fmt.Errorf("more than %d elements", b.maxElements))
+	case b.maxBytes > 0 && usage.Bytes > b.maxBytes:
+		b.fail(ErrBudgetExceeded, fmt.Errorf("more than %d bytes", b.maxBytes))
+	default:
+		return true
+	}
+
+	b.overBudget = true
+	if b.onExceeded != nil {
+		b.onExceeded(usage)
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithBudget(t *testing.T) {
+	var usages []BudgetUsage
+	backend := NewBackend(WithBudget(Budget{
+		MaxElements: 3,
+		OnExceeded:  func(u BudgetUsage) { usages = append(usages, u) },
+	}))
+	_ = backend.Begin(100, 100)
+	for i := range 5 {
+		backend.FillRect(recording.NewRect(float64(i), 0, 1, 1), recording.NewSolidBrush(gg.Red))
+	}
+
+	if len(usages) != 1 || usages[0].Elements != 4 {
+		t.Errorf("OnExceeded should be called once at the fourth element, got %+v", usages)
+	}
+	if err := backend.End(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("End() = %v, expected ErrBudgetExceeded without strict mode", err)
+	}
+	var buf bytes.Buffer
+	if _, err := backend.WriteTo(&buf); !errors.Is(err, ErrBudgetExceeded) || buf.Len() != 0 {
+		t.Errorf("WriteTo should fail without writing, got %v and %d bytes", err, buf.Len())
+	}
+	if n := strings.Count(backend.builder.String(), "<rect"); n != 3 {
+		t.Errorf("Elements beyond the budget should be dropped, got %d", n)
+	}
+
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 1, 1), recording.NewSolidBrush(gg.Red))
+	if err := backend.End(); err != nil {
+		t.Errorf("Begin should reset the budget, got %v", err)
+	}
+}
+
+func TestWithBudgetBytes(t *testing.T) {
+	backend := NewBackend(WithBudget(Budget{MaxBytes: 200}))
+	_ = backend.Begin(100, 100)
+	for range 10 {
+		backend.DrawText("a fairly long label", 10, 10, nil, recording.NewSolidBrush(gg.Black))
+	}
+	if err := backend.End(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("End() = %v, expected ErrBudgetExceeded", err)
+	}
+	if size := backend.Stats().Bytes; size > 200 {
+		t.Errorf("Content should stay within the budget, got %d bytes", size)
+	}
+}
+
+func TestWithBudgetDefinitions(t *testing.T) {
+	backend := NewBackend(WithBudget(Budget{MaxBytes: 300}))
+	_ = backend.Begin(100, 100)
+	clip := gg.NewPath()
+	clip.MoveTo(0, 0)
+	for i := range 100 {
+		clip.LineTo(float64(i), float64(i%7))
+	}
+	clip.Close()
+
+	// Clip paths count toward the budget without any drawn element.
+	backend.SetClip(clip, recording.FillRuleNonZero)
+	if !errors.Is(backend.Err(), ErrBudgetExceeded) {
+		t.Fatalf("a clip path beyond the budget should fail, got %v", backend.Err())
+	}
+
+	// Nothing is written once the budget is exceeded.
+	size := backend.defs.Len() + backend.builder.Len()
+	gradient := recording.NewLinearGradientBrush(0, 0, 10, 0).
+		AddColorStop(0, gg.Red).AddColorStop(1, gg.Blue)
+	backend.SetClip(clip, recording.FillRuleEvenOdd)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), gradient)
+	backend.DrawText("label", 10, 10, nil, gradient)
+	if err := backend.WriteRaw(`<g/>`); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("WriteRaw over budget: err = %v", err)
+	}
+	if n := backend.defs.Len() + backend.builder.Len(); n != size {
+		t.Errorf("calls over budget wrote %d bytes", n-size)
+	}
+}
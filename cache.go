@@ -0,0 +1,119 @@
+package svg
+
+import (
+	"bytes"
+	"container/list"
+	"strconv"
+	"sync"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// Cache maps recordings and options to their serialized SVG output, so
+// repeated exports of unchanged recordings, such as dashboards polled
+// every few seconds, skip playback and serialization. Entries are evicted
+// least recently used first. A Cache is safe for concurrent use.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[cacheKey]*list.Element
+	order      *list.List // most recently used first
+}
+
+// cacheEntry is a cached document.
+type cacheEntry struct {
+	key  cacheKey
+	data []byte
+}
+
+// cacheKey identifies a cached document by the recording's content hash,
+// the settings the options made and the namespaces they registered.
+type cacheKey struct {
+	recording  string
+	settings   settings
+	namespaces string
+}
+
+// NewCache returns a cache holding at most maxEntries documents.
+// A maxEntries of 0 or less means no limit.
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		entries:    make(map[cacheKey]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Render returns the SVG document for r exported with opts, from the
+// cache if possible. The returned slice is shared and must not be
+// modified.
+//
+// Entries are keyed by a hash of the recording's content and by the
+// settings the options make. Functions cannot be compared, so exports
+// with function-valued options (post-processors, signers, font size
+// resolvers, laser classifiers and budget callbacks) are not cached:
+// they are rendered on every call.
+func (c *Cache) Render(r *recording.Recording, opts ...Option) ([]byte, error) {
+	b := NewBackend(opts...)
+	if !b.cacheable() {
+		return render(b, r)
+	}
+	key := cacheKey{recording: hashRecording(r), settings: b.settings}
+	for _, ns := range b.extraNamespaces {
+		key.namespaces += strconv.Quote(ns.prefix) + strconv.Quote(ns.uri)
+	}
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		return el.Value.(*cacheEntry).data, nil
+	}
+	c.mu.Unlock()
+
+	data, err := render(b, r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		// Rendered concurrently by another caller.
+		c.order.MoveToFront(el)
+		return el.Value.(*cacheEntry).data, nil
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, data: data})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return data, nil
+}
+
+// Len returns the number of cached documents.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// render replays r onto b and returns the document.
+func render(b *Backend, r *recording.Recording) ([]byte, error) {
+	if err := b.Playback(r); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// cacheable reports whether the backend's options are all held in its
+// settings and namespaces, so Cache can key exports on them.
+func (b *Backend) cacheable() bool {
+	return len(b.postProcessors) == 0 && b.signer == nil && b.fontSizeResolver == nil &&
+		b.laserClassifier == nil && b.onExceeded == nil
+}
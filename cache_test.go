@@ -0,0 +1,115 @@
+package svg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+func TestCache(t *testing.T) {
+	record := func(w float64) *recording.Recording {
+		rec := recording.NewRecorder(100, 100)
+		rec.SetFillRGBA(1, 0, 0, 1)
+		rec.DrawRectangle(0, 0, w, 10)
+		rec.Fill()
+		return rec.FinishRecording()
+	}
+
+	cache := NewCache(2)
+	first, err := cache.Render(record(10))
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	// An identical recording hits the cache.
+	again, _ := cache.Render(record(10))
+	if &again[0] != &first[0] {
+		t.Error("Identical recording should be served from the cache")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len = %d, expected 1", cache.Len())
+	}
+
+	// Different options and recordings are separate entries.
+	byOp, _ := cache.Render(record(10), WithStructure(StructureByOp))
+	if bytes.Equal(byOp, first) {
+		t.Error("Different options should render separately")
+	}
+	_, _ = cache.Render(record(20))
+	if cache.Len() != 2 {
+		t.Errorf("Cache should evict down to 2 entries, got %d", cache.Len())
+	}
+
+	// The least recently used entry (the first document) was evicted.
+	evicted, _ := cache.Render(record(10))
+	if &evicted[0] == &first[0] || !bytes.Equal(evicted, first) {
+		t.Error("Evicted entry should be re-rendered with identical output")
+	}
+}
+
+func TestCacheKeysCompleteOptions(t *testing.T) {
+	rec := recording.NewRecorder(100, 100)
+	for i := range 20 {
+		rec.SetFillRGBA(1, 0, 0, 1)
+		rec.DrawRectangle(float64(i), 0, 1, 10)
+		rec.Fill()
+	}
+	r := rec.FinishRecording()
+
+	cache := NewCache(0)
+	plain, err := cache.Render(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Render(r, WithBudget(Budget{MaxElements: 5})); err == nil {
+		t.Error("A budgeted export should not be served from the unbudgeted entry")
+	}
+
+	// Options the provenance summary records lossily are keyed in full.
+	variants := [][]Option{
+		{WithDoctype("<!DOCTYPE svg>")},
+		{WithDoctype(DoctypeSVG11)},
+		{WithNamespace("app", "https://example.com/a")},
+		{WithNamespace("app", "https://example.com/b")},
+		{WithValidation(true)},
+	}
+	for i, opts := range variants {
+		if _, err := cache.Render(r, opts...); err != nil {
+			t.Fatalf("variant %d: %v", i, err)
+		}
+	}
+	if cache.Len() != 1+len(variants) {
+		t.Errorf("Len = %d, expected a separate entry per variant", cache.Len())
+	}
+
+	// Functions cannot be compared, so such exports are not cached.
+	processed, _ := cache.Render(r, WithPostProcessor(func(data []byte) ([]byte, error) {
+		return bytes.ToUpper(data), nil
+	}))
+	if bytes.Equal(processed, plain) || cache.Len() != 1+len(variants) {
+		t.Error("Exports with post-processors should bypass the cache")
+	}
+	for i, opt := range []Option{
+		WithSigner(SignerFunc(func([]byte) ([]byte, error) { return nil, nil })),
+		WithFontSizeResolver(func(text.Face) float64 { return 0 }),
+		WithLaserClassifier(func(LaserElement) LaserOp { return LaserCut }),
+		WithBudget(Budget{OnExceeded: func(BudgetUsage) {}}),
+	} {
+		if NewBackend(opt).cacheable() {
+			t.Errorf("function-valued option %d should make exports uncacheable", i)
+		}
+	}
+
+	// Equal option values share an entry.
+	for range 2 {
+		if _, err := cache.Render(r, WithBackground(gg.White), WithStandalone(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.Len() != 2+len(variants) {
+		t.Errorf("Len = %d, expected equal options to share an entry", cache.Len())
+	}
+}
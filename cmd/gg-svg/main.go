@@ -0,0 +1,136 @@
+// Command gg-svg converts drawings to optimized SVG for build pipelines.
+//
+// gg has no file format for recordings, so gg-svg reads SVG documents:
+// the input is decoded into a recording with svg.Decode and exported
+// again through the SVG backend, applying the requested size, precision
+// and optimizations. This normalizes and shrinks SVG assets without
+// writing Go code. Features Decode drops, such as filters, masks, markers,
+// patterns and CSS style sheets, are reported as warnings on standard
+// error.
+//
+// Usage:
+//
+//	gg-svg [flags] [input.svg]
+//
+// The input is read from standard input if no file or "-" is given, and
+// the result is written to standard output unless -o is set.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	svg "github.com/gogpu/gg-svg"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gg-svg:", err)
+		os.Exit(1)
+	}
+}
+
+// run converts the input named by args and writes the result, warning
+// about dropped features on stderr.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("gg-svg", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("o", "", "output file (default standard output)")
+	width := fs.Float64("width", 0, "output width; 0 keeps the input size, or scales with -height")
+	height := fs.Float64("height", 0, "output height; 0 keeps the input size, or scales with -width")
+	precision := fs.Int("precision", -1, "decimal places of coordinates; negative keeps full precision")
+	optimize := fs.Bool("optimize", false, "merge paths, cull off-canvas content, bake transforms and prune unused definitions")
+	simplify := fs.Float64("simplify", 0, "simplify paths within this tolerance in user units")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return errors.New("at most one input file can be given")
+	}
+
+	in := stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	rec, err := svg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	ignored, err := svg.DecodeIgnored(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for _, feature := range ignored {
+		fmt.Fprintf(stderr, "gg-svg: warning: %s are not supported and were dropped\n", feature)
+	}
+
+	opts := []svg.Option{svg.WithSimplify(*simplify)}
+	if *optimize {
+		opts = append(opts, svg.WithMergePaths(true), svg.WithCulling(true), svg.WithFlattenTransforms(true))
+	}
+	backend := svg.NewBackend(opts...)
+	if *width > 0 || *height > 0 {
+		backend.AddPass(resize(*width, *height))
+	}
+	if *precision >= 0 {
+		backend.AddPass(svg.RoundNumbers(*precision))
+	}
+	if *optimize {
+		backend.AddPass(svg.PruneDefs())
+	}
+	if err := backend.Playback(rec); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if _, err := backend.WriteTo(&buf); err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0o644)
+}
+
+// resize returns a pass that sets the document's width and height,
+// keeping its viewBox so the content scales. A zero dimension follows the
+// other with the viewBox aspect ratio.
+func resize(width, height float64) svg.Optimizer {
+	return svg.OptimizerFunc(func(root *svg.Element) error {
+		w, _ := root.Attr("width")
+		h, _ := root.Attr("height")
+		iw, err1 := strconv.ParseFloat(w, 64)
+		ih, err2 := strconv.ParseFloat(h, 64)
+		if err1 != nil || err2 != nil || iw <= 0 || ih <= 0 {
+			return fmt.Errorf("document has no size to scale from")
+		}
+		if width <= 0 {
+			width = iw * height / ih
+		}
+		if height <= 0 {
+			height = ih * width / iw
+		}
+		root.SetAttr("width", strconv.FormatFloat(width, 'g', -1, 64))
+		root.SetAttr("height", strconv.FormatFloat(height, 'g', -1, 64))
+		return nil
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+const input = `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50">
+<defs><linearGradient id="unused"><stop offset="0" stop-color="red"/></linearGradient></defs>
+<rect x="10.123456" y="10" width="30" height="20" fill="#0000ff"/>
+</svg>`
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"-width", "200", "-precision", "1", "-optimize"}, strings.NewReader(input), &out, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := out.String()
+	for _, want := range []string{`width="200"`, `height="100"`, `viewBox="0 0 100 50"`, "10.1"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output missing %s:\n%s", want, s)
+		}
+	}
+	for _, unwanted := range []string{"10.12", "unused"} {
+		if strings.Contains(s, unwanted) {
+			t.Errorf("output contains %s:\n%s", unwanted, s)
+		}
+	}
+}
+
+func TestRunInvalidInput(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(nil, strings.NewReader("<svg"), &out, io.Discard); err == nil {
+		t.Error("run accepted truncated input")
+	}
+}
+
+func TestRunWarnings(t *testing.T) {
+	src := `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10">
+<style>rect{fill:red}</style>
+<filter id="f"><feGaussianBlur stdDeviation="1"/></filter>
+<rect width="5" height="5" style="filter:url(#f)" marker-end="url(#m)"/>
+</svg>`
+	var out, stderr bytes.Buffer
+	if err := run(nil, strings.NewReader(src), &out, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"CSS style sheets", "filters", "markers"} {
+		if !strings.Contains(stderr.String(), "warning: "+want) {
+			t.Errorf("stderr missing a warning about %s:\n%s", want, stderr.String())
+		}
+	}
+
+	stderr.Reset()
+	if err := run(nil, strings.NewReader(input), &out, &stderr); err != nil || stderr.Len() != 0 {
+		t.Errorf("supported input should not warn, got %v and %q", err, stderr.String())
+	}
+}
+
+func TestRunHelp(t *testing.T) {
+	var out, stderr bytes.Buffer
+	if err := run([]string{"-h"}, strings.NewReader(input), &out, &stderr); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("-h: err = %v, expected flag.ErrHelp so main exits 0", err)
+	}
+	if !strings.Contains(stderr.String(), "-precision") {
+		t.Errorf("-h should print the flags, got %q", stderr.String())
+	}
+}
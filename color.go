@@ -0,0 +1,186 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gogpu/gg"
+)
+
+// ColorFormat selects how colors are written.
+type ColorFormat int
+
+const (
+	// ColorRGB writes colors as rgb(r,g,b). This is the default.
+	ColorRGB ColorFormat = iota
+	// ColorHex writes colors as #rrggbb, the form most SVG tooling expects.
+	ColorHex
+	// ColorShortHex writes #rgb where the color allows it, and #rrggbb
+	// otherwise.
+	ColorShortHex
+	// ColorNamed writes a CSS basic color keyword (red, navy, ...) where
+	// the color matches one exactly, and short hex otherwise.
+	ColorNamed
+)
+
+// WithColorFormat selects how fill, stroke and gradient stop colors are
+// written. Hex output is shorter than rgb() and noticeably shrinks large
+// files.
+func WithColorFormat(f ColorFormat) Option {
+	return func(b *Backend) {
+		b.colorFormat = f
+	}
+}
+
+// String returns the name of the color format.
+func (f ColorFormat) String() string {
+	switch f {
+	case ColorHex:
+		return "hex"
+	case ColorShortHex:
+		return "short-hex"
+	case ColorNamed:
+		return "named"
+	default:
+		return "rgb"
+	}
+}
+
+// WithHexAlpha writes translucent colors as 8-digit hex (#rrggbbaa, or
+// #rgba with ColorShortHex and ColorNamed) instead of a separate
+// fill-opacity, stroke-opacity or stop-opacity attribute. Opaque colors
+// follow the color format. 8-digit hex is a CSS Color Level 4 feature;
+// enable it only for modern viewers.
+func WithHexAlpha(enabled bool) Option {
+	return func(b *Backend) {
+		b.hexAlpha = enabled
+	}
+}
+
+// alphaInColor reports whether the alpha of c is written as part of the
+// color rather than as an opacity attribute.
+func (b *Backend) alphaInColor(c gg.RGBA) bool {
+	return b.hexAlpha && c.A < 1 && !b.tiny()
+}
+
+// basicColorNames maps #rrggbb values to the CSS basic color keywords.
+var basicColorNames = map[string]string{
+	"#000000": "black",
+	"#c0c0c0": "silver",
+	"#808080": "gray",
+	"#ffffff": "white",
+	"#800000": "maroon",
+	"#ff0000": "red",
+	"#800080": "purple",
+	"#ff00ff": "fuchsia",
+	"#008000": "green",
+	"#00ff00": "lime",
+	"#808000": "olive",
+	"#ffff00": "yellow",
+	"#000080": "navy",
+	"#0000ff": "blue",
+	"#008080": "teal",
+	"#00ffff": "aqua",
+	"#ffa500": "orange",
+}
+
+// formatColor writes an RGBA color in the selected color format.
+// Alpha is only included with WithHexAlpha; otherwise it is written as a
+// separate opacity attribute.
+func (b *Backend) formatColor(c gg.RGBA) string {
+	if b.alphaInColor(c) {
+		hex := b.hexColor(c) + fmt.Sprintf("%02x", b.channelByte(c.A))
+		if b.colorFormat == ColorShortHex || b.colorFormat == ColorNamed {
+			return shortHex(hex)
+		}
+		return hex
+	}
+	switch b.colorFormat {
+	case ColorHex:
+		return b.hexColor(c)
+	case ColorShortHex:
+		return shortHex(b.hexColor(c))
+	case ColorNamed:
+		hex := b.hexColor(c)
+		if name, ok := basicColorNames[hex]; ok {
+			return name
+		}
+		return shortHex(hex)
+	default:
+		return string(appendRGB(make([]byte, 0, len("rgb(255,255,255)")),
+			b.channelByte(c.R), b.channelByte(c.G), b.channelByte(c.B)))
+	}
+}
+
+// ColorRounding selects how float color channels map to 8-bit values.
+type ColorRounding int
+
+const (
+	// ColorRoundNearest rounds to the nearest value, matching the raster
+	// backends: 0.5 becomes 128. This is the default.
+	ColorRoundNearest ColorRounding = iota
+	// ColorTruncate truncates toward zero, as earlier releases did:
+	// 0.5 becomes 127.
+	ColorTruncate
+)
+
+// String returns the name of the rounding policy.
+func (r ColorRounding) String() string {
+	if r == ColorTruncate {
+		return "truncate"
+	}
+	return "nearest"
+}
+
+// WithColorRounding selects how color channels are converted to 8-bit
+// values. Use ColorTruncate to reproduce output of earlier releases.
+func WithColorRounding(r ColorRounding) Option {
+	return func(b *Backend) {
+		b.colorRounding = r
+	}
+}
+
+// channelByte converts a color channel in [0, 1] to [0, 255] using the
+// rounding policy. Out-of-range values are clamped.
+func (b *Backend) channelByte(v float64) int {
+	if b.colorRounding == ColorTruncate {
+		return int(min(max(v, 0), 1) * 255)
+	}
+	return channelByte(v)
+}
+
+// channelByte converts a color channel in [0, 1] to the nearest value in
+// [0, 255]. Out-of-range values are clamped.
+func channelByte(v float64) int {
+	return int(math.Round(min(max(v, 0), 1) * 255))
+}
+
+// appendRGB appends an rgb() color with the given 8-bit channels to dst.
+func appendRGB(dst []byte, r, g, b int) []byte {
+	dst = append(dst, "rgb("...)
+	dst = strconv.AppendInt(dst, int64(r), 10)
+	dst = append(dst, ',')
+	dst = strconv.AppendInt(dst, int64(g), 10)
+	dst = append(dst, ',')
+	dst = strconv.AppendInt(dst, int64(b), 10)
+	return append(dst, ')')
+}
+
+// hexColor returns c as #rrggbb.
+func (b *Backend) hexColor(c gg.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", b.channelByte(c.R), b.channelByte(c.G), b.channelByte(c.B))
+}
+
+// shortHex shortens #rrggbb to #rgb, or #rrggbbaa to #rgba, when each
+// channel repeats its digit.
+func shortHex(hex string) string {
+	short := "#"
+	for i := 1; i+1 < len(hex); i += 2 {
+		if hex[i] != hex[i+1] {
+			return hex
+		}
+		short += hex[i : i+1]
+	}
+	return short
+}
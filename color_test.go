@@ -0,0 +1,109 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestFormatColor(t *testing.T) {
+	tests := []struct {
+		format   ColorFormat
+		color    gg.RGBA
+		expected string
+	}{
+		{ColorRGB, gg.RGBA{R: 1, G: 0.2, B: 0, A: 1}, "rgb(255,51,0)"},
+		{ColorHex, gg.RGBA{R: 1, G: 0.2, B: 0, A: 1}, "#ff3300"},
+		{ColorShortHex, gg.RGBA{R: 1, G: 0.2, B: 0, A: 1}, "#f30"},
+		{ColorShortHex, gg.RGBA{R: 1, G: 0.5, B: 0, A: 1}, "#ff8000"},
+		{ColorNamed, gg.RGBA{R: 0, G: 0, B: 1, A: 1}, "blue"},
+		{ColorNamed, gg.RGBA{R: 1, G: 1, B: 1, A: 0.5}, "white"},
+		{ColorNamed, gg.RGBA{R: 1, G: 0.2, B: 0, A: 1}, "#f30"},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(WithColorFormat(tt.format))
+		if got := backend.formatColor(tt.color); got != tt.expected {
+			t.Errorf("%s: formatColor(%v) = %s, expected %s", tt.format, tt.color, got, tt.expected)
+		}
+	}
+}
+
+func TestWithColorFormat(t *testing.T) {
+	backend := NewBackend(WithColorFormat(ColorHex))
+	backend.ThemeColor(gg.Blue, "accent")
+	_ = backend.Begin(100, 100)
+	grad := recording.NewLinearGradientBrush(0, 0, 100, 0).
+		AddColorStop(0, gg.Red).
+		AddColorStop(1, gg.Green)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), grad)
+	backend.StrokePath(line(0, 0, 10, 10), recording.NewSolidBrush(gg.Black), recording.DefaultStroke())
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Blue))
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`stop-color="#ff0000"`,
+		`stroke="#000000"`,
+		`fill:var(--accent,#0000ff)`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
+
+func TestWithHexAlpha(t *testing.T) {
+	backend := NewBackend(WithHexAlpha(true))
+	backend.ThemeColor(gg.Blue, CurrentColor)
+	_ = backend.Begin(100, 100)
+	grad := recording.NewLinearGradientBrush(0, 0, 100, 0).
+		AddColorStop(0, gg.RGBA{R: 1, A: 0.5}).
+		AddColorStop(1, gg.Green)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), grad)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.RGBA{R: 1, G: 1, A: 0.2}))
+	backend.StrokePath(line(0, 0, 10, 10), recording.NewSolidBrush(gg.RGBA{B: 1, A: 0.5}), recording.DefaultStroke())
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`stop-color="#ff000080"/>`,
+		`fill="#ffff0033" stroke="none"`,
+		`stroke="currentColor" stroke-opacity="0.5"`,
+		`fill="rgb(0,0,0)"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if strings.Contains(svg, "fill-opacity") || strings.Contains(svg, "stop-opacity") {
+		t.Errorf("Alpha should be written in the colors, got:\n%s", svg)
+	}
+
+	short := NewBackend(WithHexAlpha(true), WithColorFormat(ColorShortHex))
+	if got := short.formatColor(gg.RGBA{R: 1, G: 1, A: 0.2}); got != "#ff03" {
+		t.Errorf("Short hex alpha = %s, expected #ff03", got)
+	}
+}
+
+func TestWithColorRounding(t *testing.T) {
+	gray := gg.RGBA{R: 0.5, G: 0.5, B: 0.5, A: 0.5}
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, "rgb(128,128,128)"},
+		{[]Option{WithColorRounding(ColorTruncate)}, "rgb(127,127,127)"},
+		{[]Option{WithColorRounding(ColorTruncate), WithHexAlpha(true)}, "#7f7f7f7f"},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(tt.opts...)
+		if got := backend.formatColor(gray); got != tt.expected {
+			t.Errorf("formatColor = %s, expected %s", got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package svg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// ContentHash returns the hex-encoded SHA-256 of the document most recently
+// written by WriteTo or SaveToFile, or "" if nothing has been written since
+// Begin. The hash is computed while the document is written, so it can be
+// used as a cache key or ETag without buffering the output or hashing it
+// in a second pass.
+func (b *Backend) ContentHash() string {
+	return b.contentHash
+}
+
+// hashingWriter passes writes through to w while hashing them.
+type hashingWriter struct {
+	w io.Writer
+	h hash.Hash
+}
+
+// newHashingWriter returns a hashingWriter writing to w.
+func newHashingWriter(w io.Writer) *hashingWriter {
+	return &hashingWriter{w: w, h: sha256.New()}
+}
+
+// Write writes p to the underlying writer and hashes the bytes accepted.
+func (hw *hashingWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.Write(p[:n])
+	return n, err
+}
+
+// sum returns the hex-encoded hash of the bytes written so far.
+func (hw *hashingWriter) sum() string {
+	return hex.EncodeToString(hw.h.Sum(nil))
+}
@@ -0,0 +1,39 @@
+package svg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestContentHash(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithPostProcessor(func(b []byte) ([]byte, error) { return bytes.ToUpper(b), nil })},
+	} {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 100)
+		backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+		if backend.ContentHash() != "" {
+			t.Error("ContentHash should be empty before WriteTo")
+		}
+
+		var buf bytes.Buffer
+		if _, err := backend.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo failed: %v", err)
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		if got, expected := backend.ContentHash(), hex.EncodeToString(sum[:]); got != expected {
+			t.Errorf("ContentHash = %s, expected %s", got, expected)
+		}
+
+		_ = backend.Begin(100, 100)
+		if backend.ContentHash() != "" {
+			t.Error("ContentHash should be reset by Begin")
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package svg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// contextChunk is the largest write between cancellation checks.
+const contextChunk = 32 << 10
+
+// contextWriter fails writes once its context is done. Large writes are
+// split into chunks so cancellation is noticed within one chunk.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw contextWriter) Write(p []byte) (int, error) {
+	var total int
+	for len(p) > 0 {
+		if err := cw.ctx.Err(); err != nil {
+			return total, err
+		}
+		chunk := p[:min(len(p), contextChunk)]
+		n, err := cw.w.Write(chunk)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		p = p[len(chunk):]
+	}
+	return total, nil
+}
+
+// WriteToContext is like WriteTo but stops writing once ctx is done,
+// returning the context's error. The output is checked between chunks of
+// at most 32 KiB, so a server can abandon a large export when its client
+// disconnects.
+func (b *Backend) WriteToContext(ctx context.Context, w io.Writer) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return b.WriteTo(contextWriter{ctx: ctx, w: w})
+}
+
+// SaveToFileContext is like SaveToFile but stops once ctx is done. The
+// partially written file is removed when the export is canceled.
+func (b *Backend) SaveToFileContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := b.saveToFile(path, func(w io.Writer) error {
+		_, err := b.WriteToContext(ctx, w)
+		return err
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		_ = os.Remove(path)
+	}
+	return err
+}
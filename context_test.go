@@ -0,0 +1,75 @@
+package svg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// cancelingWriter cancels its context after the first write.
+type cancelingWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	defer w.cancel()
+	return w.Buffer.Write(p)
+}
+
+func drawLarge(backend *Backend) {
+	_ = backend.Begin(100, 100)
+	for i := range 2000 {
+		backend.FillRect(recording.NewRect(float64(i%100), 0, 1, 1), recording.NewSolidBrush(gg.Red))
+	}
+}
+
+func TestWriteToContext(t *testing.T) {
+	backend := NewBackend()
+	drawLarge(backend)
+
+	var full bytes.Buffer
+	n, err := backend.WriteToContext(context.Background(), &full)
+	if err != nil || n != int64(full.Len()) || !strings.HasSuffix(full.String(), "</svg>\n") {
+		t.Fatalf("WriteToContext() = %d, %v", n, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &cancelingWriter{cancel: cancel}
+	_, err = backend.WriteToContext(ctx, w)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WriteToContext() should stop when canceled, got %v", err)
+	}
+	if w.Len() >= full.Len() {
+		t.Errorf("Canceled write should be partial, wrote %d of %d bytes", w.Len(), full.Len())
+	}
+}
+
+func TestSaveToFileContext(t *testing.T) {
+	backend := NewBackend()
+	drawLarge(backend)
+	path := filepath.Join(t.TempDir(), "out.svg")
+
+	if err := backend.SaveToFileContext(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("File should be written: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := backend.SaveToFileContext(ctx, path+".2"); !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveToFileContext() should fail when canceled, got %v", err)
+	}
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Errorf("Canceled export should not leave a file, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package svg
+
+import (
+	"math"
+
+	"github.com/gogpu/gg"
+)
+
+// WithRoundCorners rounds the corners of polygons and polylines to the
+// given radius at export, for a softer style without changing the drawing
+// code. Each corner between two straight segments is replaced by a
+// quadratic curve; the radius shrinks on short segments so adjacent
+// corners don't overlap. Subpaths containing curves are left unchanged.
+// Rectangles get rx and ry attributes. A radius of 0 or less disables the
+// pass.
+func WithRoundCorners(radius float64) Option {
+	return func(b *Backend) {
+		b.cornerRadius = radius
+	}
+}
+
+// roundCorners returns path with the corners of its straight subpaths
+// rounded.
+func (b *Backend) roundCorners(path *gg.Path) *gg.Path {
+	if b.cornerRadius <= 0 {
+		return path
+	}
+	result := gg.NewPath()
+	for _, sp := range splitSubpaths(path) {
+		points, closed, ok := polyline(sp)
+		if !ok || len(points) < 3 {
+			appendPath(result, sp)
+			continue
+		}
+		roundPolyline(result, points, closed, b.cornerRadius)
+	}
+	return result
+}
+
+// polyline returns the distinct vertices of a subpath made of straight
+// segments only. It reports false if the subpath contains curves.
+func polyline(sp *gg.Path) (points []gg.Point, closed, ok bool) {
+	for _, elem := range sp.Elements() {
+		var p gg.Point
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			p = e.Point
+		case gg.LineTo:
+			p = e.Point
+		case gg.Close:
+			closed = true
+			continue
+		default:
+			return nil, false, false
+		}
+		if len(points) == 0 || points[len(points)-1] != p {
+			points = append(points, p)
+		}
+	}
+	// An explicit line back to the start is part of the closing segment.
+	if closed && len(points) > 1 && points[len(points)-1] == points[0] {
+		points = points[:len(points)-1]
+	}
+	return points, closed, true
+}
+
+// roundPolyline appends the polyline through points to dst with its
+// corners rounded to radius.
+func roundPolyline(dst *gg.Path, points []gg.Point, closed bool, radius float64) {
+	n := len(points)
+	// corner returns where the rounding of vertex i starts and ends.
+	corner := func(i int) (entry, exit gg.Point) {
+		prev, p, next := points[(i+n-1)%n], points[i], points[(i+1)%n]
+		inLen, outLen := p.Distance(prev), p.Distance(next)
+		d := math.Min(radius, math.Min(inLen, outLen)/2)
+		return lerp(p, prev, d/inLen), lerp(p, next, d/outLen)
+	}
+
+	if !closed {
+		dst.MoveTo(points[0].X, points[0].Y)
+		for i := 1; i < n-1; i++ {
+			entry, exit := corner(i)
+			dst.LineTo(entry.X, entry.Y)
+			dst.QuadraticTo(points[i].X, points[i].Y, exit.X, exit.Y)
+		}
+		dst.LineTo(points[n-1].X, points[n-1].Y)
+		return
+	}
+
+	_, start := corner(0)
+	dst.MoveTo(start.X, start.Y)
+	for i := 1; i <= n; i++ {
+		entry, exit := corner(i % n)
+		p := points[i%n]
+		dst.LineTo(entry.X, entry.Y)
+		dst.QuadraticTo(p.X, p.Y, exit.X, exit.Y)
+	}
+	dst.Close()
+}
+
+// lerp returns the point t of the way from p to q.
+func lerp(p, q gg.Point, t float64) gg.Point {
+	return gg.Point{X: p.X + (q.X-p.X)*t, Y: p.Y + (q.Y-p.Y)*t}
+}
@@ -0,0 +1,49 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithRoundCorners(t *testing.T) {
+	triangle := gg.NewPath()
+	triangle.MoveTo(0, 0)
+	triangle.LineTo(100, 0)
+	triangle.LineTo(100, 100)
+	triangle.Close()
+
+	polyline := gg.NewPath()
+	polyline.MoveTo(0, 0)
+	polyline.LineTo(4, 0)
+	polyline.LineTo(4, 50)
+
+	curve := gg.NewPath()
+	curve.MoveTo(0, 0)
+	curve.LineTo(10, 0)
+	curve.QuadraticTo(20, 0, 20, 10)
+
+	backend := NewBackend(WithRoundCorners(10))
+	_ = backend.Begin(100, 100)
+	backend.FillPath(triangle, recording.NewSolidBrush(gg.Red), recording.FillRuleNonZero)
+	backend.StrokePath(polyline, recording.NewSolidBrush(gg.Black), recording.DefaultStroke())
+	backend.StrokePath(curve, recording.NewSolidBrush(gg.Black), recording.DefaultStroke())
+	backend.FillRect(recording.NewRect(0, 0, 50, 8), recording.NewSolidBrush(gg.Blue))
+	svg := writeSVG(t, backend)
+
+	expected := []string{
+		`d="M10 0L90 0Q100 0 100 10L100 90Q100 100 92.9289`,
+		`Q0 0 10 0Z"`,
+		// The first segment is only 4 long, so the radius shrinks to 2.
+		`d="M0 0L2 0Q4 0 4 2L4 50"`,
+		`d="M0 0L10 0Q20 0 20 10"`,
+		`width="50" height="8" rx="4" ry="4"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
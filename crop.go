@@ -0,0 +1,204 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+// WithAutoCrop fits the viewBox to the bounds of everything drawn, plus
+// padding on every side, so icons and glyphs can be exported without
+// measuring them first. With resize the width and height attributes are
+// set to the cropped size as well; otherwise they keep the canvas size
+// and the cropped content is scaled to fit.
+//
+// Bounds account for transforms, clips and stroke widths. Text bounds are
+// measured with the face when there is one and estimated from the font
+// size otherwise. Miter joins that extend beyond half the stroke width
+// are not included. An export with nothing drawn keeps the canvas
+// viewBox.
+func WithAutoCrop(padding float64, resize bool) Option {
+	return func(b *Backend) {
+		b.autoCrop = true
+		b.cropPadding = padding
+		b.cropResize = resize
+	}
+}
+
+// bbox is an axis-aligned rectangle in canvas coordinates.
+type bbox struct {
+	minX, minY, maxX, maxY float64
+}
+
+// emptyBBox returns a box that contains nothing and grows to fit the
+// first box united with it.
+func emptyBBox() bbox {
+	return bbox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+}
+
+func (r bbox) empty() bool {
+	return r.minX > r.maxX || r.minY > r.maxY
+}
+
+func (r bbox) union(o bbox) bbox {
+	return bbox{
+		math.Min(r.minX, o.minX), math.Min(r.minY, o.minY),
+		math.Max(r.maxX, o.maxX), math.Max(r.maxY, o.maxY),
+	}
+}
+
+func (r bbox) intersect(o bbox) bbox {
+	return bbox{
+		math.Max(r.minX, o.minX), math.Max(r.minY, o.minY),
+		math.Min(r.maxX, o.maxX), math.Min(r.maxY, o.maxY),
+	}
+}
+
+// transformedBounds returns the canvas-space bounds of the user-space
+// rectangle under the current transform.
+func (b *Backend) transformedBounds(minX, minY, maxX, maxY float64) bbox {
+	return transformBBox(b.currentTransform, minX, minY, maxX, maxY)
+}
+
+// transformBBox returns the bounds of the rectangle transformed by m.
+func transformBBox(m recording.Matrix, minX, minY, maxX, maxY float64) bbox {
+	r := emptyBBox()
+	for _, p := range [4][2]float64{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}} {
+		x, y := m.TransformPoint(p[0], p[1])
+		r = r.union(bbox{x, y, x, y})
+	}
+	return r
+}
+
+// pathBounds returns the user-space bounds of path, grown by pad.
+func pathBounds(path *gg.Path, pad float64) (minX, minY, maxX, maxY float64) {
+	r := path.BoundingBox()
+	return r.Min.X - pad, r.Min.Y - pad, r.Max.X + pad, r.Max.Y + pad
+}
+
+// includeBounds adds the user-space rectangle, clipped to the current
+// clip, to the drawn content bounds.
+func (b *Backend) includeBounds(minX, minY, maxX, maxY float64) {
+	if !b.autoCrop {
+		return
+	}
+	r := b.transformedBounds(minX, minY, maxX, maxY)
+	if b.clipBounds != nil {
+		r = r.intersect(*b.clipBounds)
+	}
+	if !r.empty() {
+		b.contentBounds = b.contentBounds.union(r)
+	}
+}
+
+// includePath adds the bounds of path, grown by pad, to the drawn
+// content bounds. The bounds are only measured with WithAutoCrop.
+func (b *Backend) includePath(path *gg.Path, pad float64) {
+	if b.autoCrop {
+		b.includeBounds(pathBounds(path, pad))
+	}
+}
+
+// includeText adds the bounds of text lines drawn at x, y to the drawn
+// content bounds and the cut line.
+func (b *Backend) includeText(lines []string, x, y float64, face text.Face) {
+	if !b.autoCrop && !b.cutline() || len(lines) == 0 {
+		return
+	}
+	r := b.textBounds(lines, x, y, face)
+	b.includeBounds(r.minX, r.minY, r.maxX, r.maxY)
+	b.addCutRect(r.minX, r.minY, r.maxX, r.maxY)
+}
+
+// textBounds estimates the untransformed bounds of text lines drawn at
+// x, y.
+func (b *Backend) textBounds(lines []string, x, y float64, face text.Face) bbox {
+	size := b.fontSize(face)
+
+	// Measure lines at the written size, and extents around the baseline.
+	scale := 1.0
+	ascent, descent := 0.8*size, 0.2*size
+	if face != nil {
+		if fs := face.Size(); fs > 0 {
+			scale = size / fs
+		}
+		m := face.Metrics()
+		ascent, descent = m.Ascent*scale, m.Descent*scale
+	}
+	width := 0.0
+	for _, line := range lines {
+		w := 0.6 * size * float64(utf8.RuneCountInString(line))
+		if face != nil {
+			w = face.Advance(line) * scale
+		}
+		width = math.Max(width, w)
+	}
+
+	switch b.baseline {
+	case BaselineTop:
+		y += ascent
+	case BaselineMiddle:
+		y += (ascent - descent) / 2
+	}
+	minX := x
+	if bidi := classifyBidi(lines[0], face); bidi == bidiRTL || bidi == bidiMixed && baseIsRTL(lines[0]) {
+		minX = x - width
+	}
+	last := y + b.lineHeight(face)*float64(len(lines)-1)
+	return bbox{minX: minX, minY: y - ascent, maxX: minX + width, maxY: last + descent}
+}
+
+// viewBox returns the document's viewBox.
+func (b *Backend) viewBox() (x, y, w, h float64) {
+	if !b.autoCrop || b.contentBounds.empty() {
+		return 0, 0, float64(b.width), float64(b.height)
+	}
+	r := b.contentBounds
+	p := b.cropPadding
+	if b.cutline() {
+		// Keep the cut line inside the cropped document.
+		p += b.cutDistance
+	}
+	return r.minX - p, r.minY - p, r.maxX - r.minX + 2*p, r.maxY - r.minY + 2*p
+}
+
+// sizeAttrs returns the root element's width, height and viewBox
+// attributes. Width and height are omitted with WithResponsive.
+func (b *Backend) sizeAttrs() string {
+	x, y, w, h, width, height := b.outputBox()
+	if m := b.cropMarkMargin(); m > 0 {
+		width *= (w + 2*m) / w
+		height *= (h + 2*m) / h
+		x, y, w, h = x-m, y-m, w+2*m, h+2*m
+	}
+
+	var size string
+	if !b.responsive {
+		size = fmt.Sprintf(` width="%g" height="%g"`, width, height)
+	}
+	return size + fmt.Sprintf(` viewBox="%g %g %g %g"`, x, y, w, h)
+}
+
+// outputBox returns the viewBox of the document after WithRotation, and
+// its width and height.
+func (b *Backend) outputBox() (x, y, w, h, width, height float64) {
+	x, y, w, h = b.viewBox()
+	cropped := b.autoCrop && !b.contentBounds.empty()
+	width, height = float64(b.width), float64(b.height)
+	if cropped && b.cropResize {
+		width, height = w, h
+	}
+	if b.rotation != 0 {
+		// The rotation group maps the content onto the origin.
+		x, y = 0, 0
+	}
+	if b.quarterTurn() {
+		w, h = h, w
+		width, height = height, width
+	}
+	return x, y, w, h, width, height
+}
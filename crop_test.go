@@ -0,0 +1,82 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithAutoCrop(t *testing.T) {
+	backend := NewBackend(WithAutoCrop(2, false))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.Rect{MinX: 10, MinY: 20, MaxX: 30, MaxY: 25}, recording.NewSolidBrush(gg.Black))
+	backend.StrokePath(line(40, 30, 50, 30), recording.NewSolidBrush(gg.Black), recording.Stroke{Width: 4})
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `width="100" height="100" viewBox="8 18 46 16"`) {
+		t.Errorf("viewBox should fit the content plus padding, got:\n%s", svg)
+	}
+}
+
+func TestWithAutoCropResize(t *testing.T) {
+	backend := NewBackend(WithAutoCrop(0, true))
+	_ = backend.Begin(100, 100)
+	backend.SetTransform(recording.Translate(5, 5))
+	backend.FillRect(recording.Rect{MinX: 0, MinY: 0, MaxX: 10, MaxY: 20}, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `width="10" height="20" viewBox="5 5 10 20"`) {
+		t.Errorf("Size should match the transformed content, got:\n%s", svg)
+	}
+}
+
+func TestWithAutoCropClip(t *testing.T) {
+	clip := gg.NewPath()
+	clip.Rectangle(0, 0, 10, 10)
+
+	backend := NewBackend(WithAutoCrop(0, false), WithBackground(gg.RGBA{R: 1, G: 1, B: 1, A: 1}))
+	_ = backend.Begin(100, 100)
+	backend.Save()
+	backend.SetClip(clip, recording.FillRuleNonZero)
+	backend.FillRect(recording.Rect{MinX: 5, MinY: 5, MaxX: 50, MaxY: 50}, recording.NewSolidBrush(gg.Black))
+	backend.Restore()
+	backend.FillRect(recording.Rect{MinX: 20, MinY: 8, MaxX: 22, MaxY: 9}, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`viewBox="5 5 17 5"`,
+		`<rect x="5" y="5" width="17" height="5" fill="rgb(255,255,255)"/>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
+
+func TestWithAutoCropText(t *testing.T) {
+	face := testFace(t, 10)
+	backend := NewBackend(WithAutoCrop(0, true))
+	_ = backend.Begin(100, 100)
+	backend.DrawText("Hi", 10, 50, face, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	m := face.Metrics()
+	want := bbox{10, 50 - m.Ascent, 10 + face.Advance("Hi"), 50 + m.Descent}
+	if got := backend.contentBounds; got != want {
+		t.Errorf("contentBounds = %v, want %v", got, want)
+	}
+	if strings.Contains(svg, `viewBox="0 0 100 100"`) {
+		t.Errorf("Text should be cropped, got:\n%s", svg)
+	}
+}
+
+func TestWithAutoCropEmpty(t *testing.T) {
+	backend := NewBackend(WithAutoCrop(5, true))
+	_ = backend.Begin(30, 40)
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `width="30" height="40" viewBox="0 0 30 40"`) {
+		t.Errorf("An empty export should keep the canvas size, got:\n%s", svg)
+	}
+}
@@ -0,0 +1,116 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+const (
+	// cropMarkLength is the length of crop marks and the diameter of
+	// registration targets, in user units.
+	cropMarkLength = 12
+	// cropMarkGap separates the marks from the edge of the canvas.
+	cropMarkGap = 2
+)
+
+// WithCropMarks prepares the export for print production: the canvas is
+// enlarged by a bleed margin and a mark area around it, and crop marks at
+// the trim corners and registration targets at the middle of each edge
+// are drawn outside the bleed, in a separate Inkscape layer labeled "crop
+// marks". Content extending past the canvas shows in the bleed; the
+// background and WithHardClipToCanvas are extended to cover it.
+//
+// The marks are drawn in the registration color, black, with hairline
+// strokes. A negative bleed is treated as 0.
+func WithCropMarks(bleed float64) Option {
+	return func(b *Backend) {
+		b.cropMarks = true
+		b.bleed = max(bleed, 0)
+	}
+}
+
+// cropMarkMargin returns how far the canvas is enlarged on each side for
+// the bleed and the marks, or 0 without crop marks.
+func (b *Backend) cropMarkMargin() float64 {
+	if !b.cropMarks {
+		return 0
+	}
+	return b.bleed + cropMarkLength + cropMarkGap
+}
+
+// canvasClip returns the clip path definition used by
+// WithHardClipToCanvas.
+func (b *Backend) canvasClip() string {
+	if !b.cropMarks {
+		return fmt.Sprintf(`<clipPath id="%s"><rect width="%d" height="%d"/></clipPath>`,
+			b.idPrefix+canvasClipID, b.width, b.height)
+	}
+	return fmt.Sprintf(`<clipPath id="%s"><rect x="%g" y="%g" width="%g" height="%g"/></clipPath>`,
+		b.idPrefix+canvasClipID, -b.bleed, -b.bleed,
+		float64(b.width)+2*b.bleed, float64(b.height)+2*b.bleed)
+}
+
+// cropMarksGroup returns the crop marks layer, or "" without crop marks.
+func (b *Backend) cropMarksGroup() string {
+	if !b.cropMarks {
+		return ""
+	}
+	x, y, w, h, _, _ := b.outputBox()
+	x1, y1 := x+w, y+h
+	near := b.bleed + cropMarkGap
+	far := near + cropMarkLength
+
+	var g strings.Builder
+	out := svgwriter.New(&g)
+	out.StartElement("g")
+	out.Attr("id", b.idPrefix+"crop-marks")
+	out.Attr("inkscape:groupmode", "layer")
+	out.Attr("inkscape:label", "crop marks")
+	out.Attr("fill", "none")
+	out.Attr("stroke", b.paintColor(gg.Black))
+	out.Number("stroke-width", 0.25)
+	line := func(ax, ay, bx, by float64) {
+		out.StartElement("line")
+		out.Number("x1", ax)
+		out.Number("y1", ay)
+		out.Number("x2", bx)
+		out.Number("y2", by)
+		out.End()
+	}
+
+	// Crop marks extend the trim edges outward from each corner.
+	for _, cx := range [2]float64{x, x1} {
+		for _, cy := range [2]float64{y, y1} {
+			dx, dy := -1.0, -1.0
+			if cx == x1 {
+				dx = 1
+			}
+			if cy == y1 {
+				dy = 1
+			}
+			line(cx+dx*near, cy, cx+dx*far, cy)
+			line(cx, cy+dy*near, cx, cy+dy*far)
+		}
+	}
+
+	// Registration targets sit centered in the mark area of each edge.
+	mid := (near + far) / 2
+	r := cropMarkLength / 2.0
+	for _, c := range [4]gg.Point{
+		{X: x + w/2, Y: y - mid}, {X: x + w/2, Y: y1 + mid},
+		{X: x - mid, Y: y + h/2}, {X: x1 + mid, Y: y + h/2},
+	} {
+		out.StartElement("circle")
+		out.Number("cx", c.X)
+		out.Number("cy", c.Y)
+		out.Number("r", r/2)
+		out.End()
+		line(c.X-r, c.Y, c.X+r, c.Y)
+		line(c.X, c.Y-r, c.X, c.Y+r)
+	}
+	out.End()
+	return g.String()
+}
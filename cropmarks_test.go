@@ -0,0 +1,48 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithCropMarks(t *testing.T) {
+	backend := NewBackend(WithCropMarks(3), WithBackground(gg.White), WithHardClipToCanvas(true))
+	_ = backend.Begin(100, 50)
+	backend.FillRect(recording.NewRect(-3, -3, 106, 56), recording.NewSolidBrush(gg.Red))
+	svg := writeSVG(t, backend)
+
+	// The margin is the bleed plus the mark length and gap: 3 + 12 + 2.
+	expected := []string{
+		`width="134" height="84" viewBox="-17 -17 134 84"`,
+		`xmlns:inkscape=`,
+		`<rect x="-3" y="-3" width="106" height="56" fill="rgb(255,255,255)"/>`,
+		`<clipPath id="canvas-clip"><rect x="-3" y="-3" width="106" height="56"/></clipPath>`,
+		`<g id="crop-marks" inkscape:groupmode="layer" inkscape:label="crop marks" fill="none" stroke="rgb(0,0,0)" stroke-width="0.25">`,
+		// Top-left corner marks.
+		`<line x1="-5" y1="0" x2="-17" y2="0"/><line x1="0" y1="-5" x2="0" y2="-17"/>`,
+		// Bottom-right corner marks.
+		`<line x1="105" y1="50" x2="117" y2="50"/><line x1="100" y1="55" x2="100" y2="67"/>`,
+		// Registration target above the top edge.
+		`<circle cx="50" cy="-11" r="3"/>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if !strings.HasSuffix(svg, "</g>\n</svg>\n") || strings.Index(svg, "crop-marks") < strings.Index(svg, "rgb(255,0,0)") {
+		t.Error("Crop marks should be drawn last")
+	}
+}
+
+func TestWithCropMarksRotated(t *testing.T) {
+	backend := NewBackend(WithCropMarks(0), WithRotation(90))
+	_ = backend.Begin(100, 50)
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `width="78" height="128" viewBox="-14 -14 78 128"`) {
+		t.Errorf("Crop marks should frame the rotated canvas, got:\n%s", svg)
+	}
+}
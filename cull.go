@@ -0,0 +1,63 @@
+package svg
+
+import (
+	"math"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+// WithCulling skips elements whose transformed bounds fall entirely
+// outside the canvas, so recordings replayed from
+// panned or zoomed scenes don't export invisible geometry.
+//
+// Bounds are conservative: strokes are grown by their joins and caps, and
+// text by its font size around the estimated extent, so partly visible
+// elements are always kept. Culling is disabled with WithAutoCrop, whose
+// viewBox fits everything drawn. With WithCropMarks the bleed and mark
+// area count as visible.
+func WithCulling(enabled bool) Option {
+	return func(b *Backend) {
+		b.culling = enabled
+	}
+}
+
+// culled reports whether the user-space rectangle lies outside the
+// visible area and should be skipped.
+func (b *Backend) culled(minX, minY, maxX, maxY float64) bool {
+	if !b.culling || b.autoCrop {
+		return false
+	}
+	m := b.cropMarkMargin()
+	view := bbox{-m, -m, float64(b.width) + m, float64(b.height) + m}
+	return b.transformedBounds(minX, minY, maxX, maxY).intersect(view).empty()
+}
+
+// culledPath reports whether path, grown by pad, is culled.
+func (b *Backend) culledPath(path *gg.Path, pad float64) bool {
+	return b.culling && b.culled(pathBounds(path, pad))
+}
+
+// culledText reports whether text lines drawn at x, y are culled.
+func (b *Backend) culledText(lines []string, x, y float64, face text.Face) bool {
+	if !b.culling || len(lines) == 0 {
+		return false
+	}
+	r := b.textBounds(lines, x, y, face)
+	size := b.fontSize(face)
+	return b.culled(r.minX-size, r.minY-size, r.maxX+size, r.maxY+size)
+}
+
+// strokeExtent returns how far a stroke can reach beyond its path.
+func strokeExtent(stroke recording.Stroke) float64 {
+	scale := math.Sqrt2 // square caps at 45°
+	if stroke.Join == recording.LineJoinMiter {
+		limit := stroke.MiterLimit
+		if limit <= 0 {
+			limit = 4
+		}
+		scale = math.Max(scale, limit)
+	}
+	return stroke.Width / 2 * scale
+}
@@ -0,0 +1,56 @@
+package svg
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithCulling(t *testing.T) {
+	backend := NewBackend(WithCulling(true))
+	_ = backend.Begin(100, 100)
+	red := recording.NewSolidBrush(gg.Red)
+	line := gg.NewPath()
+	line.MoveTo(-50, -20)
+	line.LineTo(-10, -20)
+
+	backend.FillRect(recording.NewRect(200, 0, 10, 10), red)
+	backend.SetTransform(recording.Translate(-500, 0))
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), red)
+	backend.DrawText("far away", 0, 50, nil, red)
+	backend.DrawImage(image.NewRGBA(image.Rect(0, 0, 2, 2)), recording.Rect{}, recording.NewRect(0, 0, 10, 10), recording.ImageOptions{Alpha: 1})
+	backend.SetTransform(recording.Identity())
+	backend.StrokePath(line, red, recording.DefaultStroke())
+
+	out := backend.String()
+	for _, tag := range []string{"<rect", "<text", "<image", "<path"} {
+		if strings.Contains(out, tag) {
+			t.Errorf("Off-canvas %s should be culled:\n%s", tag, out)
+		}
+	}
+
+	// Partly visible elements and wide strokes reaching the canvas stay.
+	backend.FillRect(recording.NewRect(95, 95, 10, 10), red)
+	wide := recording.DefaultStroke()
+	wide.Width = 60
+	backend.StrokePath(line, red, wide)
+	backend.DrawText("edge", 98, 50, nil, red)
+	out = backend.String()
+	for _, tag := range []string{"<rect", "<path", "<text"} {
+		if !strings.Contains(out, tag) {
+			t.Errorf("Partly visible %s should be kept:\n%s", tag, out)
+		}
+	}
+}
+
+func TestWithCullingAutoCrop(t *testing.T) {
+	backend := NewBackend(WithCulling(true), WithAutoCrop(0, true))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(200, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	if !strings.Contains(backend.String(), "<rect") {
+		t.Error("Culling should be disabled with WithAutoCrop")
+	}
+}
@@ -0,0 +1,378 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// cutlineTolerance is the curve flattening tolerance for cut line
+// geometry, in user units.
+const cutlineTolerance = 0.1
+
+// maxCutlinePoints caps the number of grid points of the cut line
+// distance field; the grid is coarsened to stay within it.
+const maxCutlinePoints = 1 << 20
+
+// cutlineColor is the conventional "CutContour" spot color.
+var cutlineColor = gg.RGBA{R: 1, B: 1, A: 1}
+
+// WithCutline adds a cut line around the union of all drawn content,
+// offset outward by distance, as required for stickers and die-cut
+// production. The cut line is written last, as a magenta hairline in a
+// separate Inkscape layer labeled "cutline"; holes in the content are
+// not cut. Clips are ignored, and text is approximated by its estimated
+// bounds. A distance of 0 or less disables the cut line.
+//
+// The outline is traced from a sampled distance field, so it follows the
+// content to within a quarter of the distance, or a 400th of the size of
+// the cut line if that is coarser.
+func WithCutline(distance float64) Option {
+	return func(b *Backend) {
+		b.cutDistance = distance
+	}
+}
+
+// cutShape is drawn content in document coordinates, as straight edges.
+type cutShape struct {
+	edges []segment
+	// filled reports whether the interior is covered, as for fills.
+	filled bool
+	// radius is the covered distance around the edges, as for strokes.
+	radius float64
+}
+
+// cutline reports whether a cut line is generated.
+func (b *Backend) cutline() bool {
+	return b.cutDistance > 0
+}
+
+// addCutPath adds the geometry of a drawn path to the cut line. Filled
+// paths cover their interior; radius covers the area around the edges.
+func (b *Backend) addCutPath(path *gg.Path, filled bool, radius float64) {
+	if !b.cutline() {
+		return
+	}
+	m := b.currentTransform
+	shape := cutShape{filled: filled, radius: radius * m.ScaleFactor()}
+	for _, sp := range splitSubpaths(path) {
+		points := sp.Flatten(cutlineTolerance)
+		for i := range points {
+			points[i].X, points[i].Y = m.TransformPoint(points[i].X, points[i].Y)
+		}
+		for i := 1; i < len(points); i++ {
+			shape.edges = append(shape.edges, segment{points[i-1], points[i]})
+		}
+		if len(points) > 0 && (filled || isClosed(sp)) {
+			shape.edges = append(shape.edges, segment{points[len(points)-1], points[0]})
+		}
+	}
+	if len(shape.edges) > 0 {
+		b.cutShapes = append(b.cutShapes, shape)
+	}
+}
+
+// addCutRect adds a filled rectangle to the cut line.
+func (b *Backend) addCutRect(minX, minY, maxX, maxY float64) {
+	if b.cutline() {
+		b.addCutPath(rectPath(recording.Rect{MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY}), true, 0)
+	}
+}
+
+// cutlineGroup returns the cut line layer, or "" if there is none.
+func (b *Backend) cutlineGroup() string {
+	if !b.cutline() || len(b.cutShapes) == 0 {
+		return ""
+	}
+	loops := traceCutline(b.cutShapes, b.cutDistance)
+	if len(loops) == 0 {
+		return ""
+	}
+	var d strings.Builder
+	for _, loop := range loops {
+		for i, p := range loop {
+			if i == 0 {
+				d.WriteString("M")
+			} else {
+				d.WriteString("L")
+			}
+			d.WriteString(fmt.Sprintf("%g %g", math.Round(p.X*1000)/1000, math.Round(p.Y*1000)/1000))
+		}
+		d.WriteString("Z")
+	}
+	return fmt.Sprintf(`<g id="%scutline" inkscape:groupmode="layer" inkscape:label="cutline">`+
+		`<path d="%s" fill="none" stroke="%s" stroke-width="0.25"/></g>`,
+		b.idPrefix, d.String(), b.paintColor(cutlineColor))
+}
+
+// traceCutline returns the outer contours of the area within distance of
+// the shapes, as closed polygons.
+func traceCutline(shapes []cutShape, distance float64) [][]gg.Point {
+	// Sample a field that is positive inside the offset area on a grid
+	// with a margin of empty cells around it.
+	bounds := emptyBBox()
+	reach := 0.0
+	for _, s := range shapes {
+		reach = math.Max(reach, s.radius)
+		for _, e := range s.edges {
+			bounds = bounds.union(bbox{math.Min(e.a.X, e.b.X), math.Min(e.a.Y, e.b.Y), math.Max(e.a.X, e.b.X), math.Max(e.a.Y, e.b.Y)})
+		}
+	}
+	// The grid resolution follows the padded area, which wide strokes
+	// make much larger than the edges.
+	pad := distance + reach
+	w, h := bounds.maxX-bounds.minX+2*pad, bounds.maxY-bounds.minY+2*pad
+	step := math.Max(distance/4, math.Max(w, h)/400)
+	if points := (w/step + 5) * (h/step + 5); points > maxCutlinePoints {
+		step *= math.Sqrt(points / maxCutlinePoints)
+	}
+	margin := pad + 2*step
+	g := newCutGrid(bbox{bounds.minX - margin, bounds.minY - margin, bounds.maxX + margin, bounds.maxY + margin}, step)
+
+	for _, s := range shapes {
+		r := s.radius + distance
+		for _, e := range s.edges {
+			g.coverEdge(e, r)
+		}
+		if s.filled {
+			g.coverInterior(s.edges, r)
+		}
+	}
+	g.fillHoles()
+	return g.contours()
+}
+
+// cutGrid samples the cut line distance field at grid points.
+type cutGrid struct {
+	origin gg.Point
+	step   float64
+	nx, ny int
+	// field holds, per grid point, how far inside the offset area it is;
+	// points outside have negative values.
+	field []float64
+}
+
+func newCutGrid(r bbox, step float64) *cutGrid {
+	nx := int(math.Ceil((r.maxX-r.minX)/step)) + 1
+	ny := int(math.Ceil((r.maxY-r.minY)/step)) + 1
+	g := &cutGrid{origin: gg.Point{X: r.minX, Y: r.minY}, step: step, nx: nx, ny: ny, field: make([]float64, nx*ny)}
+	for i := range g.field {
+		g.field[i] = -math.MaxFloat64
+	}
+	return g
+}
+
+// point returns the position of grid point i, j.
+func (g *cutGrid) point(i, j int) gg.Point {
+	return gg.Point{X: g.origin.X + float64(i)*g.step, Y: g.origin.Y + float64(j)*g.step}
+}
+
+// cover raises the field at grid point i, j to at least v.
+func (g *cutGrid) cover(i, j int, v float64) {
+	if k := j*g.nx + i; v > g.field[k] {
+		g.field[k] = v
+	}
+}
+
+// coverEdge covers the grid points within r of edge e.
+func (g *cutGrid) coverEdge(e segment, r float64) {
+	i0 := max(0, int((math.Min(e.a.X, e.b.X)-r-g.origin.X)/g.step))
+	i1 := min(g.nx-1, int((math.Max(e.a.X, e.b.X)+r-g.origin.X)/g.step)+1)
+	j0 := max(0, int((math.Min(e.a.Y, e.b.Y)-r-g.origin.Y)/g.step))
+	j1 := min(g.ny-1, int((math.Max(e.a.Y, e.b.Y)+r-g.origin.Y)/g.step)+1)
+	for j := j0; j <= j1; j++ {
+		for i := i0; i <= i1; i++ {
+			g.cover(i, j, r-segmentDistance(g.point(i, j), e))
+		}
+	}
+}
+
+// coverInterior covers the grid points inside the closed outline formed
+// by edges, using the nonzero rule.
+func (g *cutGrid) coverInterior(edges []segment, r float64) {
+	type crossing struct {
+		x       float64
+		winding int
+	}
+	var xs []crossing
+	for j := range g.ny {
+		y := g.point(0, j).Y
+		xs = xs[:0]
+		for _, e := range edges {
+			if (e.a.Y <= y) == (e.b.Y <= y) {
+				continue
+			}
+			w := 1
+			if e.b.Y < e.a.Y {
+				w = -1
+			}
+			xs = append(xs, crossing{e.a.X + (y-e.a.Y)/(e.b.Y-e.a.Y)*(e.b.X-e.a.X), w})
+		}
+		if len(xs) == 0 {
+			continue
+		}
+		for i := range g.nx {
+			x, winding := g.point(i, j).X, 0
+			for _, c := range xs {
+				if c.x < x {
+					winding += c.winding
+				}
+			}
+			if winding != 0 {
+				g.cover(i, j, r)
+			}
+		}
+	}
+}
+
+// fillHoles covers the grid points outside the offset area that are not
+// connected to the grid border, so only outer contours are traced.
+func (g *cutGrid) fillHoles() {
+	outside := make([]bool, len(g.field))
+	var queue []int
+	for j := range g.ny {
+		for i := range g.nx {
+			if i == 0 || j == 0 || i == g.nx-1 || j == g.ny-1 {
+				k := j*g.nx + i
+				outside[k] = true
+				queue = append(queue, k)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		k := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		i, j := k%g.nx, k/g.nx
+		for _, n := range [4][2]int{{i - 1, j}, {i + 1, j}, {i, j - 1}, {i, j + 1}} {
+			if n[0] < 0 || n[1] < 0 || n[0] >= g.nx || n[1] >= g.ny {
+				continue
+			}
+			if nk := n[1]*g.nx + n[0]; !outside[nk] && g.field[nk] <= 0 {
+				outside[nk] = true
+				queue = append(queue, nk)
+			}
+		}
+	}
+	for k, out := range outside {
+		if !out && g.field[k] <= 0 {
+			g.field[k] = g.step / 100
+		}
+	}
+}
+
+// cutEdgeKey identifies a grid edge: the grid point it starts at and
+// whether it runs down rather than right.
+type cutEdgeKey struct {
+	i, j int
+	down bool
+}
+
+// contours traces the zero level of the field with marching squares and
+// returns closed polygons.
+func (g *cutGrid) contours() [][]gg.Point {
+	points := make(map[cutEdgeKey]gg.Point)
+	links := make(map[cutEdgeKey][]cutEdgeKey)
+	// locate places the crossing on an edge, interpolated along the field.
+	locate := func(k cutEdgeKey) {
+		i2, j2 := k.i+1, k.j
+		if k.down {
+			i2, j2 = k.i, k.j+1
+		}
+		v1, v2 := g.field[k.j*g.nx+k.i], g.field[j2*g.nx+i2]
+		points[k] = lerp(g.point(k.i, k.j), g.point(i2, j2), v1/(v1-v2))
+	}
+	link := func(a, b cutEdgeKey) {
+		locate(a)
+		locate(b)
+		links[a] = append(links[a], b)
+		links[b] = append(links[b], a)
+	}
+
+	for j := range g.ny - 1 {
+		for i := range g.nx - 1 {
+			v := [4]float64{g.field[j*g.nx+i], g.field[j*g.nx+i+1], g.field[(j+1)*g.nx+i+1], g.field[(j+1)*g.nx+i]}
+			in := [4]bool{v[0] > 0, v[1] > 0, v[2] > 0, v[3] > 0}
+			top, right := cutEdgeKey{i, j, false}, cutEdgeKey{i + 1, j, true}
+			bottom, left := cutEdgeKey{i, j + 1, false}, cutEdgeKey{i, j, true}
+
+			var crossed []cutEdgeKey
+			for n, e := range [4]cutEdgeKey{top, right, bottom, left} {
+				if in[n] != in[(n+1)%4] {
+					crossed = append(crossed, e)
+				}
+			}
+			switch len(crossed) {
+			case 2:
+				link(crossed[0], crossed[1])
+			case 4:
+				// Saddle: the center decides whether the inside corners connect.
+				center := (v[0]+v[1]+v[2]+v[3])/4 > 0
+				if center == in[0] {
+					link(top, right)
+					link(bottom, left)
+				} else {
+					link(top, left)
+					link(right, bottom)
+				}
+			}
+		}
+	}
+
+	var loops [][]gg.Point
+	visited := make(map[cutEdgeKey]bool)
+	for j := range g.ny {
+		for i := range g.nx {
+			for _, down := range [2]bool{false, true} {
+				start := cutEdgeKey{i, j, down}
+				if visited[start] || len(links[start]) == 0 {
+					continue
+				}
+				var loop []gg.Point
+				prev, cur := start, start
+				for {
+					visited[cur] = true
+					loop = append(loop, points[cur])
+					next := links[cur][0]
+					if next == prev && len(links[cur]) > 1 {
+						next = links[cur][1]
+					}
+					prev, cur = cur, next
+					if cur == start || visited[cur] {
+						break
+					}
+				}
+				if loop = simplifyLoop(loop, g.step/50); len(loop) >= 3 {
+					loops = append(loops, loop)
+				}
+			}
+		}
+	}
+	return loops
+}
+
+// simplifyLoop drops the points of a closed polygon that lie within
+// tolerance of the line through their neighbors.
+func simplifyLoop(loop []gg.Point, tolerance float64) []gg.Point {
+	var out []gg.Point
+	for i, p := range loop {
+		if len(out) > 0 && i+1 < len(loop) &&
+			segmentDistance(p, segment{out[len(out)-1], loop[i+1]}) <= tolerance {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// segmentDistance returns the distance from p to the segment e.
+func segmentDistance(p gg.Point, e segment) float64 {
+	dx, dy := e.b.X-e.a.X, e.b.Y-e.a.Y
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((p.X-e.a.X)*dx+(p.Y-e.a.Y)*dy)/l))
+	}
+	return math.Hypot(p.X-e.a.X-t*dx, p.Y-e.a.Y-t*dy)
+}
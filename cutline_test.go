@@ -0,0 +1,100 @@
+package svg
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithCutline(t *testing.T) {
+	backend := NewBackend(WithCutline(5))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(10, 10, 20, 20), recording.NewSolidBrush(gg.Red))
+	backend.StrokePath(line(60, 50, 90, 50), recording.NewSolidBrush(gg.Black), recording.DefaultStroke())
+	svg := writeSVG(t, backend)
+
+	expected := []string{
+		`xmlns:inkscape=`,
+		`<g id="cutline" inkscape:groupmode="layer" inkscape:label="cutline"><path d="M`,
+		`Z" fill="none" stroke="rgb(255,0,255)" stroke-width="0.25"/></g>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if strings.Index(svg, `id="cutline"`) < strings.Index(svg, "<rect") {
+		t.Error("The cut line should be written after the content")
+	}
+
+	plain := NewBackend()
+	_ = plain.Begin(100, 100)
+	plain.FillRect(recording.NewRect(10, 10, 20, 20), recording.NewSolidBrush(gg.Red))
+	if svg := writeSVG(t, plain); strings.Contains(svg, "cutline") {
+		t.Errorf("Cut line should be off by default, got:\n%s", svg)
+	}
+}
+
+func TestTraceCutline(t *testing.T) {
+	square := cutShape{filled: true, edges: []segment{
+		{gg.Point{X: 10, Y: 10}, gg.Point{X: 30, Y: 10}},
+		{gg.Point{X: 30, Y: 10}, gg.Point{X: 30, Y: 30}},
+		{gg.Point{X: 30, Y: 30}, gg.Point{X: 10, Y: 30}},
+		{gg.Point{X: 10, Y: 30}, gg.Point{X: 10, Y: 10}},
+	}}
+	// A ring whose hole must not be cut.
+	ring := cutShape{radius: 2, edges: []segment{
+		{gg.Point{X: 100, Y: 10}, gg.Point{X: 140, Y: 10}},
+		{gg.Point{X: 140, Y: 10}, gg.Point{X: 140, Y: 50}},
+		{gg.Point{X: 140, Y: 50}, gg.Point{X: 100, Y: 50}},
+		{gg.Point{X: 100, Y: 50}, gg.Point{X: 100, Y: 10}},
+	}}
+
+	loops := traceCutline([]cutShape{square, ring}, 4)
+	if len(loops) != 2 {
+		t.Fatalf("Expected one outline per separate shape, got %d", len(loops))
+	}
+	for _, loop := range loops {
+		for _, p := range loop {
+			shape, offset := square, 4.0
+			if p.X > 60 {
+				shape, offset = ring, 6
+			}
+			d := math.Inf(1)
+			for _, e := range shape.edges {
+				d = math.Min(d, segmentDistance(p, e))
+			}
+			if math.Abs(d-offset) > 0.5 {
+				t.Errorf("Outline point %v is %g from the content, expected %g", p, d, offset)
+			}
+		}
+	}
+}
+
+func TestWithCutlineWideStroke(t *testing.T) {
+	// The grid follows the stroke's reach, not the edges, so a wide stroke
+	// on a short line does not sample millions of cells.
+	backend := NewBackend(WithCutline(1))
+	_ = backend.Begin(100, 100)
+	stroke := recording.DefaultStroke()
+	stroke.Width = 3000
+	backend.StrokePath(line(45, 50, 55, 50), recording.NewSolidBrush(gg.Black), stroke)
+
+	start := time.Now()
+	svg := writeSVG(t, backend)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Tracing the cut line took %v", elapsed)
+	}
+	if !strings.Contains(svg, `id="cutline"`) {
+		t.Errorf("Output should contain the cut line, got:\n%s", svg)
+	}
+
+	loops := traceCutline([]cutShape{{radius: 1e7, edges: []segment{{gg.Point{X: 0, Y: 0}, gg.Point{X: 1, Y: 0}}}}}, 0.001)
+	if len(loops) != 1 {
+		t.Errorf("Expected one outline, got %d", len(loops))
+	}
+}
@@ -0,0 +1,1145 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // decode embedded JPEG images
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// Decode parses an SVG document into a recording, so existing SVG assets
+// can be replayed onto any gg backend, including the raster and GPU
+// backends.
+//
+// Decode supports the shape elements (path, rect, circle, ellipse, line,
+// polyline, polygon), groups, nested svg elements and use references,
+// transforms, the viewBox with preserveAspectRatio, fills and strokes
+// with their presentation attributes and style declarations, linear and
+// radial gradients, clip paths, text and embedded data: URI images.
+//
+// Recordings have no transform state of their own at playback, so
+// transforms are applied to the recorded coordinates. Stroke widths, font
+// sizes and gradient radii are scaled by the transform's average scale,
+// and images drawn under a rotation or skew fill their transformed
+// bounding box. Group opacity is multiplied into the opacity of each
+// descendant. Filters, masks, markers, patterns, CSS style sheets and
+// external resources are ignored.
+//
+// The canvas size is taken from the width and height attributes of the
+// root element, or from its viewBox, and is 300 by 150 if neither is set.
+//
+// Since use references can multiply a small document into a huge
+// recording, Decode returns an error wrapping ErrDecodeLimit if the
+// document expands to more than 2^20 elements, counting every use
+// expansion, or 2^24 path commands, or embeds images of more than 2^26
+// pixels in total. Image sizes are checked before the images are decoded.
+func Decode(r io.Reader) (*recording.Recording, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, root, _, err := parseElementTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("svg: decode: %w", err)
+	}
+	if localName(root.Name) != "svg" {
+		return nil, fmt.Errorf("svg: decode: root element is <%s>, not <svg>", root.Name)
+	}
+
+	d := &decoder{ids: make(map[string]*Element), images: make(map[string]image.Image)}
+	root.Walk(func(e *Element) bool {
+		if id, ok := e.Attr("id"); ok {
+			if _, dup := d.ids[id]; !dup {
+				d.ids[id] = e
+			}
+		}
+		return true
+	})
+
+	width, height, m := d.viewport(root, 300, 150)
+	d.width, d.height = width, height
+	d.rec = recording.NewRecorder(int(math.Ceil(width)), int(math.Ceil(height)))
+	st := defaultDecodeStyle()
+	st.transform = m
+	d.children(root, st)
+	if d.err != nil {
+		return nil, fmt.Errorf("svg: decode: %w", d.err)
+	}
+	return d.rec.FinishRecording(), nil
+}
+
+// ignoredElements names the features of elements Decode ignores.
+var ignoredElements = map[string]string{
+	"filter":           "filters",
+	"mask":             "masks",
+	"marker":           "markers",
+	"pattern":          "patterns",
+	"style":            "CSS style sheets",
+	"foreignObject":    "foreign objects",
+	"animate":          "animations",
+	"animateMotion":    "animations",
+	"animateTransform": "animations",
+	"set":              "animations",
+}
+
+// ignoredProperties names the features of properties Decode ignores.
+var ignoredProperties = map[string]string{
+	"filter":       "filters",
+	"mask":         "masks",
+	"marker":       "markers",
+	"marker-start": "markers",
+	"marker-mid":   "markers",
+	"marker-end":   "markers",
+}
+
+// DecodeIgnored lists the features of the SVG document in r that Decode
+// drops, such as "filters" or "CSS style sheets", in sorted order, so
+// tools can warn that the decoded drawing differs from the document.
+func DecodeIgnored(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, root, _, err := parseElementTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("svg: decode: %w", err)
+	}
+	found := make(map[string]bool)
+	root.Walk(func(e *Element) bool {
+		if feature, ok := ignoredElements[localName(e.Name)]; ok {
+			found[feature] = true
+		}
+		for name, feature := range ignoredProperties {
+			if v := attrOrStyle(e, name); v != "" && v != "none" {
+				found[feature] = true
+			}
+		}
+		if localName(e.Name) == "image" && !strings.HasPrefix(hrefValue(e), "data:") {
+			found["external images"] = true
+		}
+		return true
+	})
+	return slices.Sorted(maps.Keys(found)), nil
+}
+
+// maxUseDepth bounds the nesting of use references, which can be cyclic.
+const maxUseDepth = 16
+
+// Limits on the recording Decode produces. They are variables so tests
+// can lower them.
+var (
+	// maxDecodeElements bounds the elements drawn, counting every
+	// expansion of a use reference.
+	maxDecodeElements = 1 << 20
+	// maxDecodeCommands bounds the path commands recorded.
+	maxDecodeCommands = 1 << 24
+	// maxDecodePixels bounds the pixels of the distinct embedded images.
+	maxDecodePixels = 1 << 26
+)
+
+// ErrDecodeLimit is returned by Decode when a document exceeds one of its
+// limits.
+var ErrDecodeLimit = errors.New("svg: decode limit exceeded")
+
+// decoder records the elements of a parsed document.
+type decoder struct {
+	rec           *recording.Recorder
+	ids           map[string]*Element
+	width, height float64
+	useDepth      int
+
+	// images caches decoded data: URIs by href, nil if they are invalid.
+	images map[string]image.Image
+
+	elements, commands, pixels int
+	// err is the limit exceeded, after which nothing more is recorded.
+	err error
+}
+
+// paint is a fill or stroke value.
+type paint struct {
+	none    bool
+	current bool // currentColor
+	color   gg.RGBA
+	ref     string // gradient ID of url(#id)
+	// fallback is used when ref does not name a gradient.
+	fallback *paint
+}
+
+// decodeStyle is the computed style of an element.
+type decodeStyle struct {
+	transform     recording.Matrix
+	fill, stroke  paint
+	color         gg.RGBA
+	opacity       float64
+	fillOpacity   float64
+	strokeOpacity float64
+	fillRule      recording.FillRule
+	clipRule      recording.FillRule
+	strokeWidth   float64
+	lineCap       recording.LineCap
+	lineJoin      recording.LineJoin
+	miterLimit    float64
+	dashes        []float64
+	dashOffset    float64
+	fontSize      float64
+	fontFamily    string
+	displayNone   bool // not inherited
+	invisible     bool
+}
+
+// defaultDecodeStyle returns the initial values of the SVG properties.
+func defaultDecodeStyle() decodeStyle {
+	return decodeStyle{
+		transform:     recording.Identity(),
+		fill:          paint{color: gg.Black},
+		stroke:        paint{none: true},
+		color:         gg.Black,
+		opacity:       1,
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		miterLimit:    4,
+		fontSize:      16,
+	}
+}
+
+// viewport returns the size of an svg element, defaulting to the given
+// size, and the transform from its viewBox to its coordinate system.
+func (d *decoder) viewport(e *Element, defWidth, defHeight float64) (width, height float64, m recording.Matrix) {
+	vb := parseNumberList(attrValue(e, "viewBox"))
+	hasVB := len(vb) == 4 && vb[2] > 0 && vb[3] > 0
+	if hasVB {
+		defWidth, defHeight = vb[2], vb[3]
+	}
+	width, height = defWidth, defHeight
+	if w, ok := parseLength(attrValue(e, "width"), defWidth, 16); ok && w > 0 {
+		width = w
+	}
+	if h, ok := parseLength(attrValue(e, "height"), defHeight, 16); ok && h > 0 {
+		height = h
+	}
+	if !hasVB {
+		return width, height, recording.Identity()
+	}
+	return width, height, viewBoxTransform(vb, width, height, attrValue(e, "preserveAspectRatio"))
+}
+
+// viewBoxTransform maps the viewBox vb onto a width by height viewport
+// as preserveAspectRatio par specifies.
+func viewBoxTransform(vb []float64, width, height float64, par string) recording.Matrix {
+	sx, sy := width/vb[2], height/vb[3]
+	fields := strings.Fields(par)
+	align, slice := "xMidYMid", false
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if len(fields) > 1 {
+		slice = fields[1] == "slice"
+	}
+	if align == "none" {
+		return recording.Matrix{A: sx, C: -vb[0] * sx, E: sy, F: -vb[1] * sy}
+	}
+	s := math.Min(sx, sy)
+	if slice {
+		s = math.Max(sx, sy)
+	}
+	tx, ty := -vb[0]*s, -vb[1]*s
+	switch {
+	case strings.Contains(align, "xMid"):
+		tx += (width - vb[2]*s) / 2
+	case strings.Contains(align, "xMax"):
+		tx += width - vb[2]*s
+	}
+	switch {
+	case strings.Contains(align, "YMid"):
+		ty += (height - vb[3]*s) / 2
+	case strings.Contains(align, "YMax"):
+		ty += height - vb[3]*s
+	}
+	return recording.Matrix{A: s, C: tx, E: s, F: ty}
+}
+
+// children renders the child elements of e.
+func (d *decoder) children(e *Element, st decodeStyle) {
+	for _, c := range e.Children {
+		if c.Name != "" {
+			d.element(c, st)
+		}
+	}
+}
+
+// element renders e with the computed style of its parent.
+func (d *decoder) element(e *Element, parent decodeStyle) {
+	name := localName(e.Name)
+	switch name {
+	case "g", "a", "switch", "svg", "use", "path", "rect", "circle", "ellipse",
+		"line", "polyline", "polygon", "text", "image":
+	default:
+		// Definitions are rendered where they are referenced; everything
+		// else is not supported.
+		return
+	}
+	if d.err != nil {
+		return
+	}
+	if d.elements++; d.elements > maxDecodeElements {
+		d.err = fmt.Errorf("%w: more than %d elements", ErrDecodeLimit, maxDecodeElements)
+		return
+	}
+	st := d.computeStyle(e, parent)
+	if st.displayNone {
+		return
+	}
+
+	if clip := d.clipPath(e, st); clip != nil {
+		d.rec.Save()
+		defer d.rec.Restore()
+		d.emitPath(clip, recording.Identity())
+		d.rec.SetFillRule(st.clipRule)
+		d.rec.Clip()
+	}
+
+	switch name {
+	case "svg":
+		x, y := d.length(e, "x", d.width), d.length(e, "y", d.height)
+		_, _, m := d.viewport(e, d.width, d.height)
+		st.transform = st.transform.Multiply(recording.Translate(x, y)).Multiply(m)
+		d.children(e, st)
+	case "use":
+		d.use(e, st)
+	case "text":
+		d.text(e, st)
+	case "image":
+		d.image(e, st)
+	case "g", "a", "switch":
+		d.children(e, st)
+	default:
+		if path := d.shape(e, name); path != nil {
+			d.draw(path, st)
+		}
+	}
+}
+
+// computeStyle applies the transform, presentation attributes and style
+// declarations of e to the inherited style.
+func (d *decoder) computeStyle(e *Element, parent decodeStyle) decodeStyle {
+	st := parent
+	st.displayNone = false
+	st.dashes = append([]float64(nil), parent.dashes...)
+	if t, ok := e.Attr("transform"); ok {
+		st.transform = st.transform.Multiply(parseTransform(t))
+	}
+
+	props := make(map[string]string)
+	for _, a := range e.Attrs {
+		props[a.Name] = a.Value
+	}
+	for _, decl := range strings.Split(props["style"], ";") {
+		if name, value, ok := strings.Cut(decl, ":"); ok {
+			props[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	// color is needed to resolve currentColor in the other properties.
+	if v, ok := props["color"]; ok {
+		if c, ok := parseColor(v); ok {
+			st.color = c
+		}
+	}
+	for name, v := range props {
+		v = strings.TrimSpace(v)
+		if v == "inherit" {
+			continue
+		}
+		switch name {
+		case "fill":
+			st.fill = parsePaint(v, parent.fill)
+		case "stroke":
+			st.stroke = parsePaint(v, parent.stroke)
+		case "opacity":
+			st.opacity *= parseOpacity(v, 1)
+		case "fill-opacity":
+			st.fillOpacity = parseOpacity(v, parent.fillOpacity)
+		case "stroke-opacity":
+			st.strokeOpacity = parseOpacity(v, parent.strokeOpacity)
+		case "fill-rule":
+			st.fillRule = parseFillRule(v)
+		case "clip-rule":
+			st.clipRule = parseFillRule(v)
+		case "stroke-width":
+			if w, ok := parseLength(v, d.diagonal(), st.fontSize); ok && w >= 0 {
+				st.strokeWidth = w
+			}
+		case "stroke-linecap":
+			st.lineCap = map[string]recording.LineCap{"round": recording.LineCapRound, "square": recording.LineCapSquare}[v]
+		case "stroke-linejoin":
+			st.lineJoin = map[string]recording.LineJoin{"round": recording.LineJoinRound, "bevel": recording.LineJoinBevel}[v]
+		case "stroke-miterlimit":
+			if l, err := strconv.ParseFloat(v, 64); err == nil && l >= 1 {
+				st.miterLimit = l
+			}
+		case "stroke-dasharray":
+			st.dashes = parseDashes(v)
+		case "stroke-dashoffset":
+			if o, ok := parseLength(v, d.diagonal(), st.fontSize); ok {
+				st.dashOffset = o
+			}
+		case "font-size":
+			if s, ok := parseLength(v, parent.fontSize, parent.fontSize); ok && s > 0 {
+				st.fontSize = s
+			}
+		case "font-family":
+			family, _, _ := strings.Cut(v, ",")
+			st.fontFamily = strings.Trim(strings.TrimSpace(family), `'"`)
+		case "display":
+			st.displayNone = v == "none"
+		case "visibility":
+			// Unlike display, visibility is inherited and can be reset by
+			// descendants.
+			st.invisible = v == "hidden" || v == "collapse"
+		}
+	}
+	return st
+}
+
+// diagonal returns the reference length for percentages that are neither
+// horizontal nor vertical.
+func (d *decoder) diagonal() float64 {
+	return math.Hypot(d.width, d.height) / math.Sqrt2
+}
+
+// length returns the attribute name of e as a user-space length, with
+// percentages relative to ref.
+func (d *decoder) length(e *Element, name string, ref float64) float64 {
+	v, _ := parseLength(attrValue(e, name), ref, 16)
+	return v
+}
+
+// shape returns the path of a basic shape element in its user space.
+func (d *decoder) shape(e *Element, name string) *gg.Path {
+	w, h, diag := d.width, d.height, d.diagonal()
+	path := gg.NewPath()
+	switch name {
+	case "path":
+		// Render the path up to the first error, as browsers do.
+		path, _ = parsePathData(attrValue(e, "d"))
+	case "rect":
+		x, y := d.length(e, "x", w), d.length(e, "y", h)
+		rw, rh := d.length(e, "width", w), d.length(e, "height", h)
+		if rw <= 0 || rh <= 0 {
+			return nil
+		}
+		rx, hasRX := parseLength(attrValue(e, "rx"), w, 16)
+		ry, hasRY := parseLength(attrValue(e, "ry"), h, 16)
+		if !hasRX {
+			rx = ry
+		}
+		if !hasRY {
+			ry = rx
+		}
+		rx, ry = math.Min(math.Max(rx, 0), rw/2), math.Min(math.Max(ry, 0), rh/2)
+		if rx == 0 || ry == 0 {
+			path.Rectangle(x, y, rw, rh)
+			break
+		}
+		path.MoveTo(x+rx, y)
+		path.LineTo(x+rw-rx, y)
+		appendArc(path, gg.Pt(x+rw-rx, y), gg.Pt(x+rw, y+ry), rx, ry, 0, false, true)
+		path.LineTo(x+rw, y+rh-ry)
+		appendArc(path, gg.Pt(x+rw, y+rh-ry), gg.Pt(x+rw-rx, y+rh), rx, ry, 0, false, true)
+		path.LineTo(x+rx, y+rh)
+		appendArc(path, gg.Pt(x+rx, y+rh), gg.Pt(x, y+rh-ry), rx, ry, 0, false, true)
+		path.LineTo(x, y+ry)
+		appendArc(path, gg.Pt(x, y+ry), gg.Pt(x+rx, y), rx, ry, 0, false, true)
+		path.Close()
+	case "circle":
+		r := d.length(e, "r", diag)
+		if r <= 0 {
+			return nil
+		}
+		path.Circle(d.length(e, "cx", w), d.length(e, "cy", h), r)
+	case "ellipse":
+		rx, ry := d.length(e, "rx", w), d.length(e, "ry", h)
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		path.Ellipse(d.length(e, "cx", w), d.length(e, "cy", h), rx, ry)
+	case "line":
+		path.MoveTo(d.length(e, "x1", w), d.length(e, "y1", h))
+		path.LineTo(d.length(e, "x2", w), d.length(e, "y2", h))
+	case "polyline", "polygon":
+		points := parseNumberList(attrValue(e, "points"))
+		for i := 0; i+1 < len(points); i += 2 {
+			if i == 0 {
+				path.MoveTo(points[i], points[i+1])
+			} else {
+				path.LineTo(points[i], points[i+1])
+			}
+		}
+		if name == "polygon" && len(points) >= 4 {
+			path.Close()
+		}
+	}
+	return path
+}
+
+// draw fills and strokes a path in the user space of st.
+func (d *decoder) draw(path *gg.Path, st decodeStyle) {
+	if st.invisible || len(path.Elements()) == 0 {
+		return
+	}
+	m := st.transform
+	if brush := d.brush(st.fill, st, st.fillOpacity, path); brush != nil {
+		d.rec.SetFillStyle(brush)
+		d.rec.SetFillRule(st.fillRule)
+		d.emitPath(path, m)
+		d.rec.Fill()
+	}
+	if st.strokeWidth <= 0 {
+		return
+	}
+	if brush := d.brush(st.stroke, st, st.strokeOpacity, path); brush != nil {
+		scale := averageScale(m)
+		d.rec.SetStrokeStyle(brush)
+		d.rec.SetLineWidth(st.strokeWidth * scale)
+		d.rec.SetLineCap(st.lineCap)
+		d.rec.SetLineJoin(st.lineJoin)
+		d.rec.SetMiterLimit(st.miterLimit)
+		if len(st.dashes) > 0 {
+			dashes := make([]float64, len(st.dashes))
+			for i, v := range st.dashes {
+				dashes[i] = v * scale
+			}
+			d.rec.SetDash(dashes...)
+			d.rec.SetDashOffset(st.dashOffset * scale)
+		} else {
+			d.rec.ClearDash()
+		}
+		d.emitPath(path, m)
+		d.rec.Stroke()
+	}
+}
+
+// emitPath adds path, transformed by m, to the recorder's current path.
+func (d *decoder) emitPath(path *gg.Path, m recording.Matrix) {
+	elems := transformPath(path, m).Elements()
+	if d.commands += len(elems); d.commands > maxDecodeCommands {
+		d.err = fmt.Errorf("%w: more than %d path commands", ErrDecodeLimit, maxDecodeCommands)
+		return
+	}
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			d.rec.MoveTo(e.Point.X, e.Point.Y)
+		case gg.LineTo:
+			d.rec.LineTo(e.Point.X, e.Point.Y)
+		case gg.QuadTo:
+			d.rec.QuadraticTo(e.Control.X, e.Control.Y, e.Point.X, e.Point.Y)
+		case gg.CubicTo:
+			d.rec.CubicTo(e.Control1.X, e.Control1.Y, e.Control2.X, e.Control2.Y, e.Point.X, e.Point.Y)
+		case gg.Close:
+			d.rec.ClosePath()
+		}
+	}
+}
+
+// averageScale returns the factor by which m scales areas, as a length.
+func averageScale(m recording.Matrix) float64 {
+	return math.Sqrt(math.Abs(m.Determinant()))
+}
+
+// clipPath returns the clip path referenced by the clip-path property of
+// e in canvas coordinates, or nil.
+func (d *decoder) clipPath(e *Element, st decodeStyle) *gg.Path {
+	id := urlID(attrOrStyle(e, "clip-path"))
+	clip, ok := d.ids[id]
+	if !ok || localName(clip.Name) != "clipPath" {
+		return nil
+	}
+	m := st.transform
+	if t, ok := clip.Attr("transform"); ok {
+		m = m.Multiply(parseTransform(t))
+	}
+	if attrValue(clip, "clipPathUnits") == "objectBoundingBox" {
+		r := d.bounds(e)
+		m = m.Multiply(recording.Matrix{A: r.maxX - r.minX, C: r.minX, E: r.maxY - r.minY, F: r.minY})
+	}
+
+	path := gg.NewPath()
+	for _, c := range clip.Children {
+		name := localName(c.Name)
+		if c.Name == "" || name == "text" || name == "use" {
+			continue
+		}
+		cm := m
+		if t, ok := c.Attr("transform"); ok {
+			cm = cm.Multiply(parseTransform(t))
+		}
+		if shape := d.shape(c, name); shape != nil {
+			appendPath(path, transformPath(shape, cm))
+		}
+	}
+	if len(path.Elements()) == 0 {
+		// An empty clip path hides the element; clip to an empty area.
+		path.Rectangle(0, 0, 0, 0)
+	}
+	return path
+}
+
+// bounds returns the user-space bounds of a shape element's geometry.
+func (d *decoder) bounds(e *Element) bbox {
+	r := emptyBBox()
+	var visit func(e *Element)
+	visit = func(e *Element) {
+		if path := d.shape(e, localName(e.Name)); path != nil && len(path.Elements()) > 0 {
+			minX, minY, maxX, maxY := pathBounds(path, 0)
+			r = r.union(bbox{minX, minY, maxX, maxY})
+		}
+		for _, c := range e.Children {
+			if c.Name != "" {
+				visit(c)
+			}
+		}
+	}
+	visit(e)
+	if r.empty() {
+		return bbox{}
+	}
+	return r
+}
+
+// use renders the element referenced by a use element.
+func (d *decoder) use(e *Element, st decodeStyle) {
+	target, ok := d.ids[strings.TrimPrefix(hrefValue(e), "#")]
+	if !ok || d.useDepth >= maxUseDepth {
+		return
+	}
+	st.transform = st.transform.Multiply(recording.Translate(d.length(e, "x", d.width), d.length(e, "y", d.height)))
+	d.useDepth++
+	defer func() { d.useDepth-- }()
+	if localName(target.Name) == "symbol" {
+		d.children(target, d.computeStyle(target, st))
+		return
+	}
+	d.element(target, st)
+}
+
+// text records a text element and its tspans as one string at the
+// element's first position.
+func (d *decoder) text(e *Element, st decodeStyle) {
+	s := strings.Join(strings.Fields(textContent(e)), " ")
+	if s == "" || st.invisible {
+		return
+	}
+	xs, ys := parseNumberList(attrValue(e, "x")), parseNumberList(attrValue(e, "y"))
+	var x, y float64
+	if len(xs) > 0 {
+		x = xs[0]
+	}
+	if len(ys) > 0 {
+		y = ys[0]
+	}
+	// Estimate the text bounds for gradients in bounding box units.
+	bounds := gg.NewPath()
+	bounds.Rectangle(x, y-0.8*st.fontSize, 0.6*st.fontSize*float64(utf8.RuneCountInString(s)), st.fontSize)
+	brush := d.brush(st.fill, st, st.fillOpacity, bounds)
+	if brush == nil {
+		return
+	}
+	x, y = st.transform.TransformPoint(x, y)
+	d.rec.SetFillStyle(brush)
+	d.rec.SetFontSize(st.fontSize * averageScale(st.transform))
+	d.rec.SetFontFamily(st.fontFamily)
+	d.rec.DrawString(s, x, y)
+}
+
+// textContent returns the character data of e and its descendants.
+func textContent(e *Element) string {
+	var s strings.Builder
+	var visit func(e *Element)
+	visit = func(e *Element) {
+		for _, c := range e.Children {
+			if c.Name == "" {
+				s.WriteString(c.Text)
+			} else if n := localName(c.Name); n == "tspan" || n == "textPath" || n == "a" {
+				visit(c)
+			}
+		}
+	}
+	visit(e)
+	return s.String()
+}
+
+// image records an image embedded as a data: URI.
+func (d *decoder) image(e *Element, st decodeStyle) {
+	if st.invisible {
+		return
+	}
+	img := d.decodeImage(hrefValue(e))
+	if img == nil {
+		return
+	}
+	size := img.Bounds().Size()
+	x, y := d.length(e, "x", d.width), d.length(e, "y", d.height)
+	w, ok := parseLength(attrValue(e, "width"), d.width, 16)
+	if !ok {
+		w = float64(size.X)
+	}
+	h, ok := parseLength(attrValue(e, "height"), d.height, 16)
+	if !ok {
+		h = float64(size.Y)
+	}
+	if w <= 0 || h <= 0 {
+		return
+	}
+	r := transformBBox(st.transform, x, y, x+w, y+h)
+	d.rec.DrawImageScaled(img, r.minX, r.minY, r.maxX-r.minX, r.maxY-r.minY)
+}
+
+// decodeImage decodes the image of a data: URI once, returning nil if it
+// is invalid or exceeds maxDecodePixels.
+func (d *decoder) decodeImage(uri string) image.Image {
+	if img, ok := d.images[uri]; ok {
+		return img
+	}
+	img, err := decodeDataURI(uri, maxDecodePixels-d.pixels)
+	if errors.Is(err, ErrDecodeLimit) {
+		d.err = err
+	}
+	if img != nil {
+		size := img.Bounds().Size()
+		d.pixels += size.X * size.Y
+	}
+	d.images[uri] = img
+	return img
+}
+
+// decodeDataURI decodes a base64 data: URI holding an image of at most
+// maxPixels pixels.
+func decodeDataURI(uri string, maxPixels int) (image.Image, error) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok || !strings.HasPrefix(uri, "data:") || !strings.HasSuffix(meta, ";base64") {
+		return nil, errors.New("not a base64 data URI")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(payload), ""))
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("empty image")
+	}
+	if cfg.Width > maxPixels/cfg.Height {
+		return nil, fmt.Errorf("%w: %dx%d image exceeds %d pixels", ErrDecodeLimit, cfg.Width, cfg.Height, maxDecodePixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	return img, err
+}
+
+// brush returns the brush for paint p with the given opacity, or nil if
+// nothing is painted. path is the painted geometry in user space, for
+// gradients in objectBoundingBox units.
+func (d *decoder) brush(p paint, st decodeStyle, opacity float64, path *gg.Path) recording.Brush {
+	opacity *= st.opacity
+	if p.ref != "" {
+		if g, ok := d.ids[p.ref]; ok {
+			if brush := d.gradient(g, st, opacity, path); brush != nil {
+				return brush
+			}
+		}
+		if p.fallback == nil {
+			return nil
+		}
+		p = *p.fallback
+	}
+	if p.none {
+		return nil
+	}
+	c := p.color
+	if p.current {
+		c = st.color
+	}
+	c.A *= opacity
+	return recording.NewSolidBrush(c)
+}
+
+// gradient returns a brush for a linearGradient or radialGradient
+// element, following href references for unset attributes and stops.
+func (d *decoder) gradient(g *Element, st decodeStyle, opacity float64, path *gg.Path) recording.Brush {
+	kind := localName(g.Name)
+	if kind != "linearGradient" && kind != "radialGradient" {
+		return nil
+	}
+	chain := []*Element{g}
+	for len(chain) < maxUseDepth {
+		next, ok := d.ids[strings.TrimPrefix(hrefValue(chain[len(chain)-1]), "#")]
+		if !ok || !strings.HasSuffix(localName(next.Name), "Gradient") {
+			break
+		}
+		chain = append(chain, next)
+	}
+	attr := func(name string) (string, bool) {
+		for _, e := range chain {
+			if v, ok := e.Attr(name); ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	var stops []recording.GradientStop
+	for _, e := range chain {
+		for _, c := range e.Children {
+			if localName(c.Name) != "stop" {
+				continue
+			}
+			offset := parseOpacity(attrOrStyle(c, "offset"), 0)
+			if n := len(stops); n > 0 {
+				offset = math.Max(offset, stops[n-1].Offset)
+			}
+			color, ok := parseColor(attrOrStyle(c, "stop-color"))
+			if !ok {
+				color = gg.Black
+			}
+			if strings.TrimSpace(attrOrStyle(c, "stop-color")) == "currentColor" {
+				color = st.color
+			}
+			color.A *= parseOpacity(attrOrStyle(c, "stop-opacity"), 1) * opacity
+			stops = append(stops, recording.GradientStop{Offset: offset, Color: color})
+		}
+		if len(stops) > 0 {
+			break
+		}
+	}
+	if len(stops) == 0 {
+		return nil
+	}
+
+	// Gradient coordinates are fractions of the bounding box by default,
+	// and percentages of the viewport in user space units.
+	m := st.transform
+	bboxUnits := true
+	if u, _ := attr("gradientUnits"); u == "userSpaceOnUse" {
+		bboxUnits = false
+	}
+	refW, refH, refD := d.width, d.height, d.diagonal()
+	if bboxUnits {
+		if path == nil || len(path.Elements()) == 0 {
+			return nil
+		}
+		minX, minY, maxX, maxY := pathBounds(path, 0)
+		if maxX == minX || maxY == minY {
+			// A bounding box without area does not define a gradient.
+			return nil
+		}
+		m = m.Multiply(recording.Matrix{A: maxX - minX, C: minX, E: maxY - minY, F: minY})
+		refW, refH, refD = 1, 1, 1
+	}
+	if t, ok := attr("gradientTransform"); ok {
+		m = m.Multiply(parseTransform(t))
+	}
+	coord := func(name string, def string, ref float64) float64 {
+		v, ok := attr(name)
+		if !ok {
+			v = def
+		}
+		if bboxUnits && !strings.HasSuffix(strings.TrimSpace(v), "%") {
+			f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return f
+		}
+		f, _ := parseLength(v, ref, 16)
+		return f
+	}
+	extend := recording.ExtendPad
+	switch spread, _ := attr("spreadMethod"); spread {
+	case "reflect":
+		extend = recording.ExtendReflect
+	case "repeat":
+		extend = recording.ExtendRepeat
+	}
+
+	if kind == "linearGradient" {
+		x0, y0 := m.TransformPoint(coord("x1", "0%", refW), coord("y1", "0%", refH))
+		x1, y1 := m.TransformPoint(coord("x2", "100%", refW), coord("y2", "0%", refH))
+		brush := recording.NewLinearGradientBrush(x0, y0, x1, y1)
+		brush.Stops = stops
+		return brush.SetExtend(extend)
+	}
+	cx, cy := coord("cx", "50%", refW), coord("cy", "50%", refH)
+	fx, fy := cx, cy
+	if _, ok := attr("fx"); ok {
+		fx = coord("fx", "50%", refW)
+	}
+	if _, ok := attr("fy"); ok {
+		fy = coord("fy", "50%", refH)
+	}
+	scale := averageScale(m)
+	brush := recording.NewRadialGradientBrush(0, 0, coord("fr", "0%", refD)*scale, coord("r", "50%", refD)*scale)
+	brush.Center.X, brush.Center.Y = m.TransformPoint(cx, cy)
+	brush.Focus.X, brush.Focus.Y = m.TransformPoint(fx, fy)
+	brush.Stops = stops
+	return brush.SetExtend(extend)
+}
+
+// parseTransform parses a transform attribute. Parsing stops at the first
+// invalid function.
+func parseTransform(v string) recording.Matrix {
+	m := recording.Identity()
+	for {
+		v = strings.TrimLeft(v, " \t\r\n,")
+		open := strings.IndexByte(v, '(')
+		end := strings.IndexByte(v, ')')
+		if open < 0 || end < open {
+			return m
+		}
+		name, args := strings.TrimSpace(v[:open]), parseNumberList(v[open+1:end])
+		v = v[end+1:]
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+		var t recording.Matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				return m
+			}
+			// SVG lists the components column by column.
+			t = recording.Matrix{A: args[0], B: args[2], C: args[4], D: args[1], E: args[3], F: args[5]}
+		case "translate":
+			t = recording.Translate(arg(0, 0), arg(1, 0))
+		case "scale":
+			t = recording.Scale(arg(0, 1), arg(1, arg(0, 1)))
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = recording.Translate(cx, cy).Multiply(recording.Rotate(arg(0, 0) * math.Pi / 180)).Multiply(recording.Translate(-cx, -cy))
+		case "skewX":
+			t = recording.Matrix{A: 1, B: math.Tan(arg(0, 0) * math.Pi / 180), E: 1}
+		case "skewY":
+			t = recording.Matrix{A: 1, D: math.Tan(arg(0, 0) * math.Pi / 180), E: 1}
+		default:
+			return m
+		}
+		m = m.Multiply(t)
+	}
+}
+
+// parsePaint parses a fill or stroke value. Invalid values keep the
+// inherited paint.
+func parsePaint(v string, inherited paint) paint {
+	switch v {
+	case "none":
+		return paint{none: true}
+	case "currentColor":
+		return paint{current: true}
+	}
+	if strings.HasPrefix(v, "url(") {
+		p := paint{ref: urlID(v), none: true}
+		if _, rest, ok := strings.Cut(v, ")"); ok {
+			if rest = strings.TrimSpace(rest); rest != "" {
+				fallback := parsePaint(rest, paint{none: true})
+				p.fallback = &fallback
+			}
+		}
+		return p
+	}
+	if c, ok := parseColor(v); ok {
+		return paint{color: c}
+	}
+	return inherited
+}
+
+// urlID returns the fragment of a url(#id) reference, or "".
+func urlID(v string) string {
+	if m := urlRef.FindStringSubmatch(v); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// colorNames maps the CSS basic color keywords to colors.
+var colorNames = func() map[string]gg.RGBA {
+	names := map[string]gg.RGBA{"transparent": {}, "grey": gg.Hex("#808080")}
+	for hex, name := range basicColorNames {
+		names[name] = gg.Hex(hex)
+	}
+	return names
+}()
+
+// parseColor parses a hex, rgb(), rgba() or keyword color.
+func parseColor(v string) (gg.RGBA, bool) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if c, ok := colorNames[v]; ok {
+		return c, true
+	}
+	if strings.HasPrefix(v, "#") {
+		hex := v[1:]
+		if _, err := strconv.ParseUint(hex, 16, 64); err != nil {
+			return gg.RGBA{}, false
+		}
+		switch len(hex) {
+		case 3, 4, 6, 8:
+		default:
+			return gg.RGBA{}, false
+		}
+		return gg.Hex(hex), true
+	}
+	open := strings.IndexByte(v, '(')
+	if open < 0 || !strings.HasSuffix(v, ")") {
+		return gg.RGBA{}, false
+	}
+	if fn := v[:open]; fn != "rgb" && fn != "rgba" {
+		return gg.RGBA{}, false
+	}
+	args := strings.FieldsFunc(v[open+1:len(v)-1], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+	if len(args) != 3 && len(args) != 4 {
+		return gg.RGBA{}, false
+	}
+	var ch [4]float64
+	ch[3] = 1
+	for i, a := range args {
+		scale := 255.0
+		if i == 3 {
+			scale = 1
+		}
+		if p, ok := strings.CutSuffix(a, "%"); ok {
+			a, scale = p, 100
+		}
+		f, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return gg.RGBA{}, false
+		}
+		ch[i] = math.Max(0, math.Min(1, f/scale))
+	}
+	return gg.RGBA{R: ch[0], G: ch[1], B: ch[2], A: ch[3]}, true
+}
+
+// parseOpacity parses a number or percentage clamped to [0, 1].
+func parseOpacity(v string, def float64) float64 {
+	v = strings.TrimSpace(v)
+	scale := 1.0
+	if p, ok := strings.CutSuffix(v, "%"); ok {
+		v, scale = p, 100
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return math.Max(0, math.Min(1, f/scale))
+}
+
+func parseFillRule(v string) recording.FillRule {
+	if v == "evenodd" {
+		return recording.FillRuleEvenOdd
+	}
+	return recording.FillRuleNonZero
+}
+
+// parseDashes parses a stroke-dasharray. Lists with an odd number of
+// values are repeated; invalid or all-zero lists disable dashing.
+func parseDashes(v string) []float64 {
+	if v == "none" {
+		return nil
+	}
+	dashes := parseNumberList(strings.ReplaceAll(v, "px", ""))
+	sum := 0.0
+	for _, d := range dashes {
+		if d < 0 {
+			return nil
+		}
+		sum += d
+	}
+	if sum == 0 {
+		return nil
+	}
+	if len(dashes)%2 == 1 {
+		dashes = append(dashes, dashes...)
+	}
+	return dashes
+}
+
+// units converts absolute CSS units to user units.
+var units = map[string]float64{
+	"px": 1, "pt": 96.0 / 72, "pc": 16, "mm": 96 / 25.4, "cm": 96 / 2.54, "in": 96,
+}
+
+// parseLength parses a length in user units. Percentages are relative to
+// ref and em units to fontSize. It reports false for empty or invalid
+// values.
+func parseLength(v string, ref, fontSize float64) (float64, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	scale := 1.0
+	if p, ok := strings.CutSuffix(v, "%"); ok {
+		v, scale = p, ref/100
+	} else if p, ok := strings.CutSuffix(v, "em"); ok {
+		v, scale = p, fontSize
+	} else if len(v) > 2 {
+		if u, ok := units[v[len(v)-2:]]; ok {
+			v, scale = v[:len(v)-2], u
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f * scale, true
+}
+
+// attrValue returns the named attribute of e, or "".
+func attrValue(e *Element, name string) string {
+	v, _ := e.Attr(name)
+	return v
+}
+
+// attrOrStyle returns a property of e set in its style attribute or as a
+// presentation attribute.
+func attrOrStyle(e *Element, name string) string {
+	for _, decl := range strings.Split(attrValue(e, "style"), ";") {
+		if n, v, ok := strings.Cut(decl, ":"); ok && strings.TrimSpace(n) == name {
+			return strings.TrimSpace(v)
+		}
+	}
+	return attrValue(e, name)
+}
+
+// hrefValue returns the href of e, in SVG 2 or XLink form.
+func hrefValue(e *Element) string {
+	if v, ok := e.Attr("href"); ok {
+		return v
+	}
+	for _, a := range e.Attrs {
+		if strings.HasSuffix(a.Name, ":href") {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// localName strips the namespace prefix from a qualified name.
+func localName(name string) string {
+	if _, local, ok := strings.Cut(name, ":"); ok {
+		return local
+	}
+	return name
+}
@@ -0,0 +1,200 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// decodeToSVG decodes src and plays it back through the SVG backend.
+func decodeToSVG(t *testing.T, src string) string {
+	t.Helper()
+	rec, err := Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	backend := NewBackend()
+	if err := backend.Playback(rec); err != nil {
+		t.Fatalf("Playback failed: %v", err)
+	}
+	return backend.String()
+}
+
+func TestDecodeShapes(t *testing.T) {
+	out := decodeToSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100">
+		<rect x="10" y="10" width="20" height="30" fill="#ff0000"/>
+		<circle cx="50" cy="50" r="10" fill="blue" stroke="rgb(0,128,0)" stroke-width="2"/>
+		<path d="M100 10 h10 v10 h-10 z" style="fill: lime; fill-opacity: 50%"/>
+		<polygon points="150,10 160,20 140,20" fill-rule="evenodd"/>
+		<line x1="0" y1="90" x2="200" y2="90" stroke="black" stroke-dasharray="4 2"/>
+		<g fill="none"><rect width="5" height="5"/></g>
+		<rect width="5" height="5" display="none"/>
+	</svg>`)
+
+	for _, want := range []string{
+		`width="200" height="100"`,
+		`d="M10 10L30 10L30 40L10 40Z" fill="rgb(255,0,0)"`,
+		`fill="rgb(0,0,255)"`,
+		`stroke="rgb(0,128,0)" stroke-width="2"`,
+		`d="M100 10L110 10L110 20L100 20Z" fill="rgb(0,255,0)" fill-opacity="0.5"`,
+		`fill-rule="evenodd"`,
+		`stroke-dasharray="4 2"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output should contain %s:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "<path"); n != 6 {
+		t.Errorf("Expected 6 paths, got %d:\n%s", n, out)
+	}
+}
+
+func TestDecodeTransforms(t *testing.T) {
+	out := decodeToSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="200" viewBox="0 0 100 100">
+		<g transform="translate(10 20)">
+			<rect width="10" height="10" transform="scale(2)" stroke="red"/>
+		</g>
+	</svg>`)
+
+	// The viewBox doubles everything: (10,20) + 2*(0..10) -> (20,40)..(60,80).
+	if !strings.Contains(out, `d="M20 40L60 40L60 80L20 80Z"`) {
+		t.Errorf("Transforms should be applied to coordinates:\n%s", out)
+	}
+	if !strings.Contains(out, `stroke-width="4"`) {
+		t.Errorf("Stroke widths should be scaled:\n%s", out)
+	}
+	if strings.Contains(out, "transform=") {
+		t.Errorf("No transform should be replayed:\n%s", out)
+	}
+}
+
+func TestDecodeGradient(t *testing.T) {
+	out := decodeToSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="100" height="100">
+		<defs>
+			<linearGradient id="base"><stop offset="0" stop-color="red"/><stop offset="100%" stop-color="blue" stop-opacity=".5"/></linearGradient>
+			<linearGradient id="g" xlink:href="#base" x1="0" x2="1"/>
+		</defs>
+		<rect x="10" y="0" width="50" height="10" fill="url(#g)"/>
+		<rect x="10" y="20" width="50" height="10" fill="url(#missing) green"/>
+	</svg>`)
+
+	for _, want := range []string{`<linearGradient`, `x1="10"`, `x2="60"`, `stop-color="rgb(0,0,255)" stop-opacity="0.5"`, `fill="rgb(0,128,0)"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output should contain %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestDecodeClipTextImage(t *testing.T) {
+	var png64 bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	enc := base64.NewEncoder(base64.StdEncoding, &png64)
+	if err := png.Encode(enc, img); err != nil {
+		t.Fatal(err)
+	}
+	enc.Close()
+	out := decodeToSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+		<clipPath id="c"><circle cx="50" cy="50" r="20"/></clipPath>
+		<rect width="100" height="100" clip-path="url(#c)"/>
+		<text x="5" y="90" font-size="12" fill="navy">Hello <tspan>world</tspan></text>
+		<image x="0" y="0" width="10" height="10" href="data:image/png;base64,`+png64.String()+`"/>
+	</svg>`)
+
+	for _, want := range []string{"<clipPath", "clip-path=", ">Hello world</text>", "<image", `width="10" height="10"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output should contain %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, src := range []string{"", "<html></html>", "<svg><rect></svg>"} {
+		if _, err := Decode(strings.NewReader(src)); err == nil {
+			t.Errorf("Decode(%q) should fail", src)
+		}
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	defer func(n int) { maxDecodeElements = n }(maxDecodeElements)
+	maxDecodeElements = 1000
+
+	// Seven levels of ten use references expand to 10^7 rects.
+	var src strings.Builder
+	src.WriteString(`<svg xmlns="http://www.w3.org/2000/svg"><defs><g id="l0">` + strings.Repeat(`<rect width="1" height="1"/>`, 10) + `</g>`)
+	for i := 1; i < 7; i++ {
+		src.WriteString(fmt.Sprintf(`<g id="l%d">`, i) + strings.Repeat(fmt.Sprintf(`<use href="#l%d"/>`, i-1), 10) + `</g>`)
+	}
+	src.WriteString(`</defs><use href="#l6"/></svg>`)
+	if _, err := Decode(strings.NewReader(src.String())); !errors.Is(err, ErrDecodeLimit) {
+		t.Errorf("use expansion: err = %v, expected ErrDecodeLimit", err)
+	}
+
+	// A PNG header claiming 50000×50000 pixels is rejected before its
+	// pixels are decoded.
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 50000)
+	binary.BigEndian.PutUint32(data[20:], 50000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	huge := `<svg xmlns="http://www.w3.org/2000/svg"><image href="data:image/png;base64,` + base64.StdEncoding.EncodeToString(data) + `"/></svg>`
+	if _, err := Decode(strings.NewReader(huge)); !errors.Is(err, ErrDecodeLimit) {
+		t.Errorf("huge image: err = %v, expected ErrDecodeLimit", err)
+	}
+}
+
+func TestDecodeIgnored(t *testing.T) {
+	ignored, err := DecodeIgnored(strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg">
+		<mask id="m"/><pattern id="p"/><rect width="1" height="1" style="filter:blur(1px)"/>
+		<image href="https://example.com/a.png"/><image href="data:image/png;base64,AAAA"/>
+	</svg>`))
+	expected := []string{"external images", "filters", "masks", "patterns"}
+	if err != nil || !slices.Equal(ignored, expected) {
+		t.Errorf("DecodeIgnored = %v, %v, expected %v", ignored, err, expected)
+	}
+}
+
+func TestParsePathData(t *testing.T) {
+	path, err := parsePathData("m10,10 20 0 v10 H10 Q 0 0 5-5 t10 0 s5 5 10 0 a5 5 0 1 0 10 0z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(appendPathData(nil, path)); !strings.HasPrefix(got, "M10 10L30 10L30 20L10 20Q0 0 5 -5Q10 -10 15 -5C15 -5 20 0 25 -5C") {
+		t.Errorf("Unexpected path data %s", got)
+	}
+	if p := path.CurrentPoint(); p != gg.Pt(10, 10) {
+		t.Errorf("Close should return to the subpath start, got %v", p)
+	}
+
+	// Paths are rendered up to the first error.
+	path, err = parsePathData("M0 0L10 10L5")
+	if err == nil || len(path.Elements()) != 2 {
+		t.Errorf("Expected an error after 2 elements, got %v with %d", err, len(path.Elements()))
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	m := parseTransform("translate(10,20) rotate(90) scale(2 3)")
+	x, y := m.TransformPoint(1, 1)
+	if math.Abs(x-7) > 1e-9 || math.Abs(y-22) > 1e-9 {
+		t.Errorf("Expected (7, 22), got (%g, %g)", x, y)
+	}
+	if got := parseTransform("matrix(1 2 3 4 5 6)"); got != (recording.Matrix{A: 1, B: 3, C: 5, D: 2, E: 4, F: 6}) {
+		t.Errorf("Unexpected matrix %+v", got)
+	}
+}
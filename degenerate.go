@@ -0,0 +1,112 @@
+package svg
+
+import (
+	"math"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// Degenerate geometry is not written: it renders nothing, but inflates
+// files and shows up as empty objects in editors. This covers paths
+// without segments, fills that enclose no area, strokes of zero length
+// with butt caps, rectangles and images with no width or height, and
+// anything painted fully transparent (see transparent).
+
+// degenerateFill reports whether filling path paints nothing because all
+// of its points, including curve control points, lie on one line.
+func degenerateFill(path *gg.Path) bool {
+	var origin, dir gg.Point
+	haveOrigin, haveDir := false, false
+	for p := range pathPoints(path) {
+		switch {
+		case !haveOrigin:
+			origin, haveOrigin = p, true
+		case !haveDir:
+			if d := p.Sub(origin); d.LengthSquared() > 0 {
+				dir, haveDir = d, true
+			}
+		default:
+			d := p.Sub(origin)
+			if math.Abs(dir.Cross(d)) > 1e-9*dir.Length()*d.Length() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// degenerateStroke reports whether stroking path paints nothing: the path
+// has no segments, its width is zero, or all of its points coincide and
+// the caps add no dot.
+func (b *Backend) degenerateStroke(path *gg.Path, stroke recording.Stroke) bool {
+	if b.remapStrokeWidth(stroke.Width) <= 0 || !hasSegments(path) {
+		return true
+	}
+	if stroke.Cap != recording.LineCapButt {
+		return false
+	}
+	var first gg.Point
+	started := false
+	for p := range pathPoints(path) {
+		if !started {
+			first, started = p, true
+		} else if p != first {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSegments reports whether path has a line, curve or close, which
+// strokes draw caps for even at zero length.
+func hasSegments(path *gg.Path) bool {
+	for _, elem := range path.Elements() {
+		switch elem.(type) {
+		case gg.LineTo, gg.QuadTo, gg.CubicTo, gg.Close:
+			return true
+		}
+	}
+	return false
+}
+
+// pathPoints yields the end and control points of the segments of path,
+// with the start point of each subpath that has segments.
+func pathPoints(path *gg.Path) func(yield func(gg.Point) bool) {
+	return func(yield func(gg.Point) bool) {
+		var current gg.Point
+		for _, elem := range path.Elements() {
+			var points []gg.Point
+			switch e := elem.(type) {
+			case gg.MoveTo:
+				current = e.Point
+				continue
+			case gg.LineTo:
+				points = []gg.Point{current, e.Point}
+			case gg.QuadTo:
+				points = []gg.Point{current, e.Control, e.Point}
+			case gg.CubicTo:
+				points = []gg.Point{current, e.Control1, e.Control2, e.Point}
+			default:
+				continue
+			}
+			for _, p := range points {
+				if !yield(p) {
+					return
+				}
+			}
+			current = points[len(points)-1]
+		}
+	}
+}
+
+// emptyRect reports whether a rectangle has no width or height.
+func emptyRect(rect recording.Rect) bool {
+	return rect.Width() == 0 || rect.Height() == 0
+}
+
+// transparent reports whether an alpha is dropped: fully transparent, or
+// below the invisible threshold of WithAlphaThresholds.
+func (b *Backend) transparent(a float64) bool {
+	return a <= 0 || a < b.alphaInvisible
+}
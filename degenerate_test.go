@@ -0,0 +1,54 @@
+package svg
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestDegenerateGeometry(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	red := recording.NewSolidBrush(gg.Red)
+
+	empty := gg.NewPath()
+	empty.MoveTo(10, 10)
+	line := gg.NewPath()
+	line.MoveTo(0, 0)
+	line.QuadraticTo(5, 5, 10, 10)
+	line.LineTo(20, 20)
+	line.Close()
+	dot := gg.NewPath()
+	dot.MoveTo(10, 10)
+	dot.LineTo(10, 10)
+
+	backend.FillPath(empty, red, recording.FillRuleNonZero)
+	backend.FillPath(line, red, recording.FillRuleNonZero)
+	backend.StrokePath(empty, red, recording.DefaultStroke())
+	backend.StrokePath(dot, red, recording.DefaultStroke())
+	zeroWidth := recording.DefaultStroke()
+	zeroWidth.Width = 0
+	backend.StrokePath(line, red, zeroWidth)
+	backend.FillRect(recording.NewRect(10, 10, 0, 20), red)
+	backend.DrawImage(image.NewRGBA(image.Rect(0, 0, 2, 2)), recording.Rect{}, recording.NewRect(0, 0, 10, 0), recording.ImageOptions{Alpha: 1})
+	backend.DrawImage(image.NewRGBA(image.Rect(0, 0, 2, 2)), recording.Rect{}, recording.NewRect(0, 0, 10, 10), recording.ImageOptions{})
+
+	out := backend.String()
+	for _, tag := range []string{"<path", "<rect", "<image"} {
+		if strings.Contains(out, tag) {
+			t.Errorf("Degenerate %s should not be written:\n%s", tag, out)
+		}
+	}
+
+	// Lines are stroked, and zero-length strokes with round caps draw dots.
+	round := recording.DefaultStroke()
+	round.Cap = recording.LineCapRound
+	backend.StrokePath(line, red, recording.DefaultStroke())
+	backend.StrokePath(dot, red, round)
+	if n := strings.Count(backend.String(), "<path"); n != 2 {
+		t.Errorf("Expected 2 stroked paths, got %d:\n%s", n, backend.String())
+	}
+}
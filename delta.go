@@ -0,0 +1,231 @@
+package svg
+
+import (
+	"bytes"
+	"maps"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// DeltaState records an export that ExportDelta can extend.
+type DeltaState struct {
+	backend   *Backend
+	resources *recording.ResourcePool
+	width     int
+	height    int
+	commands  int
+	snapshot  backendSnapshot
+}
+
+// backendSnapshot is the state a Backend accumulates during playback,
+// as of End. Everything Begin resets belongs here, except the hashes and
+// signature of the finished document.
+type backendSnapshot struct {
+	builder          []byte
+	defs             []byte
+	layers           [][]byte
+	containers       []openContainer
+	idCounter        int
+	stateStack       []backendState
+	transform        recording.Matrix
+	clipID           string
+	clipBounds       *bbox
+	bounds           bbox
+	fonts            []fontText
+	nextAttrs        elementAttrs
+	links            []string
+	usesInkscape     bool
+	styleRules       []string
+	keyframeNames    map[string]string
+	animationClasses map[string]string
+	animationRules   []string
+	drawOnRegistered bool
+	drawOnCount      int
+	cutShapes        []cutShape
+	sketch           *rand.PCG
+	lastPath         pathMerge
+	clipPaths        map[string]clipDef
+	flatClips        map[string]string
+	errs             []error
+	failures         int
+	incomplete       bool
+	elements         int
+	overBudget       bool
+	opIndex          int
+	opMethod         string
+	sourceCursor     int
+}
+
+// ExportDelta exports r, serializing only the commands recorded since the
+// export that produced prev. It returns the state to pass to the next call.
+//
+// A Recorder only ever appends, and every Recording taken from it with
+// FinishRecording shares its resources, so a later Recording extends an
+// earlier one. ExportDelta relies on this: if r comes from the same
+// Recorder as prev and has at least as many commands, the backend is
+// restored to prev and only the new commands are replayed. Otherwise,
+// including when prev is nil or was produced by another Backend, r is
+// exported in full. With WithToolpathOrdering strokes are reordered
+// globally, so every export is a full one.
+//
+// Per-second dashboard exports built by appending to one Recorder become
+// incremental instead of full rebuilds.
+func (b *Backend) ExportDelta(prev *DeltaState, r *recording.Recording) (*DeltaState, error) {
+	commands := r.Commands()
+	incremental := prev != nil && prev.backend == b && !b.toolpathOrdering &&
+		prev.resources == r.Resources() &&
+		prev.width == r.Width() && prev.height == r.Height() &&
+		prev.commands <= len(commands)
+
+	if !incremental {
+		if err := b.Playback(r); err != nil {
+			return nil, err
+		}
+	} else {
+		b.source = r
+		_ = b.Begin(r.Width(), r.Height())
+		b.restore(&prev.snapshot)
+		b.replay(r, prev.commands)
+		b.source = nil
+		if err := b.End(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &DeltaState{
+		backend:   b,
+		resources: r.Resources(),
+		width:     r.Width(),
+		height:    r.Height(),
+		commands:  len(commands),
+		snapshot:  b.snapshot(),
+	}, nil
+}
+
+// replay plays the commands of r from index from onwards, as
+// Recording.Playback does, without calling Begin or End.
+func (b *Backend) replay(r *recording.Recording, from int) {
+	res := r.Resources()
+	for _, cmd := range r.Commands()[from:] {
+		switch c := cmd.(type) {
+		case recording.SaveCommand:
+			b.Save()
+		case recording.RestoreCommand:
+			b.Restore()
+		case recording.SetTransformCommand:
+			b.SetTransform(c.Matrix)
+		case recording.SetClipCommand:
+			b.SetClip(res.GetPath(c.Path), c.Rule)
+		case recording.ClearClipCommand:
+			b.ClearClip()
+		case recording.FillPathCommand:
+			b.FillPath(res.GetPath(c.Path), res.GetBrush(c.Brush), c.Rule)
+		case recording.StrokePathCommand:
+			b.StrokePath(res.GetPath(c.Path), res.GetBrush(c.Brush), c.Stroke)
+		case recording.FillRectCommand:
+			b.FillRect(c.Rect, res.GetBrush(c.Brush))
+		case recording.DrawImageCommand:
+			b.DrawImage(res.GetImage(c.Image), c.SrcRect, c.DstRect, c.Options)
+		case recording.DrawTextCommand:
+			b.DrawText(c.Text, c.X, c.Y, nil, res.GetBrush(c.Brush))
+		}
+	}
+}
+
+// snapshot copies the playback state.
+func (b *Backend) snapshot() backendSnapshot {
+	s := backendSnapshot{
+		builder:          bytes.Clone(b.builder.Bytes()),
+		defs:             bytes.Clone(b.defs.Bytes()),
+		containers:       slices.Clone(b.containers),
+		idCounter:        b.idCounter,
+		stateStack:       slices.Clone(b.stateStack),
+		transform:        b.currentTransform,
+		clipID:           b.currentClipID,
+		clipBounds:       b.clipBounds,
+		bounds:           b.contentBounds,
+		fonts:            slices.Clone(b.fonts),
+		nextAttrs:        b.nextAttrs,
+		links:            slices.Clone(b.links),
+		usesInkscape:     b.usesInkscape,
+		styleRules:       slices.Clone(b.styleRules),
+		keyframeNames:    maps.Clone(b.keyframeNames),
+		animationClasses: maps.Clone(b.animationClasses),
+		animationRules:   slices.Clone(b.animationRules),
+		drawOnRegistered: b.drawOnRegistered,
+		drawOnCount:      b.drawOnCount,
+		cutShapes:        slices.Clone(b.cutShapes),
+		lastPath:         b.lastPath,
+		clipPaths:        maps.Clone(b.clipPaths),
+		flatClips:        maps.Clone(b.flatClips),
+		errs:             slices.Clone(b.errs),
+		failures:         b.failures,
+		incomplete:       b.incomplete,
+		elements:         b.elements,
+		overBudget:       b.overBudget,
+		opIndex:          b.opIndex,
+		opMethod:         b.opMethod,
+		sourceCursor:     b.sourceCursor,
+	}
+	s.nextAttrs.classes = slices.Clone(s.nextAttrs.classes)
+	for i := range b.layers {
+		s.layers = append(s.layers, bytes.Clone(b.layers[i].Bytes()))
+	}
+	if b.sketchPCG != nil {
+		pcg := *b.sketchPCG
+		s.sketch = &pcg
+	}
+	return s
+}
+
+// restore replaces the playback state with a snapshot. The backend must
+// have been reset by Begin. The snapshot is copied, so it can be restored
+// again.
+func (b *Backend) restore(s *backendSnapshot) {
+	b.builder.Write(s.builder)
+	b.defs.Write(s.defs)
+	for i := range s.layers {
+		if i < len(b.layers) {
+			b.layers[i].Write(s.layers[i])
+		}
+	}
+	b.containers = append(b.containers, s.containers...)
+	b.idCounter = s.idCounter
+	b.stateStack = append(b.stateStack, s.stateStack...)
+	b.currentTransform = s.transform
+	b.currentClipID = s.clipID
+	b.clipBounds = s.clipBounds
+	b.contentBounds = s.bounds
+	b.fonts = append(b.fonts, s.fonts...)
+	b.nextAttrs = s.nextAttrs
+	b.nextAttrs.classes = slices.Clone(s.nextAttrs.classes)
+	b.links = append(b.links, s.links...)
+	b.usesInkscape = s.usesInkscape
+	for _, decls := range s.styleRules {
+		b.styleClass(decls)
+	}
+	b.keyframeNames = maps.Clone(s.keyframeNames)
+	b.animationClasses = maps.Clone(s.animationClasses)
+	b.animationRules = append(b.animationRules, s.animationRules...)
+	b.drawOnRegistered = s.drawOnRegistered
+	b.drawOnCount = s.drawOnCount
+	b.cutShapes = append(b.cutShapes, s.cutShapes...)
+	if s.sketch != nil {
+		pcg := *s.sketch
+		b.sketchPCG = &pcg
+		b.sketchRNG = rand.New(b.sketchPCG)
+	}
+	b.lastPath = s.lastPath
+	b.clipPaths = maps.Clone(s.clipPaths)
+	b.flatClips = maps.Clone(s.flatClips)
+	b.errs = append(b.errs, s.errs...)
+	b.failures = s.failures
+	b.incomplete = s.incomplete
+	b.elements = s.elements
+	b.overBudget = s.overBudget
+	b.opIndex = s.opIndex
+	b.opMethod = s.opMethod
+	b.sourceCursor = s.sourceCursor
+}
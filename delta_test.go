@@ -0,0 +1,108 @@
+package svg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestExportDelta(t *testing.T) {
+	full := func(r *recording.Recording) string {
+		backend := NewBackend(WithSourceMap(), WithStyleMode(StyleClasses))
+		if err := backend.Playback(r); err != nil {
+			t.Fatalf("Playback failed: %v", err)
+		}
+		return writeSVG(t, backend)
+	}
+
+	rec := recording.NewRecorder(100, 100)
+	backend := NewBackend(WithSourceMap(), WithStyleMode(StyleClasses))
+	var state *DeltaState
+
+	for i := range 4 {
+		rec.Save()
+		rec.Translate(float64(i*10), 0)
+		rec.SetFillRGBA(1, 0, 0, 1)
+		rec.DrawCircle(5, 5, 4)
+		rec.Clip()
+		rec.DrawRectangle(0, 0, 8, 8)
+		rec.Fill()
+		if i%2 == 0 {
+			rec.Restore()
+		}
+
+		r := rec.FinishRecording()
+		var err error
+		state, err = backend.ExportDelta(state, r)
+		if err != nil {
+			t.Fatalf("ExportDelta failed: %v", err)
+		}
+		if got, expected := writeSVG(t, backend), full(r); got != expected {
+			t.Fatalf("Step %d: delta export differs from full export:\n%s\nexpected:\n%s", i, got, expected)
+		}
+	}
+
+	// A recording from another recorder is exported in full.
+	other := recording.NewRecorder(100, 100)
+	other.DrawRectangle(0, 0, 1, 1)
+	other.Fill()
+	r := other.FinishRecording()
+	if _, err := backend.ExportDelta(state, r); err != nil {
+		t.Fatalf("ExportDelta failed: %v", err)
+	}
+	if got, expected := writeSVG(t, backend), full(r); got != expected {
+		t.Errorf("Unrelated recording should be exported in full, got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestExportDeltaOptions(t *testing.T) {
+	variants := map[string][]Option{
+		"cutline":   {WithCutline(2)},
+		"sketch":    {WithSketch(1.5, 7)},
+		"merge":     {WithMergePaths(true)},
+		"flatten":   {WithFlattenTransforms(true)},
+		"draw-on":   {WithDrawOn(time.Second, true)},
+		"budget":    {WithBudget(Budget{MaxElements: 5})},
+		"structure": {WithStructure(StructureByOp), WithSketch(1, 3)},
+	}
+	for name, opts := range variants {
+		t.Run(name, func(t *testing.T) {
+			rec := recording.NewRecorder(100, 100)
+			backend := NewBackend(opts...)
+			var state *DeltaState
+			for i := range 4 {
+				x := float64(i * 20)
+				rec.SetFillRGBA(1, 0, 0, 1)
+				rec.DrawRectangle(x, 0, 10, 10)
+				rec.Fill()
+				rec.DrawRectangle(x, 12, 10, 10)
+				rec.Fill()
+				rec.Save()
+				rec.Translate(x, 30)
+				rec.DrawCircle(5, 5, 6)
+				rec.Clip()
+				rec.SetStrokeRGBA(0, 0, 1, 1)
+				rec.MoveTo(0, 0)
+				rec.LineTo(10, 10)
+				rec.Stroke()
+				rec.Restore()
+
+				r := rec.FinishRecording()
+				var err error
+				state, err = backend.ExportDelta(state, r)
+				full := NewBackend(opts...)
+				fullErr := full.Playback(r)
+				if (err == nil) != (fullErr == nil) {
+					t.Fatalf("Step %d: ExportDelta() = %v, full export = %v", i, err, fullErr)
+				}
+				if err != nil {
+					continue
+				}
+				if got, expected := writeSVG(t, backend), writeSVG(t, full); got != expected {
+					t.Fatalf("Step %d: delta export differs from full export:\n%s\nexpected:\n%s", i, got, expected)
+				}
+			}
+		})
+	}
+}
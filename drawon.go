@@ -0,0 +1,79 @@
+package svg
+
+import (
+	"math"
+	"time"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// WithDrawOn animates stroked paths as if drawn by hand: each stroke gets
+// a stroke-dasharray and stroke-dashoffset of its computed length, and a
+// CSS animation runs the offset down to zero over duration. With
+// sequential set, strokes are drawn one after another in drawing order;
+// otherwise they are all drawn at once. Dashed strokes keep their dashes
+// and are not animated. A zero duration disables the option.
+func WithDrawOn(duration time.Duration, sequential bool) Option {
+	return func(b *Backend) {
+		b.drawOnDuration = duration
+		b.drawOnSequential = sequential
+	}
+}
+
+// drawsOn reports whether a stroke is animated by WithDrawOn.
+func (b *Backend) drawsOn(stroke recording.Stroke) bool {
+	return b.drawOnDuration > 0 && len(stroke.DashPattern) == 0
+}
+
+// drawOnClass returns the class of draw-on animated strokes.
+func (b *Backend) drawOnClass() string {
+	if b.drawOnRegistered {
+		return b.idPrefix + "draw"
+	}
+	b.drawOnRegistered = true
+	name := b.idPrefix + "draw"
+	b.animationRules = append(b.animationRules,
+		"@keyframes "+name+"{to{stroke-dashoffset:0}}",
+		"."+name+"{animation:"+name+" "+cssTime(b.drawOnDuration)+" linear both}")
+	return name
+}
+
+// writeDrawOn writes the dash attributes that hide the stroke until the
+// animation reveals it, and the delay of sequential strokes.
+func (b *Backend) writeDrawOn(path *gg.Path) {
+	// Rounding up keeps the end of the stroke from showing as a dot
+	// before the animation starts.
+	length := math.Ceil(strokeLength(path))
+	b.writeNumberAttr("stroke-dasharray", length)
+	b.writeNumberAttr("stroke-dashoffset", length)
+	if b.drawOnSequential {
+		if b.drawOnCount > 0 {
+			b.writeAttr("style", "animation-delay:"+cssTime(time.Duration(b.drawOnCount)*b.drawOnDuration))
+		}
+		b.drawOnCount++
+	}
+}
+
+// strokeLength returns the length of path, including the segments that
+// close subpaths.
+func strokeLength(path *gg.Path) float64 {
+	length := path.Length(0.01)
+	var start, current gg.Point
+	for _, e := range path.Elements() {
+		switch e := e.(type) {
+		case gg.MoveTo:
+			start, current = e.Point, e.Point
+		case gg.LineTo:
+			current = e.Point
+		case gg.QuadTo:
+			current = e.Point
+		case gg.CubicTo:
+			current = e.Point
+		case gg.Close:
+			length += current.Distance(start)
+			current = start
+		}
+	}
+	return length
+}
@@ -0,0 +1,57 @@
+package svg
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestDrawOn(t *testing.T) {
+	backend := NewBackend(WithDrawOn(time.Second, true))
+	_ = backend.Begin(100, 100)
+	brush := recording.NewSolidBrush(gg.Black)
+	stroke := recording.DefaultStroke()
+
+	line := gg.NewPath()
+	line.MoveTo(0, 0)
+	line.LineTo(30, 40)
+	backend.SetNextAttrs("", "signature")
+	backend.StrokePath(line, brush, stroke)
+	backend.StrokePath(rectPath(recording.NewRect(10, 10, 20, 10)), brush, stroke)
+	dashed := stroke
+	dashed.DashPattern = []float64{4, 2}
+	backend.StrokePath(line, brush, dashed)
+	_ = backend.End()
+
+	svg := writeSVG(t, backend)
+	for _, want := range []string{
+		`@keyframes draw{to{stroke-dashoffset:0}}`,
+		`.draw{animation:draw 1s linear both}`,
+		`class="signature draw"`,
+		`stroke-dasharray="50" stroke-dashoffset="50"`,
+		`stroke-dasharray="60" stroke-dashoffset="60" style="animation-delay:1s"`,
+		`stroke-dasharray="4 2"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG missing %s:\n%s", want, svg)
+		}
+	}
+	if strings.Count(svg, `class=`) != 2 {
+		t.Errorf("dashed strokes should not be animated:\n%s", svg)
+	}
+}
+
+func TestStrokeLength(t *testing.T) {
+	circle := gg.NewPath()
+	circle.Circle(0, 0, 10)
+	if got := strokeLength(circle); math.Abs(got-20*math.Pi) > 0.1 {
+		t.Errorf("circle length = %g, want %g", got, 20*math.Pi)
+	}
+	if got := strokeLength(rectPath(recording.NewRect(0, 0, 3, 4))); got != 14 {
+		t.Errorf("closed rectangle length = %g, want 14", got)
+	}
+}
@@ -0,0 +1,173 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// Failure categories reported by OpError. Use errors.Is to branch on them.
+var (
+	// ErrUnsupportedBrush reports a brush SVG cannot represent, such as
+	// a sweep gradient or pattern, written with a fallback color.
+	ErrUnsupportedBrush = errors.New("svg: unsupported brush")
+	// ErrImageEncode reports an image that could not be encoded as PNG
+	// and was left out of the document.
+	ErrImageEncode = errors.New("svg: image encode failed")
+	// ErrInvalidGeometry reports NaN or infinite coordinates.
+	ErrInvalidGeometry = errors.New("svg: invalid geometry")
+	// ErrUnsupportedFeature reports a construct left out or approximated
+	// because the selected profile does not support it.
+	ErrUnsupportedFeature = errors.New("svg: feature not supported by profile")
+	// ErrInvalidAttribute reports an attribute added by an OnElement hook
+	// that was left out because its name is not a valid, unique XML name
+	// in a declared namespace.
+	ErrInvalidAttribute = errors.New("svg: invalid attribute")
+	// ErrNestedLink reports a BeginLink while another link is open.
+	// SVG does not allow links inside links, so the inner link was left
+	// out and its content stays in the outer one.
+	ErrNestedLink = errors.New("svg: nested link")
+	// ErrBudgetExceeded reports an export that exceeded the limits set
+	// with WithBudget. Elements from the failing one on were dropped.
+	ErrBudgetExceeded = errors.New("svg: output budget exceeded")
+)
+
+// OpError describes a failure in a single drawing operation.
+type OpError struct {
+	// Op is the index of the operation: the index into Recording.Commands
+	// when replayed with Backend.Playback, or the number of backend calls
+	// since Begin otherwise.
+	Op int
+	// Method is the backend method that failed, e.g. "FillPath".
+	Method string
+	// Kind is the failure category, one of the Err variables.
+	Kind error
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+// Error formats the failure with its operation.
+func (e *OpError) Error() string {
+	msg := fmt.Sprintf("%v in %s (op %d)", e.Kind, e.Method, e.Op)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the failure category and the underlying cause.
+func (e *OpError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// WithStrict makes End and WriteTo fail with the errors raised by drawing
+// operations, instead of silently writing fallbacks. The error joins one
+// *OpError per failure; errors.As finds the first. In strict mode WriteTo
+// writes nothing when an operation has failed.
+//
+// Without strict mode, End and WriteTo still fail when content is missing
+// from the document: an image could not be encoded (ErrImageEncode) or a
+// shape had non-finite coordinates (ErrInvalidGeometry). WriteTo writes
+// the rest of the document before returning the error. Failures that were
+// replaced by a fallback, such as ErrUnsupportedBrush, are only reported
+// by Err.
+func WithStrict(enabled bool) Option {
+	return func(b *Backend) {
+		b.strict = enabled
+	}
+}
+
+// maxFailures bounds the failures kept per export, so recordings that
+// fail on every operation don't grow the list without limit.
+const maxFailures = 100
+
+// Err returns the failures raised by drawing operations since Begin,
+// joined into one error, or nil if the export is complete and exact.
+// Beyond the first 100 failures, only their number is reported.
+func (b *Backend) Err() error {
+	errs := b.errs
+	if more := b.failures - len(errs); more > 0 {
+		errs = append(errs[:len(errs):len(errs)], fmt.Errorf("svg: %d more failures", more))
+	}
+	return errors.Join(errs...)
+}
+
+// fail records a failure of the current operation.
+func (b *Backend) fail(kind, cause error) {
+	b.failures++
+	if kind == ErrImageEncode || kind == ErrInvalidGeometry {
+		b.incomplete = true
+	}
+	if len(b.errs) < maxFailures {
+		b.errs = append(b.errs, &OpError{Op: b.opIndex, Method: b.opMethod, Kind: kind, Err: cause})
+	}
+}
+
+// strictErr returns the recorded failures in strict mode or once the
+// budget has been exceeded, when nothing is written.
+func (b *Backend) strictErr() error {
+	if b.strict || b.overBudget {
+		return b.Err()
+	}
+	return nil
+}
+
+// exportErr returns the recorded failures if End or WriteTo must report
+// them: in strict mode, over budget or when content is missing.
+func (b *Backend) exportErr() error {
+	if b.strict || b.overBudget || b.incomplete {
+		return b.Err()
+	}
+	return nil
+}
+
+// unsupportedBrush records a brush that cannot be represented.
+func (b *Backend) unsupportedBrush(brush recording.Brush) {
+	b.fail(ErrUnsupportedBrush, fmt.Errorf("%T", brush))
+}
+
+// checkPath records a path with non-finite coordinates.
+func (b *Backend) checkPath(path *gg.Path) {
+	for _, elem := range path.Elements() {
+		var points []gg.Point
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			points = []gg.Point{e.Point}
+		case gg.LineTo:
+			points = []gg.Point{e.Point}
+		case gg.QuadTo:
+			points = []gg.Point{e.Control, e.Point}
+		case gg.CubicTo:
+			points = []gg.Point{e.Control1, e.Control2, e.Point}
+		}
+		for _, p := range points {
+			if !finite(p.X, p.Y) {
+				b.fail(ErrInvalidGeometry, fmt.Errorf("non-finite point (%g, %g)", p.X, p.Y))
+				return
+			}
+		}
+	}
+}
+
+// checkCoords records non-finite coordinates.
+func (b *Backend) checkCoords(values ...float64) {
+	if !finite(values...) {
+		b.fail(ErrInvalidGeometry, fmt.Errorf("non-finite coordinates %v", values))
+	}
+}
+
+// finite reports whether all values are finite.
+func finite(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// hugeImage reports bounds PNG cannot encode.
+type hugeImage struct{ image.Image }
+
+func (hugeImage) Bounds() image.Rectangle { return image.Rect(0, 0, 1<<32, 1) }
+
+func TestWithStrict(t *testing.T) {
+	sweep := recording.NewSweepGradientBrush(50, 50, 0).AddColorStop(0, gg.Red)
+	nan := gg.NewPath()
+	nan.MoveTo(0, 0)
+	nan.LineTo(math.NaN(), 10)
+
+	tests := []struct {
+		name   string
+		draw   func(b *Backend)
+		kind   error
+		method string
+	}{
+		{"sweep", func(b *Backend) {
+			b.FillRect(recording.NewRect(0, 0, 10, 10), sweep)
+		}, ErrUnsupportedBrush, "FillRect"},
+		{"nan", func(b *Backend) {
+			b.FillPath(nan, recording.NewSolidBrush(gg.Red), recording.FillRuleNonZero)
+		}, ErrInvalidGeometry, "FillPath"},
+		{"image", func(b *Backend) {
+			b.DrawImage(hugeImage{image.NewRGBA(image.Rect(0, 0, 1, 1))},
+				recording.Rect{}, recording.NewRect(0, 0, 1, 1), recording.ImageOptions{Alpha: 1})
+		}, ErrImageEncode, "DrawImage"},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(WithStrict(true))
+		_ = backend.Begin(100, 100)
+		backend.Save()
+		tt.draw(backend)
+
+		err := backend.End()
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("%s: End() = %v, expected %v", tt.name, err, tt.kind)
+			continue
+		}
+		var opErr *OpError
+		if !errors.As(err, &opErr) || opErr.Op != 1 || opErr.Method != tt.method {
+			t.Errorf("%s: expected an OpError for op 1 (%s), got %#v", tt.name, tt.method, opErr)
+		}
+
+		var buf bytes.Buffer
+		if _, err := backend.WriteTo(&buf); !errors.Is(err, tt.kind) || buf.Len() != 0 {
+			t.Errorf("%s: WriteTo should fail without writing, got %v", tt.name, err)
+		}
+
+		// Without strict mode the document is written. Fallbacks are
+		// reported by Err only, missing content by End and WriteTo too.
+		lenient := NewBackend()
+		_ = lenient.Begin(100, 100)
+		tt.draw(lenient)
+		var expected error
+		if tt.kind != ErrUnsupportedBrush {
+			expected = tt.kind
+		}
+		if err := lenient.End(); !errors.Is(err, expected) || (expected == nil) != (err == nil) {
+			t.Errorf("%s: End() without strict mode = %v, expected %v", tt.name, err, expected)
+		}
+		buf.Reset()
+		if _, err := lenient.WriteTo(&buf); !errors.Is(err, expected) || (expected == nil) != (err == nil) || buf.Len() == 0 {
+			t.Errorf("%s: WriteTo() without strict mode = %v with %d bytes", tt.name, err, buf.Len())
+		}
+		if !errors.Is(lenient.Err(), tt.kind) {
+			t.Errorf("%s: Err() = %v", tt.name, lenient.Err())
+		}
+	}
+}
+
+func TestErrAccumulates(t *testing.T) {
+	sweep := recording.NewSweepGradientBrush(50, 50, 0).AddColorStop(0, gg.Red)
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	if err := backend.Err(); err != nil {
+		t.Fatalf("Err() = %v before any failure", err)
+	}
+	for range maxFailures + 5 {
+		backend.FillRect(recording.NewRect(0, 0, 10, 10), sweep)
+	}
+	backend.FillRect(recording.NewRect(math.NaN(), 0, 10, 10), recording.NewSolidBrush(gg.Red))
+
+	err := backend.Err()
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Err() should join the failures, got %v", err)
+	}
+	errs := joined.Unwrap()
+	if len(errs) != maxFailures+1 || errs[maxFailures].Error() != "svg: 6 more failures" {
+		t.Errorf("Failures beyond the limit should be counted, got %d errors ending in %v", len(errs), errs[len(errs)-1])
+	}
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != 1 {
+		t.Errorf("The first failure should be op 1, got %#v", opErr)
+	}
+
+	_ = backend.Begin(100, 100)
+	if err := backend.Err(); err != nil {
+		t.Errorf("Begin should clear failures, got %v", err)
+	}
+}
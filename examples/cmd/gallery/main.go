@@ -0,0 +1,26 @@
+// Command gallery renders the example corpus and its HTML gallery.
+//
+// Usage:
+//
+//	go run ./examples/cmd/gallery [-out dir]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gogpu/gg-svg/examples"
+)
+
+func main() {
+	out := flag.String("out", filepath.Join("build", "gallery"), "output directory")
+	flag.Parse()
+
+	if err := examples.Generate(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println("wrote", filepath.Join(*out, "index.html"))
+}
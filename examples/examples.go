@@ -0,0 +1,193 @@
+// Package examples renders a corpus of demonstration recordings with the
+// SVG backend and assembles them into an HTML gallery.
+//
+// Every example is rendered once per variant, where each variant enables
+// a different set of backend options, so the corpus doubles as an
+// integration test of the option combinations. Run it with
+//
+//	make gallery
+//
+// or call Generate directly.
+package examples
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/gogpu/gg"
+	svg "github.com/gogpu/gg-svg"
+	"github.com/gogpu/gg/recording"
+)
+
+// Example is a demonstration recording.
+type Example struct {
+	// Name identifies the example and names its output files.
+	Name string
+	// Description is shown in the gallery.
+	Description string
+	// Width and Height are the canvas size.
+	Width, Height int
+	// Draw records the example.
+	Draw func(r *recording.Recorder)
+}
+
+// Record returns the example's recording.
+func (e Example) Record() *recording.Recording {
+	r := recording.NewRecorder(e.Width, e.Height)
+	e.Draw(r)
+	return r.FinishRecording()
+}
+
+// Variant is a set of backend options the examples are rendered with.
+type Variant struct {
+	// Name identifies the variant and names its output files.
+	Name string
+	// Options configure the backend.
+	Options []svg.Option
+}
+
+// All returns the examples in gallery order.
+func All() []Example {
+	return []Example{
+		{"shapes", "Filled and stroked basic shapes", 200, 150, drawShapes},
+		{"gradients", "Linear and radial gradients with spread modes", 200, 150, drawGradients},
+		{"clips", "Nested clips under Save/Restore", 200, 150, drawClips},
+		{"text", "Text with transforms and translucency", 200, 150, drawText},
+		{"images", "Embedded and scaled images", 200, 150, drawImages},
+		{"dashes", "Dash patterns, caps and joins", 200, 150, drawDashes},
+	}
+}
+
+// Variants returns the option sets every example is rendered with.
+func Variants() []Variant {
+	return []Variant{
+		{"default", nil},
+		{"by-op", []svg.Option{svg.WithStructure(svg.StructureByOp)}},
+		{"classes", []svg.Option{svg.WithStyleMode(svg.StyleClasses), svg.WithIDPrefix("c-")}},
+		{"inline", []svg.Option{svg.WithStyleMode(svg.StyleInline)}},
+		{"hex", []svg.Option{svg.WithColorFormat(svg.ColorShortHex), svg.WithHexAlpha(true)}},
+		{"cropped", []svg.Option{svg.WithAutoCrop(4, true), svg.WithBackground(gg.RGBA{R: 1, G: 1, B: 1, A: 1})}},
+		{"clipped", []svg.Option{svg.WithHardClipToCanvas(true), svg.WithWinding(svg.WindingOuterCCW)}},
+		{"thresholds", []svg.Option{svg.WithOpacityPrecision(2), svg.WithAlphaThresholds(0.05, 0.95)}},
+	}
+}
+
+func drawShapes(r *recording.Recorder) {
+	r.SetFillRGB(0.9, 0.2, 0.2)
+	r.DrawRectangle(20, 20, 60, 40)
+	r.Fill()
+
+	r.SetFillRGBA(0.2, 0.4, 0.9, 0.6)
+	r.DrawCircle(110, 60, 35)
+	r.Fill()
+
+	r.SetStrokeRGB(0.1, 0.6, 0.2)
+	r.SetLineWidth(4)
+	r.DrawRoundedRectangle(30, 80, 140, 50, 12)
+	r.Stroke()
+
+	// A ring, filled with the even-odd rule.
+	r.SetFillRGB(0.9, 0.7, 0.1)
+	r.SetFillRule(recording.FillRuleEvenOdd)
+	r.DrawCircle(165, 40, 25)
+	r.DrawCircle(165, 40, 12)
+	r.Fill()
+}
+
+func drawGradients(r *recording.Recorder) {
+	linear := recording.NewLinearGradientBrush(10, 0, 90, 0)
+	linear.AddColorStop(0, gg.RGBA{R: 1, A: 1})
+	linear.AddColorStop(1, gg.RGBA{B: 1, A: 0.5})
+	r.SetFillStyle(linear)
+	r.DrawRectangle(10, 10, 80, 130)
+	r.Fill()
+
+	radial := recording.NewRadialGradientBrush(150, 75, 0, 20)
+	radial.AddColorStop(0, gg.RGBA{R: 1, G: 1, A: 1})
+	radial.AddColorStop(1, gg.RGBA{G: 0.5, A: 1})
+	radial.Extend = recording.ExtendReflect
+	r.SetFillStyle(radial)
+	r.DrawCircle(150, 75, 45)
+	r.Fill()
+}
+
+func drawClips(r *recording.Recorder) {
+	r.Save()
+	r.DrawCircle(100, 75, 60)
+	r.Clip()
+	r.SetFillRGB(0.2, 0.5, 0.8)
+	r.DrawRectangle(0, 0, 200, 75)
+	r.Fill()
+
+	r.Save()
+	r.DrawRectangle(100, 0, 100, 150)
+	r.Clip()
+	r.SetFillRGB(0.9, 0.5, 0.1)
+	r.DrawRectangle(0, 75, 200, 75)
+	r.Fill()
+	r.Restore()
+	r.Restore()
+
+	r.SetStrokeRGB(0, 0, 0)
+	r.SetLineWidth(1)
+	r.DrawCircle(100, 75, 60)
+	r.Stroke()
+}
+
+func drawText(r *recording.Recorder) {
+	r.SetFontSize(18)
+	r.SetFillRGB(0, 0, 0)
+	r.DrawString("gg-svg <&> text", 10, 30)
+
+	r.Save()
+	r.Translate(100, 90)
+	r.Rotate(-math.Pi / 12)
+	r.SetFillRGBA(0.7, 0.1, 0.4, 0.7)
+	r.DrawString("rotated", 0, 0)
+	r.Restore()
+
+	r.SetFillRGB(0.1, 0.3, 0.6)
+	r.DrawString("مرحبا", 140, 130)
+}
+
+func drawImages(r *recording.Recorder) {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := range 16 {
+		for x := range 16 {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 16), B: 128, A: 255})
+		}
+	}
+	r.DrawImage(img, 10, 10)
+	r.DrawImageScaled(img, 50, 10, 140, 60)
+
+	r.Save()
+	r.DrawCircle(100, 115, 30)
+	r.Clip()
+	r.DrawImageScaled(img, 70, 85, 60, 60)
+	r.Restore()
+}
+
+func drawDashes(r *recording.Recorder) {
+	r.SetStrokeRGB(0.2, 0.2, 0.2)
+	r.SetLineWidth(6)
+
+	caps := []recording.LineCap{recording.LineCapButt, recording.LineCapRound, recording.LineCapSquare}
+	for i, c := range caps {
+		y := 25 + float64(i)*25
+		r.SetLineCap(c)
+		r.SetDash(12, 8)
+		r.DrawLine(20, y, 180, y)
+		r.Stroke()
+	}
+
+	r.ClearDash()
+	r.SetLineJoin(recording.LineJoinRound)
+	r.SetDash(4, 4, 12)
+	r.SetDashOffset(3)
+	r.MoveTo(20, 140)
+	r.LineTo(60, 100)
+	r.LineTo(100, 140)
+	r.LineTo(140, 100)
+	r.Stroke()
+}
@@ -0,0 +1,51 @@
+package examples
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := Generate(dir); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	for _, e := range All() {
+		for _, v := range Variants() {
+			data, err := os.ReadFile(filepath.Join(dir, FileName(e, v)))
+			if err != nil {
+				t.Fatalf("Missing output: %v", err)
+			}
+			if err := wellFormed(data); err != nil {
+				t.Errorf("%s is not well-formed XML: %v", FileName(e, v), err)
+			}
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("Missing gallery: %v", err)
+	}
+	if !strings.Contains(string(index), `<img src="text-by-op.svg"`) {
+		t.Errorf("Gallery should link every rendering, got:\n%s", index)
+	}
+}
+
+func wellFormed(data []byte) error {
+	d := xml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		_, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package examples
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	svg "github.com/gogpu/gg-svg"
+)
+
+// FileName returns the name of the SVG file for an example and variant.
+func FileName(e Example, v Variant) string {
+	return e.Name + "-" + v.Name + ".svg"
+}
+
+// Generate renders every example with every variant into dir and writes
+// an index.html gallery linking them. Backends run in strict mode, so any
+// export error fails the generation.
+func Generate(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	examples := All()
+	variants := Variants()
+	for _, e := range examples {
+		rec := e.Record()
+		for _, v := range variants {
+			b := svg.NewBackend(append([]svg.Option{svg.WithStrict(true)}, v.Options...)...)
+			if err := b.Playback(rec); err != nil {
+				return fmt.Errorf("examples: %s/%s: %w", e.Name, v.Name, err)
+			}
+			if err := b.SaveToFile(filepath.Join(dir, FileName(e, v))); err != nil {
+				return fmt.Errorf("examples: %s/%s: %w", e.Name, v.Name, err)
+			}
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, "index.html"), []byte(Gallery(examples, variants)), 0o644)
+}
+
+// Gallery returns an HTML page showing each example in a row, with one
+// column per variant.
+func Gallery(examples []Example, variants []Variant) string {
+	var g strings.Builder
+	g.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>gg-svg gallery</title>\n")
+	g.WriteString("<style>body{font-family:sans-serif}td{padding:8px;vertical-align:top}" +
+		"img{border:1px solid #ccc;background:repeating-conic-gradient(#eee 0 25%,#fff 0 50%) 0 0/16px 16px}</style>\n")
+	g.WriteString("</head><body>\n<h1>gg-svg gallery</h1>\n<table>\n<tr><th></th>")
+	for _, v := range variants {
+		g.WriteString("<th>" + html.EscapeString(v.Name) + "</th>")
+	}
+	g.WriteString("</tr>\n")
+
+	for _, e := range examples {
+		g.WriteString("<tr><td><b>" + html.EscapeString(e.Name) + "</b><br>" + html.EscapeString(e.Description) + "</td>")
+		for _, v := range variants {
+			name := html.EscapeString(FileName(e, v))
+			g.WriteString(fmt.Sprintf(`<td><a href="%s"><img src="%s" width="%d" height="%d" alt="%s"></a></td>`,
+				name, name, e.Width, e.Height, name))
+		}
+		g.WriteString("</tr>\n")
+	}
+	g.WriteString("</table>\n</body></html>\n")
+	return g.String()
+}
@@ -0,0 +1,123 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// WithFlattenTransforms bakes the current transform into element
+// coordinates instead of writing a transform attribute, so scenes drawn
+// under the same transform thousands of times export as plain
+// coordinates.
+//
+// Solid fills are flattened under any transform. Strokes are flattened
+// when the transform preserves shapes (rotation, uniform scale,
+// translation), with the width and dashes scaled to match; rectangles
+// and images stay <rect> and <image> when it preserves axes, and text is
+// flattened under translations. Other elements, and elements painted with
+// gradients or patterns, whose paint is defined in the element's
+// coordinate system, keep their transform. Clip paths are copied into
+// canvas coordinates for the elements that are flattened.
+func WithFlattenTransforms(enabled bool) Option {
+	return func(b *Backend) {
+		b.flattenTransforms = enabled
+	}
+}
+
+// clipDef is a clip path as set by SetClip.
+type clipDef struct {
+	path *gg.Path
+	rule recording.FillRule
+}
+
+// flattens reports whether an element painted with brush can have the
+// current transform baked into its coordinates. Images pass a nil brush.
+func (b *Backend) flattens(brush recording.Brush) bool {
+	if !b.flattenTransforms || b.currentTransform.IsIdentity() {
+		return false
+	}
+	switch brush.(type) {
+	case nil, recording.SolidBrush:
+		return true
+	}
+	return false
+}
+
+// flatten switches the current element to canvas coordinates: the current
+// transform becomes the identity and the clip is replaced by a copy in
+// canvas coordinates. The caller transforms the element's geometry and
+// calls the returned function once the element is written.
+func (b *Backend) flatten() (restore func()) {
+	m, clipID := b.currentTransform, b.currentClipID
+	if clipID != "" {
+		b.currentClipID = b.flatClip(clipID, m)
+	}
+	b.currentTransform = recording.Identity()
+	return func() {
+		b.currentTransform, b.currentClipID = m, clipID
+	}
+}
+
+// flatClip returns the ID of a copy of the clip path clipID transformed
+// by m, writing it to the definitions on first use.
+func (b *Backend) flatClip(clipID string, m recording.Matrix) string {
+	key := clipID + " " + matrixValue(m)
+	if id, ok := b.flatClips[key]; ok {
+		return id
+	}
+	clip, ok := b.clipPaths[clipID]
+	if !ok {
+		return clipID
+	}
+	id := b.nextID("clip")
+	b.defs.WriteString(fmt.Sprintf(`<clipPath id="%s"><path d="%s"`, id, b.pathToD(transformPath(clip.path, m))))
+	if clip.rule == recording.FillRuleEvenOdd {
+		b.defs.WriteString(` clip-rule="evenodd"`)
+	}
+	b.defs.WriteString(`/></clipPath>`)
+	if b.flatClips == nil {
+		b.flatClips = make(map[string]string)
+	}
+	b.flatClips[key] = id
+	return id
+}
+
+// transformPath returns path with m applied to its points.
+func transformPath(path *gg.Path, m recording.Matrix) *gg.Path {
+	return path.Transform(gg.Matrix(m))
+}
+
+// similarityScale returns the scale factor of m if it preserves shapes,
+// combining rotation, reflection, uniform scaling and translation.
+func similarityScale(m recording.Matrix) (float64, bool) {
+	const eps = 1e-9
+	rotation := math.Abs(m.A-m.E) < eps && math.Abs(m.B+m.D) < eps
+	reflection := math.Abs(m.A+m.E) < eps && math.Abs(m.B-m.D) < eps
+	if !rotation && !reflection {
+		return 0, false
+	}
+	return math.Hypot(m.A, m.D), true
+}
+
+// axisAligned reports whether m maps axis-aligned rectangles to
+// axis-aligned rectangles.
+func axisAligned(m recording.Matrix) bool {
+	return m.B == 0 && m.D == 0
+}
+
+// scaleStroke returns stroke with its width and dash pattern scaled by s.
+func scaleStroke(stroke recording.Stroke, s float64) recording.Stroke {
+	stroke.Width *= s
+	stroke.DashOffset *= s
+	if len(stroke.DashPattern) > 0 {
+		dashes := make([]float64, len(stroke.DashPattern))
+		for i, v := range stroke.DashPattern {
+			dashes[i] = v * s
+		}
+		stroke.DashPattern = dashes
+	}
+	return stroke
+}
@@ -0,0 +1,104 @@
+package svg
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithFlattenTransforms(t *testing.T) {
+	backend := NewBackend(WithFlattenTransforms(true))
+	_ = backend.Begin(100, 100)
+	red := recording.NewSolidBrush(gg.Red)
+	path := gg.NewPath()
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.LineTo(10, 10)
+	path.Close()
+
+	backend.SetTransform(recording.Translate(20, 30).Multiply(recording.Scale(2, 2)))
+	backend.FillPath(path, red, recording.FillRuleNonZero)
+	stroke := recording.DefaultStroke()
+	stroke.Width = 3
+	stroke.DashPattern = []float64{4, 2}
+	backend.StrokePath(path, red, stroke)
+	backend.FillRect(recording.NewRect(1, 1, 5, 5), red)
+	backend.SetTransform(recording.Translate(5, 7))
+	backend.DrawText("moved", 10, 10, nil, red)
+
+	out := backend.String()
+	if strings.Contains(out, "transform=") {
+		t.Errorf("Flattened output should have no transforms:\n%s", out)
+	}
+	for _, want := range []string{
+		`d="M20 30L40 30L40 50Z"`,
+		`stroke-width="6"`,
+		`stroke-dasharray="8 4"`,
+		`x="22" y="32" width="10" height="10"`,
+		`x="15" y="17"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output should contain %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithFlattenTransformsKept(t *testing.T) {
+	backend := NewBackend(WithFlattenTransforms(true))
+	_ = backend.Begin(100, 100)
+	red := recording.NewSolidBrush(gg.Red)
+	path := gg.NewPath()
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.LineTo(10, 10)
+
+	// Gradients are defined in the element's coordinate system, and
+	// non-uniform scales would distort strokes.
+	backend.SetTransform(recording.Scale(2, 3))
+	gradient := recording.NewLinearGradientBrush(0, 0, 10, 0).
+		AddColorStop(0, gg.Red).AddColorStop(1, gg.Blue)
+	backend.FillPath(path, gradient, recording.FillRuleNonZero)
+	backend.StrokePath(path, red, recording.DefaultStroke())
+
+	out := backend.String()
+	if n := strings.Count(out, "transform="); n != 2 {
+		t.Errorf("Expected 2 kept transforms, got %d:\n%s", n, out)
+	}
+}
+
+func TestWithFlattenTransformsRotatedRect(t *testing.T) {
+	backend := NewBackend(WithFlattenTransforms(true))
+	_ = backend.Begin(100, 100)
+	backend.SetTransform(recording.Rotate(math.Pi / 4))
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+
+	out := backend.String()
+	if strings.Contains(out, "transform=") || !strings.Contains(out, "<path") {
+		t.Errorf("A rotated rect should be flattened to a path:\n%s", out)
+	}
+}
+
+func TestWithFlattenTransformsClip(t *testing.T) {
+	backend := NewBackend(WithFlattenTransforms(true))
+	_ = backend.Begin(100, 100)
+	clip := gg.NewPath()
+	clip.Rectangle(0, 0, 10, 10)
+	backend.SetTransform(recording.Translate(50, 50))
+	backend.SetClip(clip, recording.FillRuleNonZero)
+	backend.FillRect(recording.NewRect(0, 0, 20, 20), recording.NewSolidBrush(gg.Red))
+	backend.FillRect(recording.NewRect(5, 5, 20, 20), recording.NewSolidBrush(gg.Blue))
+
+	out := backend.String()
+	if strings.Contains(out, "transform=") {
+		t.Errorf("Flattened output should have no transforms:\n%s", out)
+	}
+	if !strings.Contains(out, `d="M50 50L60 50L60 60L50 60Z"`) {
+		t.Errorf("Clip path should be copied in canvas coordinates:\n%s", out)
+	}
+	if n := strings.Count(out, "<clipPath"); n != 2 {
+		t.Errorf("The copied clip should be written once, got %d clip paths:\n%s", n, out)
+	}
+}
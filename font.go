@@ -0,0 +1,212 @@
+package svg
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gogpu/gg/text"
+)
+
+// FontUsage describes how a font reaches the viewer of an export.
+type FontUsage int
+
+const (
+	// FontReferenced means text is written as <text> naming the font in
+	// font-family, and rendered with that font if it is installed on the
+	// viewing system.
+	FontReferenced FontUsage = iota
+	// FontDefault means text is written as <text> without a font family,
+	// and rendered with the viewer's default font.
+	FontDefault
+)
+
+// String returns the name of the usage.
+func (u FontUsage) String() string {
+	if u == FontDefault {
+		return "default"
+	}
+	return "referenced"
+}
+
+// FontUse reports one font used in an export.
+type FontUse struct {
+	// Family is the font family. It is empty for text drawn without a
+	// face or recorded font family, which uses the viewer's default font.
+	Family string
+	// Weight is the CSS font weight, from 100 to 900.
+	Weight int
+	// Style is "normal", "italic" or "oblique".
+	Style string
+	// Sizes lists the font sizes used, in ascending order.
+	Sizes []float64
+	// Glyphs is the number of glyphs drawn, counting every character
+	// that is not white space.
+	Glyphs int
+	// Chars holds each distinct character drawn, in code point order.
+	Chars string
+	// Usage is how the font reaches the viewer.
+	Usage FontUsage
+}
+
+// fontWeights maps the weight words of font names to CSS weights.
+var fontWeights = map[string]int{
+	"thin":       100,
+	"hairline":   100,
+	"extralight": 200,
+	"ultralight": 200,
+	"light":      300,
+	"regular":    400,
+	"book":       400,
+	"medium":     500,
+	"semibold":   600,
+	"demibold":   600,
+	"bold":       700,
+	"extrabold":  800,
+	"ultrabold":  800,
+	"black":      900,
+	"heavy":      900,
+}
+
+// fontText records text drawn with a face, for FontReport.
+type fontText struct {
+	name string
+	size float64
+	text string
+}
+
+// trackFont records text drawn with face for FontReport.
+func (b *Backend) trackFont(s string, face text.Face) {
+	b.fonts = append(b.fonts, fontText{name: b.fontName(face), size: b.fontSize(face), text: s})
+}
+
+// fontName returns the full name of the font text is drawn with: the name
+// of the face's source, or the font family recorded with text played
+// back by Playback, which passes no face.
+func (b *Backend) fontName(face text.Face) string {
+	if face != nil && face.Source() != nil {
+		return face.Source().Name()
+	}
+	if c, ok := b.sourceText(); ok {
+		return c.FontFamily
+	}
+	return ""
+}
+
+// writeFont writes the font attributes of text drawn with face: the
+// family, weight and style parsed from the font name, if there is one,
+// and the size.
+func (b *Backend) writeFont(face text.Face) {
+	if family, weight, style := parseFontName(b.fontName(face)); family != "" {
+		b.builder.WriteString(` font-family="` + escapeXML(cssFontFamily(family)) + `"`)
+		if weight != 400 {
+			b.builder.WriteString(fmt.Sprintf(` font-weight="%d"`, weight))
+		}
+		if style != "normal" {
+			b.builder.WriteString(` font-style="` + style + `"`)
+		}
+	}
+	b.builder.WriteString(fmt.Sprintf(` font-size="%g"`, b.fontSize(face)))
+}
+
+// cssFontFamily returns family as a CSS font family name, quoted unless
+// it is a sequence of identifiers.
+func cssFontFamily(family string) string {
+	for _, word := range strings.Fields(family) {
+		for i, r := range word {
+			if !unicode.IsLetter(r) && r != '-' && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+				return "'" + strings.NewReplacer("'", "", `\`, "").Replace(family) + "'"
+			}
+		}
+	}
+	return family
+}
+
+// FontReport lists every font used since the last Begin, ordered by
+// family, weight and style, so documents can be audited for font
+// licensing before they are published.
+//
+// Family, weight and style are taken from the font's name, since gg faces
+// carry no other style information: "Go Bold Italic" is reported as
+// family "Go", weight 700, style "italic". The name is that of the face,
+// or for text played back with Playback the recorded font family. This
+// backend writes all text as <text> elements with the family in
+// font-family, so fonts are FontReferenced, or FontDefault for text
+// without a family.
+func (b *Backend) FontReport() []FontUse {
+	type fontKey struct {
+		family string
+		weight int
+		style  string
+	}
+	var keys []fontKey
+	uses := make(map[fontKey]*FontUse)
+	chars := make(map[fontKey]map[rune]bool)
+
+	for _, ft := range b.fonts {
+		family, weight, style := parseFontName(ft.name)
+		key := fontKey{family, weight, style}
+		use := uses[key]
+		if use == nil {
+			use = &FontUse{Family: family, Weight: weight, Style: style, Usage: FontReferenced}
+			if family == "" {
+				use.Usage = FontDefault
+			}
+			uses[key] = use
+			chars[key] = make(map[rune]bool)
+			keys = append(keys, key)
+		}
+		if !slices.Contains(use.Sizes, ft.size) {
+			use.Sizes = append(use.Sizes, ft.size)
+		}
+		for _, r := range ft.text {
+			if !unicode.IsSpace(r) {
+				use.Glyphs++
+				chars[key][r] = true
+			}
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b fontKey) int {
+		if c := strings.Compare(a.family, b.family); c != 0 {
+			return c
+		}
+		if a.weight != b.weight {
+			return a.weight - b.weight
+		}
+		return strings.Compare(a.style, b.style)
+	})
+	report := make([]FontUse, 0, len(keys))
+	for _, key := range keys {
+		use := uses[key]
+		slices.Sort(use.Sizes)
+		runes := make([]rune, 0, len(chars[key]))
+		for r := range chars[key] {
+			runes = append(runes, r)
+		}
+		slices.Sort(runes)
+		use.Chars = string(runes)
+		report = append(report, *use)
+	}
+	return report
+}
+
+// parseFontName splits a full font name such as "Go Bold Italic" into its
+// family, CSS weight and style.
+func parseFontName(name string) (family string, weight int, style string) {
+	weight, style = 400, "normal"
+	words := strings.Fields(name)
+	for len(words) > 1 {
+		word := strings.ToLower(strings.ReplaceAll(words[len(words)-1], "-", ""))
+		if w, ok := fontWeights[word]; ok {
+			weight = w
+		} else if word == "italic" || word == "oblique" {
+			style = word
+		} else {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " "), weight, style
+}
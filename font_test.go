@@ -0,0 +1,95 @@
+package svg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestFontReport(t *testing.T) {
+	face := testFace(t, 10)
+	family, _, _ := parseFontName(face.Source().Name())
+
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.DrawText("ab a", 0, 10, face, recording.NewSolidBrush(gg.Black))
+	backend.DrawText("c", 0, 20, testFace(t, 8), recording.NewSolidBrush(gg.Black))
+	backend.DrawText("z", 0, 30, nil, recording.NewSolidBrush(gg.Black))
+
+	want := []FontUse{
+		{Family: "", Weight: 400, Style: "normal", Sizes: []float64{DefaultFontSize}, Glyphs: 1, Chars: "z", Usage: FontDefault},
+		{Family: family, Weight: 400, Style: "normal", Sizes: []float64{8, 10}, Glyphs: 4, Chars: "abc", Usage: FontReferenced},
+	}
+	if got := backend.FontReport(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FontReport() = %+v, want %+v", got, want)
+	}
+
+	_ = backend.Begin(100, 100)
+	if got := backend.FontReport(); len(got) != 0 {
+		t.Errorf("Begin should reset the report, got %+v", got)
+	}
+}
+
+func TestFontReportRecorded(t *testing.T) {
+	r := recording.NewRecorder(100, 100)
+	r.SetFontFamily("Roboto Bold")
+	r.SetFontSize(30)
+	r.DrawString("hi", 0, 40)
+
+	backend := NewBackend()
+	if err := backend.Playback(r.FinishRecording()); err != nil {
+		t.Fatal(err)
+	}
+	want := []FontUse{{Family: "Roboto", Weight: 700, Style: "normal", Sizes: []float64{30}, Glyphs: 2, Chars: "hi", Usage: FontReferenced}}
+	if got := backend.FontReport(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FontReport() = %+v, want %+v", got, want)
+	}
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `font-family="Roboto" font-weight="700" font-size="30"`) {
+		t.Errorf("Text should name its font, got:\n%s", svg)
+	}
+}
+
+func TestCSSFontFamily(t *testing.T) {
+	for family, want := range map[string]string{
+		"Go":          "Go",
+		"Open Sans":   "Open Sans",
+		"sans-serif":  "sans-serif",
+		"Font 1":      "'Font 1'",
+		"A, B":        "'A, B'",
+		`O'Neil\Sans`: "'ONeilSans'",
+	} {
+		if got := cssFontFamily(family); got != want {
+			t.Errorf("cssFontFamily(%q) = %q, want %q", family, got, want)
+		}
+	}
+}
+
+func TestParseFontName(t *testing.T) {
+	tests := []struct {
+		name, family string
+		weight       int
+		style        string
+	}{
+		{"Go", "Go", 400, "normal"},
+		{"Go Bold Italic", "Go", 700, "italic"},
+		{"Open Sans Semi-Bold", "Open Sans", 600, "normal"},
+		{"Light", "Light", 400, "normal"},
+		{"", "", 400, "normal"},
+	}
+	for _, tt := range tests {
+		family, weight, style := parseFontName(tt.name)
+		if family != tt.family || weight != tt.weight || style != tt.style {
+			t.Errorf("parseFontName(%q) = %q, %d, %q, want %q, %d, %q",
+				tt.name, family, weight, style, tt.family, tt.weight, tt.style)
+		}
+	}
+}
+
+func TestFontUsageString(t *testing.T) {
+	if FontReferenced.String() != "referenced" || FontDefault.String() != "default" {
+		t.Error("Unexpected FontUsage names")
+	}
+}
@@ -0,0 +1,83 @@
+package svg
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xhtmlNS is the XHTML namespace of foreignObject content.
+const xhtmlNS = "http://www.w3.org/1999/xhtml"
+
+// errForeignSanitized is returned by DrawForeignObject when fragments are
+// sanitized.
+var errForeignSanitized = errors.New("svg: DrawForeignObject: foreign content is not allowed when sanitizing")
+
+// DrawForeignObject draws an XHTML fragment, such as a rich-text block or
+// an embedded widget, into the rectangle at (x, y) of size w×h under the
+// current transform and clip. The fragment is wrapped in a <div> in the
+// XHTML namespace inside a <foreignObject> element. It must be
+// well-formed XML, so HTML named entities such as &nbsp; have to be
+// written as character references.
+//
+// DrawForeignObject returns an error, and writes nothing, if the fragment
+// is not well-formed, or with WithSanitizedRaw, WithNoScript or
+// WithUntrusted, which keep only SVG content. Empty rectangles draw nothing.
+//
+// Renderers other than web browsers generally ignore foreignObject, and
+// the raster backend cannot draw it, so exports relying on it are not
+// portable.
+func (b *Backend) DrawForeignObject(x, y, w, h float64, html string) error {
+	if b.sanitizesFragments() || b.scriptless() {
+		return errForeignSanitized
+	}
+	if err := checkFragment(html); err != nil {
+		return fmt.Errorf("svg: DrawForeignObject: %w", err)
+	}
+	if b.overBudget {
+		return fmt.Errorf("svg: DrawForeignObject: %w", ErrBudgetExceeded)
+	}
+	if w <= 0 || h <= 0 || b.culled(x, y, x+w, y+h) {
+		return nil
+	}
+
+	b.includeBounds(x, y, x+w, y+h)
+	b.openElement("foreignObject")
+	b.writeTransform()
+	b.writeClip()
+	b.writeNumberAttr("x", x)
+	b.writeNumberAttr("y", y)
+	b.writeNumberAttr("width", w)
+	b.writeNumberAttr("height", h)
+	b.builder.WriteString(`><div xmlns="` + xhtmlNS + `">`)
+	b.builder.WriteString(html)
+	b.builder.WriteString("</div></foreignObject>")
+	b.closeElement(kindText)
+	return nil
+}
+
+// checkFragment returns an error if an XML fragment is not well-formed.
+func checkFragment(fragment string) error {
+	d := xml.NewDecoder(strings.NewReader(fragment))
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			if depth > 0 {
+				return errors.New("unclosed element")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestDrawForeignObject(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(200, 100)
+	backend.SetTransform(recording.Translate(10, 20))
+	backend.SetNextAttrs("note")
+	if err := backend.DrawForeignObject(0, 0, 120, 40, `<p>Rich <b>text</b>&#160;here</p>`); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.DrawForeignObject(0, 0, 120, 40, `<p>unclosed`); err == nil {
+		t.Error("malformed fragment should be rejected")
+	}
+	if err := backend.DrawForeignObject(0, 0, 0, 40, `<p/>`); err != nil {
+		t.Error(err)
+	}
+	_ = backend.End()
+
+	svg := writeSVG(t, backend)
+	want := `<foreignObject id="note" transform="matrix(1,0,0,1,10,20)" x="0" y="0" width="120" height="40">` +
+		`<div xmlns="http://www.w3.org/1999/xhtml"><p>Rich <b>text</b>&#160;here</p></div></foreignObject>`
+	if !strings.Contains(svg, want) {
+		t.Errorf("SVG missing %s:\n%s", want, svg)
+	}
+	if strings.Count(svg, "<foreignObject") != 1 {
+		t.Errorf("only the valid, non-empty object should be written:\n%s", svg)
+	}
+}
+
+func TestDrawForeignObjectSanitized(t *testing.T) {
+	backend := NewBackend(WithSanitizedRaw(true))
+	_ = backend.Begin(200, 100)
+	if err := backend.DrawForeignObject(0, 0, 100, 50, `<button onclick="go()">Go</button>`); err == nil {
+		t.Error("DrawForeignObject should fail when sanitizing")
+	}
+	_ = backend.End()
+
+	if svg := writeSVG(t, backend); strings.Contains(svg, "foreignObject") {
+		t.Errorf("foreign content should not be written:\n%s", svg)
+	}
+}
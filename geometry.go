@@ -0,0 +1,93 @@
+package svg
+
+import "github.com/gogpu/gg"
+
+// splitSubpaths splits a path into one path per subpath.
+// A Close element stays with the subpath it closes.
+func splitSubpaths(path *gg.Path) []*gg.Path {
+	var subpaths []*gg.Path
+	var current *gg.Path
+
+	for _, elem := range path.Elements() {
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			current = gg.NewPath()
+			subpaths = append(subpaths, current)
+			current.MoveTo(e.Point.X, e.Point.Y)
+		default:
+			if current == nil {
+				// Elements before the first MoveTo start at the origin.
+				current = gg.NewPath()
+				subpaths = append(subpaths, current)
+				current.MoveTo(0, 0)
+			}
+			appendElement(current, elem)
+		}
+	}
+	return subpaths
+}
+
+// appendElement appends a single path element to dst.
+func appendElement(dst *gg.Path, elem gg.PathElement) {
+	switch e := elem.(type) {
+	case gg.MoveTo:
+		dst.MoveTo(e.Point.X, e.Point.Y)
+	case gg.LineTo:
+		dst.LineTo(e.Point.X, e.Point.Y)
+	case gg.QuadTo:
+		dst.QuadraticTo(e.Control.X, e.Control.Y, e.Point.X, e.Point.Y)
+	case gg.CubicTo:
+		dst.CubicTo(e.Control1.X, e.Control1.Y, e.Control2.X, e.Control2.Y, e.Point.X, e.Point.Y)
+	case gg.Close:
+		dst.Close()
+	}
+}
+
+// appendPath appends all elements of src to dst.
+func appendPath(dst, src *gg.Path) {
+	for _, elem := range src.Elements() {
+		appendElement(dst, elem)
+	}
+}
+
+// isClosed reports whether the last element of the path is a Close.
+func isClosed(path *gg.Path) bool {
+	elements := path.Elements()
+	if len(elements) == 0 {
+		return false
+	}
+	_, ok := elements[len(elements)-1].(gg.Close)
+	return ok
+}
+
+// closedCopy returns the path with an explicit Close appended if it has none,
+// for area and containment computations.
+func closedCopy(path *gg.Path) *gg.Path {
+	if isClosed(path) {
+		return path
+	}
+	c := path.Clone()
+	c.Close()
+	return c
+}
+
+// startPoint returns the first point of a path.
+func startPoint(path *gg.Path) gg.Point {
+	for _, elem := range path.Elements() {
+		if m, ok := elem.(gg.MoveTo); ok {
+			return m.Point
+		}
+	}
+	return gg.Point{}
+}
+
+// preparePath runs the enabled geometry passes over a path before it is
+// serialized, in order: welding, winding normalization, corner rounding,
+// then sketching.
+func (b *Backend) preparePath(path *gg.Path) *gg.Path {
+	path = b.weldPath(path)
+	path = b.normalizeWinding(path)
+	path = b.roundCorners(path)
+	path = b.sketchPath(path)
+	return path
+}
@@ -2,9 +2,9 @@ module github.com/gogpu/gg-svg
 
 go 1.25.5
 
-require github.com/gogpu/gg v0.23.0
-
 require (
-	golang.org/x/image v0.35.0 // indirect
-	golang.org/x/text v0.33.0 // indirect
+	github.com/gogpu/gg v0.23.0
+	golang.org/x/image v0.35.0
 )
+
+require golang.org/x/text v0.33.0 // indirect
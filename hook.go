@@ -0,0 +1,185 @@
+package svg
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// OnElement installs a function that can rewrite the attributes of every
+// drawn element (path, rect, image, text) just before it is committed,
+// e.g. to add data attributes for a web front end or strip attributes a
+// downstream tool rejects. fn receives the tag name and the element's
+// attributes with XML escaping removed, and returns the attributes to
+// write. Existing attributes keep their order; new ones are appended in
+// sorted order. Returning nil drops the element.
+//
+// New attribute names must be XML names. A prefix must be xml, xlink,
+// inkscape or one registered with WithNamespace, and a name must not
+// differ from another attribute of the element only in case. Other new
+// attributes are left out and reported as ErrInvalidAttribute.
+//
+// Definitions such as gradients and clip paths are not passed to fn.
+// Passing nil removes any previously installed function.
+func (b *Backend) OnElement(fn func(tag string, attrs map[string]string) map[string]string) {
+	b.elementHook = fn
+}
+
+// rewritesElements reports whether drawn elements are rewritten after
+// they are written, by the element hook, the style mode or theming.
+func (b *Backend) rewritesElements() bool {
+	return b.elementHook != nil || !b.tiny() && (b.styleMode != StyleAttributes || b.themeVars)
+}
+
+// rewriteCurrentElement rewrites the element written since the last
+// openElement call. It reports false if the element was dropped, in which
+// case it has been removed from the builder.
+func (b *Backend) rewriteCurrentElement() bool {
+	if !b.rewritesElements() {
+		return true
+	}
+	elem, ok := b.rewriteElement(string(b.builder.Bytes()[b.tagStart:]))
+	if !ok {
+		b.builder.Truncate(b.elementStart)
+		return false
+	}
+	b.builder.Truncate(b.tagStart)
+	b.builder.WriteString(elem)
+	return true
+}
+
+// rewriteElement passes the start tag of elem through the element hook
+// and the style mode, and returns the rewritten element, or false if the
+// hook dropped it.
+func (b *Backend) rewriteElement(elem string) (string, bool) {
+	tag, names, attrs, rest := parseStartTag(elem)
+	if tag == "" {
+		return elem, true
+	}
+	if b.elementHook != nil {
+		// fn may modify attrs in place.
+		result := b.elementHook(tag, attrs)
+		if result == nil {
+			return "", false
+		}
+		b.checkHookAttrs(names, result)
+		names, attrs = attrOrder(names, result), result
+	}
+	b.applyStyleMode(names, attrs)
+
+	var s strings.Builder
+	s.WriteString("<" + tag)
+	for _, name := range attrOrder(names, attrs) {
+		s.WriteString(fmt.Sprintf(` %s="%s"`, name, escapeXML(attrs[name])))
+	}
+	s.WriteString(rest)
+	return s.String(), true
+}
+
+// checkHookAttrs removes the attributes the element hook added to attrs
+// whose names are not valid, reporting them as ErrInvalidAttribute.
+// names are the attributes the element had before the hook ran.
+func (b *Backend) checkHookAttrs(names []string, attrs map[string]string) {
+	added := slices.Sorted(maps.Keys(attrs))
+	for _, name := range added {
+		if slices.Contains(names, name) {
+			continue
+		}
+		if err := b.hookAttrErr(name, names, added); err != nil {
+			delete(attrs, name)
+			b.fail(ErrInvalidAttribute, err)
+		}
+	}
+}
+
+// hookAttrErr returns why an attribute name added by the element hook
+// cannot be written, or nil. names and others are the other attribute
+// names of the element.
+func (b *Backend) hookAttrErr(name string, names, others []string) error {
+	prefix, local, qualified := strings.Cut(name, ":")
+	if !qualified {
+		prefix, local = "", name
+	}
+	if !isNCName(local) || qualified && !isNCName(prefix) || name == "xmlns" {
+		return fmt.Errorf("%q is not an XML name", name)
+	}
+	for _, other := range slices.Concat(names, others) {
+		if other != name && strings.EqualFold(other, name) {
+			return fmt.Errorf("%q differs from %q only in case", name, other)
+		}
+	}
+	switch {
+	case !qualified, prefix == "xml", b.registersNamespace(prefix):
+	case prefix == "xlink" && b.namespaces != NamespacesNone:
+	case prefix == "inkscape":
+		b.usesInkscape = true
+	default:
+		return fmt.Errorf("namespace prefix of %q is not declared", name)
+	}
+	return nil
+}
+
+// attrOrder returns the names in attrs: those in names keep their order,
+// and the rest follow in sorted order.
+func attrOrder(names []string, attrs map[string]string) []string {
+	var order []string
+	for _, name := range names {
+		if _, ok := attrs[name]; ok {
+			order = append(order, name)
+		}
+	}
+	var added []string
+	for name := range attrs {
+		if !slices.Contains(names, name) {
+			added = append(added, name)
+		}
+	}
+	slices.Sort(added)
+	return append(order, added...)
+}
+
+// parseStartTag splits an element written by the backend into its tag
+// name, attribute names in order, unescaped attribute values and the text
+// following the attributes (starting with ">" or "/>").
+func parseStartTag(elem string) (tag string, names []string, attrs map[string]string, rest string) {
+	if !strings.HasPrefix(elem, "<") {
+		return "", nil, nil, elem
+	}
+	i := strings.IndexAny(elem, " />")
+	if i < 0 {
+		return "", nil, nil, elem
+	}
+	tag, elem = elem[1:i], elem[i:]
+	attrs = make(map[string]string)
+
+	for {
+		elem = strings.TrimLeft(elem, " ")
+		eq := strings.Index(elem, `="`)
+		if eq < 0 || strings.HasPrefix(elem, ">") || strings.HasPrefix(elem, "/>") {
+			return tag, names, attrs, elem
+		}
+		end := strings.IndexByte(elem[eq+2:], '"')
+		if end < 0 {
+			return tag, names, attrs, elem
+		}
+		name := elem[:eq]
+		names = append(names, name)
+		attrs[name] = unescapeXML(elem[eq+2 : eq+2+end])
+		elem = elem[eq+2+end+1:]
+	}
+}
+
+// xmlUnescaper reverses escapeXML.
+var xmlUnescaper = strings.NewReplacer(
+	"&lt;", "<",
+	"&gt;", ">",
+	"&quot;", `"`,
+	"&apos;", "'",
+	"&amp;", "&",
+)
+
+// unescapeXML reverses escapeXML.
+func unescapeXML(s string) string {
+	return xmlUnescaper.Replace(s)
+}
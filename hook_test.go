@@ -0,0 +1,108 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestOnElement(t *testing.T) {
+	backend := NewBackend()
+	var tags []string
+	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+		tags = append(tags, tag)
+		if attrs["width"] == "99" {
+			return nil
+		}
+		delete(attrs, "stroke")
+		attrs["data-name"] = `a"b`
+		return attrs
+	})
+	_ = backend.Begin(100, 100)
+	brush := recording.NewSolidBrush(gg.Red)
+	backend.FillRect(recording.NewRect(0, 0, 10, 20), brush)
+	backend.FillRect(recording.NewRect(0, 0, 99, 20), brush)
+	backend.DrawText("Hi", 1, 2, nil, brush)
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`<rect x="0" y="0" width="10" height="20" fill="rgb(255,0,0)" data-name="a&quot;b"/>`,
+		`<text x="1" y="2" font-size="12" fill="rgb(255,0,0)" data-name="a&quot;b">Hi</text>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if strings.Contains(svg, `width="99"`) {
+		t.Error("Element dropped by the hook should not be written")
+	}
+	if strings.Join(tags, ",") != "rect,rect,text" {
+		t.Errorf("Hook saw tags %v", tags)
+	}
+}
+
+func TestOnElementInvalidNames(t *testing.T) {
+	backend := NewBackend(WithNamespace("app", "https://example.com/app"))
+	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+		attrs[`x="1" onload`] = "alert(1)"
+		attrs["X"] = "1"
+		attrs["other:role"] = "a"
+		attrs["xmlns"] = "https://example.com"
+		attrs["app:role"] = "b"
+		attrs["inkscape:label"] = "c"
+		attrs["data-ok"] = "d"
+		return attrs
+	})
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 20), recording.NewSolidBrush(gg.Red))
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `app:role="b" data-ok="d" inkscape:label="c"/>`) {
+		t.Errorf("valid attributes should be written, got:\n%s", svg)
+	}
+	for _, bad := range []string{"onload", `X="1"`, "other:role", `xmlns="https://example.com"`} {
+		if strings.Contains(svg, bad) {
+			t.Errorf("invalid attribute %s should be dropped, got:\n%s", bad, svg)
+		}
+	}
+	if !strings.Contains(svg, `xmlns:inkscape=`) {
+		t.Errorf("inkscape namespace should be declared, got:\n%s", svg)
+	}
+	if err := backend.Err(); !errors.Is(err, ErrInvalidAttribute) || strings.Count(err.Error(), "invalid attribute") != 4 {
+		t.Errorf("Err() = %v, want four ErrInvalidAttribute failures", err)
+	}
+	if err := backend.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestOnElementLayeredAndToolpaths(t *testing.T) {
+	backend := NewBackend(WithStructure(StructureByOp), WithToolpathOrdering(true))
+	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+		attrs["class"] = "hooked"
+		return attrs
+	})
+	_ = backend.Begin(100, 100)
+	backend.BeginLink("https://example.com")
+	backend.StrokePath(line(0, 0, 10, 10), recording.NewSolidBrush(gg.Black), recording.DefaultStroke())
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	backend.EndLink()
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`<a href="https://example.com"><path d="M0 0L10 10" fill="none"`,
+		`<a href="https://example.com"><rect x="0"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if n := strings.Count(svg, `class="hooked"/></a>`); n != 2 {
+		t.Errorf("Both elements should be hooked inside their links, got %d:\n%s", n, svg)
+	}
+}
@@ -0,0 +1,137 @@
+package svg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// Handler returns an http.Handler that serves a drawing rendered on every
+// request. render draws onto a width×height recorder and may use the
+// request, e.g. its query parameters; the recording is exported by a new
+// Backend configured with opts and written with WriteHTTP, so responses
+// carry an ETag and are gzip-compressed when the client accepts it.
+//
+//	http.Handle("/chart.svg", svg.Handler(640, 480, drawChart))
+//
+// Errors from render or the export are answered with 500 Internal Server
+// Error if no part of the response has been written yet.
+func Handler(width, height int, render func(rec *recording.Recorder, r *http.Request) error, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recording.NewRecorder(width, height)
+		if err := render(rec, r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b := NewBackend(opts...)
+		if err := b.Playback(rec.FinishRecording()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// WriteHTTP sets the ETag once the document serialized cleanly;
+		// later errors happen while the body streams and cannot be
+		// reported.
+		if err := b.WriteHTTP(w, r); err != nil && w.Header().Get("ETag") == "" {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// WriteHTTP serves the document as an HTTP response. It sets Content-Type
+// and an ETag derived from the document hash, answers a matching
+// If-None-Match with 304 Not Modified, and streams the body through gzip
+// when the request accepts it. HEAD requests receive headers only.
+//
+// The ETag is the ContentHash of the document. A plain export is
+// serialized twice, first discarding the output to hash it, so the body
+// is never buffered in full. Exports that are built in memory anyway, for
+// optimizer passes, post-processors, signing or script removal, are
+// built once and the same bytes are hashed and sent, so those steps run
+// once per request.
+func (b *Backend) WriteHTTP(w http.ResponseWriter, r *http.Request) error {
+	var body []byte
+	buffered := b.buffersOutput()
+	if buffered {
+		var buf bytes.Buffer
+		if _, err := b.WriteTo(&buf); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	} else if _, err := b.WriteTo(io.Discard); err != nil {
+		return err
+	}
+	writeBody := func(w io.Writer) error {
+		if buffered {
+			_, err := w.Write(body)
+			return err
+		}
+		_, err := b.WriteTo(w)
+		return err
+	}
+	etag := `"` + b.ContentHash()[:32] + `"`
+
+	h := w.Header()
+	h.Set("Content-Type", "image/svg+xml")
+	h.Set("ETag", etag)
+	h.Add("Vary", "Accept-Encoding")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	gz := acceptsGzip(r.Header.Get("Accept-Encoding"))
+	if gz {
+		h.Set("Content-Encoding", "gzip")
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	if !gz {
+		return writeBody(w)
+	}
+
+	zw := gzip.NewWriter(w)
+	if err := writeBody(zw); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+// Weak validators match their strong equivalents.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		return q > 0
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package svg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	plain := writeSVG(t, backend)
+
+	// Plain response
+	req := httptest.NewRequest(http.MethodGet, "/chart.svg", nil)
+	rec := httptest.NewRecorder()
+	if err := backend.WriteHTTP(rec, req); err != nil {
+		t.Fatalf("WriteHTTP failed: %v", err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	etag := rec.Header().Get("ETag")
+	if len(etag) != 34 {
+		t.Errorf("ETag should be a quoted 32-digit hash, got %q", etag)
+	}
+	if rec.Body.String() != plain {
+		t.Error("Body should be the document")
+	}
+
+	// Gzip response
+	req = httptest.NewRequest(http.MethodGet, "/chart.svg", nil)
+	req.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	rec = httptest.NewRecorder()
+	_ = backend.WriteHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatal("Response should be gzip-encoded")
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if !bytes.Equal(body, []byte(plain)) {
+		t.Error("Decompressed body should be the document")
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Error("ETag should not depend on the encoding")
+	}
+
+	// Conditional request
+	req = httptest.NewRequest(http.MethodGet, "/chart.svg", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	rec = httptest.NewRecorder()
+	_ = backend.WriteHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Matching If-None-Match should give an empty 304, got %d", rec.Code)
+	}
+}
+
+func TestWriteHTTPPostProcessed(t *testing.T) {
+	calls := 0
+	backend := NewBackend(WithPostProcessor(func(data []byte) ([]byte, error) {
+		calls++
+		return fmt.Appendf(data, "<!-- %d -->", calls), nil
+	}))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+
+	req := httptest.NewRequest(http.MethodGet, "/chart.svg", nil)
+	rec := httptest.NewRecorder()
+	if err := backend.WriteHTTP(rec, req); err != nil {
+		t.Fatalf("WriteHTTP failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Post-processor should run once per request, ran %d times", calls)
+	}
+	sum := sha256.Sum256(rec.Body.Bytes())
+	if want := `"` + hex.EncodeToString(sum[:])[:32] + `"`; rec.Header().Get("ETag") != want {
+		t.Errorf("ETag = %s, want the hash of the body %s", rec.Header().Get("ETag"), want)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"deflate, GZIP":     true,
+		"gzip;q=0":          false,
+		"*":                 true,
+		"identity, br;q=.5": false,
+	}
+	for header, expected := range tests {
+		if got := acceptsGzip(header); got != expected {
+			t.Errorf("acceptsGzip(%q) = %v, expected %v", header, got, expected)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := Handler(100, 50, func(rec *recording.Recorder, r *http.Request) error {
+		if r.URL.Query().Get("fail") != "" {
+			return errors.New("no data")
+		}
+		rec.SetFillRGBA(1, 0, 0, 1)
+		rec.DrawRectangle(10, 10, 20, 20)
+		rec.Fill()
+		return nil
+	}, WithIDPrefix("chart-"))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chart.svg", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if rec.Header().Get("Content-Type") != "image/svg+xml" || rec.Header().Get("ETag") == "" {
+		t.Errorf("missing headers: %v", rec.Header())
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `width="100"`) || !strings.Contains(body, "rgb(255,0,0)") {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/chart.svg?fail=1", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "no data") {
+		t.Errorf("render error should give 500, got %d %q", rec.Code, rec.Body.String())
+	}
+}
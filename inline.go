@@ -0,0 +1,102 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+// InlineHTML returns the document for embedding in an HTML page, typed so
+// html/template inserts it without escaping. Compared to WriteTo the XML
+// declaration and doctype are omitted, every id in the document, including
+// those set with SetNextAttrs or WriteRaw, is prepended with prefix along
+// with the references to it, and the document is sanitized as with
+// WithNoScript: only known SVG elements and attributes are kept, and
+// <title> and <desc> keep only their text. No HTML element is left, so
+// the empty elements written as <rect/> are closed as in XML, and
+// foreignObject content is dropped.
+//
+// An empty prefix is derived from the content hash, so distinct drawings
+// on one page never share IDs. Options, optimizer passes and
+// post-processors apply as they do for WriteTo.
+func (b *Backend) InlineHTML(prefix string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		return "", err
+	}
+	_, root, _, err := parseElementTree(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("svg: InlineHTML: %w", err)
+	}
+	if prefix == "" {
+		prefix = "svg" + b.ContentHash()[:8] + "-"
+	}
+	if err := b.sanitizer().sanitize(root, defaultScope); err != nil {
+		return "", fmt.Errorf("svg: InlineHTML: %w", err)
+	}
+	namespaceIDs(root, prefix)
+
+	var out strings.Builder
+	w := svgwriter.New(&out)
+	writeElementTree(w, root)
+	if err := w.Err(); err != nil {
+		return "", err
+	}
+	return template.HTML(out.String()), nil
+}
+
+// splitName is the inverse of qualifiedName.
+func splitName(name string) xml.Name {
+	if space, local, ok := strings.Cut(name, ":"); ok {
+		return xml.Name{Space: space, Local: local}
+	}
+	return xml.Name{Local: name}
+}
+
+// namespaceIDs prepends prefix to the IDs in root and rewrites the
+// url(#id) and href references to them. References to IDs that are not
+// in the document are left alone.
+func namespaceIDs(root *Element, prefix string) {
+	ids := make(map[string]bool)
+	root.Walk(func(e *Element) bool {
+		if id, ok := e.Attr("id"); ok {
+			ids[id] = true
+		}
+		return true
+	})
+	rewrite := func(s string) string {
+		return urlRef.ReplaceAllStringFunc(s, func(m string) string {
+			id := urlRef.FindStringSubmatch(m)[1]
+			if !ids[id] {
+				return m
+			}
+			return strings.TrimSuffix(m, id) + prefix + id
+		})
+	}
+
+	var visit func(e *Element)
+	visit = func(e *Element) {
+		if e.Name == "" {
+			e.Text = rewrite(e.Text)
+			return
+		}
+		for i, a := range e.Attrs {
+			switch {
+			case a.Name == "id":
+				e.Attrs[i].Value = prefix + a.Value
+			case (a.Name == "href" || strings.HasSuffix(a.Name, ":href")) && strings.HasPrefix(a.Value, "#") && ids[a.Value[1:]]:
+				e.Attrs[i].Value = "#" + prefix + a.Value[1:]
+			default:
+				e.Attrs[i].Value = rewrite(a.Value)
+			}
+		}
+		for _, c := range e.Children {
+			visit(c)
+		}
+	}
+	visit(root)
+}
@@ -0,0 +1,90 @@
+package svg
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestInlineHTML(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.SetClip(rectPath(recording.NewRect(0, 0, 50, 50)), recording.FillRuleNonZero)
+	backend.SetNextAttrs("bar")
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	_ = backend.WriteRaw(`<g onclick="alert(1)"><script>alert(2)</script><use href="#bar"/><a href="javascript:x()"/></g>`)
+	_ = backend.End()
+
+	html, err := backend.InlineHTML("c1-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(html)
+	if strings.HasPrefix(s, "<?xml") || !strings.HasPrefix(s, "<svg") {
+		t.Errorf("output should start with <svg>:\n%s", s)
+	}
+	for _, want := range []string{`id="c1-bar"`, `href="#c1-bar"`, `clip-path="url(#c1-`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output missing %s:\n%s", want, s)
+		}
+	}
+	for _, unwanted := range []string{"<script", "onclick", "javascript:", `id="bar"`} {
+		if strings.Contains(s, unwanted) {
+			t.Errorf("output contains %s:\n%s", unwanted, s)
+		}
+	}
+
+	// The result is inserted into templates verbatim.
+	var buf bytes.Buffer
+	tmpl := template.Must(template.New("").Parse(`<div>{{.}}</div>`))
+	if err := tmpl.Execute(&buf, html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<div><svg") {
+		t.Errorf("template escaped the document: %s", buf.String())
+	}
+}
+
+func TestInlineHTMLDefaultPrefix(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.SetNextAttrs("bar")
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	_ = backend.End()
+
+	html, err := backend.InlineHTML("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `id="svg` + backend.ContentHash()[:8] + `-bar"`
+	if !strings.Contains(string(html), want) {
+		t.Errorf("output missing %s:\n%s", want, html)
+	}
+}
+
+func TestInlineHTMLForeignContent(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	_ = backend.DrawForeignObject(0, 0, 50, 50, `<div><p>text</p><img src="x" onerror="alert(1)"/></div>`)
+	_ = backend.WriteRaw(`<title>name<b>bold</b></title>` +
+		`<iframe xmlns="http://www.w3.org/1999/xhtml" srcdoc="&lt;script&gt;alert(2)&lt;/script&gt;"/>`)
+	_ = backend.End()
+
+	html, err := backend.InlineHTML("c1-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(html)
+	for _, unwanted := range []string{"foreignObject", "<div", "<p", "<img", "<b>", "iframe", "onerror"} {
+		if strings.Contains(s, unwanted) {
+			t.Errorf("output contains %s:\n%s", unwanted, s)
+		}
+	}
+	if !strings.Contains(s, "<title>name</title>") {
+		t.Errorf("title should keep its text only:\n%s", s)
+	}
+}
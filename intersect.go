@@ -0,0 +1,111 @@
+package svg
+
+import (
+	"fmt"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// intersectTolerance is the curve flattening tolerance used when testing
+// paths for self-intersections, in canvas units.
+const intersectTolerance = 0.1
+
+// SelfIntersection reports a filled path whose outline crosses itself.
+// Such paths render differently under the nonzero and evenodd fill rules,
+// and tools disagree on which rule to apply when converting them.
+type SelfIntersection struct {
+	// Op is the index of the offending command in Recording.Commands.
+	Op int
+	// Rule is the fill rule the path was recorded with.
+	Rule recording.FillRule
+	// Point is the first crossing found, in canvas coordinates.
+	Point gg.Point
+}
+
+// String formats the intersection as a single human-readable line.
+func (s SelfIntersection) String() string {
+	return fmt.Sprintf("op %d: self-intersecting fill at (%g, %g)", s.Op, s.Point.X, s.Point.Y)
+}
+
+// FindSelfIntersections reports every fill command in r whose path crosses
+// itself, either within a subpath or between subpaths. Curves are flattened
+// before testing, and touching or collinear edges are not reported.
+//
+// gg does not provide boolean path operations, so intersections are only
+// reported; resolving them is left to the caller.
+func FindSelfIntersections(r *recording.Recording) []SelfIntersection {
+	var found []SelfIntersection
+	for i, cmd := range r.Commands() {
+		c, ok := cmd.(recording.FillPathCommand)
+		if !ok {
+			continue
+		}
+		path := r.Resources().GetPath(c.Path)
+		if path == nil {
+			continue
+		}
+		if pt, ok := firstCrossing(path); ok {
+			found = append(found, SelfIntersection{Op: i, Rule: c.Rule, Point: pt})
+		}
+	}
+	return found
+}
+
+// segment is a straight edge of a flattened path.
+type segment struct {
+	a, b gg.Point
+}
+
+// firstCrossing returns the first point where two edges of the filled
+// outline of path properly cross.
+func firstCrossing(path *gg.Path) (gg.Point, bool) {
+	var edges []segment
+	for _, sp := range splitSubpaths(path) {
+		points := sp.Flatten(intersectTolerance)
+		if len(points) < 2 {
+			continue
+		}
+		for i := 1; i < len(points); i++ {
+			if points[i] != points[i-1] {
+				edges = append(edges, segment{points[i-1], points[i]})
+			}
+		}
+		// Fills implicitly close every subpath.
+		if first, last := points[0], points[len(points)-1]; first != last {
+			edges = append(edges, segment{last, first})
+		}
+	}
+
+	// Quadratic, but only used for offline analysis.
+	for i := range edges {
+		for j := i + 1; j < len(edges); j++ {
+			if pt, ok := crossing(edges[i], edges[j]); ok {
+				return pt, true
+			}
+		}
+	}
+	return gg.Point{}, false
+}
+
+// crossing returns the intersection point of s and t if they properly
+// cross. Edges that only touch or are collinear do not cross.
+func crossing(s, t segment) (gg.Point, bool) {
+	d1 := orientation(t.a, t.b, s.a)
+	d2 := orientation(t.a, t.b, s.b)
+	d3 := orientation(s.a, s.b, t.a)
+	d4 := orientation(s.a, s.b, t.b)
+	if d1*d2 >= 0 || d3*d4 >= 0 {
+		return gg.Point{}, false
+	}
+	u := d1 / (d1 - d2)
+	return gg.Point{
+		X: s.a.X + u*(s.b.X-s.a.X),
+		Y: s.a.Y + u*(s.b.Y-s.a.Y),
+	}, true
+}
+
+// orientation returns the signed area of the triangle abc.
+func orientation(a, b, c gg.Point) float64 {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
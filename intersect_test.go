@@ -0,0 +1,47 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestFindSelfIntersections(t *testing.T) {
+	rec := recording.NewRecorder(200, 200)
+	rec.SetFillRGBA(0, 0, 0, 1)
+
+	// Plain rectangle: no crossing.
+	rec.DrawRectangle(10, 10, 50, 50)
+	rec.Fill()
+
+	// Bow tie: edges cross at (50, 50).
+	rec.MoveTo(0, 0)
+	rec.LineTo(100, 100)
+	rec.LineTo(100, 0)
+	rec.LineTo(0, 100)
+	rec.ClosePath()
+	rec.Fill()
+
+	// Donut: subpaths nest but do not cross.
+	rec.DrawCircle(150, 150, 40)
+	rec.DrawCircle(150, 150, 20)
+	rec.Fill()
+
+	r := rec.FinishRecording()
+	found := FindSelfIntersections(r)
+	if len(found) != 1 {
+		t.Fatalf("Expected 1 self-intersection, got %d: %v", len(found), found)
+	}
+
+	got := found[0]
+	if _, ok := r.Commands()[got.Op].(recording.FillPathCommand); !ok {
+		t.Errorf("Op %d should index a FillPathCommand, got %T", got.Op, r.Commands()[got.Op])
+	}
+	if got.Point.X != 50 || got.Point.Y != 50 {
+		t.Errorf("Crossing should be at (50, 50), got %v", got.Point)
+	}
+	if !strings.Contains(got.String(), "self-intersecting fill at (50, 50)") {
+		t.Errorf("Unexpected String(): %s", got.String())
+	}
+}
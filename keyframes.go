@@ -0,0 +1,143 @@
+package svg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+// CSSAnimation describes a CSS @keyframes animation of an element's
+// opacity and transform, an alternative to SMIL for environments that do
+// not run it.
+type CSSAnimation struct {
+	// Keyframes are the steps of the animation, in offset order.
+	Keyframes []Keyframe
+	// Duration is the length of one iteration.
+	Duration time.Duration
+	// Delay postpones the start of the animation.
+	Delay time.Duration
+	// Iterations is the number of times the animation plays; zero
+	// repeats it indefinitely.
+	Iterations int
+	// Easing is the CSS timing function, "ease" if empty.
+	Easing string
+	// Alternate plays every other iteration backwards.
+	Alternate bool
+	// Origin is the CSS transform-origin relative to the element's
+	// bounding box, e.g. "center" to rotate an element in place. If empty,
+	// transforms are relative to the user-space origin.
+	Origin string
+}
+
+// Keyframe is a step of a CSSAnimation. Empty properties are not
+// animated at this step.
+type Keyframe struct {
+	// Offset is the position of the step in the iteration, from 0 to 1.
+	Offset float64
+	// Opacity is a CSS opacity, e.g. "0.5".
+	Opacity string
+	// Transform is a CSS transform, e.g. "rotate(90deg)".
+	Transform string
+}
+
+// SetNextAnimation animates the next drawn element with CSS. The
+// keyframes are written once per distinct animation into the document's
+// <style> block, and the element references them through a class that
+// combines with SetNextAttrs. It is cleared once the element is written.
+//
+// A CSS transform replaces the element's transform attribute, so
+// elements with transform keyframes should be drawn without a transform,
+// or exported with WithFlattenTransforms.
+//
+// The CSS values are written as given, so SetNextAnimation returns an
+// error, and animates nothing, if any of them contains characters that
+// could end the rule or start another one: braces, semicolons, at signs,
+// quotes, backslashes or comments.
+func (b *Backend) SetNextAnimation(a CSSAnimation) error {
+	if err := checkCSSValue("Easing", a.Easing); err != nil {
+		return err
+	}
+	if err := checkCSSValue("Origin", a.Origin); err != nil {
+		return err
+	}
+	for _, k := range a.Keyframes {
+		if err := checkCSSValue("Keyframe.Opacity", k.Opacity); err != nil {
+			return err
+		}
+		if err := checkCSSValue("Keyframe.Transform", k.Transform); err != nil {
+			return err
+		}
+	}
+	b.nextAttrs.animation = &a
+	return nil
+}
+
+// checkCSSValue returns an error if value, written into a CSS rule as
+// the field of a CSSAnimation, could escape its declaration.
+func checkCSSValue(field, value string) error {
+	if strings.ContainsAny(value, "{};@\"'\\") || strings.Contains(value, "/*") {
+		return fmt.Errorf("svg: SetNextAnimation: invalid %s %q", field, value)
+	}
+	return nil
+}
+
+// animationClass returns the class applying a, registering its keyframes
+// and class rule on first use.
+func (b *Backend) animationClass(a *CSSAnimation) string {
+	var kf strings.Builder
+	for _, k := range a.Keyframes {
+		kf.WriteString(svgwriter.FormatNumber(k.Offset*100, 3) + "%{")
+		var decls []string
+		if k.Opacity != "" {
+			decls = append(decls, "opacity:"+k.Opacity)
+		}
+		if k.Transform != "" {
+			decls = append(decls, "transform:"+k.Transform)
+		}
+		kf.WriteString(strings.Join(decls, ";") + "}")
+	}
+	keyframes, ok := b.keyframeNames[kf.String()]
+	if !ok {
+		if b.keyframeNames == nil {
+			b.keyframeNames = make(map[string]string)
+		}
+		keyframes = fmt.Sprintf("%skf%d", b.idPrefix, len(b.keyframeNames)+1)
+		b.keyframeNames[kf.String()] = keyframes
+		b.animationRules = append(b.animationRules, "@keyframes "+keyframes+"{"+kf.String()+"}")
+	}
+
+	easing := a.Easing
+	if easing == "" {
+		easing = "ease"
+	}
+	iterations := "infinite"
+	if a.Iterations > 0 {
+		iterations = strconv.Itoa(a.Iterations)
+	}
+	decls := fmt.Sprintf("animation:%s %s %s %s %s", keyframes, cssTime(a.Duration), easing, cssTime(a.Delay), iterations)
+	if a.Alternate {
+		decls += " alternate"
+	}
+	decls += " both"
+	if a.Origin != "" {
+		decls += ";transform-box:fill-box;transform-origin:" + a.Origin
+	}
+	if class, ok := b.animationClasses[decls]; ok {
+		return class
+	}
+	if b.animationClasses == nil {
+		b.animationClasses = make(map[string]string)
+	}
+	class := fmt.Sprintf("%sa%d", b.idPrefix, len(b.animationClasses)+1)
+	b.animationClasses[decls] = class
+	b.animationRules = append(b.animationRules, "."+class+"{"+decls+"}")
+	return class
+}
+
+// cssTime formats d as CSS seconds.
+func cssTime(d time.Duration) string {
+	return svgwriter.FormatNumber(d.Seconds(), 3) + "s"
+}
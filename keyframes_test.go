@@ -0,0 +1,76 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestSetNextAnimation(t *testing.T) {
+	fade := CSSAnimation{
+		Keyframes: []Keyframe{{Offset: 0, Opacity: "0"}, {Offset: 1, Opacity: "1"}},
+		Duration:  500 * time.Millisecond,
+		Easing:    "linear",
+	}
+	spin := CSSAnimation{
+		Keyframes:  []Keyframe{{Offset: 0, Transform: "rotate(0deg)"}, {Offset: 1, Transform: "rotate(360deg)"}},
+		Duration:   2 * time.Second,
+		Iterations: 3,
+		Origin:     "center",
+	}
+
+	backend := NewBackend(WithMergePaths(true))
+	_ = backend.Begin(100, 100)
+	brush := recording.NewSolidBrush(gg.Red)
+	backend.SetNextAttrs("", "dot")
+	backend.SetNextAnimation(fade)
+	backend.FillPath(rectPath(recording.NewRect(0, 0, 10, 10)), brush, recording.FillRuleNonZero)
+	backend.SetNextAnimation(fade)
+	backend.FillPath(rectPath(recording.NewRect(20, 0, 10, 10)), brush, recording.FillRuleNonZero)
+	backend.SetNextAnimation(spin)
+	backend.FillPath(rectPath(recording.NewRect(40, 0, 10, 10)), brush, recording.FillRuleNonZero)
+	backend.FillPath(rectPath(recording.NewRect(60, 0, 10, 10)), brush, recording.FillRuleNonZero)
+	_ = backend.End()
+
+	svg := writeSVG(t, backend)
+	for _, want := range []string{
+		`@keyframes kf1{0%{opacity:0}100%{opacity:1}}`,
+		`.a1{animation:kf1 0.5s linear 0s infinite both}`,
+		`@keyframes kf2{0%{transform:rotate(0deg)}100%{transform:rotate(360deg)}}`,
+		`.a2{animation:kf2 2s ease 0s 3 both;transform-box:fill-box;transform-origin:center}`,
+		`class="dot a1"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG missing %s:\n%s", want, svg)
+		}
+	}
+	if strings.Count(svg, "@keyframes kf1") != 1 {
+		t.Error("identical keyframes should be written once")
+	}
+	if strings.Count(svg, "<path") != 4 {
+		t.Errorf("animated paths should not be merged:\n%s", svg)
+	}
+}
+
+func TestSetNextAnimationInvalid(t *testing.T) {
+	for _, a := range []CSSAnimation{
+		{Easing: "linear}.x{fill:red"},
+		{Origin: "center;animation:none"},
+		{Keyframes: []Keyframe{{Opacity: "1}@import url(x)"}}},
+		{Keyframes: []Keyframe{{Transform: `rotate(1deg) "`}}},
+		{Keyframes: []Keyframe{{Transform: "/* rest"}}},
+	} {
+		backend := NewBackend()
+		_ = backend.Begin(10, 10)
+		if err := backend.SetNextAnimation(a); err == nil {
+			t.Errorf("SetNextAnimation(%+v) should fail", a)
+		}
+		backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+		if svg := writeSVG(t, backend); strings.Contains(svg, "<style") {
+			t.Errorf("invalid animation should not be written:\n%s", svg)
+		}
+	}
+}
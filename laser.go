@@ -0,0 +1,105 @@
+package svg
+
+import (
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// LaserOp is the laser operation an element is assigned to.
+type LaserOp int
+
+const (
+	// LaserEngrave rasters the element (fills, text and images).
+	LaserEngrave LaserOp = iota
+	// LaserScore traces the element at low power.
+	LaserScore
+	// LaserCut cuts through the material along the element.
+	LaserCut
+	// LaserSkip omits the element from the output, e.g. for guides.
+	LaserSkip
+)
+
+// laserOpNames maps laser operations to their group IDs, in job order:
+// engraving and scoring run before cutting so parts don't shift.
+var laserOpNames = [...]string{
+	LaserEngrave: "engrave",
+	LaserScore:   "score",
+	LaserCut:     "cut",
+}
+
+// Conventional stroke colors for laser operations, as used by LightBurn
+// color layers and Glowforge/Epilog print drivers.
+var (
+	laserCutColor   = gg.RGBA{R: 1, G: 0, B: 0, A: 1}
+	laserScoreColor = gg.RGBA{R: 0, G: 0, B: 1, A: 1}
+)
+
+// LaserElement describes an element being classified for laser output.
+type LaserElement struct {
+	// Stroked is true for stroke operations.
+	Stroked bool
+	// Brush is the element's brush after RemapBrush, or nil for images.
+	Brush recording.Brush
+	// Stroke is the stroke style; zero for non-stroke elements.
+	Stroke recording.Stroke
+}
+
+// WithLaserClassifier selects StructureLaser and assigns every element to a
+// laser operation with fn. Elements are written into engrave, score and
+// cut groups in that order; cut and score strokes are recolored to the
+// conventional red and blue so the export is machine-ready.
+// A nil fn uses ClassifyLaserByColor.
+func WithLaserClassifier(fn func(LaserElement) LaserOp) Option {
+	return func(b *Backend) {
+		b.structure = StructureLaser
+		b.laserClassifier = fn
+	}
+}
+
+// ClassifyLaserByColor is the default laser classifier. Strokes that are
+// pure red are cut, pure blue strokes are scored, and everything else is
+// engraved.
+func ClassifyLaserByColor(el LaserElement) LaserOp {
+	solid, ok := el.Brush.(recording.SolidBrush)
+	if !el.Stroked || !ok {
+		return LaserEngrave
+	}
+	switch solid.Color {
+	case laserCutColor:
+		return LaserCut
+	case laserScoreColor:
+		return LaserScore
+	default:
+		return LaserEngrave
+	}
+}
+
+// classifyLaser assigns the current element to a laser layer and returns
+// the brush to emit for it.
+func (b *Backend) classifyLaser(stroked bool, brush recording.Brush, stroke recording.Stroke) recording.Brush {
+	if b.structure != StructureLaser {
+		return brush
+	}
+
+	classify := b.laserClassifier
+	if classify == nil {
+		classify = ClassifyLaserByColor
+	}
+	op := classify(LaserElement{Stroked: stroked, Brush: brush, Stroke: stroke})
+
+	switch op {
+	case LaserSkip:
+		b.elementLayer = -1
+	case LaserCut, LaserScore:
+		b.elementLayer = int(op)
+		if stroked {
+			if op == LaserCut {
+				return recording.NewSolidBrush(laserCutColor)
+			}
+			return recording.NewSolidBrush(laserScoreColor)
+		}
+	default:
+		b.elementLayer = int(LaserEngrave)
+	}
+	return brush
+}
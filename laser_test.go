@@ -0,0 +1,71 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestLaserClassifyByColor(t *testing.T) {
+	backend := NewBackend(WithLaserClassifier(nil))
+	if err := backend.Begin(200, 200); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	path := gg.NewPath()
+	path.Rectangle(10, 10, 50, 50)
+	stroke := recording.DefaultStroke()
+
+	backend.StrokePath(path, recording.NewSolidBrush(gg.RGBA{R: 1, A: 1}), stroke)
+	backend.StrokePath(path, recording.NewSolidBrush(gg.RGBA{B: 1, A: 1}), stroke)
+	backend.FillPath(path, recording.NewSolidBrush(gg.RGBA{A: 1}), recording.FillRuleNonZero)
+
+	svg := writeSVG(t, backend)
+	engrave := strings.Index(svg, `<g id="engrave">`)
+	score := strings.Index(svg, `<g id="score">`)
+	cut := strings.Index(svg, `<g id="cut">`)
+	if engrave < 0 || score < 0 || cut < 0 {
+		t.Fatalf("Output should contain engrave, score and cut groups:\n%s", svg)
+	}
+	if engrave >= score || score >= cut {
+		t.Error("Groups should be ordered engrave, score, cut")
+	}
+}
+
+func TestLaserCustomClassifier(t *testing.T) {
+	backend := NewBackend(WithLaserClassifier(func(el LaserElement) LaserOp {
+		switch {
+		case !el.Stroked:
+			return LaserSkip
+		case el.Stroke.Width < 1:
+			return LaserCut
+		default:
+			return LaserScore
+		}
+	}))
+	_ = backend.Begin(200, 200)
+
+	path := gg.NewPath()
+	path.Rectangle(10, 10, 50, 50)
+	hairline := recording.DefaultStroke()
+	hairline.Width = 0.1
+	green := recording.NewSolidBrush(gg.RGBA{G: 1, A: 1})
+
+	backend.StrokePath(path, green, hairline)
+	backend.StrokePath(path, green, recording.DefaultStroke())
+	backend.FillPath(path, green, recording.FillRuleNonZero)
+	backend.DrawText("guide", 0, 0, nil, green)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<g id="cut"><path d="M10 10L60 10L60 60L10 60Z" fill="none" stroke="rgb(255,0,0)"`) {
+		t.Error("Cut strokes should be recolored red")
+	}
+	if !strings.Contains(svg, `<g id="score"><path d="M10 10L60 10L60 60L10 60Z" fill="none" stroke="rgb(0,0,255)"`) {
+		t.Error("Score strokes should be recolored blue")
+	}
+	if strings.Contains(svg, "<text") || strings.Contains(svg, `<g id="engrave">`) {
+		t.Error("Skipped elements should be omitted")
+	}
+}
@@ -0,0 +1,29 @@
+package svg
+
+import "fmt"
+
+// inkscapeNS is the Inkscape extension namespace.
+const inkscapeNS = "http://www.inkscape.org/namespaces/inkscape"
+
+// BeginLayer starts a named layer. Subsequently drawn elements are placed
+// in a group marked as an Inkscape layer, so the artwork opens in Inkscape
+// with a meaningful layer panel. Layers may be nested as sublayers.
+//
+// Like links, a layer opened after Save is closed by the matching Restore
+// if EndLayer has not been called by then. Layers are ignored by layered
+// structures (StructureByOp, StructureLaser), which define their own groups.
+func (b *Backend) BeginLayer(name string) {
+	if b.structure != StructureInterleaved {
+		return
+	}
+	b.usesInkscape = true
+	b.pushContainer(containerLayer, fmt.Sprintf(
+		`<g id="%s" inkscape:groupmode="layer" inkscape:label="%s">`,
+		b.nextID("layer"), escapeXML(name)))
+}
+
+// EndLayer closes the layer opened by the most recent BeginLayer.
+// It is a no-op if no layer is open in the current Save/Restore scope.
+func (b *Backend) EndLayer() {
+	b.popContainer(containerLayer)
+}
@@ -0,0 +1,61 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestBeginEndLayer(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.Begin(200, 200); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	backend.BeginLayer("Background & grid")
+	backend.FillRect(recording.NewRect(0, 0, 200, 200), brush)
+	backend.EndLayer()
+	backend.BeginLayer("Labels")
+	backend.DrawText("A", 10, 10, nil, brush)
+	backend.EndLayer()
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"`) {
+		t.Error("Root element should declare the Inkscape namespace")
+	}
+	if !strings.Contains(svg, `<g id="layer1" inkscape:groupmode="layer" inkscape:label="Background &amp; grid"><rect`) {
+		t.Error("Output should contain the first layer")
+	}
+	if !strings.Contains(svg, `</g><g id="layer2" inkscape:groupmode="layer" inkscape:label="Labels"><text`) {
+		t.Error("Output should contain the second layer after the first")
+	}
+}
+
+func TestNoInkscapeNamespaceWithoutLayers(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+
+	if strings.Contains(writeSVG(t, backend), "xmlns:inkscape") {
+		t.Error("Inkscape namespace should only be declared when layers are used")
+	}
+}
+
+func TestLayerClosedByRestore(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+
+	backend.Save()
+	backend.BeginLayer("inner")
+	backend.Restore()
+	backend.EndLayer()
+
+	svg := writeSVG(t, backend)
+	// The group around the layer is collapsed, since the layer is its
+	// only child.
+	if !strings.Contains(svg, "\n"+`<g id="layer1" inkscape:groupmode="layer" inkscape:label="inner"></g>`+"\n</svg>") {
+		t.Errorf("Restore should close layers opened inside the group, got:\n%s", svg)
+	}
+}
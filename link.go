@@ -0,0 +1,64 @@
+package svg
+
+import (
+	"fmt"
+	"slices"
+)
+
+// BeginLink wraps subsequently drawn elements in an <a href="url"> element
+// until the matching EndLink, making chart bars, map regions and diagram
+// nodes clickable.
+//
+// SVG does not allow links inside links. A BeginLink while another link
+// is open is reported as ErrNestedLink and left out: elements drawn until
+// its EndLink stay in the outer link.
+//
+// A link opened after Save is closed by the matching Restore if EndLink
+// has not been called by then, so the document always stays well-formed.
+func (b *Backend) BeginLink(url string) {
+	if b.structure != StructureInterleaved {
+		// Elements are regrouped, so each one is wrapped individually.
+		if len(b.links) > 0 {
+			b.fail(ErrNestedLink, fmt.Errorf("%q", url))
+			url = b.links[len(b.links)-1]
+		}
+		b.links = append(b.links, url)
+		return
+	}
+	if slices.ContainsFunc(b.containers, func(c openContainer) bool { return c.kind == containerLink }) {
+		b.fail(ErrNestedLink, fmt.Errorf("%q", url))
+		b.containers[len(b.containers)-1].nestedLinks++
+		return
+	}
+	b.pushContainer(containerLink, fmt.Sprintf(`<a %s="%s">`, b.hrefAttr(), escapeXML(url)))
+}
+
+// EndLink closes the link opened by the most recent BeginLink.
+// It is a no-op if no link is open in the current Save/Restore scope.
+func (b *Backend) EndLink() {
+	if b.structure != StructureInterleaved {
+		if len(b.links) > 0 {
+			b.links = b.links[:len(b.links)-1]
+		}
+		return
+	}
+	if n := len(b.containers); n > 0 && b.containers[n-1].nestedLinks > 0 {
+		b.containers[n-1].nestedLinks--
+		return
+	}
+	b.popContainer(containerLink)
+}
+
+// openElementLink writes the per-element link wrapper used with StructureByOp.
+func (b *Backend) openElementLink() {
+	if len(b.links) > 0 {
+		b.builder.WriteString(fmt.Sprintf(`<a %s="%s">`, b.hrefAttr(), escapeXML(b.links[len(b.links)-1])))
+	}
+}
+
+// closeElementLink closes the per-element link wrapper used with StructureByOp.
+func (b *Backend) closeElementLink() {
+	if len(b.links) > 0 {
+		b.builder.WriteString("</a>")
+	}
+}
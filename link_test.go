@@ -0,0 +1,100 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestBeginEndLink(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 1, G: 0, B: 0, A: 1})
+	backend.BeginLink("https://example.com/?a=1&b=2")
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), brush)
+	backend.EndLink()
+	backend.FillRect(recording.NewRect(20, 0, 10, 10), brush)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<a href="https://example.com/?a=1&amp;b=2"><rect x="0"`) {
+		t.Error("Linked element should be wrapped in <a>")
+	}
+	if !strings.Contains(svg, `stroke="none"/></a><rect x="20"`) {
+		t.Error("Link should end at EndLink")
+	}
+}
+
+func TestLinkClosedByRestore(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	backend.Save()
+	backend.BeginLink("#a")
+	backend.FillRect(recording.NewRect(0, 0, 1, 1), brush)
+	backend.Restore()
+	backend.EndLink() // no link open in this scope
+	backend.BeginLink("#b")
+
+	svg := writeSVG(t, backend)
+	// The group around the link is collapsed, since the link is its only
+	// child.
+	if !strings.Contains(svg, "\n"+`<a href="#a"><rect`) || !strings.Contains(svg, `</a><a href="#b"></a>`) {
+		t.Errorf("Links should nest correctly with groups, got:\n%s", svg)
+	}
+}
+
+func TestLinkStructureByOp(t *testing.T) {
+	backend := NewBackend(WithStructure(StructureByOp))
+	_ = backend.Begin(100, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	backend.BeginLink("#node")
+	backend.FillRect(recording.NewRect(0, 0, 1, 1), brush)
+	backend.DrawText("label", 0, 10, nil, brush)
+	backend.EndLink()
+
+	svg := writeSVG(t, backend)
+	if strings.Count(svg, `<a href="#node">`) != 2 {
+		t.Error("Each regrouped element should carry its own link")
+	}
+	if !strings.Contains(svg, `<g id="text"><a href="#node"><text`) {
+		t.Error("Link wrapper should move with its element")
+	}
+}
+
+func TestNestedLink(t *testing.T) {
+	for _, structure := range []Structure{StructureInterleaved, StructureByOp} {
+		backend := NewBackend(WithStructure(structure))
+		_ = backend.Begin(100, 100)
+
+		brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+		backend.BeginLink("#outer")
+		backend.BeginLink("#inner")
+		backend.FillRect(recording.NewRect(0, 0, 1, 1), brush)
+		backend.EndLink()
+		backend.FillRect(recording.NewRect(2, 0, 1, 1), brush)
+		backend.EndLink()
+		backend.FillRect(recording.NewRect(4, 0, 1, 1), brush)
+
+		svg := writeSVG(t, backend)
+		if strings.Contains(svg, "#inner") {
+			t.Errorf("%v: nested link should be left out:\n%s", structure, svg)
+		}
+		if strings.Count(svg, "<a ") != strings.Count(svg, "</a>") {
+			t.Errorf("%v: links should be balanced:\n%s", structure, svg)
+		}
+		if !strings.Contains(svg, `</a><rect x="4"`) && !strings.Contains(svg, `</a>`+"\n"+`<rect x="4"`) {
+			t.Errorf("%v: outer link should end at its own EndLink:\n%s", structure, svg)
+		}
+		if !errors.Is(backend.Err(), ErrNestedLink) {
+			t.Errorf("%v: nested link should be reported, got %v", structure, backend.Err())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Viewer identifies an SVG consumer with known rendering limitations.
+type Viewer int
+
+const (
+	// ViewerPowerPoint is Microsoft PowerPoint (and other Office apps).
+	ViewerPowerPoint Viewer = iota
+	// ViewerLegacyLibrsvg is librsvg before 2.46, still common on LTS distributions.
+	ViewerLegacyLibrsvg
+	// ViewerFigma is the Figma SVG importer.
+	ViewerFigma
+	// ViewerEmail covers common email clients (Gmail, Outlook).
+	ViewerEmail
+)
+
+// viewerNames maps Viewer values to their display names.
+var viewerNames = [...]string{
+	ViewerPowerPoint:    "PowerPoint",
+	ViewerLegacyLibrsvg: "librsvg (<2.46)",
+	ViewerFigma:         "Figma import",
+	ViewerEmail:         "Email clients",
+}
+
+// allViewers lists every known Viewer in declaration order.
+var allViewers = []Viewer{ViewerPowerPoint, ViewerLegacyLibrsvg, ViewerFigma, ViewerEmail}
+
+// String returns the display name of the viewer.
+func (v Viewer) String() string {
+	if int(v) >= 0 && int(v) < len(viewerNames) {
+		return viewerNames[v]
+	}
+	return "Unknown"
+}
+
+// Finding is a single compatibility problem reported by LintFor.
+type Finding struct {
+	// Viewer is the consumer affected by the problem.
+	Viewer Viewer
+	// Construct is the SVG markup that triggers the problem.
+	Construct string
+	// Count is the number of occurrences in the document.
+	Count int
+	// Message describes the problem and how to avoid it.
+	Message string
+}
+
+// String formats the finding as a single human-readable line.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%d occurrence(s)): %s", f.Viewer, f.Construct, f.Count, f.Message)
+}
+
+// lintRule describes a construct that breaks in a set of viewers.
+type lintRule struct {
+	viewers   []Viewer
+	construct string
+	pattern   string
+	message   string
+}
+
+// lintRules is the table of known viewer incompatibilities.
+var lintRules = []lintRule{
+	{
+		viewers:   []Viewer{ViewerEmail},
+		construct: "<svg>",
+		pattern:   "<svg",
+		message:   "most email clients do not render SVG at all; send a PNG fallback",
+	},
+	{
+		viewers:   []Viewer{ViewerPowerPoint, ViewerFigma},
+		construct: "<textPath>",
+		pattern:   "<textPath",
+		message:   "text on path is dropped or flattened to a straight line",
+	},
+	{
+		viewers:   []Viewer{ViewerLegacyLibrsvg, ViewerPowerPoint},
+		construct: "href",
+		pattern:   ` href="`,
+		message:   "plain href is not resolved; references need xlink:href (WithSVGVersion(SVG11))",
+	},
+	{
+		viewers:   []Viewer{ViewerPowerPoint, ViewerFigma},
+		construct: "spreadMethod",
+		pattern:   "spreadMethod=",
+		message:   "repeat/reflect gradients are rendered as pad",
+	},
+	{
+		viewers:   []Viewer{ViewerFigma, ViewerLegacyLibrsvg},
+		construct: "unicode-bidi",
+		pattern:   "unicode-bidi=",
+		message:   "bidi embedding is ignored; mixed-direction text may be reordered",
+	},
+	{
+		viewers:   []Viewer{ViewerPowerPoint},
+		construct: "<clipPath> with evenodd",
+		pattern:   `clip-rule="evenodd"`,
+		message:   "even-odd clip rule is treated as nonzero",
+	},
+	{
+		viewers:   []Viewer{ViewerEmail},
+		construct: "<text>",
+		pattern:   "<text",
+		message:   "text relies on locally installed fonts which email clients do not provide",
+	},
+}
+
+// LintFor scans the built document for constructs known to break in the
+// given viewers and returns one Finding per affected viewer and construct.
+// With no targets, all known viewers are checked.
+// It should be called after End().
+//
+// The document is linted as WriteTo would write it, after optimizer
+// passes and post-processors, but it is not signed and ContentHash and
+// Signature are left unchanged. Failures recorded while drawing do not
+// prevent linting; an error is only returned if the document cannot be
+// built.
+func (b *Backend) LintFor(targets ...Viewer) ([]Finding, error) {
+	if len(targets) == 0 {
+		targets = allViewers
+	}
+
+	data, err := b.postProcess()
+	if err != nil {
+		return nil, err
+	}
+	doc := string(data)
+
+	var findings []Finding
+	for _, rule := range lintRules {
+		count := strings.Count(doc, rule.pattern)
+		if count == 0 {
+			continue
+		}
+		for _, v := range rule.viewers {
+			if !containsViewer(targets, v) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Viewer:    v,
+				Construct: rule.construct,
+				Count:     count,
+				Message:   rule.message,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// containsViewer reports whether v is in viewers.
+func containsViewer(viewers []Viewer, v Viewer) bool {
+	for _, candidate := range viewers {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
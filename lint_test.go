@@ -0,0 +1,77 @@
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestLintFor(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	path := gg.NewPath()
+	path.MoveTo(0, 100)
+	path.LineTo(400, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 0, G: 0, B: 0, A: 1})
+	backend.DrawTextOnPath("label", path, 0, nil, brush)
+	if err := backend.End(); err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+
+	findings, err := backend.LintFor(ViewerPowerPoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	constructs := make(map[string]bool)
+	for _, f := range findings {
+		if f.Viewer != ViewerPowerPoint {
+			t.Errorf("Unexpected viewer in findings: %v", f.Viewer)
+		}
+		constructs[f.Construct] = true
+	}
+	if !constructs["<textPath>"] {
+		t.Error("PowerPoint lint should flag textPath")
+	}
+	if !constructs["href"] {
+		t.Error("PowerPoint lint should flag plain href")
+	}
+}
+
+func TestLintForClean(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.RGBA{R: 1, A: 1}))
+	_ = backend.End()
+
+	if findings, _ := backend.LintFor(ViewerPowerPoint, ViewerFigma, ViewerLegacyLibrsvg); len(findings) != 0 {
+		t.Errorf("Simple document should have no findings, got %v", findings)
+	}
+	if findings, _ := backend.LintFor(); len(findings) != 1 || findings[0].Viewer != ViewerEmail {
+		t.Errorf("Email clients should always be flagged, got %v", findings)
+	}
+}
+
+func TestLintForIncomplete(t *testing.T) {
+	signs := 0
+	backend := NewBackend(WithSigner(SignerFunc(func([]byte) ([]byte, error) {
+		signs++
+		return []byte("sig"), nil
+	})))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, math.NaN(), 10), recording.NewSolidBrush(gg.RGBA{R: 1, A: 1}))
+	backend.DrawText("a", 0, 10, nil, recording.NewSolidBrush(gg.RGBA{A: 1}))
+
+	findings, err := backend.LintFor(ViewerEmail)
+	if err != nil || len(findings) != 2 {
+		t.Errorf("LintFor should lint an incomplete export, got %v, %v", findings, err)
+	}
+	if signs != 0 || backend.ContentHash() != "" || backend.Signature() != nil {
+		t.Error("LintFor should not sign the document or set its hash")
+	}
+}
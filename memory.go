@@ -0,0 +1,147 @@
+package svg
+
+import (
+	"bytes"
+	"sync"
+)
+
+// DefaultTrimThreshold is the retained buffer capacity, in bytes, above
+// which Begin releases buffers instead of reusing them.
+const DefaultTrimThreshold = 8 << 20
+
+// bytesPerElement is the estimated output size of one drawing command,
+// used to presize the document buffer.
+const bytesPerElement = 128
+
+// scratchPool holds buffers for formatting attribute values, such as
+// path data, before they are copied into the document.
+var scratchPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxScratch is the largest buffer returned to the scratch pool, so one
+// huge path does not pin its buffer for the life of the process.
+const maxScratch = 64 << 10
+
+// getScratch returns an empty buffer from the scratch pool.
+func getScratch() *bytes.Buffer {
+	buf := scratchPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putScratch returns a buffer obtained with getScratch to the pool.
+func putScratch(buf *bytes.Buffer) {
+	if buf.Cap() <= maxScratch {
+		scratchPool.Put(buf)
+	}
+}
+
+// Stats reports memory usage of a Backend.
+type Stats struct {
+	// Bytes is the size of the current document content and definitions.
+	Bytes int
+	// PeakBytes is the largest document size built by this backend.
+	PeakBytes int
+	// RetainedBytes is the buffer capacity currently held by the backend,
+	// including spare capacity kept for reuse.
+	RetainedBytes int
+}
+
+// WithTrimThreshold sets the retained capacity above which Begin releases
+// the backend's buffers rather than reusing them for the next export.
+//
+// Reusing buffers avoids reallocation when a backend exports many
+// similar documents, but after an unusually large export a long-lived
+// backend would otherwise hold on to that memory indefinitely.
+// A threshold of 0 or less always releases buffers.
+func WithTrimThreshold(bytes int) Option {
+	return func(b *Backend) {
+		b.trimThreshold = bytes
+	}
+}
+
+// WithSizeHint presizes the document buffer at Begin for about elements
+// drawing commands, so large scenes are not built by repeated
+// grow-and-copy. Without a hint, Playback sizes the buffer from the
+// number of recorded commands.
+func WithSizeHint(elements int) Option {
+	return func(b *Backend) {
+		b.sizeHint = elements
+	}
+}
+
+// presize grows the document buffer for the expected number of elements.
+func (b *Backend) presize() {
+	elements := b.sizeHint
+	if elements <= 0 && b.source != nil {
+		elements = len(b.source.Commands())
+	}
+	if elements > 0 {
+		b.builder.Grow(elements * bytesPerElement)
+	}
+}
+
+// Stats returns the current memory statistics of the backend.
+func (b *Backend) Stats() Stats {
+	size := b.documentLen()
+	retained := b.builder.Cap() + b.defs.Cap()
+	for i := range b.layers {
+		retained += b.layers[i].Cap()
+	}
+	return Stats{
+		Bytes:         size,
+		PeakBytes:     max(b.peakBytes, size),
+		RetainedBytes: retained,
+	}
+}
+
+// Reset discards the drawn document and starts a new one at the same
+// size, reusing the backend's buffers. It is equivalent to calling Begin
+// with the current dimensions, for loops that export one frame after
+// another.
+func (b *Backend) Reset() {
+	_ = b.Begin(b.width, b.height)
+}
+
+// Release drops the rendered document and all retained buffer capacity.
+// Call it once the output has been written when a long-lived backend is
+// going to sit idle. The backend must be restarted with Begin before
+// further use.
+func (b *Backend) Release() {
+	b.recordPeak()
+	b.builder = bytes.Buffer{}
+	b.defs = bytes.Buffer{}
+	b.layers = nil
+	b.stateStack = make([]backendState, 0, 8)
+	b.containers = nil
+}
+
+// recordPeak folds the current document size into the peak statistic.
+func (b *Backend) recordPeak() {
+	b.peakBytes = max(b.peakBytes, b.documentLen())
+}
+
+// documentLen returns the size of the buffered document content.
+func (b *Backend) documentLen() int {
+	size := b.builder.Len() + b.defs.Len()
+	for i := range b.layers {
+		size += b.layers[i].Len()
+	}
+	return size
+}
+
+// trimBuffers releases buffers whose capacity exceeds the trim threshold.
+func (b *Backend) trimBuffers() {
+	if b.builder.Cap() > b.trimThreshold {
+		b.builder = bytes.Buffer{}
+	}
+	if b.defs.Cap() > b.trimThreshold {
+		b.defs = bytes.Buffer{}
+	}
+	for i := range b.layers {
+		if b.layers[i].Cap() > b.trimThreshold {
+			b.layers[i] = bytes.Buffer{}
+		}
+	}
+}
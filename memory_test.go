@@ -0,0 +1,125 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestStatsPeakBytes(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 1, A: 1})
+	for i := 0; i < 100; i++ {
+		backend.FillRect(recording.NewRect(0, 0, 10, 10), brush)
+	}
+	_ = backend.End()
+	large := backend.Stats().Bytes
+	if large == 0 {
+		t.Fatal("Stats should report the document size")
+	}
+
+	_ = backend.Begin(100, 100)
+	stats := backend.Stats()
+	if stats.Bytes != 0 {
+		t.Errorf("Begin should reset the document size, got %d", stats.Bytes)
+	}
+	if stats.PeakBytes != large {
+		t.Errorf("PeakBytes = %d, expected %d", stats.PeakBytes, large)
+	}
+	if stats.RetainedBytes < large {
+		t.Error("Buffers below the trim threshold should be retained for reuse")
+	}
+}
+
+func TestWithTrimThreshold(t *testing.T) {
+	backend := NewBackend(WithTrimThreshold(1024))
+	_ = backend.Begin(100, 100)
+
+	backend.DrawText(strings.Repeat("x", 4096), 0, 0, nil, recording.NewSolidBrush(gg.RGBA{A: 1}))
+	_ = backend.End()
+
+	_ = backend.Begin(100, 100)
+	if retained := backend.Stats().RetainedBytes; retained != 0 {
+		t.Errorf("Oversized buffers should be released, retained %d bytes", retained)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.RGBA{A: 1}))
+	backend.Save()
+	_ = backend.End()
+
+	backend.Release()
+	stats := backend.Stats()
+	if stats.RetainedBytes != 0 || stats.Bytes != 0 {
+		t.Errorf("Release should drop all buffers, got %+v", stats)
+	}
+	if stats.PeakBytes == 0 {
+		t.Error("Release should keep the peak statistic")
+	}
+}
+
+func TestReset(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(40, 30)
+	frame := func(i int) {
+		backend.Save()
+		backend.SetTransform(recording.Translate(float64(i), 0))
+		backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+		backend.Restore()
+	}
+
+	frame(1)
+	first := backend.String()
+	backend.Reset()
+	frame(1)
+	if second := backend.String(); second != first {
+		t.Errorf("Reset should start an identical document, got:\n%s\nexpected:\n%s", second, first)
+	}
+	if !strings.Contains(first, `width="40" height="30"`) {
+		t.Errorf("Reset should keep the dimensions, got:\n%s", first)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		backend.Reset()
+	})
+	if allocs != 0 {
+		t.Errorf("Reset should reuse buffers, got %v allocations", allocs)
+	}
+}
+
+func TestWithSizeHint(t *testing.T) {
+	backend := NewBackend(WithSizeHint(1000))
+	_ = backend.Begin(100, 100)
+	if retained := backend.Stats().RetainedBytes; retained < 1000*bytesPerElement {
+		t.Errorf("Begin should presize the buffer for the hint, retained %d bytes", retained)
+	}
+
+	rec := recording.NewRecorder(100, 100)
+	for range 500 {
+		rec.DrawRectangle(0, 0, 10, 10)
+		rec.Fill()
+	}
+	backend = NewBackend()
+	if err := backend.Playback(rec.FinishRecording()); err != nil {
+		t.Fatal(err)
+	}
+	if retained := backend.Stats().RetainedBytes; retained < 500*bytesPerElement {
+		t.Errorf("Playback should presize the buffer from the recording, retained %d bytes", retained)
+	}
+}
+
+func TestPathToDScratch(t *testing.T) {
+	backend := NewBackend()
+	path := line(0, 0, 10, 10)
+	first := backend.pathToD(path)
+	if second := backend.pathToD(path); second != first || first != "M0 0L10 10" {
+		t.Errorf("pathToD() = %q, then %q", first, second)
+	}
+}
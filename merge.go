@@ -0,0 +1,99 @@
+package svg
+
+import (
+	"strings"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// WithMergePaths merges consecutive <path> elements with identical
+// attributes, other than their path data, into one path whose d attribute
+// concatenates theirs. Scatter plots and other exports of many small,
+// identically styled shapes shrink by an order of magnitude.
+//
+// Only opaque paths are merged, since overlapping translucent shapes
+// blend differently as one element, and even-odd fills are left alone,
+// since overlaps would cancel out. Nonzero fills that overlap stay
+// identical as long as their subpaths wind the same way; normalize them
+// with WithWinding if they may not. Elements with an id, a source-map
+// index, a CSS animation or a link wrapper, or rewritten by OnElement, are
+// never merged, and with a layered structure paths are not merged.
+func WithMergePaths(enabled bool) Option {
+	return func(b *Backend) {
+		b.mergePaths = enabled
+	}
+}
+
+// pathMerge tracks the last path element written, for WithMergePaths.
+type pathMerge struct {
+	// end is the offset just past the element in the builder. The next
+	// element can be merged only if it starts there.
+	end int
+	// dEnd is the offset of the closing quote of its d attribute.
+	dEnd int
+	// key is the element with its path data removed.
+	key string
+}
+
+// mergePath merges the element written since the last openElement call
+// into the previous path, if they differ only in their path data. It
+// reports whether the element was merged and removed.
+func (b *Backend) mergePath() bool {
+	if !b.mergePaths {
+		return false
+	}
+	elem := string(b.builder.Bytes()[b.elementStart:])
+	key, dStart, dEnd := mergeKey(elem)
+	if key == "" || !b.mergeable || b.tagStart != b.elementStart || b.animatedElement || b.elementHook != nil {
+		b.lastPath = pathMerge{}
+		return false
+	}
+
+	prev := b.lastPath
+	if prev.key != key || prev.end != b.elementStart {
+		b.lastPath = pathMerge{
+			end:  b.builder.Len(),
+			dEnd: b.elementStart + dEnd,
+			key:  key,
+		}
+		return false
+	}
+
+	// Splice the path data into the previous element.
+	tail := string(b.builder.Bytes()[prev.dEnd:b.elementStart])
+	b.builder.Truncate(prev.dEnd)
+	b.builder.WriteString(elem[dStart:dEnd])
+	b.builder.WriteString(tail)
+	b.lastPath.dEnd += dEnd - dStart
+	b.lastPath.end = b.builder.Len()
+	return true
+}
+
+// mergesPaint reports whether paths painted with brush may be merged.
+// Only opaque colors qualify, whichever way their alpha is written.
+func (b *Backend) mergesPaint(brush recording.Brush) bool {
+	br, ok := brush.(recording.SolidBrush)
+	return ok && b.adjustAlpha(br.Color.A) >= 1
+}
+
+// mergeKey returns elem without its path data, and the offsets of the
+// path data, if elem is a path that may be merged. It returns an empty
+// key otherwise.
+func mergeKey(elem string) (key string, dStart, dEnd int) {
+	if !strings.HasPrefix(elem, "<path ") || !strings.HasSuffix(elem, "/>") {
+		return "", 0, 0
+	}
+	for _, attr := range []string{` id="`, ` data-gg-op="`} {
+		if strings.Contains(elem, attr) {
+			return "", 0, 0
+		}
+	}
+	// Attribute values are escaped, so ` d="` only occurs as an attribute.
+	i := strings.Index(elem, ` d="`)
+	if i < 0 {
+		return "", 0, 0
+	}
+	dStart = i + len(` d="`)
+	dEnd = dStart + strings.IndexByte(elem[dStart:], '"')
+	return elem[:dStart] + elem[dEnd:], dStart, dEnd
+}
@@ -0,0 +1,107 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithMergePaths(t *testing.T) {
+	backend := NewBackend(WithMergePaths(true))
+	_ = backend.Begin(100, 100)
+	red := recording.NewSolidBrush(gg.Red)
+	dot := func(x float64) *gg.Path {
+		p := gg.NewPath()
+		p.Rectangle(x, 0, 1, 1)
+		return p
+	}
+
+	for x := range 3 {
+		backend.FillPath(dot(float64(x)), red, recording.FillRuleNonZero)
+	}
+	backend.FillPath(dot(10), recording.NewSolidBrush(gg.Blue), recording.FillRuleNonZero)
+	backend.FillPath(dot(11), recording.NewSolidBrush(gg.Blue), recording.FillRuleNonZero)
+	backend.StrokePath(dot(20), red, recording.DefaultStroke())
+	backend.StrokePath(dot(21), red, recording.DefaultStroke())
+
+	out := backend.String()
+	if n := strings.Count(out, "<path"); n != 3 {
+		t.Errorf("Expected 3 merged paths, got %d:\n%s", n, out)
+	}
+	if !strings.Contains(out, `d="M0 0L1 0L1 1L0 1ZM1 0L2 0L2 1L1 1ZM2 0L3 0L3 1L2 1Z" fill="rgb(255,0,0)"`) {
+		t.Errorf("Path data should be concatenated in order:\n%s", out)
+	}
+}
+
+func TestWithMergePathsKeepsDistinct(t *testing.T) {
+	dot := gg.NewPath()
+	dot.Rectangle(0, 0, 1, 1)
+	red := recording.NewSolidBrush(gg.Red)
+
+	tests := []struct {
+		name string
+		draw func(b *Backend)
+	}{
+		{"translucent", func(b *Backend) {
+			b.FillPath(dot, recording.NewSolidBrush(gg.RGBA{R: 1, A: 0.5}), recording.FillRuleNonZero)
+		}},
+		{"evenodd", func(b *Backend) {
+			b.FillPath(dot, red, recording.FillRuleEvenOdd)
+		}},
+		{"transform", func(b *Backend) {
+			b.SetTransform(recording.Translate(5, 5))
+			b.FillPath(dot, red, recording.FillRuleNonZero)
+		}},
+		{"id", func(b *Backend) {
+			b.SetNextAttrs("a")
+			b.FillPath(dot, red, recording.FillRuleNonZero)
+			b.SetNextAttrs("b")
+			b.FillPath(dot, red, recording.FillRuleNonZero)
+		}},
+		{"group", func(b *Backend) {
+			b.Save()
+			b.FillPath(dot, red, recording.FillRuleNonZero)
+			b.FillPath(dot, recording.NewSolidBrush(gg.Blue), recording.FillRuleNonZero)
+			b.Restore()
+		}},
+	}
+	for _, tt := range tests {
+		backend := NewBackend(WithMergePaths(true))
+		_ = backend.Begin(100, 100)
+		backend.FillPath(dot, red, recording.FillRuleNonZero)
+		tt.draw(backend)
+		backend.SetTransform(recording.Identity())
+		backend.FillPath(dot, red, recording.FillRuleNonZero)
+
+		out := backend.String()
+		if strings.Contains(out, "ZM") {
+			t.Errorf("%s: paths should not be merged:\n%s", tt.name, out)
+		}
+	}
+}
+
+func TestWithMergePathsWrittenAlpha(t *testing.T) {
+	dot := gg.NewPath()
+	dot.Rectangle(0, 0, 1, 1)
+	translucent := recording.NewSolidBrush(gg.RGBA{R: 1, A: 0.5})
+	red := recording.NewSolidBrush(gg.Red)
+
+	// Opacity and the fill rule may end up in a style attribute, a class
+	// or the color itself, so they are decided from the brush.
+	for _, opt := range []Option{WithStyleMode(StyleInline), WithStyleMode(StyleClasses), WithHexAlpha(true)} {
+		backend := NewBackend(WithMergePaths(true), opt)
+		_ = backend.Begin(100, 100)
+		backend.FillPath(dot, translucent, recording.FillRuleNonZero)
+		backend.FillPath(dot, translucent, recording.FillRuleNonZero)
+		backend.StrokePath(dot, translucent, recording.DefaultStroke())
+		backend.StrokePath(dot, translucent, recording.DefaultStroke())
+		backend.FillPath(dot, red, recording.FillRuleEvenOdd)
+		backend.FillPath(dot, red, recording.FillRuleEvenOdd)
+
+		if out := backend.String(); strings.Contains(out, "ZM") {
+			t.Errorf("translucent and even-odd paths should not be merged:\n%s", out)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package svg
+
+import (
+	"strings"
+	"time"
+)
+
+// SetTitle sets the document title, emitted as a <title> element that is
+// the first child of the root <svg>. Screen readers announce it and file
+// managers show it as the document name. An empty title emits nothing.
+//
+// Document metadata is not reset by Begin, so it can be set before
+// playing back a recording.
+func (b *Backend) SetTitle(title string) {
+	b.title = title
+}
+
+// SetDescription sets the document description, emitted as a <desc>
+// element following the title. An empty description emits nothing.
+func (b *Backend) SetDescription(desc string) {
+	b.description = desc
+}
+
+// documentInfo returns the <title>, <desc> and <metadata> elements.
+func (b *Backend) documentInfo() string {
+	var s strings.Builder
+	if b.title != "" {
+		s.WriteString("<title>")
+		s.WriteString(escapeXML(b.title))
+		s.WriteString("</title>\n")
+	}
+	if b.description != "" {
+		s.WriteString("<desc>")
+		s.WriteString(escapeXML(b.description))
+		s.WriteString("</desc>\n")
+	}
+	s.WriteString(b.metadataBlock())
+	s.WriteString(b.provenanceBlock())
+	return s.String()
+}
+
+// Metadata is machine-readable provenance serialized as a Dublin Core
+// RDF block inside <metadata>, following the layout Inkscape uses.
+// Empty fields are omitted.
+type Metadata struct {
+	// Author is the creator of the document (dc:creator).
+	Author string
+	// License is the license URL (cc:license), e.g. a Creative Commons URL.
+	License string
+	// Rights is a free-form rights statement (dc:rights).
+	Rights string
+	// Created is the creation date (dc:date).
+	Created time.Time
+	// Keywords are subject keywords (dc:subject).
+	Keywords []string
+}
+
+// isZero reports whether no field is set.
+func (m *Metadata) isZero() bool {
+	return m.Author == "" && m.License == "" && m.Rights == "" &&
+		m.Created.IsZero() && len(m.Keywords) == 0
+}
+
+// SetMetadata sets the Dublin Core metadata block. The document title set
+// with SetTitle is included as dc:title. Like the title, metadata is not
+// reset by Begin.
+func (b *Backend) SetMetadata(m Metadata) {
+	b.metadata = m
+}
+
+// metadataBlock returns the <metadata> element, or "" if no metadata is set.
+func (b *Backend) metadataBlock() string {
+	m := &b.metadata
+	if m.isZero() {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString(`<metadata><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"`)
+	s.WriteString(` xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:cc="http://creativecommons.org/ns#">`)
+	s.WriteString(`<cc:Work rdf:about=""><dc:format>image/svg+xml</dc:format>`)
+	s.WriteString(`<dc:type rdf:resource="http://purl.org/dc/dcmitype/StillImage"/>`)
+	if b.title != "" {
+		s.WriteString("<dc:title>" + escapeXML(b.title) + "</dc:title>")
+	}
+	if m.Author != "" {
+		s.WriteString("<dc:creator><cc:Agent><dc:title>" + escapeXML(m.Author) + "</dc:title></cc:Agent></dc:creator>")
+	}
+	if !m.Created.IsZero() {
+		s.WriteString("<dc:date>" + m.Created.Format(time.RFC3339) + "</dc:date>")
+	}
+	if m.Rights != "" {
+		s.WriteString("<dc:rights><cc:Agent><dc:title>" + escapeXML(m.Rights) + "</dc:title></cc:Agent></dc:rights>")
+	}
+	if len(m.Keywords) > 0 {
+		s.WriteString("<dc:subject><rdf:Bag>")
+		for _, kw := range m.Keywords {
+			s.WriteString("<rdf:li>" + escapeXML(kw) + "</rdf:li>")
+		}
+		s.WriteString("</rdf:Bag></dc:subject>")
+	}
+	if m.License != "" {
+		s.WriteString(`<cc:license rdf:resource="` + escapeXML(m.License) + `"/>`)
+	}
+	s.WriteString("</cc:Work></rdf:RDF></metadata>\n")
+	return s.String()
+}
@@ -0,0 +1,76 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetTitleDescription(t *testing.T) {
+	backend := NewBackend()
+	backend.SetTitle("Sales & revenue")
+	backend.SetDescription("Quarterly bar chart")
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	svg := writeSVG(t, backend)
+	expected := `viewBox="0 0 400 300">
+<title>Sales &amp; revenue</title>
+<desc>Quarterly bar chart</desc>
+`
+	if !strings.Contains(svg, expected) {
+		t.Errorf("Title and description should be the first children of <svg>, got:\n%s", svg)
+	}
+}
+
+func TestNoTitleByDefault(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(400, 300)
+
+	svg := writeSVG(t, backend)
+	if strings.Contains(svg, "<title>") || strings.Contains(svg, "<desc>") {
+		t.Error("Title and description should be omitted when unset")
+	}
+}
+
+func TestSetMetadata(t *testing.T) {
+	backend := NewBackend()
+	backend.SetTitle("Figure 3")
+	backend.SetMetadata(Metadata{
+		Author:   "Research <Team>",
+		License:  "https://creativecommons.org/licenses/by/4.0/",
+		Created:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Keywords: []string{"climate", "temperature"},
+	})
+	if err := backend.Begin(100, 100); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	svg := writeSVG(t, backend)
+	for _, expected := range []string{
+		`<metadata><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"`,
+		`<dc:title>Figure 3</dc:title>`,
+		`<dc:creator><cc:Agent><dc:title>Research &lt;Team&gt;</dc:title></cc:Agent></dc:creator>`,
+		`<dc:date>2026-03-01T12:00:00Z</dc:date>`,
+		`<rdf:Bag><rdf:li>climate</rdf:li><rdf:li>temperature</rdf:li></rdf:Bag>`,
+		`<cc:license rdf:resource="https://creativecommons.org/licenses/by/4.0/"/>`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("Output should contain %s", expected)
+		}
+	}
+	if strings.Index(svg, "<metadata>") < strings.Index(svg, "<title>") {
+		t.Error("Metadata should follow the title")
+	}
+}
+
+func TestNoMetadataByDefault(t *testing.T) {
+	backend := NewBackend()
+	backend.SetTitle("Only a title")
+	_ = backend.Begin(100, 100)
+
+	if strings.Contains(writeSVG(t, backend), "<metadata>") {
+		t.Error("Metadata block should be omitted when unset")
+	}
+}
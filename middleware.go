@@ -0,0 +1,331 @@
+package svg
+
+import (
+	"errors"
+	"image"
+	"io"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+// Middleware wraps a recording.Backend to observe or rewrite the calls
+// made to it. Middleware compose, so cross-cutting export passes such as
+// counting, filtering or brush remapping can be layered over the SVG
+// backend, or any other backend, without changing it.
+type Middleware func(recording.Backend) recording.Backend
+
+// Chain wraps b in the middleware. The first middleware is outermost and
+// sees each call first.
+//
+//	rec.Playback(svg.Chain(backend, svg.CountOps(counts), svg.Filter(keep)))
+func Chain(b recording.Backend, mw ...Middleware) recording.Backend {
+	for i := len(mw) - 1; i >= 0; i-- {
+		b = mw[i](b)
+	}
+	return b
+}
+
+// ErrNotSupported is returned by Decorator's WriteTo and SaveToFile when
+// the wrapped backend does not implement them.
+var ErrNotSupported = errors.New("svg: not supported by the wrapped backend")
+
+// Decorator forwards every call to Next. Middleware embed it and
+// override the calls they intercept. WriteTo and SaveToFile are forwarded
+// too, so a decorated backend can still write its output.
+type Decorator struct {
+	Next recording.Backend
+}
+
+var (
+	_ recording.WriterBackend = Decorator{}
+	_ recording.FileBackend   = Decorator{}
+)
+
+// Begin forwards to Next.
+func (d Decorator) Begin(width, height int) error { return d.Next.Begin(width, height) }
+
+// End forwards to Next.
+func (d Decorator) End() error { return d.Next.End() }
+
+// Save forwards to Next.
+func (d Decorator) Save() { d.Next.Save() }
+
+// Restore forwards to Next.
+func (d Decorator) Restore() { d.Next.Restore() }
+
+// SetTransform forwards to Next.
+func (d Decorator) SetTransform(m recording.Matrix) { d.Next.SetTransform(m) }
+
+// SetClip forwards to Next.
+func (d Decorator) SetClip(path *gg.Path, rule recording.FillRule) { d.Next.SetClip(path, rule) }
+
+// ClearClip forwards to Next.
+func (d Decorator) ClearClip() { d.Next.ClearClip() }
+
+// FillPath forwards to Next.
+func (d Decorator) FillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
+	d.Next.FillPath(path, brush, rule)
+}
+
+// StrokePath forwards to Next.
+func (d Decorator) StrokePath(path *gg.Path, brush recording.Brush, stroke recording.Stroke) {
+	d.Next.StrokePath(path, brush, stroke)
+}
+
+// FillRect forwards to Next.
+func (d Decorator) FillRect(rect recording.Rect, brush recording.Brush) { d.Next.FillRect(rect, brush) }
+
+// DrawImage forwards to Next.
+func (d Decorator) DrawImage(img image.Image, src, dst recording.Rect, opts recording.ImageOptions) {
+	d.Next.DrawImage(img, src, dst, opts)
+}
+
+// DrawText forwards to Next.
+func (d Decorator) DrawText(s string, x, y float64, face text.Face, brush recording.Brush) {
+	d.Next.DrawText(s, x, y, face, brush)
+}
+
+// WriteTo forwards to Next if it is a recording.WriterBackend.
+func (d Decorator) WriteTo(w io.Writer) (int64, error) {
+	if wb, ok := d.Next.(recording.WriterBackend); ok {
+		return wb.WriteTo(w)
+	}
+	return 0, ErrNotSupported
+}
+
+// SaveToFile forwards to Next if it is a recording.FileBackend.
+func (d Decorator) SaveToFile(path string) error {
+	if fb, ok := d.Next.(recording.FileBackend); ok {
+		return fb.SaveToFile(path)
+	}
+	return ErrNotSupported
+}
+
+// CountOps counts the calls made to the backend by method name, such as
+// "FillPath" or "SetClip", into counts, which must not be nil. Begin and
+// End are not counted.
+func CountOps(counts map[string]int) Middleware {
+	return func(next recording.Backend) recording.Backend {
+		return &opCounter{Decorator{next}, counts}
+	}
+}
+
+type opCounter struct {
+	Decorator
+	counts map[string]int
+}
+
+func (c *opCounter) Save() {
+	c.counts["Save"]++
+	c.Next.Save()
+}
+
+func (c *opCounter) Restore() {
+	c.counts["Restore"]++
+	c.Next.Restore()
+}
+
+func (c *opCounter) SetTransform(m recording.Matrix) {
+	c.counts["SetTransform"]++
+	c.Next.SetTransform(m)
+}
+
+func (c *opCounter) SetClip(path *gg.Path, rule recording.FillRule) {
+	c.counts["SetClip"]++
+	c.Next.SetClip(path, rule)
+}
+
+func (c *opCounter) ClearClip() {
+	c.counts["ClearClip"]++
+	c.Next.ClearClip()
+}
+
+func (c *opCounter) FillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
+	c.counts["FillPath"]++
+	c.Next.FillPath(path, brush, rule)
+}
+
+func (c *opCounter) StrokePath(path *gg.Path, brush recording.Brush, stroke recording.Stroke) {
+	c.counts["StrokePath"]++
+	c.Next.StrokePath(path, brush, stroke)
+}
+
+func (c *opCounter) FillRect(rect recording.Rect, brush recording.Brush) {
+	c.counts["FillRect"]++
+	c.Next.FillRect(rect, brush)
+}
+
+func (c *opCounter) DrawImage(img image.Image, src, dst recording.Rect, opts recording.ImageOptions) {
+	c.counts["DrawImage"]++
+	c.Next.DrawImage(img, src, dst, opts)
+}
+
+func (c *opCounter) DrawText(s string, x, y float64, face text.Face, brush recording.Brush) {
+	c.counts["DrawText"]++
+	c.Next.DrawText(s, x, y, face, brush)
+}
+
+// Filter drops drawing calls for which keep returns false. keep receives
+// the method name: "FillPath", "StrokePath", "FillRect", "DrawImage" or
+// "DrawText". State calls are always forwarded.
+func Filter(keep func(method string) bool) Middleware {
+	return func(next recording.Backend) recording.Backend {
+		return &filter{Decorator{next}, keep}
+	}
+}
+
+type filter struct {
+	Decorator
+	keep func(string) bool
+}
+
+func (f *filter) FillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
+	if f.keep("FillPath") {
+		f.Next.FillPath(path, brush, rule)
+	}
+}
+
+func (f *filter) StrokePath(path *gg.Path, brush recording.Brush, stroke recording.Stroke) {
+	if f.keep("StrokePath") {
+		f.Next.StrokePath(path, brush, stroke)
+	}
+}
+
+func (f *filter) FillRect(rect recording.Rect, brush recording.Brush) {
+	if f.keep("FillRect") {
+		f.Next.FillRect(rect, brush)
+	}
+}
+
+func (f *filter) DrawImage(img image.Image, src, dst recording.Rect, opts recording.ImageOptions) {
+	if f.keep("DrawImage") {
+		f.Next.DrawImage(img, src, dst, opts)
+	}
+}
+
+func (f *filter) DrawText(s string, x, y float64, face text.Face, brush recording.Brush) {
+	if f.keep("DrawText") {
+		f.Next.DrawText(s, x, y, face, brush)
+	}
+}
+
+// MapBrushes rewrites the brush of every fill, stroke and text call with
+// fn. Unlike Backend.RemapBrush it works with any backend.
+func MapBrushes(fn func(recording.Brush) recording.Brush) Middleware {
+	return func(next recording.Backend) recording.Backend {
+		return &brushMapper{Decorator{next}, fn}
+	}
+}
+
+type brushMapper struct {
+	Decorator
+	fn func(recording.Brush) recording.Brush
+}
+
+func (m *brushMapper) FillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
+	m.Next.FillPath(path, m.fn(brush), rule)
+}
+
+func (m *brushMapper) StrokePath(path *gg.Path, brush recording.Brush, stroke recording.Stroke) {
+	m.Next.StrokePath(path, m.fn(brush), stroke)
+}
+
+func (m *brushMapper) FillRect(rect recording.Rect, brush recording.Brush) {
+	m.Next.FillRect(rect, m.fn(brush))
+}
+
+func (m *brushMapper) DrawText(s string, x, y float64, face text.Face, brush recording.Brush) {
+	m.Next.DrawText(s, x, y, face, m.fn(brush))
+}
+
+// Bounds holds the bounds of drawn content collected by TrackBounds.
+type Bounds struct {
+	recording.Rect
+	// Valid is false until something is drawn.
+	Valid bool
+}
+
+// TrackBounds collects the bounds of everything drawn, under the current
+// transform, into bounds. Begin resets it. Strokes are grown by half their
+// width and text contributes only its origin, since the backend does not
+// receive text metrics. Clips are not taken into account.
+func TrackBounds(bounds *Bounds) Middleware {
+	return func(next recording.Backend) recording.Backend {
+		return &boundsTracker{Decorator: Decorator{next}, bounds: bounds, transform: recording.Identity()}
+	}
+}
+
+type boundsTracker struct {
+	Decorator
+	bounds    *Bounds
+	transform recording.Matrix
+	stack     []recording.Matrix
+}
+
+func (t *boundsTracker) Begin(width, height int) error {
+	*t.bounds = Bounds{}
+	t.transform = recording.Identity()
+	t.stack = t.stack[:0]
+	return t.Next.Begin(width, height)
+}
+
+func (t *boundsTracker) Save() {
+	t.stack = append(t.stack, t.transform)
+	t.Next.Save()
+}
+
+func (t *boundsTracker) Restore() {
+	if n := len(t.stack); n > 0 {
+		t.transform = t.stack[n-1]
+		t.stack = t.stack[:n-1]
+	}
+	t.Next.Restore()
+}
+
+func (t *boundsTracker) SetTransform(m recording.Matrix) {
+	t.transform = m
+	t.Next.SetTransform(m)
+}
+
+func (t *boundsTracker) FillPath(path *gg.Path, brush recording.Brush, rule recording.FillRule) {
+	if path != nil {
+		t.include(pathBounds(path, 0))
+	}
+	t.Next.FillPath(path, brush, rule)
+}
+
+func (t *boundsTracker) StrokePath(path *gg.Path, brush recording.Brush, stroke recording.Stroke) {
+	if path != nil {
+		t.include(pathBounds(path, stroke.Width/2))
+	}
+	t.Next.StrokePath(path, brush, stroke)
+}
+
+func (t *boundsTracker) FillRect(rect recording.Rect, brush recording.Brush) {
+	t.include(rect.MinX, rect.MinY, rect.MaxX, rect.MaxY)
+	t.Next.FillRect(rect, brush)
+}
+
+func (t *boundsTracker) DrawImage(img image.Image, src, dst recording.Rect, opts recording.ImageOptions) {
+	if img != nil {
+		t.include(dst.MinX, dst.MinY, dst.MaxX, dst.MaxY)
+	}
+	t.Next.DrawImage(img, src, dst, opts)
+}
+
+func (t *boundsTracker) DrawText(s string, x, y float64, face text.Face, brush recording.Brush) {
+	t.include(x, y, x, y)
+	t.Next.DrawText(s, x, y, face, brush)
+}
+
+// include adds the transformed user-space rectangle to the bounds.
+func (t *boundsTracker) include(minX, minY, maxX, maxY float64) {
+	r := transformBBox(t.transform, minX, minY, maxX, maxY)
+	if t.bounds.Valid {
+		r = r.union(bbox{t.bounds.MinX, t.bounds.MinY, t.bounds.MaxX, t.bounds.MaxY})
+	}
+	t.bounds.Rect = recording.Rect{MinX: r.minX, MinY: r.minY, MaxX: r.maxX, MaxY: r.maxY}
+	t.bounds.Valid = true
+}
@@ -0,0 +1,108 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	tag := func(name string) Middleware {
+		return Filter(func(string) bool {
+			calls = append(calls, name)
+			return true
+		})
+	}
+
+	b := Chain(NewBackend(), tag("outer"), tag("inner"))
+	_ = b.Begin(10, 10)
+	b.FillRect(recording.Rect{MaxX: 1, MaxY: 1}, recording.NewSolidBrush(gg.Black))
+
+	if strings.Join(calls, ",") != "outer,inner" {
+		t.Errorf("calls = %v, want [outer inner]", calls)
+	}
+}
+
+func TestMiddlewareDecorators(t *testing.T) {
+	counts := make(map[string]int)
+	var bounds Bounds
+	keep := func(method string) bool { return method != "DrawText" }
+	red := func(recording.Brush) recording.Brush { return recording.NewSolidBrush(gg.RGBA{R: 1, A: 1}) }
+
+	backend := NewBackend()
+	b := Chain(backend, CountOps(counts), TrackBounds(&bounds), Filter(keep), MapBrushes(red))
+
+	rec := recording.NewRecorder(100, 100)
+	rec.SetFillRGB(0, 0, 1)
+	rec.DrawRectangle(10, 20, 30, 40)
+	rec.Fill()
+	rec.DrawString("dropped", 90, 90)
+	if err := rec.FinishRecording().Playback(b); err != nil {
+		t.Fatalf("Playback failed: %v", err)
+	}
+
+	if counts["FillPath"] != 1 || counts["DrawText"] != 1 {
+		t.Errorf("counts = %v", counts)
+	}
+	// Text is dropped after the tracker sees it, so its origin counts.
+	want := Bounds{recording.Rect{MinX: 10, MinY: 20, MaxX: 90, MaxY: 90}, true}
+	if bounds != want {
+		t.Errorf("bounds = %+v, want %+v", bounds, want)
+	}
+
+	var buf bytes.Buffer
+	if _, err := b.(recording.WriterBackend).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	svg := buf.String()
+	if strings.Contains(svg, "dropped") {
+		t.Errorf("Filtered text should not be written, got:\n%s", svg)
+	}
+	if !strings.Contains(svg, `fill="rgb(255,0,0)"`) {
+		t.Errorf("Brushes should be remapped, got:\n%s", svg)
+	}
+}
+
+func TestDecoratorWithoutWriter(t *testing.T) {
+	d := Decorator{Next: Chain(NewBackend(), CountOps(map[string]int{}))}
+	if _, err := d.WriteTo(&bytes.Buffer{}); err != nil {
+		t.Errorf("WriteTo should reach the SVG backend through nested decorators: %v", err)
+	}
+
+	var bare struct{ recording.Backend }
+	bare.Backend = NewBackend()
+	if err := (Decorator{Next: bare}).SaveToFile("unused"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SaveToFile error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestConcurrentBackends(t *testing.T) {
+	var wg sync.WaitGroup
+	outputs := make([]string, 8)
+	for i := range outputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backend, err := recording.NewBackend("svg")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = backend.Begin(10, 10)
+			backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+			outputs[i] = backend.(*Backend).String()
+		}()
+	}
+	wg.Wait()
+	for _, out := range outputs[1:] {
+		if out != outputs[0] {
+			t.Errorf("Independent backends should produce identical output, got:\n%s\nand:\n%s", out, outputs[0])
+		}
+	}
+}
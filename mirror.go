@@ -0,0 +1,63 @@
+package svg
+
+import (
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+// WithMirror mirrors the whole document horizontally, vertically or both,
+// for transfer printing, heat-press vinyl and back-lit signage that are
+// viewed from the reverse side. Mirroring applies before WithRotation.
+//
+// Text is mirrored along with everything else; WithUnmirroredText keeps
+// it readable.
+func WithMirror(horizontal, vertical bool) Option {
+	return func(b *Backend) {
+		b.mirrorX = horizontal
+		b.mirrorY = vertical
+	}
+}
+
+// WithUnmirroredText keeps text readable in mirrored documents: each text
+// element is flipped back within its own bounds, so it occupies the
+// mirrored position but reads normally. The bounds are estimated like
+// WithAutoCrop does. Text on a path follows its mirrored path.
+func WithUnmirroredText(enabled bool) Option {
+	return func(b *Backend) {
+		b.unmirrorText = enabled
+	}
+}
+
+// mirrorMatrix returns the transform mirroring the box x, y, w, h in place.
+func (b *Backend) mirrorMatrix(x, y, w, h float64) recording.Matrix {
+	m := recording.Identity()
+	if b.mirrorX {
+		m.A, m.C = -1, 2*x+w
+	}
+	if b.mirrorY {
+		m.E, m.F = -1, 2*y+h
+	}
+	return m
+}
+
+// writeTextTransform writes the transform attribute of a text element
+// whose lines are drawn at x, y, flipping it back if the document is
+// mirrored and WithUnmirroredText is set.
+func (b *Backend) writeTextTransform(lines []string, x, y float64, face text.Face) {
+	if !b.unmirrorText || !b.mirrorX && !b.mirrorY {
+		b.writeTransform()
+		return
+	}
+	// The document mirror S follows the current transform L, so flipping
+	// the text by L⁻¹·S·L cancels it; flipping about the center of the
+	// text bounds keeps the text where the mirror put it.
+	r := b.textBounds(lines, x, y, face)
+	cx, cy := (r.minX+r.maxX)/2, (r.minY+r.maxY)/2
+	l := b.currentTransform
+	l.C, l.F = 0, 0
+	flip := l.Invert().Multiply(b.mirrorMatrix(0, 0, 0, 0)).Multiply(l)
+	m := b.currentTransform.Multiply(recording.Translate(cx, cy)).Multiply(flip).Multiply(recording.Translate(-cx, -cy))
+	if !m.IsIdentity() {
+		b.writeMatrixAttr(m)
+	}
+}
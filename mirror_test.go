@@ -0,0 +1,42 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithMirror(t *testing.T) {
+	draw := func(opts ...Option) string {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 50)
+		backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+		backend.DrawText("AB", 10, 20, nil, recording.NewSolidBrush(gg.Black))
+		return writeSVG(t, backend)
+	}
+
+	tests := []struct {
+		opts     []Option
+		expected []string
+	}{
+		{[]Option{WithMirror(true, false)}, []string{`<g transform="matrix(-1,0,0,1,100,0)">`, `<text x="10" y="20"`}},
+		{[]Option{WithMirror(false, true)}, []string{`<g transform="matrix(1,0,0,-1,0,50)">`}},
+		{[]Option{WithMirror(true, false), WithRotation(90)}, []string{`<g transform="matrix(0,-1,-1,0,50,100)">`, `viewBox="0 0 50 100"`}},
+		// The text spans x 10..24.4 and is flipped about its center 17.2.
+		{[]Option{WithMirror(true, false), WithUnmirroredText(true)}, []string{`<text transform="matrix(-1,0,0,1,34.4,0)" x="10" y="20"`}},
+	}
+	for _, tt := range tests {
+		svg := draw(tt.opts...)
+		for _, e := range tt.expected {
+			if !strings.Contains(svg, e) {
+				t.Errorf("Output should contain %s, got:\n%s", e, svg)
+			}
+		}
+	}
+
+	if svg := draw(WithUnmirroredText(true)); strings.Contains(svg, "transform") {
+		t.Errorf("Unmirrored text should not be transformed without a mirror, got:\n%s", svg)
+	}
+}
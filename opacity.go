@@ -0,0 +1,89 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// WithOpacityPrecision rounds emitted opacities, and the alpha of colors
+// written with WithHexAlpha, to the given number of decimal places. This
+// removes noise such as fill-opacity="0.7799999999999999" left by
+// floating point arithmetic. A negative value keeps full precision, which
+// is the default.
+func WithOpacityPrecision(digits int) Option {
+	return func(b *Backend) {
+		b.opacityDigits = digits
+	}
+}
+
+// WithAlphaThresholds snaps nearly transparent and nearly opaque alphas.
+// Fills, strokes, text and images with an alpha below invisible are not
+// written at all, and alphas of opaque or more are written as fully
+// opaque. The defaults, 0 and 1, change nothing; fully transparent
+// content is never written.
+//
+// Thresholds apply to solid colors, gradient stops and image alpha. A
+// gradient is skipped only when all of its stops are below invisible.
+func WithAlphaThresholds(invisible, opaque float64) Option {
+	return func(b *Backend) {
+		b.alphaInvisible = invisible
+		b.alphaOpaque = opaque
+	}
+}
+
+// adjustAlpha applies the opaque threshold and opacity precision to a.
+func (b *Backend) adjustAlpha(a float64) float64 {
+	if a >= b.alphaOpaque {
+		return 1
+	}
+	if b.opacityDigits >= 0 {
+		scale := math.Pow(10, float64(b.opacityDigits))
+		a = math.Round(a*scale) / scale
+	}
+	return a
+}
+
+// opacity formats an opacity attribute value.
+func (b *Backend) opacity(a float64) string {
+	return strconv.FormatFloat(b.adjustAlpha(a), 'g', -1, 64)
+}
+
+// adjustBrush returns brush with adjustAlpha applied to a solid color.
+// Gradient stops are adjusted as they are written.
+func (b *Backend) adjustBrush(brush recording.Brush) recording.Brush {
+	if br, ok := brush.(recording.SolidBrush); ok {
+		br.Color.A = b.adjustAlpha(br.Color.A)
+		return br
+	}
+	return brush
+}
+
+// invisible reports whether an element painted with brush is dropped
+// because it is fully transparent or below the WithAlphaThresholds
+// threshold.
+func (b *Backend) invisible(brush recording.Brush) bool {
+	var stops []recording.GradientStop
+	switch br := brush.(type) {
+	case recording.SolidBrush:
+		return b.transparent(br.Color.A)
+	case *recording.LinearGradientBrush:
+		stops = br.Stops
+	case *recording.RadialGradientBrush:
+		stops = br.Stops
+	case *recording.SweepGradientBrush:
+		stops = br.Stops
+	default:
+		return false
+	}
+	if len(stops) == 0 {
+		return false
+	}
+	for _, stop := range stops {
+		if !b.transparent(stop.Color.A) {
+			return false
+		}
+	}
+	return true
+}
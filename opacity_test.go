@@ -0,0 +1,67 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithOpacityPrecision(t *testing.T) {
+	backend := NewBackend(WithOpacityPrecision(3))
+	_ = backend.Begin(100, 100)
+	c := gg.RGBA{R: 1, A: 0.7799999999999999}
+	backend.FillRect(recording.Rect{MaxX: 10, MaxY: 10}, recording.NewSolidBrush(c))
+	backend.StrokePath(line(0, 0, 10, 10), recording.NewSolidBrush(gg.RGBA{A: 0.12345}), recording.Stroke{Width: 1})
+	grad := recording.NewLinearGradientBrush(0, 0, 10, 0)
+	grad.AddColorStop(0, gg.RGBA{A: 0.33333})
+	backend.FillRect(recording.Rect{MaxX: 10, MaxY: 10}, grad)
+
+	svg := writeSVG(t, backend)
+	expected := []string{`fill-opacity="0.78"`, `stroke-opacity="0.123"`, `stop-opacity="0.333"`}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
+
+func TestWithAlphaThresholds(t *testing.T) {
+	face := testFace(t, 10)
+	backend := NewBackend(WithAlphaThresholds(0.01, 0.99))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.Rect{MaxX: 10, MaxY: 10}, recording.NewSolidBrush(gg.RGBA{R: 1, A: 0.005}))
+	backend.DrawText("hidden", 0, 10, face, recording.NewSolidBrush(gg.RGBA{A: 0}))
+	backend.StrokePath(line(0, 0, 10, 10), recording.NewSolidBrush(gg.RGBA{B: 1, A: 0.995}), recording.Stroke{Width: 1})
+	backend.FillRect(recording.Rect{MaxX: 5, MaxY: 5}, recording.NewSolidBrush(gg.RGBA{G: 1, A: 0.5}))
+
+	svg := writeSVG(t, backend)
+	if strings.Contains(svg, "rgb(255,0,0)") || strings.Contains(svg, "hidden") {
+		t.Errorf("Invisible elements should be dropped, got:\n%s", svg)
+	}
+	if strings.Contains(svg, "stroke-opacity") {
+		t.Errorf("Nearly opaque strokes should be written opaque, got:\n%s", svg)
+	}
+	if !strings.Contains(svg, `fill="rgb(0,255,0)" fill-opacity="0.5"`) {
+		t.Errorf("Translucent fills should be kept, got:\n%s", svg)
+	}
+	if got := backend.FontReport(); len(got) != 0 {
+		t.Errorf("Dropped text should not be reported, got %+v", got)
+	}
+}
+
+func TestDefaultAlphaUnchanged(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.Rect{MaxX: 10, MaxY: 10}, recording.NewSolidBrush(gg.RGBA{R: 1, A: 0.01}))
+	backend.FillRect(recording.Rect{MaxX: 10, MaxY: 10}, recording.NewSolidBrush(gg.RGBA{G: 1, A: 0}))
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `fill-opacity="0.01"`) {
+		t.Errorf("Nearly transparent elements should be kept by default, got:\n%s", svg)
+	}
+	if strings.Contains(svg, `fill-opacity="0"`) {
+		t.Errorf("Fully transparent elements should be dropped, got:\n%s", svg)
+	}
+}
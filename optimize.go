@@ -0,0 +1,305 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+// Optimizer is a pass over the element tree of a finished document, in the
+// style of svgo plugins: renaming IDs, rounding numbers, pruning unused
+// definitions and so on. Optimize may modify root and its descendants in
+// place; an error aborts the export.
+type Optimizer interface {
+	Optimize(root *Element) error
+}
+
+// OptimizerFunc adapts a function to the Optimizer interface.
+type OptimizerFunc func(root *Element) error
+
+// Optimize calls f(root).
+func (f OptimizerFunc) Optimize(root *Element) error {
+	return f(root)
+}
+
+// AddPass appends an optimizer pass. Before WriteTo or SaveToFile writes
+// the document, it is parsed into an element tree rooted at the <svg>
+// element, the passes run on it in the order they were added, and the
+// tree is serialized again. Post-processors added with WithPostProcessor
+// receive the optimized document.
+//
+// Passes persist across Begin. The XML declaration and doctype are kept
+// as written; comments inside the root element are dropped.
+func (b *Backend) AddPass(p Optimizer) {
+	if p != nil {
+		b.passes = append(b.passes, p)
+	}
+}
+
+// Element is an XML element of the tree that optimizer passes operate on.
+// An Element with an empty Name is character data, held in Text.
+type Element struct {
+	Name     string
+	Attrs    []Attr
+	Children []*Element
+	Text     string
+}
+
+// Attr is an attribute of an Element. Names include their namespace
+// prefix, as in "xlink:href"; values are unescaped.
+type Attr struct {
+	Name, Value string
+}
+
+// Attr returns the value of the named attribute and whether it is set.
+func (e *Element) Attr(name string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets the named attribute, appending it if it is not set.
+func (e *Element) SetAttr(name, value string) {
+	for i := range e.Attrs {
+		if e.Attrs[i].Name == name {
+			e.Attrs[i].Value = value
+			return
+		}
+	}
+	e.Attrs = append(e.Attrs, Attr{Name: name, Value: value})
+}
+
+// RemoveAttr removes the named attribute.
+func (e *Element) RemoveAttr(name string) {
+	e.Attrs = slices.DeleteFunc(e.Attrs, func(a Attr) bool { return a.Name == name })
+}
+
+// Walk calls fn for e and its descendant elements in document order,
+// skipping character data. If fn returns false, the children of that
+// element are not visited.
+func (e *Element) Walk(fn func(e *Element) bool) {
+	if e.Name == "" || !fn(e) {
+		return
+	}
+	for _, c := range e.Children {
+		c.Walk(fn)
+	}
+}
+
+// optimize parses the document in data, runs the optimizer passes and
+// returns the serialized result.
+func (b *Backend) optimize(data []byte) ([]byte, error) {
+	prolog, root, epilog, err := parseElementTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("svg: parsing document for optimizer passes: %w", err)
+	}
+	for i, p := range b.passes {
+		if err := p.Optimize(root); err != nil {
+			return nil, fmt.Errorf("svg: optimizer pass %d: %w", i, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	buf.Write(prolog)
+	out := svgwriter.New(&buf)
+	writeElementTree(out, root)
+	if err := out.Err(); err != nil {
+		return nil, err
+	}
+	buf.Write(epilog)
+	return buf.Bytes(), nil
+}
+
+// parseElementTree parses an SVG document into the text before the root
+// element, the root element and the text after it.
+func parseElementTree(data []byte) (prolog []byte, root *Element, epilog []byte, err error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*Element
+	for {
+		offset := d.InputOffset()
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &Element{Name: qualifiedName(t.Name)}
+			for _, a := range t.Attr {
+				e.Attrs = append(e.Attrs, Attr{Name: qualifiedName(a.Name), Value: a.Value})
+			}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, nil, nil, fmt.Errorf("second root element <%s>", e.Name)
+				}
+				prolog, root = data[:offset], e
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, e)
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].Name != qualifiedName(t.Name) {
+				return nil, nil, nil, fmt.Errorf("unexpected </%s>", qualifiedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				epilog = data[d.InputOffset():]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, &Element{Text: string(t)})
+			}
+		}
+	}
+	if root == nil || len(stack) > 0 {
+		return nil, nil, nil, io.ErrUnexpectedEOF
+	}
+	return prolog, root, epilog, nil
+}
+
+// writeElementTree serializes e and its descendants.
+func writeElementTree(out *svgwriter.Writer, e *Element) {
+	if e.Name == "" {
+		out.Text(e.Text)
+		return
+	}
+	out.StartElement(e.Name)
+	for _, a := range e.Attrs {
+		out.Attr(a.Name, a.Value)
+	}
+	for _, c := range e.Children {
+		writeElementTree(out, c)
+	}
+	out.End()
+}
+
+// numericAttrs are the attributes whose numbers RoundNumbers rounds.
+var numericAttrs = map[string]bool{
+	"d": true, "points": true, "transform": true, "gradientTransform": true, "patternTransform": true,
+	"x": true, "y": true, "x1": true, "y1": true, "x2": true, "y2": true, "width": true, "height": true,
+	"cx": true, "cy": true, "r": true, "rx": true, "ry": true, "fx": true, "fy": true, "fr": true,
+	"stroke-width": true, "stroke-dasharray": true, "stroke-dashoffset": true, "viewBox": true,
+}
+
+// attrNumber matches a number in an attribute value.
+var attrNumber = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// RoundNumbers returns a pass that rounds the numbers in geometry,
+// transform and size attributes to digits decimal places. Colors,
+// opacities and offsets are left unchanged.
+func RoundNumbers(digits int) Optimizer {
+	return OptimizerFunc(func(root *Element) error {
+		root.Walk(func(e *Element) bool {
+			for i, a := range e.Attrs {
+				if numericAttrs[a.Name] {
+					e.Attrs[i].Value = attrNumber.ReplaceAllStringFunc(a.Value, func(s string) string {
+						v, err := strconv.ParseFloat(s, 64)
+						if err != nil {
+							return s
+						}
+						return svgwriter.FormatNumber(v, digits)
+					})
+				}
+			}
+			return true
+		})
+		return nil
+	})
+}
+
+// PruneDefs returns a pass that removes definitions that nothing in the
+// document references by url(#id) or href, and <defs> elements left
+// empty.
+func PruneDefs() Optimizer {
+	return OptimizerFunc(func(root *Element) error {
+		for {
+			refs := references(root)
+			removed := false
+			root.Walk(func(e *Element) bool {
+				if e.Name != "defs" {
+					return true
+				}
+				e.Children = slices.DeleteFunc(e.Children, func(c *Element) bool {
+					id, ok := c.Attr("id")
+					if ok && !refs[id] {
+						removed = true
+						return true
+					}
+					return false
+				})
+				return false
+			})
+			if !removed {
+				break
+			}
+		}
+		root.Walk(func(e *Element) bool {
+			e.Children = slices.DeleteFunc(e.Children, func(c *Element) bool {
+				return c.Name == "defs" && strings.TrimSpace(innerText(c)) == "" && !hasElements(c)
+			})
+			return true
+		})
+		return nil
+	})
+}
+
+// urlRef matches url(#id) references in attribute values and style sheets.
+var urlRef = regexp.MustCompile(`url\(\s*['"]?#([^)'"\s]+)`)
+
+// references returns the IDs referenced in root.
+func references(root *Element) map[string]bool {
+	refs := make(map[string]bool)
+	var visit func(e *Element)
+	visit = func(e *Element) {
+		if e.Name == "" {
+			for _, m := range urlRef.FindAllStringSubmatch(e.Text, -1) {
+				refs[m[1]] = true
+			}
+			return
+		}
+		for _, a := range e.Attrs {
+			if (a.Name == "href" || strings.HasSuffix(a.Name, ":href")) && strings.HasPrefix(a.Value, "#") {
+				refs[a.Value[1:]] = true
+			}
+			for _, m := range urlRef.FindAllStringSubmatch(a.Value, -1) {
+				refs[m[1]] = true
+			}
+		}
+		for _, c := range e.Children {
+			visit(c)
+		}
+	}
+	visit(root)
+	return refs
+}
+
+// innerText returns the character data directly inside e.
+func innerText(e *Element) string {
+	var s strings.Builder
+	for _, c := range e.Children {
+		if c.Name == "" {
+			s.WriteString(c.Text)
+		}
+	}
+	return s.String()
+}
+
+// hasElements reports whether e has child elements.
+func hasElements(e *Element) bool {
+	return slices.ContainsFunc(e.Children, func(c *Element) bool { return c.Name != "" })
+}
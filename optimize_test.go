@@ -0,0 +1,68 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestAddPass(t *testing.T) {
+	backend := NewBackend()
+	backend.AddPass(OptimizerFunc(func(root *Element) error {
+		root.Walk(func(e *Element) bool {
+			if e.Name == "rect" {
+				e.SetAttr("data-seen", "yes")
+				e.RemoveAttr("stroke")
+			}
+			return true
+		})
+		return nil
+	}))
+	backend.AddPass(RoundNumbers(1))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(1.234, 5.678, 10, 10), recording.NewSolidBrush(gg.Red))
+
+	out := backend.String()
+	for _, want := range []string{`x="1.2" y="5.7"`, `data-seen="yes"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output should contain %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `stroke="none"`) {
+		t.Errorf("Removed attributes should not be written:\n%s", out)
+	}
+}
+
+func TestAddPassError(t *testing.T) {
+	errPass := errors.New("pass failed")
+	backend := NewBackend()
+	backend.AddPass(OptimizerFunc(func(*Element) error { return errPass }))
+	_ = backend.Begin(10, 10)
+	if _, err := backend.MarshalText(); !errors.Is(err, errPass) {
+		t.Errorf("Expected the pass error, got %v", err)
+	}
+}
+
+func TestPruneDefs(t *testing.T) {
+	backend := NewBackend()
+	backend.AddPass(PruneDefs())
+	_ = backend.Begin(100, 100)
+	clip := gg.NewPath()
+	clip.Rectangle(0, 0, 10, 10)
+	backend.SetClip(clip, recording.FillRuleNonZero)
+	backend.ClearClip()
+
+	out := backend.String()
+	if strings.Contains(out, "<defs") || strings.Contains(out, "clipPath") {
+		t.Errorf("Unused definitions should be pruned:\n%s", out)
+	}
+
+	backend.SetClip(clip, recording.FillRuleNonZero)
+	backend.FillRect(recording.NewRect(0, 0, 20, 20), recording.NewSolidBrush(gg.Red))
+	if out := backend.String(); !strings.Contains(out, "<clipPath") {
+		t.Errorf("Referenced definitions should be kept:\n%s", out)
+	}
+}
@@ -0,0 +1,238 @@
+package svg
+
+import (
+	"time"
+
+	"github.com/gogpu/gg"
+)
+
+// Option configures a Backend. Options are passed to NewBackend and
+// persist across Begin calls.
+type Option func(*Backend)
+
+// settings holds the output settings made by options. It is comparable,
+// so Cache can key exports on it. Options that take functions or lists
+// keep them in Backend instead; Cache.Render does not cache exports with
+// function-valued options, and adds the registered namespaces to its key.
+type settings struct {
+	idPrefix string
+
+	// Color output
+	colorFormat   ColorFormat
+	colorRounding ColorRounding
+	hexAlpha      bool
+
+	// Opacity
+	opacityDigits  int
+	alphaInvisible float64
+	alphaOpaque    float64
+
+	// Text
+	wrapWidth       float64
+	baseline        Baseline
+	defaultFontSize float64
+	textLength      bool
+
+	// Document
+	profile          Profile
+	version          SVGVersion
+	omitDeclaration  bool
+	standalone       string // "yes", "no" or "" for no declaration
+	doctype          string
+	namespaces       Namespaces
+	structure        Structure
+	styleMode        StyleMode
+	overflow         Overflow
+	responsive       bool
+	aspectRatio      AspectRatio
+	imageAspectRatio AspectRatio
+	background       gg.RGBA
+	hasBackground    bool
+	hardClip         bool
+	sourceMap        bool
+	provenance       bool
+
+	// Geometry
+	dashUnits         DashUnits
+	rotation          int
+	mirrorX           bool
+	mirrorY           bool
+	unmirrorText      bool
+	cornerRadius      float64
+	winding           Winding
+	sketchRoughness   float64
+	sketchSeed        uint64
+	weldTolerance     float64
+	weldClose         bool
+	simplifyTolerance float64
+	flattenTransforms bool
+	culling           bool
+	mergePaths        bool
+	toolpathOrdering  bool
+
+	// Print and cutting
+	autoCrop    bool
+	cropPadding float64
+	cropResize  bool
+	cropMarks   bool
+	bleed       float64
+	cutDistance float64
+
+	// Animation
+	drawOnDuration   time.Duration
+	drawOnSequential bool
+
+	// Scripts and untrusted content
+	scriptsEnabled bool
+	noScript       bool
+	untrusted      bool
+	sanitizeRaw    bool
+
+	// Failure handling and limits
+	strict      bool
+	validate    bool
+	maxBytes    int
+	maxElements int
+
+	// Memory management
+	trimThreshold int
+	sizeHint      int
+}
+
+// WithHardClipToCanvas clips all content to the canvas rectangle.
+//
+// Browsers show geometry that extends past the viewBox when an SVG is
+// opened directly, unlike the raster backends which discard it. With hard
+// clipping enabled, the document content is wrapped in a group clipped to
+// the canvas so the output matches the raster result.
+func WithHardClipToCanvas(enabled bool) Option {
+	return func(b *Backend) {
+		b.hardClip = enabled
+	}
+}
+
+// Overflow controls the overflow attribute of the root <svg> element.
+type Overflow int
+
+const (
+	// OverflowDefault emits no overflow attribute and leaves the behavior
+	// to the viewer (hidden for standalone documents, but frequently
+	// visible when the SVG is inlined into HTML).
+	OverflowDefault Overflow = iota
+	// OverflowVisible lets content outside the viewBox show.
+	OverflowVisible
+	// OverflowHidden hides content outside the viewBox.
+	OverflowHidden
+)
+
+// String returns the SVG attribute value for the overflow policy.
+func (o Overflow) String() string {
+	switch o {
+	case OverflowVisible:
+		return "visible"
+	case OverflowHidden:
+		return "hidden"
+	default:
+		return "auto"
+	}
+}
+
+// WithOverflow sets an explicit overflow policy on the root <svg> element,
+// so exports behave the same whether opened directly or embedded in a
+// constrained HTML layout.
+//
+// The root <svg> is the only viewport-establishing element the backend
+// emits; SVG ignores overflow on <g> elements, so Save/Restore groups are
+// not affected. Use WithHardClipToCanvas to clip content geometrically.
+func WithOverflow(o Overflow) Option {
+	return func(b *Backend) {
+		b.overflow = o
+	}
+}
+
+// AspectRatio is a preserveAspectRatio value: an alignment such as
+// "xMinYMid", optionally followed by "meet" or "slice", or "none".
+type AspectRatio string
+
+const (
+	// AspectNone scales content non-uniformly to fill the viewport.
+	AspectNone AspectRatio = "none"
+	// AspectMeet scales content uniformly to fit inside the viewport,
+	// centered. This is the SVG default.
+	AspectMeet AspectRatio = "xMidYMid meet"
+	// AspectSlice scales content uniformly to cover the viewport,
+	// centered, cropping what overflows.
+	AspectSlice AspectRatio = "xMidYMid slice"
+)
+
+// WithAspectRatio sets preserveAspectRatio on the root <svg> element,
+// which controls how the viewBox is fitted when the document is displayed
+// at a size with a different aspect ratio. An empty value omits the
+// attribute.
+func WithAspectRatio(a AspectRatio) Option {
+	return func(b *Backend) {
+		b.aspectRatio = a
+	}
+}
+
+// WithImageAspectRatio sets preserveAspectRatio on <image> elements. The
+// default, AspectNone, stretches each image to its destination rectangle
+// like the raster backends do; AspectMeet and AspectSlice keep the
+// image's proportions when the rectangle's differ.
+func WithImageAspectRatio(a AspectRatio) Option {
+	return func(b *Backend) {
+		b.imageAspectRatio = a
+	}
+}
+
+// WithResponsive omits the width and height attributes of the root <svg>
+// element, keeping only the viewBox, so the document scales to its
+// container when embedded in HTML. Standalone viewers fall back to their
+// default size.
+func WithResponsive(enabled bool) Option {
+	return func(b *Backend) {
+		b.responsive = enabled
+	}
+}
+
+// WithIDPrefix prepends prefix to every ID the backend generates (clip
+// paths, gradients, layers and groups), so several exports can be inlined
+// into one HTML page without their references colliding. IDs set with
+// SetNextAttrs are not changed. The prefix must be valid at the start of
+// an XML name, e.g. "chart1-".
+//
+// Generated IDs are deterministic: identical drawing calls produce
+// identical IDs, with or without a prefix.
+func WithIDPrefix(prefix string) Option {
+	return func(b *Backend) {
+		b.idPrefix = prefix
+	}
+}
+
+// DashUnits selects how recorded dash patterns are interpreted.
+type DashUnits int
+
+const (
+	// DashUserUnits writes dash lengths and offsets unchanged, as user
+	// units. This is the default and matches gg's raster backend.
+	DashUserUnits DashUnits = iota
+	// DashStrokeWidths treats dash lengths and offsets as multiples of the
+	// stroke width, as in drawing APIs where a dash pattern of {3, 1}
+	// scales with the line. They are multiplied by the written width.
+	DashStrokeWidths
+)
+
+// String returns the name of the dash units.
+func (u DashUnits) String() string {
+	if u == DashStrokeWidths {
+		return "stroke-widths"
+	}
+	return "user-units"
+}
+
+// WithDashUnits selects how recorded dash patterns are interpreted.
+func WithDashUnits(u DashUnits) Option {
+	return func(b *Backend) {
+		b.dashUnits = u
+	}
+}
@@ -0,0 +1,164 @@
+package svg
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithHardClipToCanvas(t *testing.T) {
+	backend := NewBackend(WithHardClipToCanvas(true))
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 1, G: 0, B: 0, A: 1})
+	backend.FillRect(recording.NewRect(-50, -50, 600, 600), brush)
+	backend.Save()
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<clipPath id="canvas-clip"><rect width="400" height="300"/></clipPath>`) {
+		t.Error("Output should define the canvas clip path")
+	}
+	if !strings.Contains(svg, `<g clip-path="url(#canvas-clip)"><rect`) {
+		t.Error("Content should be wrapped in the canvas clip group")
+	}
+	if !strings.Contains(svg, "</g></g>\n</svg>") {
+		t.Error("Canvas clip group should close after unclosed groups")
+	}
+}
+
+func TestHardClipDisabledByDefault(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(400, 300)
+
+	if strings.Contains(writeSVG(t, backend), canvasClipID) {
+		t.Error("Canvas clip should not be emitted by default")
+	}
+}
+
+func TestWithOverflow(t *testing.T) {
+	tests := []struct {
+		overflow Overflow
+		expected string
+	}{
+		{OverflowVisible, `overflow="visible"`},
+		{OverflowHidden, `overflow="hidden"`},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(WithOverflow(tt.overflow))
+		_ = backend.Begin(100, 100)
+
+		svg := writeSVG(t, backend)
+		if !strings.Contains(svg, `viewBox="0 0 100 100" `+tt.expected+`>`) {
+			t.Errorf("Root element should contain %s", tt.expected)
+		}
+	}
+
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	if strings.Contains(writeSVG(t, backend), "overflow=") {
+		t.Error("Default overflow should not emit an attribute")
+	}
+}
+
+func TestWithResponsive(t *testing.T) {
+	backend := NewBackend(WithResponsive(true))
+	_ = backend.Begin(120, 80)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 120 80">`) {
+		t.Errorf("Root element should only carry a viewBox, got:\n%s", svg)
+	}
+	if strings.Contains(svg, `width="120"`) {
+		t.Error("Responsive output should not have a fixed width")
+	}
+}
+
+func TestWithAspectRatio(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	draw := func(opts ...Option) string {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 100)
+		backend.DrawImage(img, recording.Rect{MaxX: 4, MaxY: 2}, recording.Rect{MaxX: 50, MaxY: 50}, recording.DefaultImageOptions())
+		return writeSVG(t, backend)
+	}
+
+	svg := draw()
+	if !strings.Contains(svg, `preserveAspectRatio="none"/>`) || strings.Count(svg, "preserveAspectRatio") != 1 {
+		t.Errorf("Images should stretch by default and the root should have no attribute, got:\n%s", svg)
+	}
+
+	svg = draw(WithAspectRatio(AspectSlice), WithImageAspectRatio("xMinYMin meet"))
+	expected := []string{`viewBox="0 0 100 100" preserveAspectRatio="xMidYMid slice"`, `preserveAspectRatio="xMinYMin meet"/>`}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
+
+func TestWithIDPrefix(t *testing.T) {
+	export := func(opts ...Option) string {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 100)
+		clip := gg.NewPath()
+		clip.Rectangle(0, 0, 50, 50)
+		backend.SetClip(clip, recording.FillRuleNonZero)
+		grad := recording.NewLinearGradientBrush(0, 0, 100, 0).
+			AddColorStop(0, gg.Red).
+			AddColorStop(1, gg.Blue)
+		backend.FillRect(recording.NewRect(0, 0, 100, 100), grad)
+		return writeSVG(t, backend)
+	}
+
+	if export() != export() {
+		t.Error("Identical inputs should produce identical output")
+	}
+
+	svg := export(WithIDPrefix("chart1-"), WithHardClipToCanvas(true))
+	expected := []string{
+		`<clipPath id="chart1-clip1">`,
+		`<linearGradient id="chart1-lg2"`,
+		`fill="url(#chart1-lg2)"`,
+		`clip-path="url(#chart1-clip1)"`,
+		`<clipPath id="chart1-canvas-clip">`,
+		`<g clip-path="url(#chart1-canvas-clip)">`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+
+	backend := NewBackend(WithIDPrefix("p-"), WithStructure(StructureByOp))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `<g id="p-fills">`) {
+		t.Errorf("Layer groups should be prefixed, got:\n%s", svg)
+	}
+}
+
+func TestWithDashUnits(t *testing.T) {
+	draw := func(opts ...Option) string {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 100)
+		stroke := recording.DefaultStroke()
+		stroke.Width = 2
+		stroke.DashPattern = []float64{3, 1}
+		stroke.DashOffset = 1
+		backend.StrokePath(line(0, 0, 100, 0), recording.NewSolidBrush(gg.Black), stroke)
+		return writeSVG(t, backend)
+	}
+
+	if svg := draw(); !strings.Contains(svg, `stroke-dasharray="3 1" stroke-dashoffset="1"`) {
+		t.Errorf("Dashes should be written in user units by default, got:\n%s", svg)
+	}
+	if svg := draw(WithDashUnits(DashStrokeWidths)); !strings.Contains(svg, `stroke-dasharray="6 2" stroke-dashoffset="2"`) {
+		t.Errorf("Dashes should be scaled by the stroke width, got:\n%s", svg)
+	}
+}
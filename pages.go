@@ -0,0 +1,199 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+	"github.com/gogpu/gg/recording"
+)
+
+// sodipodiNS is the namespace of Inkscape's document settings.
+const sodipodiNS = "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"
+
+// pageGap is the space between pages in Inkscape multi-page documents.
+const pageGap = 20
+
+// PageLayout selects how WritePages combines pages into one document.
+type PageLayout int
+
+const (
+	// PageStack places every page at the origin in a group with the id
+	// "pageN". Only the first page is displayed; viewers and scripts flip
+	// pages by toggling the groups' display attribute.
+	PageStack PageLayout = iota
+	// PageInkscape lays the pages out side by side with Inkscape 1.2
+	// multi-page markup, so the document opens as a multi-page document
+	// and each page exports to its own file or PDF page.
+	PageInkscape
+)
+
+// String returns the name of the layout.
+func (l PageLayout) String() string {
+	if l == PageInkscape {
+		return "inkscape"
+	}
+	return "stack"
+}
+
+// SavePages exports each recording to its own file. The file names are
+// formatted from pattern and the page number, starting at 1, as in
+// "report-%02d.svg". It returns the names of the files written.
+func SavePages(pattern string, pages []*recording.Recording, opts ...Option) ([]string, error) {
+	if !strings.Contains(pattern, "%") {
+		return nil, fmt.Errorf("svg: page file pattern %q has no page number verb", pattern)
+	}
+	var names []string
+	for i, r := range pages {
+		name := fmt.Sprintf(pattern, i+1)
+		b := NewBackend(opts...)
+		if err := b.Playback(r); err != nil {
+			return names, fmt.Errorf("svg: page %d: %w", i+1, err)
+		}
+		if err := b.SaveToFile(name); err != nil {
+			return names, fmt.Errorf("svg: page %d: %w", i+1, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// WritePages exports the recordings as the pages of one document, laid
+// out as layout selects. Each page is exported with a Backend configured
+// with opts and nested in its own <svg> element, so pages keep their
+// viewBox and are clipped to their canvas; generated IDs get a "pN-"
+// prefix so pages do not collide.
+func WritePages(w io.Writer, layout PageLayout, pages []*recording.Recording, opts ...Option) (int64, error) {
+	if len(pages) == 0 {
+		return 0, errors.New("svg: no pages to write")
+	}
+	var prolog []byte
+	root := &Element{Name: "svg"}
+	var namedView *Element
+	if layout == PageInkscape {
+		namedView = &Element{Name: "sodipodi:namedview", Attrs: []Attr{{Name: "id", Value: "namedview"}}}
+		root.Children = append(root.Children, &Element{Text: "\n"}, namedView)
+	}
+
+	var width, height float64
+	for i, r := range pages {
+		p, page, err := exportElementTree(r, opts, fmt.Sprintf("p%d-", i+1))
+		if err != nil {
+			return 0, fmt.Errorf("svg: page %d: %w", i+1, err)
+		}
+		if i == 0 {
+			prolog = p
+		}
+		pw, ph := pageSize(page, r)
+
+		group := &Element{Name: "g", Attrs: []Attr{{Name: "id", Value: "page" + strconv.Itoa(i+1)}}}
+		var x float64
+		switch layout {
+		case PageInkscape:
+			x = width
+			if i > 0 {
+				x += pageGap
+			}
+			group.SetAttr("inkscape:groupmode", "layer")
+			group.SetAttr("inkscape:label", "Page "+strconv.Itoa(i+1))
+			namedView.Children = append(namedView.Children, &Element{Name: "inkscape:page", Attrs: []Attr{
+				{Name: "id", Value: "page-" + strconv.Itoa(i+1)},
+				{Name: "x", Value: svgwriter.FormatNumber(x, -1)},
+				{Name: "y", Value: "0"},
+				{Name: "width", Value: svgwriter.FormatNumber(pw, -1)},
+				{Name: "height", Value: svgwriter.FormatNumber(ph, -1)},
+			}})
+			width = x + pw
+		default:
+			if i > 0 {
+				group.SetAttr("display", "none")
+			}
+			width = max(width, pw)
+		}
+		height = max(height, ph)
+
+		nestDocument(root, page)
+		if x != 0 {
+			page.SetAttr("x", svgwriter.FormatNumber(x, -1))
+		}
+		group.Children = []*Element{page}
+		root.Children = append(root.Children, &Element{Text: "\n"}, group)
+	}
+	if layout == PageInkscape {
+		root.SetAttr("xmlns:inkscape", inkscapeNS)
+		root.SetAttr("xmlns:sodipodi", sodipodiNS)
+	}
+	return writeNested(w, prolog, root, width, height)
+}
+
+// writeNested writes a document assembled from nested exports, sizing
+// root to width×height.
+func writeNested(w io.Writer, prolog []byte, root *Element, width, height float64) (int64, error) {
+	root.Children = append(root.Children, &Element{Text: "\n"})
+	root.SetAttr("width", svgwriter.FormatNumber(width, -1))
+	root.SetAttr("height", svgwriter.FormatNumber(height, -1))
+	root.SetAttr("viewBox", "0 0 "+svgwriter.FormatNumber(width, -1)+" "+svgwriter.FormatNumber(height, -1))
+
+	var buf bytes.Buffer
+	buf.Write(prolog)
+	out := svgwriter.New(&buf)
+	writeElementTree(out, root)
+	if err := out.Err(); err != nil {
+		return 0, err
+	}
+	buf.WriteString("\n")
+	return buf.WriteTo(w)
+}
+
+// exportElementTree exports r with a Backend configured with opts and
+// parses the document. prefix is appended to the ID prefix, so the IDs
+// and style classes of several exports can share one document.
+func exportElementTree(r *recording.Recording, opts []Option, prefix string) ([]byte, *Element, error) {
+	b := NewBackend(opts...)
+	b.idPrefix += prefix
+	if err := b.Playback(r); err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		return nil, nil, err
+	}
+	prolog, root, _, err := parseElementTree(buf.Bytes())
+	return prolog, root, err
+}
+
+// pageSize returns the size of an exported page, falling back to the
+// recording's canvas if the root element has no absolute size.
+func pageSize(root *Element, r *recording.Recording) (float64, float64) {
+	w, h := float64(r.Width()), float64(r.Height())
+	if v, ok := root.Attr("width"); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			w = f
+		}
+	}
+	if v, ok := root.Attr("height"); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			h = f
+		}
+	}
+	return w, h
+}
+
+// nestDocument prepares the root element of an exported document for
+// nesting in root, moving its namespace declarations up to root.
+func nestDocument(root, doc *Element) {
+	kept := doc.Attrs[:0]
+	for _, a := range doc.Attrs {
+		switch {
+		case a.Name == "xmlns" || strings.HasPrefix(a.Name, "xmlns:"):
+			root.SetAttr(a.Name, a.Value)
+		case a.Name != "version":
+			kept = append(kept, a)
+		}
+	}
+	doc.Attrs = kept
+}
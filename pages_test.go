@@ -0,0 +1,82 @@
+package svg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func testPages() []*recording.Recording {
+	var pages []*recording.Recording
+	for _, size := range []int{100, 80} {
+		rec := recording.NewRecorder(size, size)
+		rec.SetFillRGBA(1, 0, 0, 1)
+		rec.DrawRectangle(10, 10, 20, 20)
+		rec.Clip()
+		rec.DrawRectangle(0, 0, 50, 50)
+		rec.Fill()
+		pages = append(pages, rec.FinishRecording())
+	}
+	return pages
+}
+
+func TestSavePages(t *testing.T) {
+	dir := t.TempDir()
+	names, err := SavePages(filepath.Join(dir, "page-%02d.svg"), testPages())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || filepath.Base(names[1]) != "page-02.svg" {
+		t.Fatalf("names = %v", names)
+	}
+	data, err := os.ReadFile(names[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `width="80"`) {
+		t.Errorf("second page should be 80 wide:\n%s", data)
+	}
+	if _, err := SavePages(filepath.Join(dir, "page.svg"), testPages()); err == nil {
+		t.Error("pattern without a verb should be rejected")
+	}
+}
+
+func TestWritePagesStack(t *testing.T) {
+	var s strings.Builder
+	if _, err := WritePages(&s, PageStack, testPages()); err != nil {
+		t.Fatal(err)
+	}
+	out := s.String()
+	for _, want := range []string{
+		`width="100" height="100" viewBox="0 0 100 100"`,
+		`<g id="page1"><svg width="100"`,
+		`<g id="page2" display="none"><svg width="80"`,
+		`url(#p1-clip`, `url(#p2-clip`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestWritePagesInkscape(t *testing.T) {
+	var s strings.Builder
+	if _, err := WritePages(&s, PageInkscape, testPages()); err != nil {
+		t.Fatal(err)
+	}
+	out := s.String()
+	for _, want := range []string{
+		`xmlns:sodipodi=`,
+		`width="200" height="100"`,
+		`<inkscape:page id="page-1" x="0" y="0" width="100" height="100"/>`,
+		`<inkscape:page id="page-2" x="120" y="0" width="80" height="80"/>`,
+		`inkscape:label="Page 2"><svg width="80" height="80" viewBox="0 0 80 80" x="120"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gogpu/gg"
+)
+
+// parsePathData parses the d attribute of an SVG path. Arcs are converted
+// to cubic curves. As SVG renderers do, it returns the path up to the
+// first error along with the error.
+func parsePathData(d string) (*gg.Path, error) {
+	path := gg.NewPath()
+	s := pathScanner{s: d}
+	var cmd byte
+	var start, current, lastControl gg.Point
+	var lastCmd byte
+	for {
+		s.skipSpace()
+		if s.done() {
+			return path, nil
+		}
+		if c := s.s[s.i]; isPathCommand(c) {
+			cmd = c
+			s.i++
+		} else if cmd == 0 {
+			return path, fmt.Errorf("path data must start with a command, found %q", c)
+		} else if cmd == 'M' || cmd == 'm' {
+			// Coordinates after a moveto are implicit linetos: M→L, m→l.
+			cmd--
+		} else if cmd == 'Z' || cmd == 'z' {
+			return path, fmt.Errorf("unexpected number after closepath at offset %d", s.i)
+		}
+
+		rel := cmd >= 'a'
+		offset := func(p gg.Point) gg.Point {
+			if rel {
+				return p.Add(current)
+			}
+			return p
+		}
+		upper := cmd &^ 0x20
+		var err error
+		switch upper {
+		case 'Z':
+			path.Close()
+			current = start
+		case 'M':
+			var p gg.Point
+			if p, err = s.point(); err == nil {
+				current = offset(p)
+				start = current
+				path.MoveTo(current.X, current.Y)
+			}
+		case 'L':
+			var p gg.Point
+			if p, err = s.point(); err == nil {
+				current = offset(p)
+				path.LineTo(current.X, current.Y)
+			}
+		case 'H', 'V':
+			var v float64
+			if v, err = s.number(); err == nil {
+				switch {
+				case upper == 'H' && rel:
+					current.X += v
+				case upper == 'H':
+					current.X = v
+				case rel:
+					current.Y += v
+				default:
+					current.Y = v
+				}
+				path.LineTo(current.X, current.Y)
+			}
+		case 'C', 'S':
+			var c1, c2, p gg.Point
+			if upper == 'C' {
+				if c1, err = s.point(); err == nil {
+					c1 = offset(c1)
+				}
+			} else {
+				// The first control point reflects the previous curve's.
+				c1 = current
+				if l := lastCmd &^ 0x20; l == 'C' || l == 'S' {
+					c1 = current.Mul(2).Sub(lastControl)
+				}
+			}
+			if err == nil {
+				c2, err = s.point()
+			}
+			if err == nil {
+				p, err = s.point()
+			}
+			if err == nil {
+				c2, p = offset(c2), offset(p)
+				path.CubicTo(c1.X, c1.Y, c2.X, c2.Y, p.X, p.Y)
+				current, lastControl = p, c2
+			}
+		case 'Q', 'T':
+			var c, p gg.Point
+			if upper == 'Q' {
+				if c, err = s.point(); err == nil {
+					c = offset(c)
+				}
+			} else {
+				c = current
+				if l := lastCmd &^ 0x20; l == 'Q' || l == 'T' {
+					c = current.Mul(2).Sub(lastControl)
+				}
+			}
+			if err == nil {
+				p, err = s.point()
+			}
+			if err == nil {
+				p = offset(p)
+				path.QuadraticTo(c.X, c.Y, p.X, p.Y)
+				current, lastControl = p, c
+			}
+		case 'A':
+			var rx, ry, angle float64
+			var large, sweep bool
+			var p gg.Point
+			if rx, err = s.number(); err == nil {
+				ry, err = s.number()
+			}
+			if err == nil {
+				angle, err = s.number()
+			}
+			if err == nil {
+				large, err = s.flag()
+			}
+			if err == nil {
+				sweep, err = s.flag()
+			}
+			if err == nil {
+				p, err = s.point()
+			}
+			if err == nil {
+				p = offset(p)
+				appendArc(path, current, p, rx, ry, angle*math.Pi/180, large, sweep)
+				current = p
+			}
+		}
+		if err != nil {
+			return path, err
+		}
+		lastCmd = cmd
+	}
+}
+
+func isPathCommand(c byte) bool {
+	switch c &^ 0x20 {
+	case 'M', 'L', 'H', 'V', 'C', 'S', 'Q', 'T', 'A', 'Z':
+		return true
+	}
+	return false
+}
+
+// pathScanner reads numbers from path data and other number lists.
+type pathScanner struct {
+	s string
+	i int
+}
+
+func (s *pathScanner) done() bool {
+	return s.i >= len(s.s)
+}
+
+// skipSpace skips whitespace and at most one comma.
+func (s *pathScanner) skipSpace() {
+	comma := false
+	for !s.done() {
+		switch c := s.s[s.i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+		case c == ',' && !comma:
+			comma = true
+		default:
+			return
+		}
+		s.i++
+	}
+}
+
+// number reads a number.
+func (s *pathScanner) number() (float64, error) {
+	s.skipSpace()
+	start := s.i
+	if !s.done() && (s.s[s.i] == '+' || s.s[s.i] == '-') {
+		s.i++
+	}
+	digits := s.digits()
+	if !s.done() && s.s[s.i] == '.' {
+		s.i++
+		digits += s.digits()
+	}
+	if digits == 0 {
+		s.i = start
+		return 0, fmt.Errorf("expected a number at offset %d", start)
+	}
+	if !s.done() && (s.s[s.i] == 'e' || s.s[s.i] == 'E') {
+		mark := s.i
+		s.i++
+		if !s.done() && (s.s[s.i] == '+' || s.s[s.i] == '-') {
+			s.i++
+		}
+		if s.digits() == 0 {
+			// An "e" not followed by an exponent belongs to the next token.
+			s.i = mark
+		}
+	}
+	return strconv.ParseFloat(s.s[start:s.i], 64)
+}
+
+func (s *pathScanner) digits() int {
+	n := 0
+	for !s.done() && s.s[s.i] >= '0' && s.s[s.i] <= '9' {
+		s.i++
+		n++
+	}
+	return n
+}
+
+// flag reads an arc flag, which need not be separated from what follows.
+func (s *pathScanner) flag() (bool, error) {
+	s.skipSpace()
+	if !s.done() && (s.s[s.i] == '0' || s.s[s.i] == '1') {
+		s.i++
+		return s.s[s.i-1] == '1', nil
+	}
+	return false, fmt.Errorf("expected an arc flag at offset %d", s.i)
+}
+
+func (s *pathScanner) point() (gg.Point, error) {
+	x, err := s.number()
+	if err != nil {
+		return gg.Point{}, err
+	}
+	y, err := s.number()
+	return gg.Pt(x, y), err
+}
+
+// parseNumberList parses a list of numbers separated by whitespace or
+// commas, such as a points or viewBox attribute, stopping at the first
+// invalid number.
+func parseNumberList(v string) []float64 {
+	s := pathScanner{s: v}
+	var values []float64
+	for {
+		n, err := s.number()
+		if err != nil {
+			return values
+		}
+		values = append(values, n)
+	}
+}
+
+// appendArc appends an elliptical arc from p0 to p1 as cubic curves,
+// following the endpoint parameterization of SVG 1.1 appendix F.6.
+func appendArc(path *gg.Path, p0, p1 gg.Point, rx, ry, phi float64, large, sweep bool) {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if p0 == p1 {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		path.LineTo(p1.X, p1.Y)
+		return
+	}
+
+	sin, cos := math.Sincos(phi)
+	dx, dy := (p0.X-p1.X)/2, (p0.Y-p1.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// Scale radii up that are too small to reach the end point.
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		k = -k
+	}
+	cx1, cy1 := k*rx*y1/ry, -k*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (p0.X+p1.X)/2
+	cy := sin*cx1 + cos*cy1 + (p0.Y+p1.Y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// Approximate each quarter turn or less with one cubic.
+	n := max(1, int(math.Ceil(math.Abs(delta)/(math.Pi/2))))
+	step := delta / float64(n)
+	t := 4.0 / 3 * math.Tan(step/4)
+	at := func(a float64) (gg.Point, gg.Point) {
+		s, c := math.Sincos(a)
+		p := gg.Pt(cx+rx*c*cos-ry*s*sin, cy+rx*c*sin+ry*s*cos)
+		d := gg.Pt(-rx*s*cos-ry*c*sin, -rx*s*sin+ry*c*cos)
+		return p, d
+	}
+	from, fromD := at(theta)
+	for i := 1; i <= n; i++ {
+		to, toD := at(theta + step*float64(i))
+		if i == n {
+			to = p1
+		}
+		c1, c2 := from.Add(fromD.Mul(t)), to.Sub(toD.Mul(t))
+		path.CubicTo(c1.X, c1.Y, c2.X, c2.Y, to.X, to.Y)
+		from, fromD = to, toD
+	}
+}
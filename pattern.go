@@ -0,0 +1,109 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// PatternBrush paints with a pattern defined by DefinePatternFromRecording.
+// The embedded SolidBrush is the fallback color, used when the pattern
+// is not defined and by backends that don't know about patterns.
+type PatternBrush struct {
+	recording.SolidBrush
+	// Name is the name the pattern was defined with.
+	Name string
+}
+
+// Pattern returns a brush that paints with the named pattern, falling back
+// to the given color.
+func Pattern(name string, fallback gg.RGBA) PatternBrush {
+	return PatternBrush{SolidBrush: recording.NewSolidBrush(fallback), Name: name}
+}
+
+// DefinePatternFromRecording renders tile into a <pattern> definition of
+// size w by h, repeated in user space from the origin. Fills, strokes and
+// text painted with Pattern(name, ...) use it, so hatches, textures and
+// repeated motifs can be authored with gg itself. Pass a PatternBrush
+// directly to the drawing methods, record it with SetFillStyle, or swap
+// it in with RemapBrush.
+//
+// The tile is rendered with this backend's color output options. Like
+// theme colors, patterns are not reset by Begin; defining a name again
+// replaces its pattern.
+func (b *Backend) DefinePatternFromRecording(name string, tile *recording.Recording, w, h float64) error {
+	if tile == nil {
+		return errors.New("svg: nil pattern tile")
+	}
+	id := b.patternID(name)
+
+	sub := NewBackend(WithIDPrefix(id+"-"), WithStrict(true))
+	sub.colorFormat = b.colorFormat
+	sub.colorRounding = b.colorRounding
+	sub.hexAlpha = b.hexAlpha
+	sub.profile = b.profile
+	if err := sub.Playback(tile); err != nil {
+		return fmt.Errorf("svg: pattern %q: %w", name, err)
+	}
+
+	var def strings.Builder
+	def.Write(sub.defs.Bytes())
+	def.WriteString(fmt.Sprintf(`<pattern id="%s" patternUnits="userSpaceOnUse" width="%g" height="%g">`, id, w, h))
+	def.Write(sub.builder.Bytes())
+	for i := len(sub.containers) - 1; i >= 0; i-- {
+		def.WriteString(sub.containers[i].kind.endTag())
+	}
+	def.WriteString("</pattern>")
+
+	if b.patterns == nil {
+		b.patterns = make(map[string]string)
+	}
+	if _, ok := b.patterns[id]; !ok {
+		b.patternOrder = append(b.patternOrder, id)
+	}
+	b.patterns[id] = def.String()
+	return nil
+}
+
+// patternID returns the element ID of the named pattern.
+func (b *Backend) patternID(name string) string {
+	return b.idPrefix + "pattern-" + strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// patternFallback replaces a pattern brush by its fallback color if the
+// pattern is undefined or unsupported by the profile.
+func (b *Backend) patternFallback(brush recording.Brush) recording.Brush {
+	br, ok := brush.(PatternBrush)
+	if !ok {
+		return brush
+	}
+	switch {
+	case b.tiny():
+		b.fail(ErrUnsupportedFeature, errors.New("patterns"))
+	case b.patterns[b.patternID(br.Name)] == "":
+		b.fail(ErrUnsupportedBrush, fmt.Errorf("pattern %q is not defined", br.Name))
+	default:
+		return brush
+	}
+	return br.SolidBrush
+}
+
+// patternDefs returns the definitions of all patterns.
+func (b *Backend) patternDefs() string {
+	var defs strings.Builder
+	if b.tiny() {
+		return ""
+	}
+	for _, id := range b.patternOrder {
+		defs.WriteString(b.patterns[id])
+	}
+	return defs.String()
+}
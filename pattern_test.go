@@ -0,0 +1,63 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func hatchTile() *recording.Recording {
+	rec := recording.NewRecorder(8, 8)
+	rec.SetFillRGBA(0, 0, 1, 1)
+	rec.DrawRectangle(0, 0, 4, 4)
+	rec.Fill()
+	return rec.FinishRecording()
+}
+
+func TestDefinePatternFromRecording(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.DefinePatternFromRecording("hatch", hatchTile(), 8, 8); err != nil {
+		t.Fatal(err)
+	}
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 50, 50), Pattern("hatch", gg.Red))
+	backend.StrokePath(line(0, 0, 10, 10), Pattern("hatch", gg.Red), recording.DefaultStroke())
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`<pattern id="pattern-hatch" patternUnits="userSpaceOnUse" width="8" height="8">`,
+		`fill="rgb(0,0,255)"`,
+		`</pattern>`,
+		`fill="url(#pattern-hatch)"`,
+		`stroke="url(#pattern-hatch)"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if strings.Index(svg, "</pattern>") > strings.Index(svg, `fill="url(#pattern-hatch)"`) {
+		t.Error("Pattern should be defined before use")
+	}
+}
+
+func TestPatternFallback(t *testing.T) {
+	backend := NewBackend(WithStrict(true))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 50, 50), Pattern("missing", gg.Red))
+
+	if err := backend.End(); !errors.Is(err, ErrUnsupportedBrush) {
+		t.Errorf("Undefined pattern should fail with ErrUnsupportedBrush, got %v", err)
+	}
+
+	backend = NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 50, 50), Pattern("missing", gg.Red))
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `fill="rgb(255,0,0)"`) {
+		t.Errorf("Undefined pattern should fall back to its color, got:\n%s", svg)
+	}
+}
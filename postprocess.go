@@ -0,0 +1,50 @@
+package svg
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// WithPostProcessor adds a function that transforms the serialized document
+// before WriteTo or SaveToFile completes, e.g. to run an external optimizer
+// or a custom sanitizer. The option may be given several times; processors
+// run in the order they were added, each receiving the previous output.
+//
+// When any processor is installed, the document is buffered in memory
+// before being written.
+func WithPostProcessor(fn func([]byte) ([]byte, error)) Option {
+	return func(b *Backend) {
+		if fn != nil {
+			b.postProcessors = append(b.postProcessors, fn)
+		}
+	}
+}
+
+// postProcess serializes the document and runs it through the optimizer
+// passes, the installed post-processors and, with WithNoScript or
+// WithUntrusted, the removal of unsafe content.
+func (b *Backend) postProcess() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := b.writeDocument(&buf); err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+	if len(b.passes) > 0 {
+		var err error
+		if data, err = b.optimize(data); err != nil {
+			return nil, err
+		}
+	}
+	for i, fn := range b.postProcessors {
+		out, err := fn(data)
+		if err != nil {
+			return nil, fmt.Errorf("svg: post-processor %d: %w", i, err)
+		}
+		data = out
+	}
+	if b.scriptless() {
+		return b.scrub(data)
+	}
+	return data, nil
+}
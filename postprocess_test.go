@@ -0,0 +1,54 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithPostProcessor(t *testing.T) {
+	var calls []string
+	backend := NewBackend(
+		WithPostProcessor(func(data []byte) ([]byte, error) {
+			calls = append(calls, "first")
+			return bytes.ReplaceAll(data, []byte("<svg "), []byte("<svg data-processed=\"1\" ")), nil
+		}),
+		WithPostProcessor(func(data []byte) ([]byte, error) {
+			calls = append(calls, "second")
+			if !bytes.Contains(data, []byte("data-processed")) {
+				t.Error("Second processor should see the first processor's output")
+			}
+			return data, nil
+		}),
+	)
+	if err := backend.Begin(100, 100); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `data-processed="1"`) {
+		t.Error("Output should contain the processed document")
+	}
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("Processors ran in wrong order: %v", calls)
+	}
+}
+
+func TestPostProcessorError(t *testing.T) {
+	errBoom := errors.New("boom")
+	backend := NewBackend(WithPostProcessor(func([]byte) ([]byte, error) {
+		return nil, errBoom
+	}))
+	_ = backend.Begin(100, 100)
+	_ = backend.End()
+
+	var buf bytes.Buffer
+	n, err := backend.WriteTo(&buf)
+	if !errors.Is(err, errBoom) {
+		t.Errorf("WriteTo should return the processor error, got %v", err)
+	}
+	if n != 0 || buf.Len() != 0 {
+		t.Error("Nothing should be written when a processor fails")
+	}
+}
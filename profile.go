@@ -0,0 +1,89 @@
+package svg
+
+// Profile selects the SVG language profile the output conforms to.
+type Profile int
+
+const (
+	// ProfileFull writes full SVG. This is the default.
+	ProfileFull Profile = iota
+	// ProfileTiny restricts output to SVG Tiny 1.2 for embedded devices
+	// and EPUB readers that only support Tiny.
+	ProfileTiny
+)
+
+// String returns the name of the profile.
+func (p Profile) String() string {
+	if p == ProfileTiny {
+		return "tiny"
+	}
+	return "full"
+}
+
+// WithProfile selects the SVG profile.
+//
+// With ProfileTiny the root element declares version="1.2" and
+// baseProfile="tiny", references use xlink:href, and constructs outside
+// Tiny 1.2 are left out:
+//   - clip paths, including WithHardClipToCanvas, so content is unclipped
+//   - gradient spreadMethod and focal points
+//   - textLength, and dominant-baseline for text drawn without a face
+//   - CSS: style modes and theme colors fall back to plain attributes
+//   - 8-digit hex colors; alpha stays in opacity attributes
+//   - text on a path, written as plain text at the start of the path
+//
+// Clips and text on a path are reported as ErrUnsupportedFeature.
+func WithProfile(p Profile) Option {
+	return func(b *Backend) {
+		b.profile = p
+	}
+}
+
+// tiny reports whether output is restricted to SVG Tiny 1.2.
+func (b *Backend) tiny() bool {
+	return b.profile == ProfileTiny
+}
+
+// hrefAttr returns the name of the attribute used for references.
+func (b *Backend) hrefAttr() string {
+	if !b.svg2() {
+		return "xlink:href"
+	}
+	return "href"
+}
+
+// SVGVersion selects the SVG version whose syntax the output uses.
+type SVGVersion int
+
+const (
+	// SVG2 writes SVG 2 syntax: plain href references and the fr
+	// focal radius on radial gradients. This is the default.
+	SVG2 SVGVersion = iota
+	// SVG11 writes SVG 1.1 syntax for older renderers and editors:
+	// references use xlink:href, and radial gradients with a start
+	// radius are approximated by rescaling their stop offsets.
+	SVG11
+)
+
+// String returns the version number.
+func (v SVGVersion) String() string {
+	if v == SVG11 {
+		return "1.1"
+	}
+	return "2.0"
+}
+
+// WithSVGVersion selects the SVG version, trading fidelity for compatibility.
+// ProfileTiny implies its own version and ignores this option.
+//
+// gg has no mesh or sweep brushes SVG 2 could express natively; sweep
+// gradients fall back to their first stop color in both versions.
+func WithSVGVersion(v SVGVersion) Option {
+	return func(b *Backend) {
+		b.version = v
+	}
+}
+
+// svg2 reports whether SVG 2 syntax may be written.
+func (b *Backend) svg2() bool {
+	return b.version == SVG2 && !b.tiny()
+}
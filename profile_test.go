@@ -0,0 +1,109 @@
+package svg
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// drawProfileSample draws constructs that differ between profiles.
+func drawProfileSample(b *Backend) {
+	_ = b.Begin(100, 100)
+	clip := gg.NewPath()
+	clip.Rectangle(0, 0, 50, 50)
+	b.SetClip(clip, recording.FillRuleNonZero)
+
+	grad := recording.NewRadialGradientBrush(50, 50, 0, 40)
+	grad.AddColorStop(0, gg.RGBA{R: 1, A: 1}).AddColorStop(1, gg.RGBA{B: 1, A: 0.5})
+	grad.Extend = recording.ExtendReflect
+	b.FillRect(recording.Rect{MaxX: 100, MaxY: 100}, grad)
+
+	translucent := gg.RGBA{G: 1, A: 0.5}
+	b.ThemeColor(translucent, "accent")
+	b.FillRect(recording.Rect{MaxX: 10, MaxY: 10}, recording.NewSolidBrush(translucent))
+	b.DrawImage(image.NewRGBA(image.Rect(0, 0, 1, 1)), recording.Rect{MaxX: 1, MaxY: 1}, recording.Rect{MaxX: 5, MaxY: 5}, recording.DefaultImageOptions())
+	b.DrawTextOnPath("curve", line(10, 90, 90, 90), 0, nil, recording.NewSolidBrush(gg.Black))
+}
+
+func TestWithProfileTiny(t *testing.T) {
+	backend := NewBackend(WithProfile(ProfileTiny), WithHardClipToCanvas(true),
+		WithStyleMode(StyleClasses), WithHexAlpha(true))
+	drawProfileSample(backend)
+	svg := writeSVG(t, backend)
+
+	expected := []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.2" baseProfile="tiny"`,
+		`<radialGradient id="rg1" gradientUnits="userSpaceOnUse" cx="50" cy="50" r="40">`,
+		`fill="rgb(0,255,0)" fill-opacity="0.5"`,
+		`xlink:href="data:image/png;base64,`,
+		`<text x="10" y="90" font-size="12" fill="rgb(0,0,0)">curve</text>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	for _, unexpected := range []string{"clip", "spreadMethod", "<style", "var(", "class=", "textPath", " href="} {
+		if strings.Contains(svg, unexpected) {
+			t.Errorf("Tiny output should not contain %s, got:\n%s", unexpected, svg)
+		}
+	}
+
+	var opErr *OpError
+	if !errors.As(backend.Err(), &opErr) || !errors.Is(opErr, ErrUnsupportedFeature) || opErr.Method != "SetClip" {
+		t.Errorf("Dropped clip should be reported, got %v", backend.Err())
+	}
+}
+
+func TestWithProfileFull(t *testing.T) {
+	backend := NewBackend()
+	drawProfileSample(backend)
+	svg := writeSVG(t, backend)
+
+	expected := []string{
+		`r="40" fx="50" fy="50" spreadMethod="reflect"><stop`,
+		`clip-path="url(#clip1)"`,
+		`<textPath href="#tp`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if strings.Contains(svg, "baseProfile") {
+		t.Error("Full output should not declare a profile")
+	}
+}
+
+func TestWithSVGVersion(t *testing.T) {
+	draw := func(backend *Backend) string {
+		_ = backend.Begin(100, 100)
+		grad := recording.NewRadialGradientBrush(50, 50, 10, 50).
+			AddColorStop(0, gg.Red).
+			AddColorStop(1, gg.Blue)
+		backend.FillRect(recording.NewRect(0, 0, 100, 100), grad)
+		backend.DrawTextOnPath("curve", line(0, 0, 100, 0), 0, nil, recording.NewSolidBrush(gg.Black))
+		return writeSVG(t, backend)
+	}
+
+	svg2 := draw(NewBackend())
+	for _, e := range []string{`r="50" fx="50" fy="50" fr="10">`, `<stop offset="0" `, `<textPath href="#tp`} {
+		if !strings.Contains(svg2, e) {
+			t.Errorf("SVG 2 output should contain %s, got:\n%s", e, svg2)
+		}
+	}
+
+	svg11 := draw(NewBackend(WithSVGVersion(SVG11)))
+	for _, e := range []string{` version="1.1"`, `<stop offset="0.2" `, `<textPath xlink:href="#tp`} {
+		if !strings.Contains(svg11, e) {
+			t.Errorf("SVG 1.1 output should contain %s, got:\n%s", e, svg11)
+		}
+	}
+	if strings.Contains(svg11, "fr=") {
+		t.Errorf("SVG 1.1 output should not use fr, got:\n%s", svg11)
+	}
+}
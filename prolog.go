@@ -0,0 +1,208 @@
+package svg
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// DoctypeSVG11 is the document type declaration of SVG 1.1, for
+// validators and CAD importers that require one.
+const DoctypeSVG11 = `<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd">`
+
+// Namespaces selects which namespace declarations the root element carries.
+type Namespaces int
+
+const (
+	// NamespacesAll declares the SVG and XLink namespaces. This is the
+	// default.
+	NamespacesAll Namespaces = iota
+	// NamespacesUsed declares the XLink namespace only if the document
+	// uses xlink:href.
+	NamespacesUsed
+	// NamespacesNone writes no namespace declarations, for SVG inlined in
+	// HTML, where the parser supplies them. Standalone files need them.
+	NamespacesNone
+)
+
+// String returns the name of the namespace mode.
+func (n Namespaces) String() string {
+	switch n {
+	case NamespacesUsed:
+		return "used"
+	case NamespacesNone:
+		return "none"
+	default:
+		return "all"
+	}
+}
+
+// Well-known namespaces for WithNamespace.
+const (
+	// InkscapeNamespace is the namespace of Inkscape's extension
+	// attributes, such as inkscape:label and inkscape:groupmode.
+	InkscapeNamespace = inkscapeNS
+	// SodipodiNamespace is the namespace of Inkscape's document settings,
+	// such as sodipodi:namedview and sodipodi:nodetypes.
+	SodipodiNamespace = sodipodiNS
+)
+
+// namespaceDecl is a namespace registered with WithNamespace.
+type namespaceDecl struct {
+	prefix, uri string
+}
+
+// isNCName reports whether s is an XML name without a colon, which
+// rules out quotes, whitespace and markup in namespace prefixes.
+func isNCName(s string) bool {
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc) ||
+			r == '-' || r == '.' || r == '\u00b7'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// WithXMLDeclaration controls whether the document starts with an
+// <?xml ...?> declaration. It is written by default; inline HTML
+// embedding needs it left out.
+func WithXMLDeclaration(enabled bool) Option {
+	return func(b *Backend) {
+		b.omitDeclaration = !enabled
+	}
+}
+
+// WithStandalone adds standalone="yes" or standalone="no" to the XML
+// declaration.
+func WithStandalone(standalone bool) Option {
+	return func(b *Backend) {
+		b.standalone = "no"
+		if standalone {
+			b.standalone = "yes"
+		}
+	}
+}
+
+// WithDoctype writes a document type declaration, such as DoctypeSVG11,
+// after the XML declaration.
+func WithDoctype(doctype string) Option {
+	return func(b *Backend) {
+		b.doctype = doctype
+	}
+}
+
+// WithNamespaces selects the namespace declarations on the root element.
+func WithNamespaces(n Namespaces) Option {
+	return func(b *Backend) {
+		b.namespaces = n
+	}
+}
+
+// WithNamespace declares the namespace uri with prefix on the root
+// element, so attributes in it, such as those an OnElement hook or
+// SetNextAttrs adds, or elements in WriteRaw fragments, can use the
+// prefix without declaring it themselves:
+//
+//	backend := svg.NewBackend(
+//		svg.WithNamespace("sodipodi", svg.SodipodiNamespace),
+//		svg.WithNamespace("app", "https://example.com/ns/app"),
+//	)
+//	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+//		attrs["app:layer"] = "shapes"
+//		return attrs
+//	})
+//
+// The option may be given several times; declarations are written in the
+// order given, after the SVG and XLink ones, and registering a prefix
+// again replaces its namespace. They are written with every Namespaces
+// mode. The prefix must be an XML name without a colon, other than xml
+// and xmlns; options with any other prefix are ignored. The xlink and
+// inkscape prefixes are bound to their standard namespaces, which the
+// backend declares itself when it uses them; registering them makes sure
+// they are always declared, and uri is ignored.
+func WithNamespace(prefix, uri string) Option {
+	return func(b *Backend) {
+		if !isNCName(prefix) || strings.EqualFold(prefix, "xml") || strings.EqualFold(prefix, "xmlns") {
+			return
+		}
+		for i := range b.extraNamespaces {
+			if b.extraNamespaces[i].prefix == prefix {
+				b.extraNamespaces[i].uri = uri
+				return
+			}
+		}
+		b.extraNamespaces = append(b.extraNamespaces, namespaceDecl{prefix, uri})
+	}
+}
+
+// registersNamespace reports whether prefix was registered with
+// WithNamespace.
+func (b *Backend) registersNamespace(prefix string) bool {
+	for _, ns := range b.extraNamespaces {
+		if ns.prefix == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// prolog returns the XML declaration and document type declaration.
+func (b *Backend) prolog() string {
+	var p strings.Builder
+	if !b.omitDeclaration {
+		p.WriteString(`<?xml version="1.0" encoding="UTF-8"`)
+		if b.standalone != "" {
+			p.WriteString(` standalone="` + b.standalone + `"`)
+		}
+		p.WriteString("?>\n")
+	}
+	if b.doctype != "" {
+		p.WriteString(b.doctype + "\n")
+	}
+	return p.String()
+}
+
+// usesXLink reports whether the content written so far, in any buffer,
+// uses the XLink namespace.
+func (b *Backend) usesXLink() bool {
+	used := []byte("xlink:")
+	if bytes.Contains(b.builder.Bytes(), used) || bytes.Contains(b.defs.Bytes(), used) ||
+		strings.Contains(b.patternDefs(), "xlink:") {
+		return true
+	}
+	for i := range b.layers {
+		if bytes.Contains(b.layers[i].Bytes(), used) {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceAttrs returns the namespace declarations of the root element,
+// except the Inkscape one, which header writes.
+func (b *Backend) namespaceAttrs() string {
+	var s strings.Builder
+	xlink := b.registersNamespace("xlink")
+	switch b.namespaces {
+	case NamespacesNone:
+	case NamespacesUsed:
+		s.WriteString(` xmlns="http://www.w3.org/2000/svg"`)
+		xlink = xlink || b.usesXLink()
+	default:
+		s.WriteString(` xmlns="http://www.w3.org/2000/svg"`)
+		xlink = true
+	}
+	if xlink {
+		s.WriteString(` xmlns:xlink="http://www.w3.org/1999/xlink"`)
+	}
+	for _, ns := range b.extraNamespaces {
+		if ns.prefix != "xlink" && ns.prefix != "inkscape" {
+			s.WriteString(` xmlns:` + ns.prefix + `="` + escapeXML(ns.uri) + `"`)
+		}
+	}
+	return s.String()
+}
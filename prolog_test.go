@@ -0,0 +1,105 @@
+package svg
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestProlog(t *testing.T) {
+	backend := NewBackend(WithStandalone(false), WithDoctype(DoctypeSVG11))
+	_ = backend.Begin(10, 10)
+	svg := writeSVG(t, backend)
+
+	expected := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" + DoctypeSVG11 + "\n<svg "
+	if !strings.HasPrefix(svg, expected) {
+		t.Errorf("Output should start with %s, got:\n%s", expected, svg)
+	}
+
+	backend = NewBackend(WithXMLDeclaration(false), WithNamespaces(NamespacesNone))
+	_ = backend.Begin(10, 10)
+	svg = writeSVG(t, backend)
+	if !strings.HasPrefix(svg, `<svg width="10"`) {
+		t.Errorf("Output should start with a bare <svg> element, got:\n%s", svg)
+	}
+}
+
+func TestNamespacesUsed(t *testing.T) {
+	backend := NewBackend(WithNamespaces(NamespacesUsed))
+	_ = backend.Begin(10, 10)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+	svg := writeSVG(t, backend)
+	if strings.Contains(svg, "xmlns:xlink") || !strings.Contains(svg, `xmlns="http://www.w3.org/2000/svg"`) {
+		t.Errorf("Output should only declare the SVG namespace, got:\n%s", svg)
+	}
+
+	backend = NewBackend(WithNamespaces(NamespacesUsed), WithSVGVersion(SVG11))
+	_ = backend.Begin(10, 10)
+	backend.BeginLink("https://example.com")
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+	backend.EndLink()
+	svg = writeSVG(t, backend)
+	if !strings.Contains(svg, "xmlns:xlink") {
+		t.Errorf("Output using xlink:href should declare the XLink namespace, got:\n%s", svg)
+	}
+
+	// Layered structures keep elements out of the main buffer until the
+	// document is written.
+	backend = NewBackend(WithNamespaces(NamespacesUsed), WithSVGVersion(SVG11), WithStructure(StructureByOp))
+	_ = backend.Begin(10, 10)
+	backend.DrawImage(image.NewRGBA(image.Rect(0, 0, 2, 2)), recording.Rect{}, recording.NewRect(0, 0, 5, 5),
+		recording.ImageOptions{Alpha: 1})
+	svg = writeSVG(t, backend)
+	if !strings.Contains(svg, "xlink:href") || !strings.Contains(svg, "xmlns:xlink") {
+		t.Errorf("Layered output using xlink:href should declare the XLink namespace, got:\n%s", svg)
+	}
+}
+
+func TestWithNamespace(t *testing.T) {
+	backend := NewBackend(
+		WithNamespace("app", "https://example.com/old"),
+		WithNamespace("sodipodi", SodipodiNamespace),
+		WithNamespace("app", "https://example.com/a&b"),
+		WithNamespace("inkscape", "ignored"),
+		WithNamespace("xlink", "ignored"),
+		WithNamespace("xml", "ignored"),
+		WithNamespace("xmlns", "ignored"),
+		WithNamespace("", "ignored"),
+		WithNamespace("1app", "ignored"),
+		WithNamespace("a:b", "ignored"),
+		WithNamespace(`x="y" onload="alert(1)`, "ignored"),
+		WithNamespace("a b", "ignored"),
+	)
+	_ = backend.Begin(10, 10)
+	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+		attrs["app:role"] = "swatch"
+		return attrs
+	})
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+	svg := writeSVG(t, backend)
+
+	want := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"` +
+		` xmlns:app="https://example.com/a&amp;b" xmlns:sodipodi="` + SodipodiNamespace + `"`
+	if !strings.Contains(svg, want) || !strings.Contains(svg, `xmlns:inkscape="`+InkscapeNamespace+`"`) {
+		t.Errorf("SVG missing namespace declarations %s:\n%s", want, svg)
+	}
+	if strings.Count(svg, "xmlns:xlink") != 1 || strings.Contains(svg, "ignored") || strings.Contains(svg, "old") {
+		t.Errorf("namespaces should be declared once:\n%s", svg)
+	}
+	if !strings.Contains(svg, `app:role="swatch"`) {
+		t.Errorf("hook attribute missing:\n%s", svg)
+	}
+	if problems := validateDocument([]byte(svg)); len(problems) > 0 {
+		t.Errorf("document is invalid: %v", problems)
+	}
+
+	backend = NewBackend(WithNamespaces(NamespacesNone), WithNamespace("xlink", ""))
+	_ = backend.Begin(10, 10)
+	svg = writeSVG(t, backend)
+	if !strings.HasPrefix(svg[strings.Index(svg, "<svg"):], `<svg xmlns:xlink="http://www.w3.org/1999/xlink" width="10"`) {
+		t.Errorf("registered xlink should be declared in NamespacesNone mode:\n%s", svg)
+	}
+}
@@ -0,0 +1,332 @@
+package svg
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"image"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// Version is the gg-svg release recorded in provenance blocks. When it is
+// empty, as it is by default, the version of this module in the binary's
+// build information is recorded instead. Release builds of tools that
+// vendor or replace the module can set it at link time:
+//
+//	go build -ldflags "-X github.com/gogpu/gg-svg.Version=v1.2.3"
+var Version string
+
+// modulePath is the import path of this module.
+const modulePath = "github.com/gogpu/gg-svg"
+
+// buildVersion is the version of this module in the build information,
+// or "devel" if it is unknown, as in tests and local builds.
+var buildVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	mod := &info.Main
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			mod = dep
+		}
+	}
+	if mod.Path != modulePath || mod.Version == "" || mod.Version == "(devel)" {
+		return "devel"
+	}
+	return mod.Version
+})
+
+// generatorVersion returns the version recorded in provenance blocks.
+func generatorVersion() string {
+	if Version != "" {
+		return Version
+	}
+	return buildVersion()
+}
+
+// provenanceNS is the namespace of the provenance block elements.
+const provenanceNS = "https://github.com/gogpu/gg-svg/provenance"
+
+// WithProvenance embeds a <metadata> block naming the gg-svg version and
+// the options used, so generated assets can be traced back to their
+// inputs. When the recording is replayed with Backend.Playback, the block
+// also carries a SHA-256 hash of the recording's commands and resources.
+func WithProvenance() Option {
+	return func(b *Backend) {
+		b.provenance = true
+	}
+}
+
+// provenanceBlock returns the provenance <metadata> element, or "" if
+// provenance is disabled.
+func (b *Backend) provenanceBlock() string {
+	if !b.provenance {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString(`<metadata><gg:provenance xmlns:gg="` + provenanceNS + `"`)
+	s.WriteString(` generator="gg-svg ` + escapeXML(generatorVersion()) + `"`)
+	if b.recordingHash != "" {
+		s.WriteString(` recording="sha256:` + b.recordingHash + `"`)
+	}
+	s.WriteString(">")
+	for _, opt := range b.optionSummary() {
+		s.WriteString(fmt.Sprintf(`<gg:option name="%s" value="%s"/>`, opt[0], escapeXML(opt[1])))
+	}
+	s.WriteString("</gg:provenance></metadata>\n")
+	return s.String()
+}
+
+// optionSummary lists the options that differ from their defaults as
+// name/value pairs. Function-valued options are reported by presence only,
+// and scripts by the number added with AddScript. Every With option that
+// changes the document must be listed; TestOptionSummaryComplete checks.
+func (b *Backend) optionSummary() [][2]string {
+	var opts [][2]string
+	add := func(name string, value any) {
+		opts = append(opts, [2]string{name, fmt.Sprint(value)})
+	}
+
+	if len(b.extraNamespaces) > 0 {
+		prefixes := make([]string, len(b.extraNamespaces))
+		for i, ns := range b.extraNamespaces {
+			prefixes[i] = ns.prefix
+		}
+		add("extra-namespaces", strings.Join(prefixes, " "))
+	}
+	if b.untrusted {
+		add("untrusted", true)
+	}
+	if b.noScript {
+		add("no-script", true)
+	}
+	if b.scriptsEnabled {
+		add("scripts", len(b.scripts))
+	}
+	if b.maxBytes > 0 || b.maxElements > 0 {
+		add("budget", fmt.Sprintf("%d/%d", b.maxBytes, b.maxElements))
+	}
+	if b.drawOnDuration > 0 {
+		add("draw-on", fmt.Sprintf("%s,%t", b.drawOnDuration, b.drawOnSequential))
+	}
+	if b.simplifyTolerance > 0 {
+		add("simplify", b.simplifyTolerance)
+	}
+	if b.flattenTransforms {
+		add("flatten-transforms", true)
+	}
+	if b.culling {
+		add("culling", true)
+	}
+	if b.mergePaths {
+		add("merge-paths", true)
+	}
+	if len(b.patternOrder) > 0 {
+		add("patterns", strings.Join(b.patternOrder, ","))
+	}
+	if b.version != SVG2 {
+		add("version", b.version)
+	}
+	if b.cropMarks {
+		add("crop-marks", b.bleed)
+	}
+	if b.cutline() {
+		add("cutline", b.cutDistance)
+	}
+	if b.cornerRadius > 0 {
+		add("round-corners", b.cornerRadius)
+	}
+	if b.sketching() {
+		add("sketch", fmt.Sprintf("%g,%d", b.sketchRoughness, b.sketchSeed))
+	}
+	if b.mirrorX || b.mirrorY {
+		add("mirror", fmt.Sprintf("%t,%t", b.mirrorX, b.mirrorY))
+	}
+	if b.unmirrorText {
+		add("unmirrored-text", true)
+	}
+	if b.rotation != 0 {
+		add("rotation", b.rotation)
+	}
+	if b.dashUnits != DashUserUnits {
+		add("dash-units", b.dashUnits)
+	}
+	if b.omitDeclaration {
+		add("xml-declaration", false)
+	}
+	if b.standalone != "" {
+		add("standalone", b.standalone == "yes")
+	}
+	if b.doctype != "" {
+		add("doctype", true)
+	}
+	if b.namespaces != NamespacesAll {
+		add("namespaces", b.namespaces)
+	}
+	if b.profile != ProfileFull {
+		add("profile", b.profile)
+	}
+	if b.structure != StructureInterleaved {
+		add("structure", b.structure)
+	}
+	if b.laserClassifier != nil {
+		add("laser-classifier", true)
+	}
+	if b.hardClip {
+		add("hard-clip", true)
+	}
+	if b.styleMode != StyleAttributes {
+		add("style-mode", b.styleMode)
+	}
+	if b.idPrefix != "" {
+		add("id-prefix", b.idPrefix)
+	}
+	if b.overflow != OverflowDefault {
+		add("overflow", b.overflow)
+	}
+	if b.aspectRatio != "" {
+		add("aspect-ratio", b.aspectRatio)
+	}
+	if b.imageAspectRatio != "" {
+		add("image-aspect-ratio", b.imageAspectRatio)
+	}
+	if b.responsive {
+		add("responsive", true)
+	}
+	if b.opacityDigits >= 0 {
+		add("opacity-precision", b.opacityDigits)
+	}
+	if b.alphaInvisible != 0 || b.alphaOpaque != 1 {
+		add("alpha-thresholds", fmt.Sprintf("%g/%g", b.alphaInvisible, b.alphaOpaque))
+	}
+	if b.autoCrop {
+		add("auto-crop", fmt.Sprintf("%g/%t", b.cropPadding, b.cropResize))
+	}
+	if b.hasBackground {
+		add("background", fmt.Sprintf("%s/%g", colorToCSS(b.background), b.background.A))
+	}
+	if b.wrapWidth > 0 {
+		add("text-wrap", b.wrapWidth)
+	}
+	if b.baseline != BaselineAlphabetic {
+		add("baseline", b.baseline)
+	}
+	if b.defaultFontSize != DefaultFontSize {
+		add("default-font-size", b.defaultFontSize)
+	}
+	if b.fontSizeResolver != nil {
+		add("font-size-resolver", true)
+	}
+	if b.textLength {
+		add("text-length", true)
+	}
+	if b.winding != WindingAsRecorded {
+		add("winding", b.winding)
+	}
+	if b.weldTolerance > 0 {
+		add("weld-tolerance", b.weldTolerance)
+		add("weld-close", b.weldClose)
+	}
+	if b.toolpathOrdering {
+		add("toolpath-ordering", true)
+	}
+	if b.strict {
+		add("strict", true)
+	}
+	if b.sanitizeRaw {
+		add("sanitize-raw", true)
+	}
+	if b.sourceMap {
+		add("source-map", true)
+	}
+	if b.trimThreshold != DefaultTrimThreshold {
+		add("trim-threshold", b.trimThreshold)
+	}
+	if len(b.postProcessors) > 0 {
+		add("post-processors", len(b.postProcessors))
+	}
+	if len(b.passes) > 0 {
+		add("optimizer-passes", len(b.passes))
+	}
+	if b.signer != nil {
+		add("signed", true)
+	}
+	if b.brushRemap != nil {
+		add("brush-remap", true)
+	}
+	if b.strokeWidthRemap != nil {
+		add("stroke-width-remap", true)
+	}
+	if b.colorFormat != ColorRGB {
+		add("color-format", b.colorFormat)
+	}
+	if b.colorRounding != ColorRoundNearest {
+		add("color-rounding", b.colorRounding)
+	}
+	if b.hexAlpha {
+		add("hex-alpha", true)
+	}
+	if len(b.themeColors) > 0 {
+		add("theme-colors", len(b.themeColors))
+	}
+	if b.elementHook != nil {
+		add("element-hook", true)
+	}
+	return opts
+}
+
+// hashRecording returns the hex SHA-256 of the recording's dimensions,
+// commands and referenced resources.
+func hashRecording(r *recording.Recording) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%dx%d\n", r.Width(), r.Height())
+	for _, cmd := range r.Commands() {
+		fmt.Fprintf(h, "%T%v\n", cmd, cmd)
+	}
+
+	res := r.Resources()
+	for i := range res.PathCount() {
+		fmt.Fprintf(h, "path%v\n", res.GetPath(recording.PathRef(i)).Elements())
+	}
+	for i := range res.BrushCount() {
+		fmt.Fprintf(h, "brush%v\n", res.GetBrush(recording.BrushRef(i)))
+	}
+	for i := range res.ImageCount() {
+		hashImage(h, res.GetImage(recording.ImageRef(i)))
+	}
+	for i := range res.FontCount() {
+		if face := res.GetFont(recording.FontRef(i)); face != nil {
+			fmt.Fprintf(h, "font%s %g\n", face.Source().Name(), face.Size())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashImage writes the bounds and pixels of img to h.
+func hashImage(h hash.Hash, img image.Image) {
+	if img == nil {
+		return
+	}
+	bounds := img.Bounds()
+	fmt.Fprintf(h, "image%v\n", bounds)
+	var px [8]byte
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			binary.BigEndian.PutUint16(px[0:], uint16(r))
+			binary.BigEndian.PutUint16(px[2:], uint16(g))
+			binary.BigEndian.PutUint16(px[4:], uint16(b))
+			binary.BigEndian.PutUint16(px[6:], uint16(a))
+			h.Write(px[:])
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package svg
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+func TestWithProvenance(t *testing.T) {
+	record := func(w float64) *recording.Recording {
+		rec := recording.NewRecorder(100, 100)
+		rec.SetFillRGBA(1, 0, 0, 1)
+		rec.DrawRectangle(0, 0, w, 10)
+		rec.Fill()
+		return rec.FinishRecording()
+	}
+	hashRe := regexp.MustCompile(`recording="sha256:([0-9a-f]{64})"`)
+
+	export := func(r *recording.Recording) string {
+		backend := NewBackend(WithProvenance(), WithStructure(StructureByOp), WithWelding(0.5, true))
+		if err := backend.Playback(r); err != nil {
+			t.Fatalf("Playback failed: %v", err)
+		}
+		return writeSVG(t, backend)
+	}
+
+	first := export(record(10))
+	expected := []string{
+		`<gg:provenance xmlns:gg="https://github.com/gogpu/gg-svg/provenance" generator="gg-svg ` + generatorVersion() + `"`,
+		`<gg:option name="structure" value="by-op"/>`,
+		`<gg:option name="weld-tolerance" value="0.5"/>`,
+		`<gg:option name="weld-close" value="true"/>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(first, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, first)
+		}
+	}
+
+	h1 := hashRe.FindStringSubmatch(first)
+	h2 := hashRe.FindStringSubmatch(export(record(10)))
+	h3 := hashRe.FindStringSubmatch(export(record(20)))
+	if h1 == nil || h2 == nil || h3 == nil {
+		t.Fatalf("Output should contain a recording hash, got:\n%s", first)
+	}
+	if h1[1] != h2[1] {
+		t.Error("Identical recordings should hash identically")
+	}
+	if h1[1] == h3[1] {
+		t.Error("Different recordings should hash differently")
+	}
+}
+
+func TestWithProvenanceDirectCalls(t *testing.T) {
+	backend := NewBackend(WithProvenance())
+	_ = backend.Begin(100, 100)
+	svg := writeSVG(t, backend)
+
+	if !strings.Contains(svg, "<gg:provenance") {
+		t.Errorf("Output should contain a provenance block, got:\n%s", svg)
+	}
+	if strings.Contains(svg, "recording=") {
+		t.Error("Direct calls have no recording to hash")
+	}
+
+	plain := NewBackend()
+	_ = plain.Begin(100, 100)
+	if strings.Contains(writeSVG(t, plain), "provenance") {
+		t.Error("Provenance should be off by default")
+	}
+}
+
+func TestProvenanceVersion(t *testing.T) {
+	if v := generatorVersion(); v != "devel" {
+		t.Errorf("Tests have no module version, got %q", v)
+	}
+
+	Version = "v9.8.7"
+	defer func() { Version = "" }()
+	backend := NewBackend(WithProvenance())
+	_ = backend.Begin(10, 10)
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `generator="gg-svg v9.8.7"`) {
+		t.Errorf("Output should record the link-time version, got:\n%s", svg)
+	}
+}
+
+// TestOptionSummaryComplete fails when a With option is added without
+// being reported by optionSummary: every option of the package must be
+// listed below with a non-default argument, or in exempt if it leaves the
+// document unchanged.
+func TestOptionSummaryComplete(t *testing.T) {
+	options := map[string]Option{
+		"WithAlphaThresholds":   WithAlphaThresholds(0.01, 0.99),
+		"WithAspectRatio":       WithAspectRatio(AspectSlice),
+		"WithAutoCrop":          WithAutoCrop(4, true),
+		"WithBackground":        WithBackground(gg.White),
+		"WithBaseline":          WithBaseline(BaselineTop),
+		"WithBudget":            WithBudget(Budget{MaxBytes: 1000}),
+		"WithColorFormat":       WithColorFormat(ColorHex),
+		"WithColorRounding":     WithColorRounding(ColorTruncate),
+		"WithCropMarks":         WithCropMarks(3),
+		"WithCulling":           WithCulling(true),
+		"WithCutline":           WithCutline(5),
+		"WithDashUnits":         WithDashUnits(DashStrokeWidths),
+		"WithDefaultFontSize":   WithDefaultFontSize(20),
+		"WithDoctype":           WithDoctype(DoctypeSVG11),
+		"WithDrawOn":            WithDrawOn(time.Second, false),
+		"WithFlattenTransforms": WithFlattenTransforms(true),
+		"WithFontSizeResolver":  WithFontSizeResolver(func(text.Face) float64 { return 10 }),
+		"WithHardClipToCanvas":  WithHardClipToCanvas(true),
+		"WithHexAlpha":          WithHexAlpha(true),
+		"WithIDPrefix":          WithIDPrefix("chart-"),
+		"WithImageAspectRatio":  WithImageAspectRatio(AspectNone),
+		"WithLaserClassifier":   WithLaserClassifier(func(LaserElement) LaserOp { return LaserCut }),
+		"WithMergePaths":        WithMergePaths(true),
+		"WithMirror":            WithMirror(true, false),
+		"WithNamespace":         WithNamespace("dc", "http://purl.org/dc/elements/1.1/"),
+		"WithNamespaces":        WithNamespaces(NamespacesUsed),
+		"WithNoScript":          WithNoScript(true),
+		"WithOpacityPrecision":  WithOpacityPrecision(2),
+		"WithOverflow":          WithOverflow(OverflowHidden),
+		"WithPostProcessor":     WithPostProcessor(func(data []byte) ([]byte, error) { return data, nil }),
+		"WithProfile":           WithProfile(ProfileTiny),
+		"WithResponsive":        WithResponsive(true),
+		"WithRotation":          WithRotation(90),
+		"WithRoundCorners":      WithRoundCorners(2),
+		"WithSVGVersion":        WithSVGVersion(SVG11),
+		"WithSanitizedRaw":      WithSanitizedRaw(true),
+		"WithScripts":           WithScripts(true),
+		"WithSigner":            WithSigner(SignerFunc(func([]byte) ([]byte, error) { return nil, nil })),
+		"WithSimplify":          WithSimplify(0.5),
+		"WithSketch":            WithSketch(1, 7),
+		"WithSourceMap":         WithSourceMap(),
+		"WithStandalone":        WithStandalone(true),
+		"WithStrict":            WithStrict(true),
+		"WithStructure":         WithStructure(StructureByOp),
+		"WithStyleMode":         WithStyleMode(StyleClasses),
+		"WithTextLength":        WithTextLength(true),
+		"WithTextWrap":          WithTextWrap(100),
+		"WithToolpathOrdering":  WithToolpathOrdering(true),
+		"WithTrimThreshold":     WithTrimThreshold(2 * DefaultTrimThreshold),
+		"WithUnmirroredText":    WithUnmirroredText(true),
+		"WithUntrusted":         WithUntrusted(true),
+		"WithWelding":           WithWelding(0.5, false),
+		"WithWinding":           WithWinding(WindingOuterCCW),
+		"WithXMLDeclaration":    WithXMLDeclaration(false),
+	}
+	exempt := map[string]bool{
+		"WithProvenance": true, // writes the summary itself
+		"WithSizeHint":   true, // presizes buffers only
+		"WithValidation": true, // checks the document in End only
+	}
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "With") || fn.Type.Results == nil {
+				continue
+			}
+			if result, ok := fn.Type.Results.List[0].Type.(*ast.Ident); ok && result.Name == "Option" {
+				declared[fn.Name.Name] = true
+			}
+		}
+	}
+
+	for name := range declared {
+		if _, ok := options[name]; !ok && !exempt[name] {
+			t.Errorf("%s is not covered: report it in optionSummary and list it here", name)
+		}
+	}
+	base := NewBackend().optionSummary()
+	for name, opt := range options {
+		if !declared[name] {
+			t.Errorf("%s is not an option of the package", name)
+			continue
+		}
+		if summary := NewBackend(opt).optionSummary(); reflect.DeepEqual(summary, base) {
+			t.Errorf("%s should be reported by optionSummary", name)
+		}
+	}
+}
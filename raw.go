@@ -0,0 +1,61 @@
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// WithSanitizedRaw makes WriteRaw sanitize fragments before inserting them.
+// Sanitized fragments must be well-formed XML, and only known SVG elements
+// and attributes are kept: <script>, <foreignObject>, elements in other
+// namespaces, event handler attributes (onclick, onload, ...) and
+// javascript: and data: links are removed, as are processing instructions
+// and directives. DrawForeignObject fails, since its HTML content cannot
+// be sanitized this way.
+func WithSanitizedRaw(enabled bool) Option {
+	return func(b *Backend) {
+		b.sanitizeRaw = enabled
+	}
+}
+
+// WriteRaw inserts an SVG fragment at the current position in the
+// document, for elements the backend doesn't generate (filters,
+// animations, foreignObject). The fragment is not wrapped in the current
+// transform or clip. With a layered structure it is written at document
+// level, ahead of the layer groups.
+//
+// Without WithSanitizedRaw or WithUntrusted the fragment is written
+// verbatim and the caller is responsible for its validity. With either,
+// WriteRaw returns an error if the fragment is not well-formed, and
+// nothing is written.
+func (b *Backend) WriteRaw(fragment string) error {
+	if b.sanitizesFragments() {
+		clean, err := b.sanitizer().sanitizeFragment(fragment)
+		if err != nil {
+			return fmt.Errorf("svg: WriteRaw: %w", err)
+		}
+		fragment = clean
+	}
+	if b.overBudget {
+		return fmt.Errorf("svg: WriteRaw: %w", ErrBudgetExceeded)
+	}
+	// A fragment may hold any number of elements, so the group around
+	// it is kept.
+	start := b.builder.Len()
+	b.builder.WriteString(fragment)
+	if !b.checkBudget() {
+		b.builder.Truncate(start)
+		return fmt.Errorf("svg: WriteRaw: %w", ErrBudgetExceeded)
+	}
+	b.addChild(2)
+	b.lastPath = pathMerge{}
+	return nil
+}
+
+// qualifiedName returns the prefixed name as written in the source.
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
@@ -0,0 +1,81 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWriteRaw(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	fragment := `<circle cx="5" cy="5" r="5" onclick="alert(1)"/>`
+	if err := backend.WriteRaw(fragment); err != nil {
+		t.Fatalf("WriteRaw failed: %v", err)
+	}
+	backend.FillRect(recording.NewRect(20, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+
+	svg := writeSVG(t, backend)
+	first := strings.Index(svg, `<rect x="0"`)
+	raw := strings.Index(svg, fragment)
+	second := strings.Index(svg, `<rect x="20"`)
+	if first < 0 || raw < 0 || second < 0 || !(first < raw && raw < second) {
+		t.Errorf("Fragment should be written verbatim between the rects, got:\n%s", svg)
+	}
+}
+
+func TestWriteRawSanitized(t *testing.T) {
+	tests := []struct {
+		fragment string
+		expected string
+	}{
+		{
+			`<filter id="f"><feGaussianBlur stdDeviation="2"/></filter>`,
+			`<filter id="f"><feGaussianBlur stdDeviation="2"/></filter>`,
+		},
+		{
+			`<g onload="x()"><script>alert(1)</script><rect width="1"/></g>`,
+			`<g><rect width="1"/></g>`,
+		},
+		{
+			`<a xlink:href="java&#10;script:alert(1)"><text>a &amp; b</text></a>`,
+			`<a><text>a &amp; b</text></a>`,
+		},
+		{
+			`<g><set attributeName="href" to="javascript:alert(1)"/></g>`,
+			`<g/>`,
+		},
+		{
+			`<foreignObject><p xmlns="http://www.w3.org/1999/xhtml">x</p></foreignObject><rect fill="url(#p)" data-x="1"/>`,
+			`<rect fill="url(#p)" data-x="1"/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(WithSanitizedRaw(true))
+		_ = backend.Begin(100, 100)
+		if err := backend.WriteRaw(tt.fragment); err != nil {
+			t.Fatalf("WriteRaw(%q) failed: %v", tt.fragment, err)
+		}
+		svg := writeSVG(t, backend)
+		if !strings.Contains(svg, tt.expected) {
+			t.Errorf("WriteRaw(%q): output should contain %s, got:\n%s", tt.fragment, tt.expected, svg)
+		}
+	}
+}
+
+func TestWriteRawSanitizedMalformed(t *testing.T) {
+	backend := NewBackend(WithSanitizedRaw(true))
+	_ = backend.Begin(100, 100)
+	for _, fragment := range []string{`<g><rect/>`, `<g></rect>`, `<g attr=1/>`} {
+		if err := backend.WriteRaw(fragment); err == nil {
+			t.Errorf("WriteRaw(%q) should fail", fragment)
+		}
+	}
+	if svg := writeSVG(t, backend); strings.Contains(svg, "<g>") {
+		t.Errorf("Rejected fragments should not be written, got:\n%s", svg)
+	}
+}
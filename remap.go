@@ -0,0 +1,63 @@
+package svg
+
+import (
+	"math"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// RemapBrush installs a function that rewrites every brush before it is
+// serialized. It is applied uniformly to fills, strokes, rectangles and text,
+// so a single recording can be exported in several color schemes (or with
+// gradients swapped for flat colors) without touching the drawing code.
+//
+// Returning nil from fn falls back to the original brush.
+// Passing nil removes any previously installed function.
+func (b *Backend) RemapBrush(fn func(recording.Brush) recording.Brush) {
+	b.brushRemap = fn
+}
+
+// remapBrush applies the installed brush remap function, if any.
+func (b *Backend) remapBrush(brush recording.Brush) recording.Brush {
+	if b.brushRemap == nil {
+		return brush
+	}
+	if mapped := b.brushRemap(brush); mapped != nil {
+		return mapped
+	}
+	return brush
+}
+
+// RemapStrokeWidth installs a function that rewrites every stroke width
+// before it is serialized, e.g. to enforce a minimum visible width for
+// plotter output or to scale all widths when exporting at a different size.
+// Passing nil removes any previously installed function.
+func (b *Backend) RemapStrokeWidth(fn func(width float64) float64) {
+	b.strokeWidthRemap = fn
+}
+
+// MinStrokeWidth returns a stroke width remap function that raises every
+// width below minWidth to minWidth.
+func MinStrokeWidth(minWidth float64) func(float64) float64 {
+	return func(width float64) float64 {
+		return math.Max(width, minWidth)
+	}
+}
+
+// remapStrokeWidth applies the installed stroke width remap function, if any.
+func (b *Backend) remapStrokeWidth(width float64) float64 {
+	if b.strokeWidthRemap == nil {
+		return width
+	}
+	return b.strokeWidthRemap(width)
+}
+
+// fillBrush returns the brush to emit for a fill or text element.
+func (b *Backend) fillBrush(brush recording.Brush) recording.Brush {
+	return b.adjustBrush(b.patternFallback(b.classifyLaser(false, b.remapBrush(brush), recording.Stroke{})))
+}
+
+// strokeBrush returns the brush to emit for a stroke element.
+func (b *Backend) strokeBrush(brush recording.Brush, stroke recording.Stroke) recording.Brush {
+	return b.adjustBrush(b.patternFallback(b.classifyLaser(true, b.remapBrush(brush), stroke)))
+}
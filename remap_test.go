@@ -0,0 +1,74 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestRemapBrush(t *testing.T) {
+	backend := NewBackend()
+	backend.RemapBrush(func(br recording.Brush) recording.Brush {
+		if _, ok := br.(*recording.LinearGradientBrush); ok {
+			return recording.NewSolidBrush(gg.RGBA{R: 0, G: 0, B: 1, A: 1})
+		}
+		return nil
+	})
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	path := gg.NewPath()
+	path.Rectangle(10, 10, 50, 50)
+
+	grad := recording.NewLinearGradientBrush(0, 0, 100, 0).
+		AddColorStop(0, gg.RGBA{R: 1, G: 0, B: 0, A: 1}).
+		AddColorStop(1, gg.RGBA{R: 0, G: 1, B: 0, A: 1})
+	backend.FillPath(path, grad, recording.FillRuleNonZero)
+	backend.StrokePath(path, recording.NewSolidBrush(gg.RGBA{R: 1, G: 0, B: 0, A: 1}), recording.DefaultStroke())
+
+	svg := writeSVG(t, backend)
+	if strings.Contains(svg, "<linearGradient") {
+		t.Error("Remapped gradient should not be emitted")
+	}
+	if !strings.Contains(svg, `fill="rgb(0,0,255)"`) {
+		t.Error("Output should contain remapped fill color")
+	}
+	if !strings.Contains(svg, `stroke="rgb(255,0,0)"`) {
+		t.Error("Brushes mapped to nil should keep their original color")
+	}
+}
+
+func TestRemapStrokeWidth(t *testing.T) {
+	backend := NewBackend()
+	backend.RemapStrokeWidth(MinStrokeWidth(0.5))
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	path := gg.NewPath()
+	path.MoveTo(0, 0)
+	path.LineTo(100, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 0, G: 0, B: 0, A: 1})
+	thin := recording.DefaultStroke()
+	thin.Width = 0.1
+	thick := recording.DefaultStroke()
+	thick.Width = 3
+
+	backend.StrokePath(path, brush, thin)
+	backend.StrokePath(path, brush, thick)
+
+	svg := writeSVG(t, backend)
+	if strings.Contains(svg, `stroke-width="0.1"`) {
+		t.Error("Thin stroke should be raised to the minimum width")
+	}
+	if !strings.Contains(svg, `stroke-width="0.5"`) {
+		t.Error("Output should contain the minimum stroke width")
+	}
+	if !strings.Contains(svg, `stroke-width="3"`) {
+		t.Error("Wide strokes should be left unchanged")
+	}
+}
@@ -0,0 +1,35 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestDrawTextRuns(t *testing.T) {
+	backend := NewBackend()
+	var _ RichTextBackend = backend
+
+	_ = backend.Begin(200, 100)
+	backend.DrawTextRuns([]TextRun{
+		{Text: "x"},
+		{Text: "2", Script: ScriptSuper, Size: 8},
+		{Text: " + H"},
+		{Text: "2", Script: ScriptSub, Size: 8, Brush: recording.NewSolidBrush(gg.Red)},
+		{Text: "<O>", Shift: -1.5},
+	}, 10, 50, nil, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	expected := `<text x="10" y="50" font-size="12" fill="rgb(0,0,0)">` +
+		`<tspan>x</tspan>` +
+		`<tspan baseline-shift="super" font-size="8">2</tspan>` +
+		`<tspan> + H</tspan>` +
+		`<tspan baseline-shift="sub" font-size="8" fill="rgb(255,0,0)">2</tspan>` +
+		`<tspan baseline-shift="-1.5">&lt;O&gt;</tspan>` +
+		`</text>`
+	if !strings.Contains(svg, expected) {
+		t.Errorf("Output should contain %s, got:\n%s", expected, svg)
+	}
+}
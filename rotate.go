@@ -0,0 +1,54 @@
+package svg
+
+import "github.com/gogpu/gg/recording"
+
+// WithRotation rotates the whole document clockwise by 90, 180 or 270
+// degrees, for exporting a recording made in one orientation to a
+// portrait or landscape target. For quarter turns the width and height
+// are swapped. Other angles are ignored.
+//
+// The content is wrapped in a group whose transform maps the rotated
+// viewBox, including any WithAutoCrop bounds, onto the origin.
+func WithRotation(degrees int) Option {
+	return func(b *Backend) {
+		degrees %= 360
+		if degrees < 0 {
+			degrees += 360
+		}
+		if degrees%90 != 0 {
+			degrees = 0
+		}
+		b.rotation = degrees
+	}
+}
+
+// quarterTurn reports whether the rotation swaps width and height.
+func (b *Backend) quarterTurn() bool {
+	return b.rotation == 90 || b.rotation == 270
+}
+
+// documentTransform returns the transform applied to the whole document
+// by WithMirror and WithRotation: the viewBox is mirrored in place, then
+// rotated onto the origin.
+func (b *Backend) documentTransform() recording.Matrix {
+	x, y, w, h := b.viewBox()
+	m := b.mirrorMatrix(x, y, w, h)
+	switch b.rotation {
+	case 90:
+		m = recording.Matrix{A: 0, B: -1, C: y + h, D: 1, E: 0, F: -x}.Multiply(m)
+	case 180:
+		m = recording.Matrix{A: -1, B: 0, C: x + w, D: 0, E: -1, F: y + h}.Multiply(m)
+	case 270:
+		m = recording.Matrix{A: 0, B: 1, C: -y, D: -1, E: 0, F: x + w}.Multiply(m)
+	}
+	return m
+}
+
+// documentGroup returns the start tag of the group transforming the
+// whole document, or "" if the document is not rotated or mirrored.
+func (b *Backend) documentGroup() string {
+	if b.rotation == 0 && !b.mirrorX && !b.mirrorY {
+		return ""
+	}
+	return `<g transform="` + matrixValue(b.documentTransform()) + `">`
+}
@@ -0,0 +1,40 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithRotation(t *testing.T) {
+	tests := []struct {
+		degrees  int
+		expected []string
+	}{
+		{90, []string{`width="50" height="100" viewBox="0 0 50 100"`, `<g transform="matrix(0,1,-1,0,50,0)">`}},
+		{180, []string{`width="100" height="50" viewBox="0 0 100 50"`, `<g transform="matrix(-1,0,0,-1,100,50)">`}},
+		{-90, []string{`width="50" height="100" viewBox="0 0 50 100"`, `<g transform="matrix(0,-1,1,0,0,100)">`}},
+	}
+	for _, tt := range tests {
+		backend := NewBackend(WithRotation(tt.degrees))
+		_ = backend.Begin(100, 50)
+		backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+		svg := writeSVG(t, backend)
+		for _, e := range tt.expected {
+			if !strings.Contains(svg, e) {
+				t.Errorf("Rotation %d should contain %s, got:\n%s", tt.degrees, e, svg)
+			}
+		}
+		if !strings.HasSuffix(svg, "</g>\n</svg>\n") {
+			t.Errorf("Rotation %d group should be closed, got:\n%s", tt.degrees, svg)
+		}
+	}
+
+	backend := NewBackend(WithRotation(45))
+	_ = backend.Begin(100, 50)
+	if svg := writeSVG(t, backend); strings.Contains(svg, "<g") {
+		t.Errorf("Other angles should be ignored, got:\n%s", svg)
+	}
+}
@@ -0,0 +1,378 @@
+package svg
+
+import (
+	"errors"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+// Namespaces the sanitizer resolves element and attribute names against.
+const (
+	svgNS   = "http://www.w3.org/2000/svg"
+	xlinkNS = "http://www.w3.org/1999/xlink"
+	xmlNS   = "http://www.w3.org/XML/1998/namespace"
+)
+
+// inertNS are the non-SVG namespaces the backend itself writes, for
+// metadata and editor settings. No renderer interprets their content, so
+// the sanitizer keeps their elements and attributes.
+var inertNS = map[string]bool{
+	"http://www.w3.org/1999/02/22-rdf-syntax-ns#": true,
+	"http://purl.org/dc/elements/1.1/":            true,
+	"http://creativecommons.org/ns#":              true,
+	provenanceNS:                                  true,
+	inkscapeNS:                                    true,
+	sodipodiNS:                                    true,
+}
+
+// nameSet returns a set of the space-separated names.
+func nameSet(names string) map[string]bool {
+	set := make(map[string]bool)
+	for _, n := range strings.Fields(names) {
+		set[n] = true
+	}
+	return set
+}
+
+// svgElements are the SVG elements the sanitizer keeps. script,
+// foreignObject and the elements loading other documents are missing.
+var svgElements = nameSet(`a animate animateMotion animateTransform circle
+	clipPath defs desc ellipse feBlend feColorMatrix feComponentTransfer
+	feComposite feConvolveMatrix feDiffuseLighting feDisplacementMap
+	feDistantLight feDropShadow feFlood feFuncA feFuncB feFuncG feFuncR
+	feGaussianBlur feImage feMerge feMergeNode feMorphology feOffset
+	fePointLight feSpecularLighting feSpotLight feTile feTurbulence filter g
+	image line linearGradient marker mask metadata mpath path pattern polygon
+	polyline radialGradient rect set stop style svg switch symbol text
+	textPath title tspan use view`)
+
+// svgAttributes are the SVG attributes the sanitizer keeps, besides
+// data-* and aria-* attributes. Event handlers are missing.
+var svgAttributes = nameSet(`accumulate additive alignment-baseline
+	amplitude attributeName attributeType azimuth baseFrequency
+	baseline-shift baseProfile begin bias by calcMode class clip clip-path
+	clip-rule clipPathUnits color color-interpolation
+	color-interpolation-filters color-rendering cursor cx cy d
+	diffuseConstant direction display divisor dominant-baseline dur dx dy
+	edgeMode elevation end exponent fill fill-opacity fill-rule filter
+	filterUnits flood-color flood-opacity font font-family font-size
+	font-size-adjust font-stretch font-style font-variant font-weight fr from
+	fx fy gradientTransform gradientUnits height id image-rendering in in2
+	intercept isolation k1 k2 k3 k4 kernelMatrix kernelUnitLength keyPoints
+	keySplines keyTimes lang lengthAdjust letter-spacing lighting-color
+	limitingConeAngle marker marker-end marker-mid marker-start markerHeight
+	markerUnits markerWidth mask mask-type maskContentUnits maskUnits max
+	media method min mix-blend-mode mode numOctaves offset opacity operator
+	order orient overflow paint-order path pathLength patternContentUnits
+	patternTransform patternUnits pointer-events points pointsAtX pointsAtY
+	pointsAtZ preserveAlpha preserveAspectRatio primitiveUnits r radius refX
+	refY rel repeatCount repeatDur requiredExtensions requiredFeatures restart
+	result role rotate rx ry scale seed shape-rendering side slope spacing
+	specularConstant specularExponent spreadMethod startOffset stdDeviation
+	stitchTiles stop-color stop-opacity stroke stroke-dasharray
+	stroke-dashoffset stroke-linecap stroke-linejoin stroke-miterlimit
+	stroke-opacity stroke-width style surfaceScale systemLanguage tabindex
+	tableValues target targetX targetY text-anchor text-decoration
+	text-rendering textLength to transform transform-origin type
+	unicode-bidi values vector-effect version viewBox visibility white-space
+	width word-spacing writing-mode x x1 x2 xChannelSelector y y1 y2
+	yChannelSelector z`)
+
+// cssAttributes are the attributes whose values are CSS that may hold
+// url() references: the style attribute, the presentation attributes
+// referring to other elements and the animation values that can set them.
+var cssAttributes = nameSet(`style fill stroke clip-path mask filter marker
+	marker-start marker-mid marker-end cursor from to by values`)
+
+// cssFunctions are the CSS functions the sanitizer keeps besides url().
+// None of them loads a resource.
+var cssFunctions = nameSet(`rgb rgba hsl hsla hwb lab lch oklab oklch
+	color calc min max clamp var matrix matrix3d translate translatex
+	translatey translate3d scale scalex scaley scale3d rotate rotate3d skew
+	skewx skewy cubic-bezier steps linear-gradient radial-gradient
+	conic-gradient blur brightness contrast drop-shadow grayscale hue-rotate
+	invert opacity saturate sepia inset circle ellipse polygon rect`)
+
+// embeddedImage matches the data URIs allowed in <image href>.
+var embeddedImage = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp)[;,]`)
+
+// cssComment matches a CSS comment.
+var cssComment = regexp.MustCompile(`/\*(?s:.*?)\*/`)
+
+// cssImport matches an @import rule.
+var cssImport = regexp.MustCompile(`(?i)@import[^;]*;?`)
+
+// sanitizer removes everything but known SVG content from an element
+// tree: elements and attributes outside the SVG namespace (other than
+// the inert metadata ones), script, foreignObject, event handlers,
+// script and data: URLs, CSS escapes and CSS functions that load
+// resources other than url().
+type sanitizer struct {
+	// external keeps references to resources outside the document.
+	// Otherwise only #id references and embedded images are kept.
+	external bool
+}
+
+// sanitize sanitizes root, which must be an SVG element. scope maps the
+// namespace prefixes declared around root to their namespaces, with ""
+// for the default namespace.
+func (p sanitizer) sanitize(root *Element, scope map[string]string) error {
+	if !p.element(root, scope) {
+		return errors.New("root element <" + root.Name + "> is not SVG")
+	}
+	return nil
+}
+
+// element sanitizes e and reports whether it is kept.
+func (p sanitizer) element(e *Element, parent map[string]string) bool {
+	scope := parent
+	copied := false
+	for _, a := range e.Attrs {
+		if prefix, ok := declaredPrefix(a.Name); ok {
+			if !copied {
+				scope, copied = maps.Clone(parent), true
+				if scope == nil {
+					scope = make(map[string]string)
+				}
+			}
+			scope[prefix] = a.Value
+		}
+	}
+	name := splitName(e.Name)
+	ns := resolveNS(scope, name.Space)
+	switch {
+	case ns == svgNS && svgElements[name.Local]:
+		if target, ok := e.Attr("attributeName"); ok && animatesUnsafe(target) {
+			return false
+		}
+	case inertNS[ns]:
+	default:
+		return false
+	}
+
+	kept := e.Attrs[:0]
+	for _, a := range e.Attrs {
+		if p.attr(&a, name.Local, ns, scope) {
+			kept = append(kept, a)
+		}
+	}
+	e.Attrs = kept
+
+	// HTML parses the content of inline <title> and <desc> as HTML, so
+	// they keep only their text.
+	textOnly := ns == svgNS && (name.Local == "title" || name.Local == "desc")
+	e.Children = slices.DeleteFunc(e.Children, func(c *Element) bool {
+		if c.Name == "" {
+			if ns == svgNS && name.Local == "style" {
+				c.Text = p.css(c.Text)
+			}
+			return false
+		}
+		return textOnly || !p.element(c, scope)
+	})
+	return true
+}
+
+// attr reports whether the attribute a of an element with the local
+// name elem in namespace ns is kept, rewriting its value if needed.
+func (p sanitizer) attr(a *Attr, elem, ns string, scope map[string]string) bool {
+	if prefix, ok := declaredPrefix(a.Name); ok {
+		return a.Value == svgNS || prefix != "" && a.Value == xlinkNS || inertNS[a.Value]
+	}
+	n := splitName(a.Name)
+	if n.Space != "" {
+		switch attrNS := resolveNS(scope, n.Space); attrNS {
+		case xlinkNS:
+			return n.Local == "href" && p.href(elem, a.Value)
+		case xmlNS:
+			return n.Local == "space" || n.Local == "lang"
+		default:
+			return inertNS[attrNS]
+		}
+	}
+	switch {
+	case inertNS[ns]:
+		return true
+	case n.Local == "href":
+		return p.href(elem, a.Value)
+	case svgAttributes[n.Local] || strings.HasPrefix(n.Local, "data-") || strings.HasPrefix(n.Local, "aria-"):
+	default:
+		return false
+	}
+	if cssAttributes[n.Local] {
+		a.Value = p.css(a.Value)
+	}
+	return true
+}
+
+// href reports whether an href on the element named local is kept.
+func (p sanitizer) href(local, target string) bool {
+	target = compactURL(target)
+	switch {
+	case strings.HasPrefix(target, "#"):
+		return true
+	case embeddedImage.MatchString(target):
+		return local == "image"
+	}
+	return p.external && safeScheme(target)
+}
+
+// url reports whether a CSS url() target is kept.
+func (p sanitizer) url(target string) bool {
+	target = compactURL(strings.Trim(strings.TrimSpace(target), `'"`))
+	return strings.HasPrefix(target, "#") || p.external && safeScheme(target)
+}
+
+// css sanitizes a style sheet, style attribute or presentation attribute
+// value. CSS escapes can spell anything, so CSS holding a backslash is
+// dropped entirely. @import rules are removed, url() values the
+// sanitizer doesn't keep and functions other than cssFunctions are
+// replaced with none.
+func (p sanitizer) css(s string) string {
+	s = cssComment.ReplaceAllString(s, "")
+	if strings.ContainsRune(s, '\\') {
+		return ""
+	}
+	s = cssImport.ReplaceAllString(s, "")
+
+	var out strings.Builder
+	for {
+		open := strings.IndexByte(s, '(')
+		if open < 0 {
+			out.WriteString(s)
+			return out.String()
+		}
+		start := open
+		for start > 0 && isCSSNameByte(s[start-1]) {
+			start--
+		}
+		name := strings.ToLower(s[start:open])
+		out.WriteString(s[:start])
+		end := closingParen(s, open)
+		if end < 0 {
+			out.WriteString("none")
+			return out.String()
+		}
+		switch {
+		case name == "url":
+			if p.url(s[open+1 : end-1]) {
+				out.WriteString(s[start:end])
+			} else {
+				out.WriteString("none")
+			}
+		case name == "" || cssFunctions[name]:
+			out.WriteString(s[start : open+1])
+			out.WriteString(p.css(s[open+1 : end-1]))
+			out.WriteString(")")
+		default:
+			out.WriteString("none")
+		}
+		s = s[end:]
+	}
+}
+
+// closingParen returns the index after the parenthesis closing the one at
+// open, skipping quoted strings, or -1 if it is not closed.
+func closingParen(s string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// isCSSNameByte reports whether c can be part of a CSS function name.
+func isCSSNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// animatesUnsafe reports whether an animation of the attribute target
+// could insert a link or an event handler.
+func animatesUnsafe(target string) bool {
+	local := strings.ToLower(splitName(strings.TrimSpace(target)).Local)
+	return local == "href" || strings.HasPrefix(local, "on")
+}
+
+// compactURL lowercases a URL and removes the whitespace and control
+// characters browsers ignore in it.
+func compactURL(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+// safeScheme reports whether a compacted URL has no scheme or one that
+// cannot run script or embed a document.
+func safeScheme(url string) bool {
+	scheme, _, ok := strings.Cut(url, ":")
+	if !ok || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	return scheme != "javascript" && scheme != "vbscript" && scheme != "data"
+}
+
+// declaredPrefix reports whether an attribute declares a namespace, and
+// the prefix it declares ("" for the default namespace).
+func declaredPrefix(name string) (prefix string, ok bool) {
+	if name == "xmlns" {
+		return "", true
+	}
+	return strings.CutPrefix(name, "xmlns:")
+}
+
+// resolveNS returns the namespace of an element with the prefix.
+func resolveNS(scope map[string]string, prefix string) string {
+	if prefix == "xml" {
+		return xmlNS
+	}
+	return scope[prefix]
+}
+
+// defaultScope is the scope assumed around documents and fragments, as
+// SVG embedded in HTML has it: documents written without namespace
+// declarations are still SVG.
+var defaultScope = map[string]string{"": svgNS, "xlink": xlinkNS}
+
+// sanitizeFragment sanitizes a fragment of SVG content, returning an
+// error if it is not well-formed.
+func (p sanitizer) sanitizeFragment(fragment string) (string, error) {
+	_, root, _, err := parseElementTree([]byte("<svg>" + fragment + "</svg>"))
+	if err != nil {
+		return "", err
+	}
+	if err := p.sanitize(root, defaultScope); err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	w := svgwriter.New(&out)
+	for _, c := range root.Children {
+		writeElementTree(w, c)
+	}
+	if err := w.Err(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
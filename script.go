@@ -0,0 +1,92 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+// ErrScriptsDisabled is returned by AddScript unless scripts are enabled
+// with WithScripts.
+var ErrScriptsDisabled = errors.New("svg: scripts are not enabled")
+
+// WithScripts enables AddScript. Scripts are off by default, since an SVG
+// with scripts runs them when opened as a document, and exports are often
+// served to or opened by users who did not write them.
+func WithScripts(enabled bool) Option {
+	return func(b *Backend) {
+		b.scriptsEnabled = enabled
+	}
+}
+
+// WithNoScript guarantees that the written document contains no script:
+// after optimizer passes and post-processors, the document is parsed and
+// only known SVG elements and attributes are kept, as WithUntrusted does,
+// wherever the content came from (AddScript, WriteRaw, OnElement or a
+// post-processor). <script>, <foreignObject>, elements in other
+// namespaces, event handler attributes, javascript: and data: links and
+// CSS escapes are removed. Unlike WithUntrusted, references to external
+// resources are kept. AddScript returns ErrScriptsDisabled even if
+// WithScripts is given, and DrawForeignObject fails.
+//
+// The document is buffered in memory before being written, and WriteTo
+// fails if it is not well-formed XML.
+func WithNoScript(enabled bool) Option {
+	return func(b *Backend) {
+		b.noScript = enabled
+	}
+}
+
+// AddScript appends a <script> element with the JavaScript source js to
+// the document, for interactive standalone SVGs such as dashboards. It
+// returns ErrScriptsDisabled unless the backend was created with
+// WithScripts and without WithNoScript or WithUntrusted.
+//
+// Scripts are written at the end of the document, so they run once the
+// drawing is in the DOM. Like the title, they are not reset by Begin.
+func (b *Backend) AddScript(js string) error {
+	if !b.scriptsEnabled || b.scriptless() {
+		return ErrScriptsDisabled
+	}
+	b.scripts = append(b.scripts, js)
+	return nil
+}
+
+// scriptBlock returns the <script> elements added with AddScript.
+func (b *Backend) scriptBlock() string {
+	if b.scriptless() {
+		return ""
+	}
+	var s strings.Builder
+	for _, js := range b.scripts {
+		// "]]>" cannot appear inside a CDATA section; split it across two.
+		s.WriteString("<script><![CDATA[" + strings.ReplaceAll(js, "]]>", "]]]]><![CDATA[>") + "]]></script>\n")
+	}
+	return s.String()
+}
+
+// scrub sanitizes a serialized document, removing references to external
+// resources too with WithUntrusted.
+func (b *Backend) scrub(data []byte) ([]byte, error) {
+	prolog, root, epilog, err := parseElementTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("svg: parsing document for sanitization: %w", err)
+	}
+	if err := b.sanitizer().sanitize(root, defaultScope); err != nil {
+		return nil, fmt.Errorf("svg: sanitizing document: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	buf.Write(prolog)
+	out := svgwriter.New(&buf)
+	writeElementTree(out, root)
+	if err := out.Err(); err != nil {
+		return nil, err
+	}
+	buf.Write(epilog)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestAddScript(t *testing.T) {
+	if err := NewBackend().AddScript("alert(1)"); !errors.Is(err, ErrScriptsDisabled) {
+		t.Errorf("AddScript without WithScripts: err = %v", err)
+	}
+
+	backend := NewBackend(WithScripts(true))
+	if err := backend.AddScript(`if (a]]>b) update()`); err != nil {
+		t.Fatal(err)
+	}
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	_ = backend.End()
+
+	svg := writeSVG(t, backend)
+	want := "<script><![CDATA[if (a]]]]><![CDATA[>b) update()]]></script>\n\n</svg>"
+	if !strings.Contains(svg, want) {
+		t.Errorf("SVG missing %q:\n%s", want, svg)
+	}
+	if _, root, _, err := parseElementTree([]byte(svg)); err != nil || innerText(root.Children[len(root.Children)-2]) != "if (a]]>b) update()" {
+		t.Errorf("script should survive as written, err = %v", err)
+	}
+}
+
+func TestNoScript(t *testing.T) {
+	backend := NewBackend(WithScripts(true), WithNoScript(true),
+		WithPostProcessor(func(data []byte) ([]byte, error) {
+			return []byte(strings.Replace(string(data), "</svg>", `<script>late()</script>`+
+				`<foreignObject><iframe xmlns="http://www.w3.org/1999/xhtml" srcdoc="&lt;script&gt;x()&lt;/script&gt;"/></foreignObject>`+
+				`<a href="https://example.com/"><rect width="2" height="2"/></a></svg>`, 1)), nil
+		}))
+	if err := backend.AddScript("alert(1)"); !errors.Is(err, ErrScriptsDisabled) {
+		t.Errorf("AddScript in no-script mode: err = %v", err)
+	}
+	_ = backend.Begin(100, 100)
+	_ = backend.WriteRaw(`<g onload="x()"><script>alert(2)</script><a href=" javascript:y()"><rect width="1" height="1"/></a></g>`)
+	if err := backend.DrawForeignObject(0, 0, 50, 50, `<p onclick="z()">hi</p>`); err == nil {
+		t.Error("DrawForeignObject should fail in no-script mode")
+	}
+	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+		attrs["onmouseover"] = "w()"
+		return attrs
+	})
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	_ = backend.End()
+
+	svg := writeSVG(t, backend)
+	for _, unwanted := range []string{"<script", "onload", "onclick", "onmouseover", "javascript:", "foreignObject", "iframe"} {
+		if strings.Contains(svg, unwanted) {
+			t.Errorf("no-script output contains %s:\n%s", unwanted, svg)
+		}
+	}
+	if !strings.Contains(svg, `<rect width="1" height="1"/>`) || !strings.Contains(svg, `<a href="https://example.com/">`) {
+		t.Errorf("non-script content should be kept:\n%s", svg)
+	}
+}
@@ -0,0 +1,115 @@
+package svg
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// knockoutColor is the paper color used to knock out other colors in a
+// separation.
+var knockoutColor = gg.RGBA{R: 1, G: 1, B: 1, A: 1}
+
+// ExportSeparations splits a recording by color into one SVG file per
+// color, for screen-printing and vinyl-cutting workflows where each color
+// is produced separately. Files are named "separation-RRGGBB.svg" and
+// written to dir, which must exist. It returns the written paths in order
+// of first appearance of each color.
+//
+// Every separation contains the complete drawing order: shapes of the
+// separation's color keep their color while all other shapes are painted
+// white, so areas covered by later shapes are knocked out exactly as in
+// the composite. Gradients are assigned to the separation of their first
+// stop. Images cannot be separated and are omitted.
+//
+// Pattern brushes and other brushes without a color cannot be separated
+// either; their shapes are knocked out in every separation. After writing
+// all files, ExportSeparations then returns the paths with an error
+// wrapping ErrUnsupportedBrush that counts them.
+func ExportSeparations(r *recording.Recording, dir string, opts ...Option) ([]string, error) {
+	// First pass: collect colors in order of appearance.
+	var colors []string
+	seen := make(map[string]bool)
+	unseparable := 0
+	probe := NewBackend(opts...)
+	probe.RemapBrush(func(br recording.Brush) recording.Brush {
+		key, ok := probe.separationKey(br)
+		switch {
+		case !ok:
+			unseparable++
+		case !seen[key]:
+			seen[key] = true
+			colors = append(colors, key)
+		}
+		return nil
+	})
+	if err := r.Playback(separationBackend{probe}); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(colors))
+	for _, key := range colors {
+		backend := NewBackend(opts...)
+		backend.RemapBrush(func(br recording.Brush) recording.Brush {
+			if k, ok := backend.separationKey(br); ok && k == key {
+				return nil
+			}
+			return recording.NewSolidBrush(knockoutColor)
+		})
+		if err := r.Playback(separationBackend{backend}); err != nil {
+			return paths, err
+		}
+
+		path := filepath.Join(dir, "separation-"+key+".svg")
+		if err := backend.SaveToFile(path); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	if unseparable > 0 {
+		return paths, fmt.Errorf("%w: shapes with pattern or other colorless brushes knocked out of every separation: %d",
+			ErrUnsupportedBrush, unseparable)
+	}
+	return paths, nil
+}
+
+// separationBackend wraps a Backend for separation playback, dropping
+// images which cannot be split by color.
+type separationBackend struct {
+	*Backend
+}
+
+// DrawImage implements recording.Backend by omitting the image.
+func (separationBackend) DrawImage(image.Image, recording.Rect, recording.Rect, recording.ImageOptions) {
+}
+
+// separationKey returns the separation a brush belongs to as an RRGGBB
+// string, rounding channels as colors are written.
+func (b *Backend) separationKey(br recording.Brush) (string, bool) {
+	var c gg.RGBA
+	switch br := br.(type) {
+	case recording.SolidBrush:
+		c = br.Color
+	case *recording.LinearGradientBrush:
+		if len(br.Stops) == 0 {
+			return "", false
+		}
+		c = br.Stops[0].Color
+	case *recording.RadialGradientBrush:
+		if len(br.Stops) == 0 {
+			return "", false
+		}
+		c = br.Stops[0].Color
+	case *recording.SweepGradientBrush:
+		if len(br.Stops) == 0 {
+			return "", false
+		}
+		c = br.Stops[0].Color
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("%02x%02x%02x", b.channelByte(c.R), b.channelByte(c.G), b.channelByte(c.B)), true
+}
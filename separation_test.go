@@ -0,0 +1,103 @@
+package svg
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestExportSeparations(t *testing.T) {
+	rec := recording.NewRecorder(200, 100)
+	rec.SetFillRGBA(1, 0, 0, 1)
+	rec.DrawRectangle(10, 10, 100, 50)
+	rec.Fill()
+	rec.SetFillRGBA(0, 0, 1, 1)
+	rec.DrawRectangle(50, 20, 100, 50)
+	rec.Fill()
+	rec.SetFillRGBA(1, 0, 0, 1)
+	rec.DrawRectangle(0, 0, 5, 5)
+	rec.Fill()
+
+	dir := t.TempDir()
+	paths, err := ExportSeparations(rec.FinishRecording(), dir)
+	if err != nil {
+		t.Fatalf("ExportSeparations failed: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "separation-ff0000.svg"),
+		filepath.Join(dir, "separation-0000ff.svg"),
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Got %d separations, expected %d: %v", len(paths), len(expected), paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("paths[%d] = %s, expected %s", i, paths[i], expected[i])
+		}
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("Failed to read separation: %v", err)
+	}
+	red := string(data)
+	if strings.Count(red, `fill="rgb(255,0,0)"`) != 2 {
+		t.Error("Red separation should contain both red shapes")
+	}
+	if strings.Count(red, `fill="rgb(255,255,255)"`) != 1 {
+		t.Error("Red separation should knock out the blue shape in white")
+	}
+	if strings.Index(red, "rgb(255,255,255)") > strings.LastIndex(red, "rgb(255,0,0)") {
+		t.Error("Drawing order should be preserved")
+	}
+}
+
+func TestExportSeparationsRounding(t *testing.T) {
+	rec := recording.NewRecorder(10, 10)
+	rec.SetFillRGBA(0.5, 0.2, 1, 1)
+	rec.DrawRectangle(0, 0, 5, 5)
+	rec.Fill()
+	r := rec.FinishRecording()
+
+	// Separation names round channels as the written colors do.
+	paths, err := ExportSeparations(r, t.TempDir())
+	if err != nil || len(paths) != 1 || filepath.Base(paths[0]) != "separation-8033ff.svg" {
+		t.Errorf("ExportSeparations() = %v, %v, expected separation-8033ff.svg", paths, err)
+	}
+	paths, err = ExportSeparations(r, t.TempDir(), WithColorRounding(ColorTruncate))
+	if err != nil || len(paths) != 1 || filepath.Base(paths[0]) != "separation-7f33ff.svg" {
+		t.Errorf("ExportSeparations(ColorTruncate) = %v, %v, expected separation-7f33ff.svg", paths, err)
+	}
+}
+
+func TestExportSeparationsPattern(t *testing.T) {
+	rec := recording.NewRecorder(20, 20)
+	rec.SetFillRGBA(1, 0, 0, 1)
+	rec.DrawRectangle(0, 0, 10, 10)
+	rec.Fill()
+	rec.DrawImage(image.NewRGBA(image.Rect(0, 0, 2, 2)), 0, 0)
+	rec.SetFillStyle(recording.NewPatternBrush(0))
+	rec.DrawRectangle(5, 5, 10, 10)
+	rec.Fill()
+
+	paths, err := ExportSeparations(rec.FinishRecording(), t.TempDir())
+	if !errors.Is(err, ErrUnsupportedBrush) || !strings.HasSuffix(err.Error(), "separation: 1") {
+		t.Errorf("ExportSeparations should report the pattern fill, got %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("Got separations %v, expected only the red one", paths)
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `fill="rgb(255,255,255)"`) {
+		t.Errorf("The pattern fill should be knocked out, got:\n%s", data)
+	}
+}
@@ -0,0 +1,61 @@
+package svg
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithSigner(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewBackend(WithSigner(Ed25519Signer(priv)))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	_ = backend.End()
+
+	path := filepath.Join(t.TempDir(), "out.svg")
+	if err := backend.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		t.Fatalf("Signature file should be written: %v", err)
+	}
+
+	if !bytes.Equal(sig, backend.Signature()) {
+		t.Error("Signature file should match Signature()")
+	}
+	if !ed25519.Verify(pub, doc, sig) {
+		t.Error("Signature should verify against the written document")
+	}
+}
+
+func TestWithSignerError(t *testing.T) {
+	failing := SignerFunc(func([]byte) ([]byte, error) {
+		return nil, errors.New("no key")
+	})
+	backend := NewBackend(WithSigner(failing))
+	_ = backend.Begin(100, 100)
+
+	var buf bytes.Buffer
+	if _, err := backend.WriteTo(&buf); err == nil {
+		t.Error("WriteTo should fail when signing fails")
+	}
+	if buf.Len() != 0 {
+		t.Error("Nothing should be written when signing fails")
+	}
+}
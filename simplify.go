@@ -0,0 +1,355 @@
+package svg
+
+import (
+	"math"
+
+	"github.com/gogpu/gg"
+)
+
+// WithSimplify reduces the number of points written for each path while
+// keeping the output within tolerance of the drawn geometry, so plots and
+// traces sampled at thousands of points export at a readable size.
+//
+// Runs of line segments are decimated with the Ramer–Douglas–Peucker
+// algorithm. Runs of smoothly joined Bézier curves are refitted with
+// fewer cubic curves; corners between curves are kept. The tolerance is
+// in user-space units, before the element's transform. A tolerance of 0
+// or less disables simplification.
+func WithSimplify(tolerance float64) Option {
+	return func(b *Backend) {
+		b.simplifyTolerance = tolerance
+	}
+}
+
+const (
+	// curveSamples is the number of points sampled on each curve that is
+	// refitted.
+	curveSamples = 8
+
+	// cornerAngle is the smallest angle between the tangents of two joined
+	// curves that is kept as a corner, in radians.
+	cornerAngle = 10 * math.Pi / 180
+
+	// fitIterations bounds the reparameterization steps tried before a
+	// fitted curve is split.
+	fitIterations = 16
+)
+
+// simplifyPath returns path with its line runs decimated and its curve
+// runs refitted to the simplify tolerance.
+func (b *Backend) simplifyPath(path *gg.Path) *gg.Path {
+	if b.simplifyTolerance <= 0 {
+		return path
+	}
+	s := simplifier{tolerance: b.simplifyTolerance, out: gg.NewPath()}
+	for _, elem := range path.Elements() {
+		switch e := elem.(type) {
+		case gg.LineTo:
+			s.flushCurves()
+			if len(s.lines) == 0 {
+				s.lines = append(s.lines, s.current)
+			}
+			s.lines = append(s.lines, e.Point)
+			s.current = e.Point
+		case gg.QuadTo:
+			s.flushLines()
+			s.addCurve(elem, s.current.Lerp(e.Control, 2.0/3), e.Point.Lerp(e.Control, 2.0/3), e.Point)
+		case gg.CubicTo:
+			s.flushLines()
+			s.addCurve(elem, e.Control1, e.Control2, e.Point)
+		case gg.MoveTo:
+			s.flush()
+			appendElement(s.out, elem)
+			s.start, s.current = e.Point, e.Point
+		default:
+			s.flush()
+			appendElement(s.out, elem)
+			s.current = s.start
+		}
+	}
+	s.flush()
+	return s.out
+}
+
+// simplifier collects runs of lines and curves while a path is copied.
+type simplifier struct {
+	tolerance      float64
+	out            *gg.Path
+	start, current gg.Point
+	lines          []gg.Point // the run's start point and line end points
+	curves         []curve
+}
+
+// curve is a Bézier segment of a curve run, as a cubic.
+type curve struct {
+	elem           gg.PathElement
+	p0, c1, c2, p3 gg.Point
+}
+
+// point returns the point at t on the curve.
+func (c curve) point(t float64) gg.Point {
+	return cubicPoint(c.p0, c.c1, c.c2, c.p3, t)
+}
+
+// startTangent returns the direction the curve leaves its start in.
+func (c curve) startTangent() gg.Point {
+	for _, p := range []gg.Point{c.c1, c.c2, c.p3} {
+		if d := p.Sub(c.p0); d.LengthSquared() > 0 {
+			return d.Normalize()
+		}
+	}
+	return gg.Point{}
+}
+
+// endTangent returns the direction pointing back along the curve from
+// its end.
+func (c curve) endTangent() gg.Point {
+	for _, p := range []gg.Point{c.c2, c.c1, c.p0} {
+		if d := p.Sub(c.p3); d.LengthSquared() > 0 {
+			return d.Normalize()
+		}
+	}
+	return gg.Point{}
+}
+
+func (s *simplifier) addCurve(elem gg.PathElement, c1, c2, p3 gg.Point) {
+	s.curves = append(s.curves, curve{elem: elem, p0: s.current, c1: c1, c2: c2, p3: p3})
+	s.current = p3
+}
+
+func (s *simplifier) flush() {
+	s.flushLines()
+	s.flushCurves()
+}
+
+// flushLines writes the pending line run, decimated.
+func (s *simplifier) flushLines() {
+	if len(s.lines) == 0 {
+		return
+	}
+	for _, p := range decimate(s.lines, s.tolerance)[1:] {
+		s.out.LineTo(p.X, p.Y)
+	}
+	s.lines = s.lines[:0]
+}
+
+// flushCurves writes the pending curve run, refitting each part between
+// corners.
+func (s *simplifier) flushCurves() {
+	start := 0
+	for i := 1; i <= len(s.curves); i++ {
+		if i < len(s.curves) && !corner(s.curves[i-1], s.curves[i]) {
+			continue
+		}
+		s.writeCurves(s.curves[start:i])
+		start = i
+	}
+	s.curves = s.curves[:0]
+}
+
+// corner reports whether two joined curves meet at an angle.
+func corner(a, b curve) bool {
+	in, out := a.endTangent().Mul(-1), b.startTangent()
+	return math.Abs(math.Atan2(in.Cross(out), in.Dot(out))) > cornerAngle
+}
+
+// writeCurves writes a smooth chain of curves, replaced by fitted cubics
+// if that takes fewer segments.
+func (s *simplifier) writeCurves(chain []curve) {
+	if len(chain) > 1 {
+		points := make([]gg.Point, 0, len(chain)*curveSamples+1)
+		points = append(points, chain[0].p0)
+		for _, c := range chain {
+			for k := 1; k <= curveSamples; k++ {
+				points = append(points, c.point(float64(k)/curveSamples))
+			}
+		}
+		f := fitter{tolerance: s.tolerance}
+		f.fit(points, chain[0].startTangent(), chain[len(chain)-1].endTangent())
+		if len(f.cubics) < len(chain) {
+			for _, c := range f.cubics {
+				s.out.CubicTo(c.c1.X, c.c1.Y, c.c2.X, c.c2.Y, c.p3.X, c.p3.Y)
+			}
+			return
+		}
+	}
+	for _, c := range chain {
+		appendElement(s.out, c.elem)
+	}
+}
+
+// decimate returns the points of a polyline that the Ramer–Douglas–Peucker
+// algorithm keeps at tolerance. The first and last points are always kept.
+func decimate(points []gg.Point, tolerance float64) []gg.Point {
+	if len(points) < 3 {
+		return points
+	}
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		farthest, dist := 0, tolerance
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(points[i], segment{points[first], points[last]}); d > dist {
+				farthest, dist = i, d
+			}
+		}
+		if farthest > 0 {
+			keep[farthest] = true
+			stack = append(stack, [2]int{first, farthest}, [2]int{farthest, last})
+		}
+	}
+	kept := make([]gg.Point, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// fitter fits cubic Bézier curves to sampled points, following Schneider's
+// algorithm from Graphics Gems: a least-squares fit with fixed end
+// tangents, improved by Newton reparameterization while its error falls
+// and split at the point of largest error until every point is within
+// tolerance.
+type fitter struct {
+	tolerance float64
+	cubics    []curve
+}
+
+// fit fits points, leaving the first point in direction t1 and arriving
+// at the last from direction -t2.
+func (f *fitter) fit(points []gg.Point, t1, t2 gg.Point) {
+	first, last := points[0], points[len(points)-1]
+	if len(points) == 2 {
+		d := first.Distance(last) / 3
+		f.cubics = append(f.cubics, curve{p0: first, c1: first.Add(t1.Mul(d)), c2: last.Add(t2.Mul(d)), p3: last})
+		return
+	}
+
+	u := chordLengths(points)
+	c := fitCubic(points, u, t1, t2)
+	maxErr, split := fitError(points, c, u)
+	if maxErr <= f.tolerance {
+		f.cubics = append(f.cubics, c)
+		return
+	}
+	for range fitIterations {
+		nu := reparameterize(points, c, u)
+		nc := fitCubic(points, nu, t1, t2)
+		nErr, nSplit := fitError(points, nc, nu)
+		if nErr >= maxErr {
+			break
+		}
+		u, c, maxErr, split = nu, nc, nErr, nSplit
+		if maxErr <= f.tolerance {
+			f.cubics = append(f.cubics, c)
+			return
+		}
+	}
+
+	center := points[split-1].Sub(points[split+1])
+	if center.LengthSquared() == 0 {
+		center = points[split-1].Sub(points[split])
+	}
+	center = center.Normalize()
+	f.fit(points[:split+1], t1, center)
+	f.fit(points[split:], center.Mul(-1), t2)
+}
+
+// chordLengths parameterizes points by their distance along the polyline,
+// from 0 to 1.
+func chordLengths(points []gg.Point) []float64 {
+	u := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		u[i] = u[i-1] + points[i].Distance(points[i-1])
+	}
+	total := u[len(u)-1]
+	for i := range u {
+		if total > 0 {
+			u[i] /= total
+		} else {
+			u[i] = float64(i) / float64(len(u)-1)
+		}
+	}
+	return u
+}
+
+// fitCubic returns the cubic with the given end tangents that fits points
+// at parameters u best in the least-squares sense.
+func fitCubic(points []gg.Point, u []float64, t1, t2 gg.Point) curve {
+	first, last := points[0], points[len(points)-1]
+	var c00, c01, c11, x0, x1 float64
+	for i, p := range points {
+		t := u[i]
+		mt := 1 - t
+		b0, b1, b2, b3 := mt*mt*mt, 3*t*mt*mt, 3*t*t*mt, t*t*t
+		a1, a2 := t1.Mul(b1), t2.Mul(b2)
+		c00 += a1.Dot(a1)
+		c01 += a1.Dot(a2)
+		c11 += a2.Dot(a2)
+		tmp := p.Sub(first.Mul(b0 + b1)).Sub(last.Mul(b2 + b3))
+		x0 += a1.Dot(tmp)
+		x1 += a2.Dot(tmp)
+	}
+
+	var alpha1, alpha2 float64
+	if det := c00*c11 - c01*c01; det != 0 {
+		alpha1 = (x0*c11 - x1*c01) / det
+		alpha2 = (c00*x1 - c01*x0) / det
+	}
+	// Fall back to the Wu/Barsky heuristic when the fit degenerates.
+	if eps := 1e-6 * first.Distance(last); alpha1 < eps || alpha2 < eps {
+		alpha1 = first.Distance(last) / 3
+		alpha2 = alpha1
+	}
+	return curve{p0: first, c1: first.Add(t1.Mul(alpha1)), c2: last.Add(t2.Mul(alpha2)), p3: last}
+}
+
+// fitError returns the largest distance between points and the cubic at
+// parameters u, and the index of the interior point where it occurs.
+func fitError(points []gg.Point, c curve, u []float64) (float64, int) {
+	maxErr, split := 0.0, len(points)/2
+	for i := 1; i < len(points)-1; i++ {
+		if d := c.point(u[i]).Distance(points[i]); d > maxErr {
+			maxErr, split = d, i
+		}
+	}
+	return maxErr, split
+}
+
+// reparameterize moves each parameter in u closer to the parameter of the
+// nearest point on the cubic with a Newton–Raphson step.
+func reparameterize(points []gg.Point, c curve, u []float64) []float64 {
+	next := make([]float64, len(u))
+	for i, p := range points {
+		t := u[i]
+		d := c.point(t).Sub(p)
+		d1 := cubicDerivative(c, t)
+		d2 := cubicSecondDerivative(c, t)
+		denom := d1.Dot(d1) + d.Dot(d2)
+		if denom != 0 {
+			t -= d.Dot(d1) / denom
+		}
+		next[i] = math.Max(0, math.Min(1, t))
+	}
+	return next
+}
+
+// cubicPoint returns the point at t on the cubic Bézier p0, c1, c2, p3.
+func cubicPoint(p0, c1, c2, p3 gg.Point, t float64) gg.Point {
+	mt := 1 - t
+	return p0.Mul(mt * mt * mt).Add(c1.Mul(3 * mt * mt * t)).Add(c2.Mul(3 * mt * t * t)).Add(p3.Mul(t * t * t))
+}
+
+func cubicDerivative(c curve, t float64) gg.Point {
+	mt := 1 - t
+	return c.c1.Sub(c.p0).Mul(3 * mt * mt).Add(c.c2.Sub(c.c1).Mul(6 * mt * t)).Add(c.p3.Sub(c.c2).Mul(3 * t * t))
+}
+
+func cubicSecondDerivative(c curve, t float64) gg.Point {
+	return c.c2.Sub(c.c1.Mul(2)).Add(c.p0).Mul(6 * (1 - t)).Add(c.p3.Sub(c.c2.Mul(2)).Add(c.c1).Mul(6 * t))
+}
@@ -0,0 +1,105 @@
+package svg
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithSimplifyLines(t *testing.T) {
+	const tolerance = 0.1
+	path := gg.NewPath()
+	var points []gg.Point
+	for i := 0; i <= 1000; i++ {
+		x := float64(i) / 10
+		p := gg.Pt(x, 50+40*math.Sin(x/10))
+		points = append(points, p)
+		if i == 0 {
+			path.MoveTo(p.X, p.Y)
+		} else {
+			path.LineTo(p.X, p.Y)
+		}
+	}
+
+	backend := NewBackend(WithSimplify(tolerance))
+	simplified := backend.simplifyPath(path)
+	var kept []gg.Point
+	for _, elem := range simplified.Elements() {
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			kept = append(kept, e.Point)
+		case gg.LineTo:
+			kept = append(kept, e.Point)
+		default:
+			t.Fatalf("Unexpected element %T", elem)
+		}
+	}
+	if len(kept) > 100 {
+		t.Errorf("Expected the polyline to be decimated, kept %d of %d points", len(kept), len(points))
+	}
+	if kept[0] != points[0] || kept[len(kept)-1] != points[len(points)-1] {
+		t.Error("End points should be kept")
+	}
+	for _, p := range points {
+		dist := math.Inf(1)
+		for i := 1; i < len(kept); i++ {
+			dist = math.Min(dist, segmentDistance(p, segment{kept[i-1], kept[i]}))
+		}
+		if dist > tolerance+1e-9 {
+			t.Fatalf("Point %v is %g from the simplified path", p, dist)
+		}
+	}
+}
+
+func TestWithSimplifyCurves(t *testing.T) {
+	// A sine wave drawn as 40 short cubic segments.
+	const n = 40
+	f := func(x float64) gg.Point { return gg.Pt(x, 50+40*math.Sin(x/16)) }
+	df := func(x float64) float64 { return 40 * math.Cos(x/16) / 16 }
+	path := gg.NewPath()
+	path.MoveTo(f(0).X, f(0).Y)
+	for i := range n {
+		x0, x1 := float64(i)*2.5, float64(i+1)*2.5
+		h := (x1 - x0) / 3
+		p0, p3 := f(x0), f(x1)
+		path.CubicTo(x0+h, p0.Y+h*df(x0), x1-h, p3.Y-h*df(x1), p3.X, p3.Y)
+	}
+
+	const tolerance = 0.05
+	simplified := NewBackend(WithSimplify(tolerance)).simplifyPath(path)
+	cubics := 0
+	for _, elem := range simplified.Elements() {
+		c, ok := elem.(gg.CubicTo)
+		if !ok {
+			continue
+		}
+		cubics++
+		// The wave's slope is at most 2.5, which bounds the vertical
+		// distance of a point within tolerance of it.
+		if p := c.Point; math.Abs(p.Y-f(p.X).Y) > 3*tolerance {
+			t.Errorf("Segment end %v should lie near the curve", p)
+		}
+	}
+	if cubics == 0 || cubics >= n/4 {
+		t.Errorf("Expected the curve chain to be refitted with fewer cubics, got %d", cubics)
+	}
+}
+
+func TestWithSimplifyCorners(t *testing.T) {
+	path := gg.NewPath()
+	path.MoveTo(0, 0)
+	path.CubicTo(10, 0, 20, 0, 30, 0)
+	path.CubicTo(30, 10, 30, 20, 30, 30)
+	path.LineTo(0, 30)
+	path.Close()
+
+	backend := NewBackend(WithSimplify(1))
+	_ = backend.Begin(100, 100)
+	backend.FillPath(path, recording.NewSolidBrush(gg.Red), recording.FillRuleNonZero)
+	if out := backend.String(); !strings.Contains(out, `d="M0 0C10 0 20 0 30 0C30 10 30 20 30 30L0 30Z"`) {
+		t.Errorf("Curves meeting at a corner should be kept:\n%s", out)
+	}
+}
@@ -0,0 +1,114 @@
+package svg
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// WithSketch gives filled, stroked and clip shapes a hand-drawn look:
+// points are displaced by up to roughness user units and straight lines
+// are bent into slight curves. Rectangles are written as paths so they are
+// sketched too. The displacement is pseudo-random but seeded, so exports
+// of the same recording are identical. A roughness of 0 or less disables
+// the pass.
+func WithSketch(roughness float64, seed uint64) Option {
+	return func(b *Backend) {
+		b.sketchRoughness = roughness
+		b.sketchSeed = seed
+	}
+}
+
+// sketching reports whether the sketch pass is enabled.
+func (b *Backend) sketching() bool {
+	return b.sketchRoughness > 0
+}
+
+// resetSketch restarts the sketch displacement sequence for a new export.
+func (b *Backend) resetSketch() {
+	if b.sketching() {
+		b.sketchPCG = rand.NewPCG(b.sketchSeed, 0)
+		b.sketchRNG = rand.New(b.sketchPCG)
+	}
+}
+
+// sketchPath returns path with displaced points and bent lines.
+func (b *Backend) sketchPath(path *gg.Path) *gg.Path {
+	if !b.sketching() {
+		return path
+	}
+	if b.sketchRNG == nil {
+		b.resetSketch()
+	}
+	result := gg.NewPath()
+	var start, current gg.Point
+	line := func(end gg.Point) {
+		c1 := b.bend(current, end, 1.0/3)
+		c2 := b.bend(current, end, 2.0/3)
+		result.CubicTo(c1.X, c1.Y, c2.X, c2.Y, end.X, end.Y)
+		current = end
+	}
+	for _, elem := range path.Elements() {
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			current = b.jitter(e.Point, 0.5)
+			start = current
+			result.MoveTo(current.X, current.Y)
+		case gg.LineTo:
+			line(b.jitter(e.Point, 0.5))
+		case gg.QuadTo:
+			c := b.jitter(e.Control, 1)
+			current = b.jitter(e.Point, 0.5)
+			result.QuadraticTo(c.X, c.Y, current.X, current.Y)
+		case gg.CubicTo:
+			c1 := b.jitter(e.Control1, 1)
+			c2 := b.jitter(e.Control2, 1)
+			current = b.jitter(e.Point, 0.5)
+			result.CubicTo(c1.X, c1.Y, c2.X, c2.Y, current.X, current.Y)
+		case gg.Close:
+			// The closing line is bent like the others.
+			if current != start {
+				line(start)
+			}
+			result.Close()
+		}
+	}
+	return result
+}
+
+// jitter displaces p by up to scale times the roughness on each axis.
+func (b *Backend) jitter(p gg.Point, scale float64) gg.Point {
+	r := b.sketchRoughness * scale
+	return roundPoint(p.X+r*(2*b.sketchRNG.Float64()-1), p.Y+r*(2*b.sketchRNG.Float64()-1))
+}
+
+// bend returns the point at t along the line from p to q, displaced
+// perpendicular to it by up to the roughness. Short lines bend less.
+func (b *Backend) bend(p, q gg.Point, t float64) gg.Point {
+	dx, dy := q.X-p.X, q.Y-p.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return p
+	}
+	offset := b.sketchRoughness * math.Min(1, length/(10*b.sketchRoughness)) * (2*b.sketchRNG.Float64() - 1)
+	return roundPoint(p.X+t*dx-dy/length*offset, p.Y+t*dy+dx/length*offset)
+}
+
+// roundPoint rounds sketched coordinates to hundredths; the displacement
+// is random anyway, and full precision only bloats the output.
+func roundPoint(x, y float64) gg.Point {
+	return gg.Point{X: math.Round(x*100) / 100, Y: math.Round(y*100) / 100}
+}
+
+// rectPath returns a closed path around rect.
+func rectPath(rect recording.Rect) *gg.Path {
+	path := gg.NewPath()
+	path.MoveTo(rect.MinX, rect.MinY)
+	path.LineTo(rect.MaxX, rect.MinY)
+	path.LineTo(rect.MaxX, rect.MaxY)
+	path.LineTo(rect.MinX, rect.MaxY)
+	path.Close()
+	return path
+}
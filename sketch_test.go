@@ -0,0 +1,55 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithSketch(t *testing.T) {
+	draw := func(opts ...Option) string {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 100)
+		backend.FillRect(recording.NewRect(10, 10, 30, 30), recording.NewSolidBrush(gg.Red))
+		backend.StrokePath(line(0, 0, 100, 100), recording.NewSolidBrush(gg.Black), recording.DefaultStroke())
+		return writeSVG(t, backend)
+	}
+
+	plain := draw()
+	if !strings.Contains(plain, "<rect") || !strings.Contains(plain, `d="M0 0L100 100"`) {
+		t.Fatalf("Unsketched output should be exact, got:\n%s", plain)
+	}
+
+	sketched := draw(WithSketch(2, 1))
+	if strings.Contains(sketched, "<rect") || strings.Contains(sketched, "L") || strings.Count(sketched, "C") < 5 {
+		t.Errorf("Sketched shapes should be curved paths, got:\n%s", sketched)
+	}
+	if again := draw(WithSketch(2, 1)); again != sketched {
+		t.Error("Sketches with the same seed should be identical")
+	}
+	if other := draw(WithSketch(2, 2)); other == sketched {
+		t.Error("Sketches with different seeds should differ")
+	}
+}
+
+func TestSketchBounds(t *testing.T) {
+	backend := NewBackend(WithSketch(1, 3))
+	_ = backend.Begin(100, 100)
+	path := backend.sketchPath(line(0, 0, 100, 0))
+	for _, elem := range path.Elements() {
+		var points []gg.Point
+		switch e := elem.(type) {
+		case gg.MoveTo:
+			points = []gg.Point{e.Point}
+		case gg.CubicTo:
+			points = []gg.Point{e.Control1, e.Control2, e.Point}
+		}
+		for _, p := range points {
+			if p.Y < -1 || p.Y > 1 {
+				t.Errorf("Point %v is displaced more than the roughness", p)
+			}
+		}
+	}
+}
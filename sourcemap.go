@@ -0,0 +1,81 @@
+package svg
+
+import (
+	"fmt"
+
+	"github.com/gogpu/gg/recording"
+)
+
+// WithSourceMap annotates every drawn element with a data-gg-op attribute
+// holding the index of the command that produced it, so a node in a
+// misbehaving export can be traced back to its draw call.
+//
+// When the recording is replayed with Backend.Playback the index refers to
+// Recording.Commands. Otherwise it counts backend calls since Begin.
+func WithSourceMap() Option {
+	return func(b *Backend) {
+		b.sourceMap = true
+	}
+}
+
+// Playback replays r into the backend. Unlike r.Playback(b), it lets
+// WithSourceMap report indices into r.Commands and WithProvenance record
+// a hash of r.
+func (b *Backend) Playback(r *recording.Recording) error {
+	b.source = r
+	defer func() { b.source = nil }()
+	return r.Playback(b)
+}
+
+// advanceOp moves to the next backend call, named method. The op index
+// is used by the source map and in error reports.
+func (b *Backend) advanceOp(method string) {
+	b.opMethod = method
+	if b.source == nil {
+		b.opIndex++
+		return
+	}
+	commands := b.source.Commands()
+
+	// Style commands are folded into draw commands during playback and
+	// never reach the backend.
+	for b.sourceCursor < len(commands) && !dispatched(commands[b.sourceCursor]) {
+		b.sourceCursor++
+	}
+	b.opIndex = b.sourceCursor
+	b.sourceCursor++
+}
+
+// sourceText returns the recorded command of the DrawText call being
+// played back by Playback. Playback passes no face, so the recorded font
+// family and size are only available from the command.
+func (b *Backend) sourceText() (recording.DrawTextCommand, bool) {
+	if b.source == nil || b.opMethod != "DrawText" {
+		return recording.DrawTextCommand{}, false
+	}
+	commands := b.source.Commands()
+	if b.opIndex < 0 || b.opIndex >= len(commands) {
+		return recording.DrawTextCommand{}, false
+	}
+	c, ok := commands[b.opIndex].(recording.DrawTextCommand)
+	return c, ok
+}
+
+// writeSourceOp writes the data-gg-op attribute for the current element.
+func (b *Backend) writeSourceOp() {
+	if b.sourceMap {
+		b.builder.WriteString(fmt.Sprintf(` data-gg-op="%d"`, b.opIndex))
+	}
+}
+
+// dispatched reports whether Recording.Playback turns cmd into a backend call.
+func dispatched(cmd recording.Command) bool {
+	switch cmd.(type) {
+	case recording.SaveCommand, recording.RestoreCommand,
+		recording.SetTransformCommand, recording.SetClipCommand, recording.ClearClipCommand,
+		recording.FillPathCommand, recording.StrokePathCommand, recording.FillRectCommand,
+		recording.DrawImageCommand, recording.DrawTextCommand:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithSourceMapPlayback(t *testing.T) {
+	rec := recording.NewRecorder(100, 100)
+	rec.SetFillRGBA(1, 0, 0, 1)
+	rec.DrawRectangle(0, 0, 10, 10)
+	rec.Fill()
+	rec.SetLineWidth(2)
+	rec.SetStrokeRGBA(0, 0, 1, 1)
+	rec.DrawLine(0, 0, 50, 50)
+	rec.Stroke()
+	r := rec.FinishRecording()
+
+	backend := NewBackend(WithSourceMap())
+	if err := backend.Playback(r); err != nil {
+		t.Fatalf("Playback failed: %v", err)
+	}
+	svg := writeSVG(t, backend)
+
+	for i, cmd := range r.Commands() {
+		switch cmd.(type) {
+		case recording.FillPathCommand, recording.StrokePathCommand:
+			expected := fmt.Sprintf(`data-gg-op="%d"`, i)
+			if !strings.Contains(svg, expected) {
+				t.Errorf("Output should contain %s, got:\n%s", expected, svg)
+			}
+		}
+	}
+}
+
+func TestWithSourceMapDirectCalls(t *testing.T) {
+	backend := NewBackend(WithSourceMap())
+	_ = backend.Begin(100, 100)
+	backend.Save()
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	backend.Restore()
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<rect data-gg-op="1"`) {
+		t.Errorf("Rect should carry the backend call index 1, got:\n%s", svg)
+	}
+
+	plain := NewBackend()
+	_ = plain.Begin(100, 100)
+	plain.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	if svg := writeSVG(t, plain); strings.Contains(svg, "data-gg-op") {
+		t.Error("Source map attributes should be off by default")
+	}
+}
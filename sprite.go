@@ -0,0 +1,117 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+	"github.com/gogpu/gg/recording"
+)
+
+// SpriteBuilder combines recordings into one SVG sprite sheet of <symbol>
+// elements, for icon systems that reference icons with
+// <use href="sprite.svg#name">.
+//
+//	sprites := svg.NewSpriteBuilder()
+//	_ = sprites.Add("check", checkIcon)
+//	_ = sprites.Add("close", closeIcon)
+//	err := sprites.SaveToFile("icons.svg")
+type SpriteBuilder struct {
+	opts    []Option
+	names   map[string]bool
+	prolog  []byte
+	root    *Element
+	symbols []*Element
+}
+
+// NewSpriteBuilder returns a SpriteBuilder that exports each recording
+// with a Backend configured with opts.
+func NewSpriteBuilder(opts ...Option) *SpriteBuilder {
+	return &SpriteBuilder{opts: opts, names: make(map[string]bool)}
+}
+
+// Add exports r as the symbol with the given id. The symbol's viewBox is
+// the recording's canvas. IDs and style classes generated for the symbol
+// get name and "-" appended to the ID prefix, so symbols never collide;
+// IDs set with SetNextAttrs or WriteRaw are kept as they are.
+func (s *SpriteBuilder) Add(name string, r *recording.Recording) error {
+	if name == "" || strings.ContainsAny(name, " \t\r\n\"'#") {
+		return fmt.Errorf("svg: invalid sprite name %q", name)
+	}
+	if s.names[name] {
+		return fmt.Errorf("svg: duplicate sprite name %q", name)
+	}
+
+	prolog, root, err := exportElementTree(r, s.opts, name+"-")
+	if err != nil {
+		return fmt.Errorf("svg: sprite %q: %w", name, err)
+	}
+
+	symbol := &Element{Name: "symbol", Attrs: []Attr{{Name: "id", Value: name}}, Children: root.Children}
+	for _, a := range root.Attrs {
+		switch {
+		case a.Name == "xmlns" || strings.HasPrefix(a.Name, "xmlns:"),
+			a.Name == "width", a.Name == "height", a.Name == "version", a.Name == "id":
+		default:
+			symbol.Attrs = append(symbol.Attrs, a)
+		}
+	}
+	if _, ok := symbol.Attr("viewBox"); !ok {
+		symbol.SetAttr("viewBox", fmt.Sprintf("0 0 %d %d", r.Width(), r.Height()))
+	}
+
+	if s.root == nil {
+		s.prolog = prolog
+		s.root = &Element{Name: "svg"}
+	}
+	// Symbols may use namespaces the first one did not declare.
+	for _, a := range root.Attrs {
+		if a.Name == "xmlns" || strings.HasPrefix(a.Name, "xmlns:") {
+			s.root.SetAttr(a.Name, a.Value)
+		}
+	}
+	s.names[name] = true
+	s.symbols = append(s.symbols, symbol, &Element{Text: "\n"})
+	return nil
+}
+
+// Len returns the number of symbols added.
+func (s *SpriteBuilder) Len() int {
+	return len(s.names)
+}
+
+// WriteTo writes the sprite sheet. The XML declaration and namespaces
+// are those of the first symbol's export.
+func (s *SpriteBuilder) WriteTo(w io.Writer) (int64, error) {
+	if s.root == nil {
+		return 0, errors.New("svg: sprite sheet has no symbols")
+	}
+	var buf bytes.Buffer
+	buf.Write(s.prolog)
+	out := svgwriter.New(&buf)
+	s.root.Children = append([]*Element{{Text: "\n"}}, s.symbols...)
+	writeElementTree(out, s.root)
+	if err := out.Err(); err != nil {
+		return 0, err
+	}
+	buf.WriteString("\n")
+	return buf.WriteTo(w)
+}
+
+// SaveToFile writes the sprite sheet to a file.
+func (s *SpriteBuilder) SaveToFile(path string) error {
+	f, err := os.Create(path) //nolint:gosec // Path is provided by user code
+	if err != nil {
+		return err
+	}
+	_, writeErr := s.WriteTo(f)
+	closeErr := f.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	return closeErr
+}
@@ -0,0 +1,60 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func spriteIcon(size int) *recording.Recording {
+	rec := recording.NewRecorder(size, size)
+	rec.SetFillRGBA(0, 0, 1, 1)
+	rec.DrawCircle(float64(size)/2, float64(size)/2, float64(size)/4)
+	rec.Fill()
+	return rec.FinishRecording()
+}
+
+func TestSpriteBuilder(t *testing.T) {
+	sprites := NewSpriteBuilder(WithStyleMode(StyleClasses))
+	if err := sprites.Add("check", spriteIcon(24)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sprites.Add("close", spriteIcon(16)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sprites.Add("check", spriteIcon(24)); err == nil {
+		t.Error("duplicate name should be rejected")
+	}
+	if err := sprites.Add("a b", spriteIcon(24)); err == nil {
+		t.Error("invalid name should be rejected")
+	}
+	if sprites.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", sprites.Len())
+	}
+
+	var s strings.Builder
+	if _, err := sprites.WriteTo(&s); err != nil {
+		t.Fatal(err)
+	}
+	out := s.String()
+	for _, want := range []string{
+		`<symbol id="check" viewBox="0 0 24 24">`,
+		`<symbol id="close" viewBox="0 0 16 16">`,
+		`.check-s1{`, `.close-s1{`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("sprite missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "<svg") != 1 || strings.Contains(out, `width="24"`) {
+		t.Errorf("symbols should not keep their root elements:\n%s", out)
+	}
+}
+
+func TestSpriteBuilderEmpty(t *testing.T) {
+	var s strings.Builder
+	if _, err := NewSpriteBuilder().WriteTo(&s); err == nil {
+		t.Error("empty sprite sheet should be an error")
+	}
+}
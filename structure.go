@@ -0,0 +1,141 @@
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Structure selects how drawn elements are arranged in the document.
+type Structure int
+
+const (
+	// StructureInterleaved emits elements in drawing order, with
+	// Save/Restore mapped to nested groups. This is the default.
+	StructureInterleaved Structure = iota
+	// StructureByOp collects elements into one labeled group per operation
+	// type: images, fills, strokes and text, in that stacking order.
+	// Drawing order is preserved within each group. Save/Restore groups
+	// are not emitted since transforms and clips are per-element attributes.
+	//
+	// Engraving, plotting and print-separation workflows need this
+	// ordering, which cannot be reconstructed from interleaved output.
+	StructureByOp
+	// StructureLaser collects elements into engrave, score and cut groups,
+	// in that order, as classified by WithLaserClassifier.
+	StructureLaser
+)
+
+// String returns the name of the structure.
+func (s Structure) String() string {
+	switch s {
+	case StructureByOp:
+		return "by-op"
+	case StructureLaser:
+		return "laser"
+	default:
+		return "interleaved"
+	}
+}
+
+// elementKind classifies drawn elements by the operation that produced them.
+type elementKind int
+
+const (
+	kindImage elementKind = iota
+	kindFill
+	kindStroke
+	kindText
+	kindCount
+)
+
+// elementKindNames maps element kinds to their group IDs.
+var elementKindNames = [kindCount]string{
+	kindImage:  "images",
+	kindFill:   "fills",
+	kindStroke: "strokes",
+	kindText:   "text",
+}
+
+// WithStructure selects the document structure.
+func WithStructure(s Structure) Option {
+	return func(b *Backend) {
+		b.structure = s
+	}
+}
+
+// layerNames returns the group IDs of the layers used by the structure.
+func (b *Backend) layerNames() []string {
+	switch b.structure {
+	case StructureByOp:
+		return elementKindNames[:]
+	case StructureLaser:
+		return laserOpNames[:]
+	default:
+		return nil
+	}
+}
+
+// resetLayers empties the layer buffers, keeping their capacity.
+func (b *Backend) resetLayers() {
+	n := len(b.layerNames())
+	if len(b.layers) != n {
+		b.layers = make([]bytes.Buffer, n)
+	}
+	for i := range b.layers {
+		b.layers[i].Reset()
+	}
+}
+
+// closeElement finishes the element started by the last openElement
+// call, applying the element hook, style mode and budget. With a layered
+// structure the element is moved to its layer.
+func (b *Backend) closeElement(kind elementKind) {
+	if !b.rewriteCurrentElement() || !b.withinBudget() {
+		return
+	}
+	if b.structure == StructureInterleaved {
+		if !b.mergePath() {
+			b.addChild(1)
+		}
+		return
+	}
+	b.closeElementLink()
+
+	layer := int(kind)
+	if b.structure == StructureLaser {
+		layer = b.elementLayer
+	}
+	if layer >= 0 && layer < len(b.layers) {
+		b.layers[layer].Write(b.builder.Bytes()[b.elementStart:])
+	}
+	b.builder.Truncate(b.elementStart)
+}
+
+// writeLayers writes the layer groups collected with a layered structure.
+func (b *Backend) writeLayers(w io.Writer) (int64, error) {
+	var total int64
+	names := b.layerNames()
+	for i := range b.layers {
+		layer := &b.layers[i]
+		if layer.Len() == 0 {
+			continue
+		}
+		n, err := fmt.Fprintf(w, `<g id="%s%s">`, b.idPrefix, names[i])
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		n, err = w.Write(layer.Bytes())
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		n, err = w.Write([]byte("</g>"))
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
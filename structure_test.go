@@ -0,0 +1,51 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestStructureByOp(t *testing.T) {
+	backend := NewBackend(WithStructure(StructureByOp))
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	path := gg.NewPath()
+	path.Rectangle(10, 10, 50, 50)
+	brush := recording.NewSolidBrush(gg.RGBA{R: 1, G: 0, B: 0, A: 1})
+
+	backend.Save()
+	backend.DrawText("first", 0, 10, nil, brush)
+	backend.StrokePath(path, brush, recording.DefaultStroke())
+	backend.FillPath(path, brush, recording.FillRuleNonZero)
+	backend.Restore()
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), brush)
+	backend.DrawText("second", 0, 20, nil, brush)
+
+	svg := writeSVG(t, backend)
+	fills := strings.Index(svg, `<g id="fills"><path`)
+	strokes := strings.Index(svg, `<g id="strokes"><path`)
+	texts := strings.Index(svg, `<g id="text"><text`)
+	if fills < 0 || strokes < 0 || texts < 0 {
+		t.Fatalf("Output should contain fills, strokes and text groups:\n%s", svg)
+	}
+	if fills >= strokes || strokes >= texts {
+		t.Error("Groups should be ordered fills, strokes, text")
+	}
+	if strings.Contains(svg, `<g id="images">`) {
+		t.Error("Empty groups should be omitted")
+	}
+	if !strings.Contains(svg, `stroke="none"/><rect`) {
+		t.Error("Drawing order should be preserved within a group")
+	}
+	if strings.Index(svg, ">first<") > strings.Index(svg, ">second<") {
+		t.Error("Text order should be preserved")
+	}
+	if strings.Contains(svg, "<g><") {
+		t.Error("Save/Restore groups should not be emitted")
+	}
+}
@@ -0,0 +1,138 @@
+package svg
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StyleMode selects how fill, stroke and font properties are written.
+type StyleMode int
+
+const (
+	// StyleAttributes writes presentation attributes such as fill="..."
+	// on every element. This is the default.
+	StyleAttributes StyleMode = iota
+	// StyleClasses collects each distinct combination of style properties
+	// into a CSS class in a <style> block and references it with class=.
+	// Documents where thousands of elements share a handful of styles
+	// shrink considerably.
+	//
+	// CSS in inlined SVG applies to the whole HTML page; combine with
+	// WithIDPrefix, which also prefixes class names, when inlining
+	// several documents.
+	StyleClasses
+	// StyleInline writes the style properties of each element as a single
+	// style="fill:...;stroke:..." attribute, for toolchains that ignore
+	// presentation attributes. Content Security Policies that forbid
+	// inline styles reject this mode; use StyleAttributes there.
+	StyleInline
+)
+
+// String returns the name of the style mode.
+func (m StyleMode) String() string {
+	switch m {
+	case StyleClasses:
+		return "classes"
+	case StyleInline:
+		return "inline"
+	default:
+		return "attributes"
+	}
+}
+
+// styleProperties are the presentation attributes moved into CSS, in the
+// order they are written.
+var styleProperties = []string{
+	"fill", "fill-opacity", "fill-rule",
+	"stroke", "stroke-opacity", "stroke-width", "stroke-linecap", "stroke-linejoin",
+	"stroke-miterlimit", "stroke-dasharray", "stroke-dashoffset",
+	"font-family", "font-weight", "font-style", "font-size",
+}
+
+// WithStyleMode selects how style properties are written.
+func WithStyleMode(m StyleMode) Option {
+	return func(b *Backend) {
+		b.styleMode = m
+	}
+}
+
+// styleDeclarations removes the style properties from attrs and returns
+// them as CSS declarations, e.g. "fill:red;stroke:none". If varsOnly is
+// true, only properties whose value uses a CSS variable are moved.
+func styleDeclarations(names []string, attrs map[string]string, varsOnly bool) string {
+	var decls []string
+	for _, name := range names {
+		if v, ok := attrs[name]; ok && slices.Contains(styleProperties, name) {
+			if varsOnly && !strings.Contains(v, "var(") {
+				continue
+			}
+			// Unlike the presentation attribute, the CSS property needs a unit.
+			if _, err := strconv.ParseFloat(v, 64); err == nil && name == "font-size" {
+				v += "px"
+			}
+			decls = append(decls, name+":"+v)
+			delete(attrs, name)
+		}
+	}
+	return strings.Join(decls, ";")
+}
+
+// styleClass returns the CSS class for a declaration block, registering a
+// new class on first use.
+func (b *Backend) styleClass(decls string) string {
+	if class, ok := b.styleClasses[decls]; ok {
+		return class
+	}
+	if b.styleClasses == nil {
+		b.styleClasses = make(map[string]string)
+	}
+	class := fmt.Sprintf("%ss%d", b.idPrefix, len(b.styleRules)+1)
+	b.styleClasses[decls] = class
+	b.styleRules = append(b.styleRules, decls)
+	return class
+}
+
+// applyStyleMode rewrites the style properties in attrs for the style mode.
+func (b *Backend) applyStyleMode(names []string, attrs map[string]string) {
+	if b.tiny() {
+		return
+	}
+	// CSS variables only resolve in CSS, so themed properties always
+	// leave their presentation attributes.
+	decls := styleDeclarations(names, attrs, b.styleMode == StyleAttributes)
+	if decls == "" {
+		return
+	}
+	if b.styleMode != StyleClasses {
+		if existing := attrs["style"]; existing != "" {
+			decls = existing + ";" + decls
+		}
+		attrs["style"] = decls
+		return
+	}
+	class := b.styleClass(decls)
+	if existing := attrs["class"]; existing != "" {
+		class = existing + " " + class
+	}
+	attrs["class"] = class
+}
+
+// styleBlock returns the <style> element holding the collected classes
+// and animations, or "" if there are none.
+func (b *Backend) styleBlock() string {
+	if len(b.styleRules) == 0 && len(b.animationRules) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString("<style>")
+	for i, decls := range b.styleRules {
+		s.WriteString(fmt.Sprintf(".%ss%d{%s}", b.idPrefix, i+1, escapeXML(decls)))
+	}
+	for _, rule := range b.animationRules {
+		s.WriteString(escapeXML(rule))
+	}
+	s.WriteString("</style>\n")
+	return s.String()
+}
@@ -0,0 +1,74 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithStyleModeClasses(t *testing.T) {
+	backend := NewBackend(WithStyleMode(StyleClasses))
+	_ = backend.Begin(100, 100)
+	red := recording.NewSolidBrush(gg.Red)
+	for i := range 3 {
+		backend.FillRect(recording.NewRect(float64(i*10), 0, 5, 5), red)
+	}
+	backend.SetNextAttrs("", "user")
+	backend.StrokePath(line(0, 0, 10, 10), red, recording.DefaultStroke())
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`<style>.s1{fill:rgb(255,0,0);stroke:none}.s2{fill:none;stroke:rgb(255,0,0);stroke-width:1;stroke-linecap:butt;stroke-linejoin:miter;stroke-miterlimit:4}</style>`,
+		`<rect x="20" y="0" width="5" height="5" class="s1"/>`,
+		`<path class="user s2" d="M0 0L10 10"/>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if n := strings.Count(svg, `class="s1"`); n != 3 {
+		t.Errorf("All rects should share class s1, got %d", n)
+	}
+
+	// Classes are reset by Begin.
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Blue))
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `<style>.s1{fill:rgb(0,0,255);stroke:none}</style>`) {
+		t.Errorf("Classes should restart after Begin, got:\n%s", svg)
+	}
+}
+
+func TestWithStyleModeClassesPrefix(t *testing.T) {
+	backend := NewBackend(WithStyleMode(StyleClasses), WithIDPrefix("c1-"))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `.c1-s1{`) || !strings.Contains(svg, `class="c1-s1"`) {
+		t.Errorf("Class names should carry the ID prefix, got:\n%s", svg)
+	}
+}
+
+func TestWithStyleModeInline(t *testing.T) {
+	backend := NewBackend(WithStyleMode(StyleInline))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.RGBA{R: 1, A: 0.5}))
+	backend.DrawText("Hi", 1, 2, nil, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`<rect x="0" y="0" width="5" height="5" style="fill:rgb(255,0,0);fill-opacity:0.5;stroke:none"/>`,
+		`<text x="1" y="2" style="font-size:12px;fill:rgb(0,0,0)">Hi</text>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+	if strings.Contains(svg, "<style>") {
+		t.Error("Inline mode should not emit a <style> block")
+	}
+}
@@ -0,0 +1,240 @@
+package svgtest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Difference is a structural difference between two SVG documents.
+type Difference struct {
+	// Path locates the element in the first document, or in the second
+	// for added elements, as in "/svg/g[2]/path[1]": each step names an
+	// element and its position among siblings of the same name.
+	Path string
+	// Message describes the difference, e.g. `fill: "red" != "blue"`.
+	Message string
+}
+
+// String returns the path and message.
+func (d Difference) String() string {
+	return d.Path + ": " + d.Message
+}
+
+// Diff parses two SVG documents and returns how b differs from a in
+// element structure, attributes and text. Formatting that Normalize
+// removes is ignored, so documents that normalize equally have no
+// differences. Child elements are aligned by name, so an inserted or
+// removed element is reported once rather than as a change of every
+// following sibling.
+func Diff(a, b []byte) ([]Difference, error) {
+	ra, err := parse(a)
+	if err != nil {
+		return nil, fmt.Errorf("svgtest: first document: %w", err)
+	}
+	rb, err := parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("svgtest: second document: %w", err)
+	}
+	renumberIDs(ra)
+	renumberIDs(rb)
+
+	var diffs []Difference
+	if ra.name != rb.name {
+		return []Difference{{Path: "/" + ra.name, Message: fmt.Sprintf("root <%s> != <%s>", ra.name, rb.name)}}, nil
+	}
+	diffNodes(&diffs, "/"+ra.name, ra, rb)
+	return diffs, nil
+}
+
+// diffNodes appends the differences between elements a and b, which have
+// the same name, at path.
+func diffNodes(diffs *[]Difference, path string, a, b *node) {
+	add := func(path, format string, args ...any) {
+		*diffs = append(*diffs, Difference{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	av, bv := attrValues(a), attrValues(b)
+	names := make([]string, 0, len(av)+len(bv))
+	for name := range av {
+		names = append(names, name)
+	}
+	for name := range bv {
+		if _, ok := av[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		x, inA := av[name]
+		y, inB := bv[name]
+		switch {
+		case !inB:
+			add(path, "attribute %s=%q removed", name, x)
+		case !inA:
+			add(path, "attribute %s=%q added", name, y)
+		case x != y:
+			add(path, "%s: %q != %q", name, x, y)
+		}
+	}
+
+	if ta, tb := text(a), text(b); ta != tb {
+		add(path, "text %q != %q", ta, tb)
+	}
+
+	ea, eb := elements(a), elements(b)
+	pa, pb := stepNames(ea), stepNames(eb)
+	i, j := 0, 0
+	for _, m := range alignByName(ea, eb) {
+		for ; i < m[0]; i++ {
+			add(path+"/"+pa[i], "element <%s> removed", ea[i].name)
+		}
+		for ; j < m[1]; j++ {
+			add(path+"/"+pb[j], "element <%s> added", eb[j].name)
+		}
+		diffNodes(diffs, path+"/"+pa[i], ea[i], eb[j])
+		i, j = i+1, j+1
+	}
+	for ; i < len(ea); i++ {
+		add(path+"/"+pa[i], "element <%s> removed", ea[i].name)
+	}
+	for ; j < len(eb); j++ {
+		add(path+"/"+pb[j], "element <%s> added", eb[j].name)
+	}
+}
+
+// attrValues returns the normalized attribute values of n by name.
+func attrValues(n *node) map[string]string {
+	values := make(map[string]string, len(n.attrs))
+	for _, a := range n.attrs {
+		values[qualified(a.Name)] = normalizeValue(a)
+	}
+	return values
+}
+
+// text returns the character data directly inside n.
+func text(n *node) string {
+	var parts []string
+	for _, c := range n.children {
+		if c.name == "" {
+			parts = append(parts, c.text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// elements returns the child elements of n.
+func elements(n *node) []*node {
+	var children []*node
+	for _, c := range n.children {
+		if c.name != "" {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// stepNames returns the path steps of sibling elements, such as "g[2]".
+func stepNames(nodes []*node) []string {
+	counts := make(map[string]int)
+	steps := make([]string, len(nodes))
+	for i, n := range nodes {
+		counts[n.name]++
+		steps[i] = n.name + "[" + strconv.Itoa(counts[n.name]) + "]"
+	}
+	return steps
+}
+
+// alignByName returns the index pairs of a longest common subsequence of
+// the element names in a and b. Common leading and trailing elements are
+// matched directly and the rest is aligned with Hirschberg's algorithm,
+// so memory grows linearly with the number of siblings.
+func alignByName(a, b []*node) [][2]int {
+	na, nb := names(a), names(b)
+	var pairs [][2]int
+	prefix := 0
+	for prefix < len(na) && prefix < len(nb) && na[prefix] == nb[prefix] {
+		pairs = append(pairs, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(na)-prefix && suffix < len(nb)-prefix &&
+		na[len(na)-1-suffix] == nb[len(nb)-1-suffix] {
+		suffix++
+	}
+	pairs = hirschberg(na[prefix:len(na)-suffix], nb[prefix:len(nb)-suffix], prefix, prefix, pairs)
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{len(na) - k, len(nb) - k})
+	}
+	return pairs
+}
+
+func names(nodes []*node) []string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = n.name
+	}
+	return names
+}
+
+// hirschberg appends the index pairs of a longest common subsequence of a
+// and b, offset by i and j, to pairs. It splits a in half and b where the
+// LCS lengths of the two halves add up to the most, then recurses.
+func hirschberg(a, b []string, i, j int, pairs [][2]int) [][2]int {
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		return pairs
+	case len(a) == 1:
+		if k := slices.Index(b, a[0]); k >= 0 {
+			pairs = append(pairs, [2]int{i, j + k})
+		}
+		return pairs
+	}
+	mid := len(a) / 2
+	head := lcsLengths(a[:mid], b)
+	ra, rb := slices.Clone(a[mid:]), slices.Clone(b)
+	slices.Reverse(ra)
+	slices.Reverse(rb)
+	tail := lcsLengths(ra, rb)
+	split, best := 0, -1
+	for k := range len(b) + 1 {
+		if n := head[k] + tail[len(b)-k]; n > best {
+			split, best = k, n
+		}
+	}
+	pairs = hirschberg(a[:mid], b[:split], i, j, pairs)
+	return hirschberg(a[mid:], b[split:], i+mid, j+split, pairs)
+}
+
+// lcsLengths returns the LCS lengths of a with every prefix of b, indexed
+// by the prefix length, keeping only one row of the table.
+func lcsLengths(a, b []string) []int {
+	row := make([]int, len(b)+1)
+	for _, x := range a {
+		diag := 0
+		for k, y := range b {
+			next := row[k+1]
+			if x == y {
+				row[k+1] = diag + 1
+			} else {
+				row[k+1] = max(row[k+1], row[k])
+			}
+			diag = next
+		}
+	}
+	return row
+}
+
+// formatDiffs formats differences one per line, at most limit of them.
+func formatDiffs(diffs []Difference, limit int) string {
+	var s strings.Builder
+	for i, d := range diffs {
+		if i == limit {
+			fmt.Fprintf(&s, "  ... and %d more\n", len(diffs)-limit)
+			break
+		}
+		s.WriteString("  " + d.String() + "\n")
+	}
+	return s.String()
+}
@@ -0,0 +1,69 @@
+package svgtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := `<svg xmlns="http://www.w3.org/2000/svg">
+<defs><linearGradient id="g1"/></defs>
+<g fill="red"><rect width="1"/><circle r="2"/></g>
+<text>old</text>
+</svg>`
+	b := `<svg xmlns="http://www.w3.org/2000/svg"><defs><linearGradient id="other"/></defs><g fill="blue" opacity="0.5"><path d="M0 0"/><rect width="1.0"/></g><text>new</text><title>added</title></svg>`
+
+	diffs, err := Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.String())
+	}
+	want := []string{
+		`/svg/g[1]: fill: "red" != "blue"`,
+		`/svg/g[1]: attribute opacity="0.5" added`,
+		`/svg/g[1]/path[1]: element <path> added`,
+		`/svg/g[1]/circle[1]: element <circle> removed`,
+		`/svg/text[1]: text "old" != "new"`,
+		`/svg/title[1]: element <title> added`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	diffs, err = Diff([]byte(a), []byte(a))
+	if err != nil || len(diffs) != 0 {
+		t.Errorf("identical documents: %v, %v", diffs, err)
+	}
+	if _, err := Diff([]byte(a), []byte("<svg>")); err == nil {
+		t.Error("malformed document should be an error")
+	}
+}
+
+func TestDiffManySiblings(t *testing.T) {
+	// Flat exports have tens of thousands of sibling paths; aligning them
+	// must not take quadratic memory.
+	const n = 20000
+	var a, b strings.Builder
+	a.WriteString(`<svg xmlns="http://www.w3.org/2000/svg">`)
+	b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg">`)
+	for i := range n {
+		a.WriteString(`<path d="M0 0"/><rect/>`)
+		b.WriteString(`<path d="M0 0"/>`)
+		if i != n/2 {
+			b.WriteString(`<rect/>`)
+		}
+	}
+	a.WriteString(`</svg>`)
+	b.WriteString(`</svg>`)
+
+	diffs, err := Diff([]byte(a.String()), []byte(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || !strings.Contains(diffs[0].String(), "element <rect> removed") {
+		t.Errorf("Diff = %v, want one removed rect", diffs)
+	}
+}
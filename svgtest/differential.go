@@ -0,0 +1,145 @@
+package svgtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"math/rand/v2"
+
+	svg "github.com/gogpu/gg-svg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/recording/backends/raster"
+)
+
+// Rasterizer renders an SVG document to an image of the given size.
+// Differential testing needs an independent renderer, for example a
+// wrapper around resvg or a headless browser.
+type Rasterizer func(doc []byte, width, height int) (image.Image, error)
+
+// Differential compares SVG exports, rendered by an injected Rasterizer,
+// with the output of gg's raster backend. Systematic differences point
+// to fidelity bugs in the export, such as transforms or clips applied in
+// the wrong coordinate space.
+type Differential struct {
+	// Rasterize renders the exported SVG documents. It is required.
+	Rasterize Rasterizer
+	// Options configure the SVG backend.
+	Options []svg.Option
+	// Generate returns a recording for a random source. nil uses
+	// RandomRecording, whose text, gradients and images the raster
+	// backend may render differently; set Threshold accordingly.
+	Generate func(rng *rand.Rand) *recording.Recording
+	// Tolerance is the per-channel difference, out of 255, below which
+	// pixels are considered equal. It absorbs antialiasing differences.
+	Tolerance int
+	// Threshold is the fraction of differing pixels above which Fuzz
+	// reports a recording.
+	Threshold float64
+}
+
+// Mismatch is the result of comparing one recording.
+type Mismatch struct {
+	// Seed is the seed the recording was generated from by Fuzz.
+	Seed uint64
+	// Recording is the compared recording.
+	Recording *recording.Recording
+	// SVG is the exported document.
+	SVG []byte
+	// Raster and Rendered are the raster backend's image and the
+	// rasterized SVG.
+	Raster, Rendered image.Image
+	// Diff is the fraction of pixels that differ by more than the
+	// tolerance.
+	Diff float64
+}
+
+// errRasterPanic reports a recording the raster backend panicked on.
+var errRasterPanic = errors.New("svgtest: raster backend panicked")
+
+// Compare exports r, renders it both ways and measures the difference.
+func (d *Differential) Compare(r *recording.Recording) (*Mismatch, error) {
+	if d.Rasterize == nil {
+		return nil, errors.New("svgtest: Differential has no Rasterizer")
+	}
+	rb, err := rasterize(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b := svg.NewBackend(d.Options...)
+	if err := b.Playback(r); err != nil {
+		return nil, err
+	}
+	var doc bytes.Buffer
+	if _, err := b.WriteTo(&doc); err != nil {
+		return nil, err
+	}
+	rendered, err := d.Rasterize(doc.Bytes(), r.Width(), r.Height())
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mismatch{Recording: r, SVG: doc.Bytes(), Raster: rb.Image(), Rendered: rendered}
+	m.Diff = pixelDiff(m.Raster, m.Rendered, d.Tolerance)
+	return m, nil
+}
+
+// fuzzComplexity is the number of operations of the recordings Fuzz
+// generates by default.
+const fuzzComplexity = 24
+
+// rasterize plays r back into gg's raster backend. The backend panics on
+// some recordings, such as dashed curves in gg v0.23, which is returned
+// as an error.
+func rasterize(r *recording.Recording) (rb *raster.Backend, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v", errRasterPanic, p)
+		}
+	}()
+	rb = raster.NewBackend()
+	if err := r.Playback(rb); err != nil {
+		return nil, err
+	}
+	return rb, nil
+}
+
+// Fuzz compares n generated recordings, using seeds seed through
+// seed+n-1, and returns those whose difference exceeds the threshold.
+// Each mismatch can be reproduced from its seed. Recordings the raster
+// backend panics on are skipped and reported in the returned error,
+// joined with their seeds; other errors stop Fuzz.
+func (d *Differential) Fuzz(seed uint64, n int) ([]Mismatch, error) {
+	generate := d.Generate
+	if generate == nil {
+		generate = func(rng *rand.Rand) *recording.Recording {
+			return RandomRecording(rng.Uint64(), fuzzComplexity)
+		}
+	}
+
+	var outliers []Mismatch
+	var skipped []error
+	for i := range uint64(n) {
+		rng := rand.New(rand.NewPCG(seed+i, 0))
+		m, err := d.Compare(generate(rng))
+		if errors.Is(err, errRasterPanic) {
+			skipped = append(skipped, fmt.Errorf("seed %d: %w", seed+i, err))
+			continue
+		}
+		if err != nil {
+			return outliers, err
+		}
+		if m.Diff > d.Threshold {
+			m.Seed = seed + i
+			outliers = append(outliers, *m)
+		}
+	}
+	return outliers, errors.Join(skipped...)
+}
+
+// pixelDiff returns the fraction of pixels of a that differ from b by more
+// than tolerance in any channel. Pixels missing from b count as different.
+func pixelDiff(a, b image.Image, tolerance int) float64 {
+	return ComparePixels(a, b, tolerance).Differing
+}
@@ -0,0 +1,195 @@
+package svgtest
+
+import (
+	"errors"
+	"image"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+var (
+	elementPattern   = regexp.MustCompile(`<(rect|path)\s([^>]*?)/>`)
+	attrPattern      = regexp.MustCompile(`([\w-]+)="([^"]*)"`)
+	pathTokenPattern = regexp.MustCompile(`[MLQCZ]|-?[\d.]+(?:e-?\d+)?`)
+)
+
+// fillRasterizer renders the filled <rect> and <path> elements of an
+// export with gg, honoring transforms. It ignores clips and strokes.
+func fillRasterizer(doc []byte, width, height int) (image.Image, error) {
+	ctx := gg.NewContext(width, height)
+	for _, el := range elementPattern.FindAllStringSubmatch(string(doc), -1) {
+		attrs := make(map[string]string)
+		for _, a := range attrPattern.FindAllStringSubmatch(el[2], -1) {
+			attrs[a[1]] = a[2]
+		}
+		fill := parseNumbers(attrs["fill"])
+		if len(fill) != 3 {
+			continue
+		}
+
+		ctx.Identity()
+		if m := parseNumbers(attrs["transform"]); len(m) == 6 {
+			ctx.SetTransform(gg.Matrix{A: m[0], B: m[2], C: m[4], D: m[1], E: m[3], F: m[5]})
+		}
+		ctx.ClearPath()
+		if el[1] == "rect" {
+			ctx.DrawRectangle(parseNumber(attrs["x"]), parseNumber(attrs["y"]), parseNumber(attrs["width"]), parseNumber(attrs["height"]))
+		} else {
+			tracePath(ctx, attrs["d"])
+		}
+		alpha := 1.0
+		if a, ok := attrs["fill-opacity"]; ok {
+			alpha = parseNumber(a)
+		}
+		rule := gg.FillRuleNonZero
+		if attrs["fill-rule"] == "evenodd" {
+			rule = gg.FillRuleEvenOdd
+		}
+		ctx.SetFillRule(rule)
+		ctx.SetRGBA(fill[0]/255, fill[1]/255, fill[2]/255, alpha)
+		if err := ctx.Fill(); err != nil {
+			return nil, err
+		}
+	}
+	return ctx.Image(), nil
+}
+
+// tracePath appends the absolute M, L, Q, C and Z commands written by
+// pathToD to the context's path.
+func tracePath(ctx *gg.Context, d string) {
+	var cmd string
+	var args []float64
+	flush := func() {
+		switch {
+		case cmd == "M" && len(args) == 2:
+			ctx.MoveTo(args[0], args[1])
+		case cmd == "L" && len(args) == 2:
+			ctx.LineTo(args[0], args[1])
+		case cmd == "Q" && len(args) == 4:
+			ctx.QuadraticTo(args[0], args[1], args[2], args[3])
+		case cmd == "C" && len(args) == 6:
+			ctx.CubicTo(args[0], args[1], args[2], args[3], args[4], args[5])
+		case cmd == "Z":
+			ctx.ClosePath()
+		}
+		args = args[:0]
+	}
+	for _, t := range pathTokenPattern.FindAllString(d, -1) {
+		if v, err := strconv.ParseFloat(t, 64); err == nil {
+			args = append(args, v)
+			continue
+		}
+		flush()
+		cmd = t
+	}
+	flush()
+}
+
+func parseNumber(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// randomFills records translucent filled rectangles, circles and
+// polygons, which fillRasterizer can render.
+func randomFills(rng *rand.Rand) *recording.Recording {
+	r := recording.NewRecorder(64, 64)
+	for range 1 + rng.IntN(6) {
+		r.SetFillRGBA(rng.Float64(), rng.Float64(), rng.Float64(), 0.25+rng.Float64()*0.75)
+		switch rng.IntN(3) {
+		case 0:
+			r.DrawRectangle(rng.Float64()*48, rng.Float64()*48, 4+rng.Float64()*16, 4+rng.Float64()*16)
+		case 1:
+			r.DrawCircle(8+rng.Float64()*48, 8+rng.Float64()*48, 2+rng.Float64()*12)
+		default:
+			r.SetFillRule(recording.FillRule(rng.IntN(2)))
+			r.MoveTo(rng.Float64()*64, rng.Float64()*64)
+			for range 2 + rng.IntN(5) {
+				r.LineTo(rng.Float64()*64, rng.Float64()*64)
+			}
+			r.ClosePath()
+		}
+		r.Fill()
+	}
+	return r.FinishRecording()
+}
+
+func TestDifferentialFuzz(t *testing.T) {
+	d := &Differential{
+		Rasterize: fillRasterizer,
+		Generate:  randomFills,
+		Tolerance: 8,
+		Threshold: 0.01,
+	}
+	outliers, err := d.Fuzz(1, 25)
+	if err != nil {
+		t.Fatalf("Fuzz failed: %v", err)
+	}
+	for _, m := range outliers {
+		t.Errorf("Seed %d differs from the raster backend in %.1f%% of pixels:\n%s", m.Seed, m.Diff*100, m.SVG)
+	}
+}
+
+func TestDifferentialDefaultGenerator(t *testing.T) {
+	// Every recording differs by at most all of its pixels. Recordings
+	// the raster backend panics on are skipped.
+	d := &Differential{Rasterize: fillRasterizer, Threshold: 1}
+	outliers, err := d.Fuzz(1, 10)
+	if len(outliers) != 0 {
+		t.Errorf("Fuzz with the default generator = %d outliers", len(outliers))
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); err != nil && !ok {
+		t.Errorf("Fuzz failed: %v", err)
+	} else if ok {
+		for _, e := range joined.Unwrap() {
+			if !errors.Is(e, errRasterPanic) {
+				t.Errorf("Fuzz failed: %v", e)
+			}
+		}
+	}
+}
+
+func FuzzDifferential(f *testing.F) {
+	for seed := range uint64(4) {
+		f.Add(seed)
+	}
+	d := &Differential{Rasterize: fillRasterizer, Tolerance: 8}
+	f.Fuzz(func(t *testing.T, seed uint64) {
+		m, err := d.Compare(randomFills(rand.New(rand.NewPCG(seed, 0))))
+		if err != nil {
+			t.Fatalf("Compare failed: %v", err)
+		}
+		if m.Diff > 0.01 {
+			t.Errorf("Export differs from the raster backend in %.1f%% of pixels:\n%s", m.Diff*100, m.SVG)
+		}
+	})
+}
+
+func TestDifferentialDetectsMismatch(t *testing.T) {
+	blank := func(_ []byte, width, height int) (image.Image, error) {
+		return image.NewRGBA(image.Rect(0, 0, width, height)), nil
+	}
+	rec := recording.NewRecorder(10, 10)
+	rec.SetFillRGB(1, 0, 0)
+	rec.DrawRectangle(0, 0, 5, 10)
+	rec.Fill()
+	r := rec.FinishRecording()
+
+	if _, err := (&Differential{}).Compare(r); err == nil {
+		t.Error("Compare without a Rasterizer should fail")
+	}
+
+	d := &Differential{Rasterize: blank}
+	m, err := d.Compare(r)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if m.Diff < 0.4 || m.Diff > 0.6 {
+		t.Errorf("Diff = %g, want about 0.5", m.Diff)
+	}
+}
@@ -0,0 +1,312 @@
+package svgtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg-svg/svgwriter"
+)
+
+// Precision is the number of decimal places numbers are rounded to by
+// Normalize.
+const Precision = 3
+
+// UpdateEnv is the environment variable that makes AssertGolden write
+// golden files when set to a non-empty value.
+const UpdateEnv = "SVGTEST_UPDATE"
+
+// node is an element or, with an empty name, character data.
+type node struct {
+	name     string
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+// Normalize returns doc in a canonical form with one element per line.
+// The prolog, comments and processing instructions are removed,
+// whitespace-only text is dropped and other text has its whitespace
+// collapsed, attributes are sorted by name and have their whitespace
+// collapsed, path data and point lists are tokenized, IDs are renumbered "id1",
+// "id2", ... in document order along with the url(#id) and href
+// references to them, and numbers in attribute values are rounded to
+// Precision decimal places and formatted canonically.
+func Normalize(doc []byte) ([]byte, error) {
+	root, err := parse(doc)
+	if err != nil {
+		return nil, err
+	}
+	renumberIDs(root)
+
+	var buf bytes.Buffer
+	write(&buf, root, 0)
+	return buf.Bytes(), nil
+}
+
+// AssertGolden reports a test failure if got does not match the golden
+// file at path after normalization. If the UpdateEnv environment variable
+// is set, it writes the normalized document to path instead, creating
+// missing directories.
+func AssertGolden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	norm, err := Normalize(got)
+	if err != nil {
+		t.Fatalf("svgtest: normalizing output: %v", err)
+		return
+	}
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("svgtest: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, norm, 0o644); err != nil {
+			t.Fatalf("svgtest: %v", err)
+		}
+		return
+	}
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("svgtest: %v (run with %s=1 to create it)", err, UpdateEnv)
+		return
+	}
+	want, err := Normalize(golden)
+	if err != nil {
+		t.Fatalf("svgtest: normalizing %s: %v", path, err)
+		return
+	}
+	if !bytes.Equal(norm, want) {
+		// Report how the output differs from the golden file, falling back
+		// to the first differing line for changes Diff does not see, such
+		// as text moving between elements.
+		msg := firstDifference(norm, want)
+		if diffs, err := Diff(golden, got); err == nil && len(diffs) > 0 {
+			msg = formatDiffs(diffs, 20)
+		}
+		t.Errorf("svgtest: output does not match %s:\n%s", path, msg)
+	}
+}
+
+// firstDifference describes the first line at which got and want differ.
+func firstDifference(got, want []byte) string {
+	g := strings.Split(string(got), "\n")
+	w := strings.Split(string(want), "\n")
+	for i := 0; i < max(len(g), len(w)); i++ {
+		var gl, wl string
+		if i < len(g) {
+			gl = g[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if gl != wl {
+			return fmt.Sprintf("line %d:\n  got:  %s\n  want: %s", i+1, gl, wl)
+		}
+	}
+	return "documents differ"
+}
+
+// parse parses doc into a tree of its root element.
+func parse(doc []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var root *node
+	var stack []*node
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: qualified(t.Name), attrs: slices.Clone(t.Attr)}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, errors.New("svgtest: more than one root element")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("svgtest: unexpected </%s>", qualified(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			text := strings.Join(strings.Fields(string(t)), " ")
+			if len(stack) > 0 && text != "" {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &node{text: text})
+			}
+		}
+	}
+	if root == nil || len(stack) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return root, nil
+}
+
+// qualified returns the prefixed name as written in the source.
+func qualified(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// urlRef matches url(#id) references.
+var urlRef = regexp.MustCompile(`url\(\s*['"]?#([^)'"\s]+)`)
+
+// renumberIDs renames the IDs in root in document order and rewrites
+// the references to them.
+func renumberIDs(root *node) {
+	ids := make(map[string]string)
+	var collect func(n *node)
+	collect = func(n *node) {
+		for _, a := range n.attrs {
+			if a.Name.Local == "id" && a.Name.Space == "" {
+				if _, ok := ids[a.Value]; !ok {
+					ids[a.Value] = "id" + strconv.Itoa(len(ids)+1)
+				}
+			}
+		}
+		for _, c := range n.children {
+			collect(c)
+		}
+	}
+	collect(root)
+
+	rewrite := func(s string) string {
+		return urlRef.ReplaceAllStringFunc(s, func(m string) string {
+			id := urlRef.FindStringSubmatch(m)[1]
+			if renamed, ok := ids[id]; ok {
+				return strings.TrimSuffix(m, id) + renamed
+			}
+			return m
+		})
+	}
+	var visit func(n *node)
+	visit = func(n *node) {
+		if n.name == "" {
+			n.text = rewrite(n.text)
+			return
+		}
+		for i, a := range n.attrs {
+			switch {
+			case a.Name.Local == "id" && a.Name.Space == "":
+				n.attrs[i].Value = ids[a.Value]
+			case a.Name.Local == "href" && strings.HasPrefix(a.Value, "#"):
+				if renamed, ok := ids[a.Value[1:]]; ok {
+					n.attrs[i].Value = "#" + renamed
+				}
+			default:
+				n.attrs[i].Value = rewrite(a.Value)
+			}
+		}
+		for _, c := range n.children {
+			visit(c)
+		}
+	}
+	visit(root)
+}
+
+// number matches a number in an attribute value.
+var number = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// roundNumbers rounds the numbers in an attribute value. Digits that are
+// part of a name or a hex color, such as in "s1" or "#ff0000", are left
+// alone.
+func roundNumbers(s string) string {
+	matches := number.FindAllStringIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var out strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[0] > 0 && isNameByte(s[m[0]-1]) {
+			continue
+		}
+		v, err := strconv.ParseFloat(s[m[0]:m[1]], 64)
+		if err != nil {
+			continue
+		}
+		out.WriteString(s[last:m[0]])
+		out.WriteString(svgwriter.FormatNumber(v, Precision))
+		last = m[1]
+	}
+	out.WriteString(s[last:])
+	return out.String()
+}
+
+// normalizeValue returns the normalized value of an attribute.
+func normalizeValue(a xml.Attr) string {
+	v := strings.Join(strings.Fields(a.Value), " ")
+	if a.Name.Local == "d" || a.Name.Local == "points" {
+		v = pathTokens(v)
+	}
+	return roundNumbers(v)
+}
+
+// pathTokens rewrites path data or a point list with its commands and
+// numbers separated by single spaces.
+func pathTokens(s string) string {
+	var tokens []string
+	for len(s) > 0 {
+		if loc := number.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			tokens = append(tokens, s[:loc[1]])
+			s = s[loc[1]:]
+			continue
+		}
+		if c := s[0]; c != ' ' && c != ',' {
+			tokens = append(tokens, s[:1])
+		}
+		s = s[1:]
+	}
+	return strings.Join(tokens, " ")
+}
+
+func isNameByte(c byte) bool {
+	return c == '#' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// write writes n and its descendants, indented by depth.
+func write(buf *bytes.Buffer, n *node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if n.name == "" {
+		buf.WriteString(indent + svgwriter.Escape(n.text) + "\n")
+		return
+	}
+	attrs := slices.Clone(n.attrs)
+	slices.SortFunc(attrs, func(a, b xml.Attr) int {
+		return strings.Compare(qualified(a.Name), qualified(b.Name))
+	})
+	buf.WriteString(indent + "<" + n.name)
+	for _, a := range attrs {
+		buf.WriteString(" " + qualified(a.Name) + `="` + svgwriter.Escape(normalizeValue(a)) + `"`)
+	}
+	if len(n.children) == 0 {
+		buf.WriteString("/>\n")
+		return
+	}
+	buf.WriteString(">\n")
+	for _, c := range n.children {
+		write(buf, c, depth+1)
+	}
+	buf.WriteString(indent + "</" + n.name + ">\n")
+}
@@ -0,0 +1,92 @@
+package svgtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	a := `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="100.0001" height="50">
+  <!-- comment -->
+  <defs><clipPath id="clip7"><rect width="10" height="10"/></clipPath></defs>
+  <path clip-path="url(#clip7)" fill="#ff0000" class="s1" d="M0.12345 1L2 3"/>
+  <text x="1">  Hello
+     world </text>
+</svg>`
+	b := `<svg height="50" width="100" xmlns="http://www.w3.org/2000/svg"><defs><clipPath id="c1"><rect height="10" width="10"/></clipPath></defs><path d="M0.123,1L2 3" class="s1" fill="#ff0000" clip-path="url(#c1)"/><text x="1">Hello world</text></svg>`
+
+	na, err := Normalize([]byte(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb, err := Normalize([]byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`id="id1"`, `d="M 0.123 1 L 2 3"`, `clip-path="url(#id1)"`, `fill="#ff0000"`, `class="s1"`, `width="100"`, "    Hello world\n"} {
+		if !strings.Contains(string(na), want) {
+			t.Errorf("normalized document missing %q:\n%s", want, na)
+		}
+	}
+	if string(na) != string(nb) {
+		t.Errorf("documents should normalize equally:\n%s\n%s", na, nb)
+	}
+
+	if _, err := Normalize([]byte("<svg><g></svg>")); err == nil {
+		t.Error("malformed document should be an error")
+	}
+}
+
+// recordingTB records failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "chart.svg")
+	doc := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect id="a" width="1.00001"/></svg>`)
+
+	tb := &recordingTB{TB: t}
+	AssertGolden(tb, path, doc)
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], UpdateEnv) {
+		t.Errorf("missing golden file should fail with a hint, got %q", tb.failures)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, path, doc)
+	if len(tb.failures) != 0 {
+		t.Fatalf("update failed: %q", tb.failures)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, path, []byte(`<svg xmlns="http://www.w3.org/2000/svg">`+"\n"+`<rect width="1" id="b"/></svg>`))
+	if len(tb.failures) != 0 {
+		t.Errorf("equivalent document should match: %q", tb.failures)
+	}
+
+	tb = &recordingTB{TB: t}
+	AssertGolden(tb, path, []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="2" id="b"/></svg>`))
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], `/svg/rect[1]: width: "1" != "2"`) {
+		t.Errorf("different document should fail with the first difference, got %q", tb.failures)
+	}
+}
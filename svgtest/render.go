@@ -0,0 +1,140 @@
+package svgtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+
+	svg "github.com/gogpu/gg-svg"
+	"github.com/gogpu/gg/recording/backends/raster"
+)
+
+// Render rasterizes an SVG document in Go: the document is read with
+// svg.Decode and the recording replayed onto gg's raster backend.
+// RoundTrip uses it to compare exports with the raster backend's output
+// without an external renderer.
+//
+// With a zero width and height the image has the size of the document.
+// Otherwise the document's width and height are replaced, keeping its
+// viewBox so the content scales; a viewBox is added from the original
+// size if the document has none. A zero dimension follows the other with
+// the document's aspect ratio.
+//
+// Only what Decode supports is drawn; filters, masks, patterns and style
+// sheets are ignored, and text is drawn with gg's default font. Render
+// reads documents with this module's own importer, so it cannot serve
+// as the Rasterizer of a Differential.
+func Render(doc []byte, width, height int) (image.Image, error) {
+	if width > 0 || height > 0 {
+		var err error
+		if doc, err = resizeDocument(doc, float64(width), float64(height)); err != nil {
+			return nil, err
+		}
+	}
+	rec, err := svg.Decode(bytes.NewReader(doc))
+	if err != nil {
+		return nil, err
+	}
+	rb := raster.NewBackend()
+	if err := rec.Playback(rb); err != nil {
+		return nil, err
+	}
+	return rb.Image(), nil
+}
+
+// resizeDocument sets the width and height of the root element of doc,
+// rewriting only its start tag.
+func resizeDocument(doc []byte, width, height float64) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var root xml.StartElement
+	var start, end int64
+	for {
+		start = d.InputOffset()
+		tok, err := d.RawToken()
+		if err != nil {
+			return nil, fmt.Errorf("svgtest: parsing document to render: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			root, end = se, d.InputOffset()
+			break
+		}
+	}
+
+	vb := parseNumbers(attr(root, "viewBox"))
+	iw, err1 := strconv.ParseFloat(attr(root, "width"), 64)
+	ih, err2 := strconv.ParseFloat(attr(root, "height"), 64)
+	if (err1 != nil || err2 != nil) && len(vb) == 4 {
+		iw, ih, err1, err2 = vb[2], vb[3], nil, nil
+	}
+	if err1 != nil || err2 != nil || iw <= 0 || ih <= 0 {
+		return nil, errors.New("svgtest: document has no size to scale from")
+	}
+	if len(vb) != 4 {
+		setAttr(&root, "viewBox", "0 0 "+strconv.FormatFloat(iw, 'g', -1, 64)+" "+strconv.FormatFloat(ih, 'g', -1, 64))
+	}
+	if width <= 0 {
+		width = iw * height / ih
+	}
+	if height <= 0 {
+		height = ih * width / iw
+	}
+	setAttr(&root, "width", strconv.FormatFloat(width, 'g', -1, 64))
+	setAttr(&root, "height", strconv.FormatFloat(height, 'g', -1, 64))
+
+	var buf bytes.Buffer
+	buf.Write(doc[:start])
+	buf.WriteString("<" + qualified(root.Name))
+	for _, a := range root.Attr {
+		buf.WriteString(" " + qualified(a.Name) + `="`)
+		if err := xml.EscapeText(&buf, []byte(a.Value)); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('"')
+	}
+	if bytes.HasSuffix(doc[:end], []byte("/>")) {
+		buf.WriteString("/>")
+	} else {
+		buf.WriteByte('>')
+	}
+	buf.Write(doc[end:])
+	return buf.Bytes(), nil
+}
+
+// attr returns the value of the unprefixed attribute name of e.
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// setAttr sets the unprefixed attribute name of e, appending it if e has
+// none.
+func setAttr(e *xml.StartElement, name, value string) {
+	for i, a := range e.Attr {
+		if a.Name.Space == "" && a.Name.Local == name {
+			e.Attr[i].Value = value
+			return
+		}
+	}
+	e.Attr = append(e.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// parseNumbers returns the numbers of a whitespace or comma separated
+// list, such as a viewBox.
+func parseNumbers(s string) []float64 {
+	var values []float64
+	for _, t := range number.FindAllString(s, -1) {
+		v, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil
+		}
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,62 @@
+package svgtest
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestRender(t *testing.T) {
+	doc := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="20" height="10">` +
+		`<rect x="10" width="10" height="10" fill="rgb(255,0,0)"/></svg>`)
+
+	img, err := Render(doc, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 10 {
+		t.Fatalf("bounds = %v, want 20x10", b)
+	}
+	if c := color.RGBAModel.Convert(img.At(15, 5)).(color.RGBA); c.R != 255 || c.A != 255 {
+		t.Errorf("pixel inside the rect = %v, want red", c)
+	}
+	if _, _, _, a := img.At(5, 5).RGBA(); a != 0 {
+		t.Errorf("pixel outside the rect has alpha %d", a)
+	}
+
+	// Scaling adds a viewBox from the original size.
+	img, err = Render(doc, 40, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 20 {
+		t.Fatalf("scaled bounds = %v, want 40x20", b)
+	}
+	if c := color.RGBAModel.Convert(img.At(30, 15)).(color.RGBA); c.R != 255 {
+		t.Errorf("scaled pixel inside the rect = %v, want red", c)
+	}
+	if _, _, _, a := img.At(15, 15).RGBA(); a != 0 {
+		t.Errorf("scaled pixel outside the rect has alpha %d", a)
+	}
+
+	if _, err := Render([]byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 10, 10); err == nil {
+		t.Error("scaling a document without a size should fail")
+	}
+}
+
+func TestRenderMatchesRaster(t *testing.T) {
+	r := recording.NewRecorder(32, 32)
+	r.SetFillRGBA(0, 0.5, 1, 1)
+	r.DrawRectangle(4, 4, 16, 20)
+	r.Fill()
+
+	d := &Differential{Rasterize: Render, Tolerance: 8}
+	m, err := d.Compare(r.FinishRecording())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Diff > 0 {
+		t.Errorf("Render differs from the raster backend in %.2f%% of pixels:\n%s", m.Diff*100, m.SVG)
+	}
+}
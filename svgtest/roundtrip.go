@@ -0,0 +1,138 @@
+package svgtest
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	svg "github.com/gogpu/gg-svg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/recording/backends/raster"
+)
+
+// RoundTrip measures how faithfully specific content survives export:
+// a recording is exported to SVG, the document is rendered back with
+// Render, which reads it with svg.Decode, and compared pixel by pixel
+// with the recording replayed onto gg's raster backend.
+//
+// The check runs entirely in Go, so it fits ordinary unit tests, but it
+// only covers what Decode understands; content the importer ignores
+// (filters, masks, patterns, style sheets) shows up as a difference.
+type RoundTrip struct {
+	// Options configure the SVG backend.
+	Options []svg.Option
+	// Tolerance is the per-channel difference, out of 255, below which
+	// pixels are considered equal. It absorbs antialiasing differences.
+	Tolerance int
+}
+
+// RoundTripResult is the result of a round trip of one recording.
+type RoundTripResult struct {
+	// SVG is the exported document.
+	SVG []byte
+	// Original and Reimported are the raster backend's images of the
+	// recording and of the decoded document.
+	Original, Reimported image.Image
+	// Metrics compare Reimported with Original.
+	Metrics PixelMetrics
+}
+
+// PixelMetrics summarizes the difference between two images.
+type PixelMetrics struct {
+	// Differing is the fraction of pixels that differ by more than the
+	// tolerance in any channel.
+	Differing float64
+	// MaxDelta is the largest difference in any channel, out of 255.
+	MaxDelta int
+	// MeanDelta is the mean absolute difference over all channels of all
+	// pixels, out of 255.
+	MeanDelta float64
+	// PSNR is the peak signal-to-noise ratio in decibels; it is +Inf for
+	// identical images.
+	PSNR float64
+}
+
+// String formats the metrics for test failure messages.
+func (m PixelMetrics) String() string {
+	return fmt.Sprintf("%.2f%% of pixels differ, max delta %d, mean delta %.3f, PSNR %.1f dB",
+		m.Differing*100, m.MaxDelta, m.MeanDelta, m.PSNR)
+}
+
+// Check runs r through the round trip.
+func (rt *RoundTrip) Check(r *recording.Recording) (*RoundTripResult, error) {
+	original := raster.NewBackend()
+	if err := r.Playback(original); err != nil {
+		return nil, err
+	}
+
+	b := svg.NewBackend(rt.Options...)
+	if err := b.Playback(r); err != nil {
+		return nil, err
+	}
+	var doc bytes.Buffer
+	if _, err := b.WriteTo(&doc); err != nil {
+		return nil, err
+	}
+
+	reimported, err := Render(doc.Bytes(), 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("svgtest: re-importing export: %w", err)
+	}
+
+	res := &RoundTripResult{SVG: doc.Bytes(), Original: original.Image(), Reimported: reimported}
+	res.Metrics = ComparePixels(res.Original, res.Reimported, rt.Tolerance)
+	return res, nil
+}
+
+// ComparePixels measures the difference of b from a over the bounds of a.
+// Pixels of a missing from b count as differing by 255 in every channel.
+func ComparePixels(a, b image.Image, tolerance int) PixelMetrics {
+	var m PixelMetrics
+	bounds := a.Bounds()
+	if bounds.Empty() {
+		m.PSNR = math.Inf(1)
+		return m
+	}
+	differing := 0
+	var sum, sumSquares float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			deltas := [4]int{255, 255, 255, 255}
+			if (image.Point{x, y}).In(b.Bounds()) {
+				ca := color.RGBAModel.Convert(a.At(x, y)).(color.RGBA)
+				cb := color.RGBAModel.Convert(b.At(x, y)).(color.RGBA)
+				deltas = [4]int{channelDiff(ca.R, cb.R), channelDiff(ca.G, cb.G),
+					channelDiff(ca.B, cb.B), channelDiff(ca.A, cb.A)}
+			}
+			over := false
+			for _, d := range deltas {
+				over = over || d > tolerance
+				m.MaxDelta = max(m.MaxDelta, d)
+				sum += float64(d)
+				sumSquares += float64(d * d)
+			}
+			if over {
+				differing++
+			}
+		}
+	}
+
+	pixels := float64(bounds.Dx() * bounds.Dy())
+	m.Differing = float64(differing) / pixels
+	m.MeanDelta = sum / (pixels * 4)
+	if mse := sumSquares / (pixels * 4); mse == 0 {
+		m.PSNR = math.Inf(1)
+	} else {
+		m.PSNR = 10 * math.Log10(255*255/mse)
+	}
+	return m
+}
+
+func channelDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,57 @@
+package svgtest
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestRoundTrip(t *testing.T) {
+	r := recording.NewRecorder(64, 64)
+	r.SetFillRGBA(1, 0, 0, 1)
+	r.DrawRectangle(8, 8, 32, 24)
+	r.Fill()
+	r.SetFillRGBA(0, 0, 1, 0.5)
+	r.DrawCircle(40, 40, 16)
+	r.Fill()
+	r.SetStrokeRGBA(0, 0.5, 0, 1)
+	r.SetLineWidth(3)
+	r.MoveTo(4, 60)
+	r.LineTo(60, 4)
+	r.Stroke()
+
+	rt := &RoundTrip{Tolerance: 8}
+	res, err := rt.Check(r.FinishRecording())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Metrics.Differing > 0.02 {
+		t.Errorf("round trip lost fidelity: %v\n%s", res.Metrics, res.SVG)
+	}
+	if res.Metrics.PSNR < 30 {
+		t.Errorf("PSNR = %.1f dB, want at least 30", res.Metrics.PSNR)
+	}
+}
+
+func TestComparePixels(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	b := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	if m := ComparePixels(a, b, 0); m.Differing != 0 || m.MaxDelta != 0 || !math.IsInf(m.PSNR, 1) {
+		t.Errorf("identical images: %+v", m)
+	}
+
+	b.SetRGBA(0, 0, color.RGBA{R: 100})
+	b.SetRGBA(1, 0, color.RGBA{G: 4})
+	m := ComparePixels(a, b, 5)
+	if m.Differing != 0.25 || m.MaxDelta != 100 || m.MeanDelta != 104.0/16 {
+		t.Errorf("metrics = %+v", m)
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, 1, 2))
+	if m := ComparePixels(a, small, 0); m.Differing != 0.5 || m.MaxDelta != 255 {
+		t.Errorf("missing pixels: %+v", m)
+	}
+}
@@ -0,0 +1,65 @@
+package svgtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+
+	svg "github.com/gogpu/gg-svg"
+)
+
+func TestStressRandomRecordings(t *testing.T) {
+	variants := [][]svg.Option{
+		nil,
+		{svg.WithStyleMode(svg.StyleClasses), svg.WithSourceMap()},
+		{svg.WithMergePaths(true)},
+		{svg.WithAutoCrop(4, true), svg.WithRotation(90), svg.WithMirror(true, false)},
+		{svg.WithProfile(svg.ProfileTiny), svg.WithSVGVersion(svg.SVG11)},
+	}
+	for seed := range uint64(10) {
+		r := RandomRecording(seed, 80)
+		for i, opts := range variants {
+			backend := svg.NewBackend(opts...)
+			if err := backend.Playback(r); err != nil {
+				t.Fatalf("Seed %d, variant %d: Playback() = %v", seed, i, err)
+			}
+			var buf bytes.Buffer
+			if _, err := backend.WriteTo(&buf); err != nil {
+				t.Fatalf("Seed %d, variant %d: WriteTo() = %v", seed, i, err)
+			}
+			dec := xml.NewDecoder(&buf)
+			for {
+				_, err := dec.Token()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Seed %d, variant %d: malformed output: %v", seed, i, err)
+				}
+			}
+		}
+	}
+}
+
+func TestAddPassIdentity(t *testing.T) {
+	rec := RandomRecording(7, 60)
+	plain := svg.NewBackend()
+	plain.SetTitle("a <b> & 'c'")
+	plain.SetMetadata(svg.Metadata{Author: "gg", Keywords: []string{"x"}})
+	if err := plain.Playback(rec); err != nil {
+		t.Fatal(err)
+	}
+	optimized := svg.NewBackend()
+	optimized.SetTitle("a <b> & 'c'")
+	optimized.SetMetadata(svg.Metadata{Author: "gg", Keywords: []string{"x"}})
+	optimized.AddPass(svg.OptimizerFunc(func(*svg.Element) error { return nil }))
+	if err := optimized.Playback(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := optimized.String(), plain.String(); got != want {
+		t.Errorf("A pass that changes nothing should keep the document:\n%s\nwant:\n%s", got, want)
+	}
+}
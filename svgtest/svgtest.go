@@ -0,0 +1,298 @@
+// Package svgtest provides test inputs and assertions for recording
+// backends and their exports.
+//
+// RandomRecording generates reproducible recordings that exercise every
+// recording operation and brush type, so the SVG backend, its optimizer
+// passes and downstream backends can share one stress-testing source.
+//
+// AssertGolden compares generated SVG documents with golden files after
+// normalizing them, so regression tests of exports do not break on
+// formatting changes:
+//
+//	func TestChart(t *testing.T) {
+//		backend := svg.NewBackend()
+//		_ = rec.Playback(backend)
+//		svgtest.AssertGolden(t, "testdata/chart.svg", backend.Bytes())
+//	}
+//
+// Run the tests with SVGTEST_UPDATE=1 to write the golden files instead
+// of comparing against them. Failures list the differences found by
+// Diff, which compares the element trees of two documents.
+//
+// Differential compares exports, rendered by an injected Rasterizer, with
+// gg's raster backend, and its Fuzz method searches generated recordings
+// for ones that differ.
+package svgtest
+
+import (
+	"image"
+	"math"
+	"math/rand/v2"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// Width and Height are the canvas size of generated recordings.
+const (
+	Width  = 256
+	Height = 256
+)
+
+// RandomRecording records complexity random operations on a Width by
+// Height canvas. The same seed and complexity always produce the same
+// commands. Operations cover state save and restore, every transform,
+// clipping, filled and stroked paths with lines and curves, rectangles,
+// images, text, dash patterns, caps, joins and fill rules; brushes cover
+// solid colors, linear, radial and sweep gradients and image patterns.
+//
+// The first twelve operations draw one of each kind, so any complexity of
+// at least twelve covers them all. Saved states are restored before the
+// recording is finished.
+func RandomRecording(seed uint64, complexity int) *recording.Recording {
+	g := generator{
+		rng: rand.New(rand.NewPCG(seed, 0)),
+		r:   recording.NewRecorder(Width, Height),
+	}
+	for i := range max(complexity, 0) {
+		op := i % opCount
+		if i >= opCount {
+			op = g.rng.IntN(opCount)
+		}
+		g.op(op)
+	}
+	for ; g.depth > 0; g.depth-- {
+		g.r.Restore()
+	}
+	return g.r.FinishRecording()
+}
+
+// opCount is the number of operation kinds generator.op chooses from.
+const opCount = 12
+
+// generator draws random operations into a recorder.
+type generator struct {
+	rng    *rand.Rand
+	r      *recording.Recorder
+	depth  int
+	images int
+}
+
+func (g *generator) op(op int) {
+	r := g.r
+	switch op {
+	case 0:
+		r.Save()
+		g.depth++
+	case 1:
+		if g.depth > 0 {
+			r.Restore()
+			g.depth--
+		}
+	case 2:
+		g.transform()
+	case 3:
+		r.SetFillStyle(g.brush())
+		r.SetFillRule(recording.FillRule(g.rng.IntN(2)))
+		g.path()
+		r.Fill()
+	case 4:
+		r.SetStrokeStyle(g.brush())
+		g.strokeStyle()
+		g.path()
+		r.Stroke()
+	case 5:
+		r.SetFillStyle(g.brush())
+		r.FillRectangle(g.coord(Width), g.coord(Height), 1+g.coord(Width/2), 1+g.coord(Height/2))
+	case 6:
+		r.SetStrokeStyle(g.brush())
+		g.strokeStyle()
+		r.StrokeRectangle(g.coord(Width), g.coord(Height), 1+g.coord(Width/2), 1+g.coord(Height/2))
+	case 7:
+		g.image()
+	case 8:
+		r.SetFillStyle(g.brush())
+		r.SetFontSize(8 + g.coord(24))
+		r.DrawString(g.text(), g.coord(Width), g.coord(Height))
+	case 9:
+		g.shape()
+		r.Clip()
+	case 10:
+		r.ResetClip()
+	default:
+		r.SetFillStyle(g.brush())
+		r.SetStrokeStyle(g.brush())
+		g.shape()
+		r.FillStroke()
+	}
+}
+
+// coord returns a coordinate in [0, max], rounded to a quarter unit.
+func (g *generator) coord(max float64) float64 {
+	return math.Round(g.rng.Float64()*max*4) / 4
+}
+
+func (g *generator) color() gg.RGBA {
+	a := 1.0
+	if g.rng.IntN(3) == 0 {
+		a = 0.25 + g.rng.Float64()*0.75
+	}
+	return gg.RGBA{R: g.rng.Float64(), G: g.rng.Float64(), B: g.rng.Float64(), A: a}
+}
+
+func (g *generator) transform() {
+	r := g.r
+	switch g.rng.IntN(5) {
+	case 0:
+		r.Translate(g.coord(Width/2)-Width/4, g.coord(Height/2)-Height/4)
+	case 1:
+		r.RotateAbout(g.rng.Float64()*2*math.Pi, Width/2, Height/2)
+	case 2:
+		r.Scale(0.5+g.rng.Float64(), 0.5+g.rng.Float64())
+	case 3:
+		r.Shear(g.rng.Float64()-0.5, g.rng.Float64()-0.5)
+	default:
+		r.Transform(recording.Matrix{
+			A: 0.5 + g.rng.Float64(), B: g.rng.Float64() - 0.5, C: g.coord(Width / 4),
+			D: g.rng.Float64() - 0.5, E: 0.5 + g.rng.Float64(), F: g.coord(Height / 4),
+		})
+	}
+}
+
+// brush returns a random brush of any type. Image patterns are only
+// chosen once an image has been drawn, since they reference one.
+func (g *generator) brush() recording.Brush {
+	kinds := 4
+	if g.images > 0 {
+		kinds++
+	}
+	switch g.rng.IntN(kinds) {
+	case 0:
+		return recording.NewSolidBrush(g.color())
+	case 1:
+		br := recording.NewLinearGradientBrush(g.coord(Width), g.coord(Height), g.coord(Width), g.coord(Height))
+		for _, s := range g.stops() {
+			br.AddColorStop(s.Offset, s.Color)
+		}
+		return br.SetExtend(g.extend())
+	case 2:
+		r0 := g.coord(Width / 8)
+		br := recording.NewRadialGradientBrush(g.coord(Width), g.coord(Height), r0, r0+1+g.coord(Width/2))
+		for _, s := range g.stops() {
+			br.AddColorStop(s.Offset, s.Color)
+		}
+		return br.SetExtend(g.extend())
+	case 3:
+		br := recording.NewSweepGradientBrush(g.coord(Width), g.coord(Height), g.rng.Float64()*2*math.Pi)
+		for _, s := range g.stops() {
+			br.AddColorStop(s.Offset, s.Color)
+		}
+		return br
+	default:
+		// The recorder adds images to the resource pool in drawing order.
+		ref := recording.ImageRef(uint32(g.rng.IntN(g.images)))
+		return recording.NewPatternBrush(ref).SetRepeat(recording.RepeatMode(g.rng.IntN(4)))
+	}
+}
+
+// stops returns two to four evenly spaced color stops.
+func (g *generator) stops() []recording.GradientStop {
+	n := 2 + g.rng.IntN(3)
+	stops := make([]recording.GradientStop, n)
+	for i := range stops {
+		stops[i] = recording.GradientStop{Offset: float64(i) / float64(n-1), Color: g.color()}
+	}
+	return stops
+}
+
+func (g *generator) extend() recording.ExtendMode {
+	return recording.ExtendMode(g.rng.IntN(3))
+}
+
+func (g *generator) strokeStyle() {
+	r := g.r
+	r.SetLineWidth(0.5 + g.coord(8))
+	r.SetLineCap(recording.LineCap(g.rng.IntN(3)))
+	r.SetLineJoin(recording.LineJoin(g.rng.IntN(3)))
+	r.SetMiterLimit(1 + g.coord(10))
+	if g.rng.IntN(2) == 0 {
+		r.SetDash(1+g.coord(10), 1+g.coord(10))
+		r.SetDashOffset(g.coord(10))
+	} else {
+		r.ClearDash()
+	}
+}
+
+// path records one or two subpaths of lines, quadratic and cubic curves.
+func (g *generator) path() {
+	r := g.r
+	for range 1 + g.rng.IntN(2) {
+		r.MoveTo(g.coord(Width), g.coord(Height))
+		for range 1 + g.rng.IntN(5) {
+			switch g.rng.IntN(3) {
+			case 0:
+				r.LineTo(g.coord(Width), g.coord(Height))
+			case 1:
+				r.QuadraticTo(g.coord(Width), g.coord(Height), g.coord(Width), g.coord(Height))
+			default:
+				r.CubicTo(g.coord(Width), g.coord(Height), g.coord(Width), g.coord(Height), g.coord(Width), g.coord(Height))
+			}
+		}
+		if g.rng.IntN(2) == 0 {
+			r.ClosePath()
+		}
+	}
+}
+
+// shape records a closed shape built from the recorder's shape helpers.
+func (g *generator) shape() {
+	r := g.r
+	switch g.rng.IntN(5) {
+	case 0:
+		r.DrawRectangle(g.coord(Width), g.coord(Height), 1+g.coord(Width/2), 1+g.coord(Height/2))
+	case 1:
+		r.DrawRoundedRectangle(g.coord(Width), g.coord(Height), 8+g.coord(Width/2), 8+g.coord(Height/2), g.coord(4))
+	case 2:
+		r.DrawCircle(g.coord(Width), g.coord(Height), 2+g.coord(Width/4))
+	case 3:
+		r.DrawEllipse(g.coord(Width), g.coord(Height), 2+g.coord(Width/4), 2+g.coord(Height/4))
+	default:
+		r.MoveTo(Width/2, Height/2)
+		r.DrawArc(Width/2, Height/2, 2+g.coord(Width/4), g.rng.Float64()*math.Pi, (1+g.rng.Float64())*math.Pi)
+		r.ClosePath()
+	}
+}
+
+// image draws a small generated image, at its pixel size or scaled.
+func (g *generator) image() {
+	w, h := 1+g.rng.IntN(8), 1+g.rng.IntN(8)
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(g.rng.IntN(256))
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		// Keep the pixels valid premultiplied colors.
+		a := img.Pix[i]
+		for c := i - 3; c < i; c++ {
+			img.Pix[c] = min(img.Pix[c], a)
+		}
+	}
+	if g.rng.IntN(2) == 0 {
+		g.r.DrawImage(img, g.rng.IntN(Width), g.rng.IntN(Height))
+	} else {
+		g.r.DrawImageScaled(img, g.coord(Width), g.coord(Height), 1+g.coord(Width/2), 1+g.coord(Height/2))
+	}
+	g.images++
+}
+
+// words are combined into generated text. They include characters that
+// need escaping in XML and right-to-left script.
+var words = []string{"gg", "svg", "stress", "a<b", "&amp;", `"quoted"`, "naïve", "שלום", "مرحبا", "日本語", "🙂"}
+
+func (g *generator) text() string {
+	s := words[g.rng.IntN(len(words))]
+	for range g.rng.IntN(3) {
+		s += " " + words[g.rng.IntN(len(words))]
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package svgtest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gogpu/gg/recording"
+)
+
+func TestRandomRecordingReproducible(t *testing.T) {
+	a := RandomRecording(7, 50).Commands()
+	b := RandomRecording(7, 50).Commands()
+	if !reflect.DeepEqual(a, b) {
+		t.Error("The same seed should produce the same commands")
+	}
+	if reflect.DeepEqual(a, RandomRecording(8, 50).Commands()) {
+		t.Error("Different seeds should produce different commands")
+	}
+	if n := len(RandomRecording(7, 0).Commands()); n != 0 {
+		t.Errorf("Complexity 0 should record nothing, got %d commands", n)
+	}
+}
+
+func TestRandomRecordingCoverage(t *testing.T) {
+	ops := map[string]bool{}
+	brushes := map[string]bool{}
+	depth := 0
+	for seed := range uint64(20) {
+		r := RandomRecording(seed, 60)
+		for _, cmd := range r.Commands() {
+			ops[reflect.TypeOf(cmd).Name()] = true
+			switch cmd.(type) {
+			case recording.SaveCommand:
+				depth++
+			case recording.RestoreCommand:
+				depth--
+			}
+		}
+		if depth != 0 {
+			t.Fatalf("Seed %d: unbalanced save and restore", seed)
+		}
+		pool := r.Resources()
+		for i := range pool.BrushCount() {
+			brushes[reflect.TypeOf(pool.GetBrush(recording.BrushRef(uint32(i)))).String()] = true
+		}
+	}
+
+	for _, want := range []string{
+		"SaveCommand", "RestoreCommand", "SetTransformCommand", "SetClipCommand", "ClearClipCommand",
+		"FillPathCommand", "StrokePathCommand", "FillRectCommand", "StrokeRectCommand",
+		"DrawImageCommand", "DrawTextCommand", "SetDashCommand", "SetFillRuleCommand",
+	} {
+		if !ops[want] {
+			t.Errorf("No %s generated", want)
+		}
+	}
+	for _, want := range []string{
+		"recording.SolidBrush", "*recording.LinearGradientBrush", "*recording.RadialGradientBrush",
+		"*recording.SweepGradientBrush", "*recording.PatternBrush",
+	} {
+		if !brushes[want] {
+			t.Errorf("No %s generated", want)
+		}
+	}
+}
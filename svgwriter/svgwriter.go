@@ -0,0 +1,251 @@
+// Package svgwriter writes SVG markup: elements, escaped attributes and
+// text, and numbers formatted with a controlled precision.
+//
+// It is the serializer used by the gg-svg backend, exposed so that other
+// exporters, such as diagram or font tools, produce output that escapes
+// and formats numbers the same way without depending on the recording
+// backend.
+//
+//	w := svgwriter.New(os.Stdout)
+//	w.StartElement("svg")
+//	w.Attr("xmlns", "http://www.w3.org/2000/svg")
+//	w.StartElement("circle")
+//	w.Number("cx", 10)
+//	w.Number("cy", 10)
+//	w.Number("r", 5)
+//	w.End() // <circle .../>
+//	w.End() // </svg>
+//	if err := w.Err(); err != nil {
+//		...
+//	}
+package svgwriter
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Writer errors, returned by Err.
+var (
+	// ErrNotInTag is returned when an attribute is written after the
+	// start tag of the current element was closed by content, or outside
+	// any element.
+	ErrNotInTag = errors.New("svgwriter: attribute outside a start tag")
+
+	// ErrNoElement is returned when End is called with no open element.
+	ErrNoElement = errors.New("svgwriter: End without an open element")
+)
+
+// Writer writes SVG elements to an io.Writer. Each call writes through
+// immediately, so the underlying writer sees the document as it is
+// built; wrap it in a bufio.Writer for unbuffered destinations.
+//
+// Errors are sticky: after the first error every call is a no-op, and
+// Err reports it. A Writer is not safe for concurrent use.
+type Writer struct {
+	w         io.Writer
+	buf       []byte
+	open      []string
+	inTag     bool
+	precision int
+	err       error
+}
+
+// New returns a Writer that writes to w, formatting numbers with the
+// shortest representation that round-trips.
+func New(w io.Writer) *Writer {
+	return &Writer{w: w, precision: -1}
+}
+
+// SetPrecision rounds numbers written afterwards to digits decimal
+// places. A negative value restores the shortest round-trip formatting.
+func (w *Writer) SetPrecision(digits int) {
+	w.precision = digits
+}
+
+// StartElement opens an element. Attributes can be written until the
+// element gets content or is ended.
+func (w *Writer) StartElement(name string) {
+	if w.err != nil {
+		return
+	}
+	w.buf = w.closeTag(w.buf[:0])
+	w.buf = append(w.buf, '<')
+	w.buf = append(w.buf, name...)
+	w.open = append(w.open, name)
+	w.inTag = true
+	w.flush()
+}
+
+// Attr writes an attribute of the current element. The value is escaped.
+func (w *Writer) Attr(name, value string) {
+	if !w.attrStart(name) {
+		return
+	}
+	w.buf = AppendEscaped(w.buf, value)
+	w.buf = append(w.buf, '"')
+	w.flush()
+}
+
+// Number writes a numeric attribute of the current element.
+func (w *Writer) Number(name string, v float64) {
+	w.Numbers(name, v)
+}
+
+// Numbers writes an attribute holding a space-separated list of numbers,
+// such as a viewBox or a points list.
+func (w *Writer) Numbers(name string, vs ...float64) {
+	if !w.attrStart(name) {
+		return
+	}
+	for i, v := range vs {
+		if i > 0 {
+			w.buf = append(w.buf, ' ')
+		}
+		w.buf = AppendNumber(w.buf, v, w.precision)
+	}
+	w.buf = append(w.buf, '"')
+	w.flush()
+}
+
+// Text writes escaped character data inside the current element.
+func (w *Writer) Text(s string) {
+	if w.err != nil {
+		return
+	}
+	w.buf = w.closeTag(w.buf[:0])
+	w.buf = AppendEscaped(w.buf, s)
+	w.flush()
+}
+
+// Raw writes markup inside the current element without escaping it.
+// The caller is responsible for s being well-formed.
+func (w *Writer) Raw(s string) {
+	if w.err != nil {
+		return
+	}
+	w.buf = w.closeTag(w.buf[:0])
+	w.buf = append(w.buf, s...)
+	w.flush()
+}
+
+// End closes the innermost open element, as an empty-element tag if it
+// has no content.
+func (w *Writer) End() {
+	if w.err != nil {
+		return
+	}
+	if len(w.open) == 0 {
+		w.err = ErrNoElement
+		return
+	}
+	name := w.open[len(w.open)-1]
+	w.open = w.open[:len(w.open)-1]
+	if w.inTag {
+		w.inTag = false
+		w.buf = append(w.buf[:0], "/>"...)
+	} else {
+		w.buf = append(w.buf[:0], "</"...)
+		w.buf = append(w.buf, name...)
+		w.buf = append(w.buf, '>')
+	}
+	w.flush()
+}
+
+// Depth returns the number of open elements.
+func (w *Writer) Depth() int {
+	return len(w.open)
+}
+
+// Err returns the first error that occurred while writing.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// attrStart appends ` name="` to the buffer and reports whether an
+// attribute can be written.
+func (w *Writer) attrStart(name string) bool {
+	if w.err != nil {
+		return false
+	}
+	if !w.inTag {
+		w.err = ErrNotInTag
+		return false
+	}
+	w.buf = append(w.buf[:0], ' ')
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, `="`...)
+	return true
+}
+
+// closeTag appends the end of a pending start tag.
+func (w *Writer) closeTag(dst []byte) []byte {
+	if w.inTag {
+		w.inTag = false
+		dst = append(dst, '>')
+	}
+	return dst
+}
+
+func (w *Writer) flush() {
+	if _, err := w.w.Write(w.buf); err != nil {
+		w.err = err
+	}
+}
+
+// FormatNumber formats v as an SVG number, rounded to precision decimal
+// places, or in the shortest round-trip form if precision is negative.
+func FormatNumber(v float64, precision int) string {
+	return string(AppendNumber(nil, v, precision))
+}
+
+// AppendNumber appends v formatted as by FormatNumber to dst. Negative
+// zero is written as 0.
+func AppendNumber(dst []byte, v float64, precision int) []byte {
+	if precision >= 0 && !math.IsInf(v, 0) {
+		p := math.Pow10(precision)
+		if r := math.Round(v*p) / p; !math.IsInf(r, 0) && !math.IsNaN(r) {
+			v = r
+		}
+	}
+	if v == 0 {
+		return append(dst, '0')
+	}
+	return strconv.AppendFloat(dst, v, 'g', -1, 64)
+}
+
+// Escape returns s with the five XML special characters replaced by
+// entities, so it can be used in attribute values and text.
+func Escape(s string) string {
+	if !strings.ContainsAny(s, `&<>"'`) {
+		return s
+	}
+	return string(AppendEscaped(make([]byte, 0, len(s)+16), s))
+}
+
+// AppendEscaped appends s, escaped as by Escape, to dst.
+func AppendEscaped(dst []byte, s string) []byte {
+	for {
+		i := strings.IndexAny(s, `&<>"'`)
+		if i < 0 {
+			return append(dst, s...)
+		}
+		dst = append(dst, s[:i]...)
+		switch s[i] {
+		case '&':
+			dst = append(dst, "&amp;"...)
+		case '<':
+			dst = append(dst, "&lt;"...)
+		case '>':
+			dst = append(dst, "&gt;"...)
+		case '"':
+			dst = append(dst, "&quot;"...)
+		default:
+			dst = append(dst, "&apos;"...)
+		}
+		s = s[i+1:]
+	}
+}
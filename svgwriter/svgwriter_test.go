@@ -0,0 +1,98 @@
+package svgwriter
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var sb strings.Builder
+	w := New(&sb)
+	w.StartElement("g")
+	w.Attr("id", `a"b`)
+	w.StartElement("rect")
+	w.Number("x", 1.5)
+	w.Numbers("points", 0, -0.0, 1e6)
+	w.End()
+	w.StartElement("text")
+	w.Text("a < b & c")
+	w.End()
+	w.End()
+	if err := w.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	expected := `<g id="a&quot;b"><rect x="1.5" points="0 0 1e+06"/><text>a &lt; b &amp; c</text></g>`
+	if sb.String() != expected {
+		t.Errorf("got %s, expected %s", sb.String(), expected)
+	}
+	if w.Depth() != 0 {
+		t.Errorf("Depth() = %d after closing every element", w.Depth())
+	}
+}
+
+func TestWriterPrecision(t *testing.T) {
+	var sb strings.Builder
+	w := New(&sb)
+	w.SetPrecision(2)
+	w.StartElement("circle")
+	w.Number("r", 1.23456)
+	w.Number("cx", -0.001)
+	w.SetPrecision(-1)
+	w.Number("cy", 1.23456)
+	w.End()
+	if expected := `<circle r="1.23" cx="0" cy="1.23456"/>`; sb.String() != expected {
+		t.Errorf("got %s, expected %s", sb.String(), expected)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	var sb strings.Builder
+	w := New(&sb)
+	w.StartElement("text")
+	w.Text("x")
+	w.Attr("late", "1")
+	if !errors.Is(w.Err(), ErrNotInTag) {
+		t.Errorf("Err() = %v, expected ErrNotInTag", w.Err())
+	}
+	w.End()
+	if strings.Contains(sb.String(), "late") || strings.Contains(sb.String(), "</text>") {
+		t.Errorf("Calls after an error should write nothing, got %s", sb.String())
+	}
+
+	w = New(&sb)
+	w.End()
+	if !errors.Is(w.Err(), ErrNoElement) {
+		t.Errorf("Err() = %v, expected ErrNoElement", w.Err())
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v         float64
+		precision int
+		expected  string
+	}{
+		{1.0 / 3, -1, "0.3333333333333333"},
+		{1.0 / 3, 3, "0.333"},
+		{math.Copysign(0, -1), -1, "0"},
+		{2.5, 0, "3"},
+		{1e-7, -1, "1e-07"},
+	}
+	for _, tt := range tests {
+		if got := FormatNumber(tt.v, tt.precision); got != tt.expected {
+			t.Errorf("FormatNumber(%v, %d) = %q, expected %q", tt.v, tt.precision, got, tt.expected)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := Escape(`<a href="x">'&'</a>`); got != "&lt;a href=&quot;x&quot;&gt;&apos;&amp;&apos;&lt;/a&gt;" {
+		t.Errorf("Escape() = %q", got)
+	}
+	if s := "plain"; Escape(s) != s {
+		t.Errorf("Escape should return strings without special characters unchanged")
+	}
+}
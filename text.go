@@ -0,0 +1,129 @@
+package svg
+
+import (
+	"fmt"
+
+	"github.com/gogpu/gg/text"
+)
+
+// WithTextLength pins the rendered width of every text element to the
+// advance measured with its face by emitting textLength and lengthAdjust.
+// Browsers that substitute a different font then stretch or squeeze the
+// glyphs instead of changing the line width, keeping laid-out labels intact.
+// Text drawn without a face is not affected.
+func WithTextLength(enabled bool) Option {
+	return func(b *Backend) {
+		b.textLength = enabled
+	}
+}
+
+// writeTextLength writes textLength/lengthAdjust attributes if enabled.
+func (b *Backend) writeTextLength(s string, face text.Face) {
+	if !b.textLength || face == nil || b.tiny() {
+		return
+	}
+	advance := face.Advance(s)
+	if advance <= 0 {
+		return
+	}
+	b.builder.WriteString(fmt.Sprintf(` textLength="%g" lengthAdjust="spacingAndGlyphs"`, advance))
+}
+
+// DefaultFontSize is the font size used for text drawn without a face, or
+// with a face that reports neither a size nor a line height, unless the
+// text was played back from a recording that holds its size.
+const DefaultFontSize = 12.0
+
+// WithDefaultFontSize sets the font size used when the face is nil or
+// reports neither a size nor a line height and no size was recorded with
+// the text. Sizes of 0 or less are ignored.
+func WithDefaultFontSize(size float64) Option {
+	return func(b *Backend) {
+		if size > 0 {
+			b.defaultFontSize = size
+		}
+	}
+}
+
+// WithFontSizeResolver overrides the font size heuristic. fn receives the
+// face passed to DrawText, which may be nil, and returns the size to
+// write; a result of 0 or less falls back to the built-in policy of
+// Size, then line height, then the size recorded with the text when it
+// is played back with Playback, then the default size. This corrects
+// faces whose metrics don't match their nominal size. Playback passes no
+// face, so fn receives nil for recorded text.
+func WithFontSizeResolver(fn func(text.Face) float64) Option {
+	return func(b *Backend) {
+		b.fontSizeResolver = fn
+	}
+}
+
+// Baseline selects what the y coordinate passed to DrawText refers to.
+type Baseline int
+
+const (
+	// BaselineAlphabetic treats y as the alphabetic baseline, as SVG
+	// does. This is the default.
+	BaselineAlphabetic Baseline = iota
+	// BaselineTop treats y as the top of the text (the font ascent).
+	BaselineTop
+	// BaselineMiddle treats y as the vertical center of the text, midway
+	// between ascent and descent.
+	BaselineMiddle
+)
+
+// String returns the name of the baseline policy.
+func (p Baseline) String() string {
+	switch p {
+	case BaselineTop:
+		return "top"
+	case BaselineMiddle:
+		return "middle"
+	default:
+		return "alphabetic"
+	}
+}
+
+// WithBaseline sets how DrawText interprets its y coordinate. gg text
+// helpers differ in convention, and text recorded against the top or
+// center of a line otherwise sits visibly too high in the export.
+//
+// When a face is available the offset is computed from its metrics and
+// written as dy, which every viewer honors. Without a face, the
+// dominant-baseline attribute is used instead.
+func WithBaseline(p Baseline) Option {
+	return func(b *Backend) {
+		b.baseline = p
+	}
+}
+
+// writeBaseline writes the dy or dominant-baseline attribute that moves
+// text from the baseline policy to the alphabetic baseline.
+func (b *Backend) writeBaseline(face text.Face) {
+	if b.baseline == BaselineAlphabetic {
+		return
+	}
+	if face == nil {
+		if b.tiny() {
+			return
+		}
+		if b.baseline == BaselineTop {
+			b.builder.WriteString(` dominant-baseline="text-before-edge"`)
+		} else {
+			b.builder.WriteString(` dominant-baseline="central"`)
+		}
+		return
+	}
+
+	m := face.Metrics()
+	// Metrics are for the face size; scale them to the written size.
+	scale := 1.0
+	if size := face.Size(); size > 0 {
+		scale = b.fontSize(face) / size
+	}
+	dy := m.Ascent
+	if b.baseline == BaselineMiddle {
+		dy = (m.Ascent - m.Descent) / 2
+	}
+	b.builder.WriteString(fmt.Sprintf(` dy="%g"`, dy*scale))
+}
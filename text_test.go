@@ -0,0 +1,127 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// testFace returns a Go Regular face at the given size.
+func testFace(t *testing.T, size float64) text.Face {
+	t.Helper()
+
+	src, err := text.NewFontSource(goregular.TTF)
+	if err != nil {
+		t.Fatalf("Failed to load font: %v", err)
+	}
+	return src.Face(size)
+}
+
+func TestWithTextLength(t *testing.T) {
+	face := testFace(t, 20)
+
+	backend := NewBackend(WithTextLength(true))
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 0, G: 0, B: 0, A: 1})
+	backend.DrawText("Hello", 10, 50, face, brush)
+	backend.DrawText("No face", 10, 100, nil, brush)
+
+	svg := writeSVG(t, backend)
+	expected := fmt.Sprintf(`textLength="%g" lengthAdjust="spacingAndGlyphs"`, face.Advance("Hello"))
+	if !strings.Contains(svg, expected) {
+		t.Errorf("Output should contain %s", expected)
+	}
+	if strings.Count(svg, "textLength=") != 1 {
+		t.Error("Text without a face should not be pinned")
+	}
+}
+
+func TestFontSizePolicy(t *testing.T) {
+	face := testFace(t, 20)
+	draw := func(opts ...Option) string {
+		backend := NewBackend(opts...)
+		_ = backend.Begin(100, 100)
+		backend.DrawText("a", 0, 0, nil, recording.NewSolidBrush(gg.Black))
+		backend.DrawText("b", 0, 0, face, recording.NewSolidBrush(gg.Black))
+		return writeSVG(t, backend)
+	}
+
+	svg := draw()
+	if !strings.Contains(svg, `font-size="12" fill="rgb(0,0,0)">a<`) || !strings.Contains(svg, `font-size="20" fill="rgb(0,0,0)">b<`) {
+		t.Errorf("Default policy should use 12 and the face size, got:\n%s", svg)
+	}
+
+	svg = draw(WithDefaultFontSize(16))
+	if !strings.Contains(svg, `font-size="16" fill="rgb(0,0,0)">a<`) || !strings.Contains(svg, `font-size="20" fill="rgb(0,0,0)">b<`) {
+		t.Errorf("Default size should only apply without a face, got:\n%s", svg)
+	}
+
+	svg = draw(WithFontSizeResolver(func(f text.Face) float64 {
+		if f == nil {
+			return 0
+		}
+		return f.Size() * 0.75
+	}))
+	if !strings.Contains(svg, `font-size="12" fill="rgb(0,0,0)">a<`) || !strings.Contains(svg, `font-size="15" fill="rgb(0,0,0)">b<`) {
+		t.Errorf("Resolver should override the face size and fall back for nil, got:\n%s", svg)
+	}
+}
+
+func TestFontSizeRecorded(t *testing.T) {
+	r := recording.NewRecorder(100, 100)
+	r.SetFontSize(30)
+	r.DrawString("a", 0, 20)
+	rec := r.FinishRecording()
+
+	backend := NewBackend(WithDefaultFontSize(16))
+	if err := backend.Playback(rec); err != nil {
+		t.Fatal(err)
+	}
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `font-size="30"`) {
+		t.Errorf("Playback should use the recorded size, got:\n%s", svg)
+	}
+
+	backend = NewBackend(WithFontSizeResolver(func(text.Face) float64 { return 10 }))
+	if err := backend.Playback(rec); err != nil {
+		t.Fatal(err)
+	}
+	if svg := writeSVG(t, backend); !strings.Contains(svg, `font-size="10"`) {
+		t.Errorf("The resolver should override the recorded size, got:\n%s", svg)
+	}
+}
+
+func TestWithBaseline(t *testing.T) {
+	face := testFace(t, 20)
+	m := face.Metrics()
+
+	tests := []struct {
+		baseline Baseline
+		face     text.Face
+		expected string
+	}{
+		{BaselineTop, face, fmt.Sprintf(`font-size="20" dy="%g"`, m.Ascent)},
+		{BaselineMiddle, face, fmt.Sprintf(`font-size="20" dy="%g"`, (m.Ascent-m.Descent)/2)},
+		{BaselineTop, nil, `font-size="12" dominant-baseline="text-before-edge"`},
+		{BaselineMiddle, nil, `font-size="12" dominant-baseline="central"`},
+		{BaselineAlphabetic, face, `font-size="20" fill=`},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(WithBaseline(tt.baseline))
+		_ = backend.Begin(100, 100)
+		backend.DrawText("Hg", 10, 10, tt.face, recording.NewSolidBrush(gg.Black))
+
+		svg := writeSVG(t, backend)
+		if !strings.Contains(svg, tt.expected) {
+			t.Errorf("%s: output should contain %s, got:\n%s", tt.baseline, tt.expected, svg)
+		}
+	}
+}
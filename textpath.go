@@ -0,0 +1,84 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+	"github.com/gogpu/gg/text"
+)
+
+// TextOnPathBackend is implemented by backends that can lay text out
+// along an arbitrary path. Callers can type-assert a recording.Backend
+// to this interface to detect the capability.
+type TextOnPathBackend interface {
+	recording.Backend
+
+	// DrawTextOnPath draws s along path, starting offset user units from
+	// the start of the path.
+	DrawTextOnPath(s string, path *gg.Path, offset float64, face text.Face, brush recording.Brush)
+}
+
+// DrawTextOnPath draws text along the given path.
+// The path is written to the definitions section and referenced from a
+// <textPath> element, so curved labels stay real, selectable SVG text.
+func (b *Backend) DrawTextOnPath(s string, path *gg.Path, offset float64, face text.Face, brush recording.Brush) {
+	b.advanceOp("DrawTextOnPath")
+	if b.overBudget {
+		return
+	}
+	if path == nil {
+		return
+	}
+	brush = b.fillBrush(brush)
+	if b.invisible(brush) || b.culledPath(path, b.fontSize(face)) {
+		return
+	}
+
+	// Glyphs sit on either side of the path.
+	b.includePath(path, b.fontSize(face))
+	b.addCutPath(path, false, b.fontSize(face))
+	b.trackFont(s, face)
+	if b.tiny() {
+		b.fail(ErrUnsupportedFeature, errors.New("text on a path"))
+		b.drawTextAtPathStart(s, path, face, brush)
+		return
+	}
+
+	pathID := b.nextID("tp")
+	b.defs.WriteString(fmt.Sprintf(`<path id="%s" d="%s"/>`, pathID, b.pathToD(path)))
+
+	b.openElement("text")
+	b.writeTransform()
+	b.writeClip()
+	b.writeFont(face)
+	b.writeFill(brush)
+	b.builder.WriteString(">")
+
+	b.builder.WriteString(fmt.Sprintf(`<textPath %s="#%s"`, b.hrefAttr(), pathID))
+	if offset != 0 {
+		b.builder.WriteString(fmt.Sprintf(` startOffset="%g"`, offset))
+	}
+	b.builder.WriteString(">")
+	b.builder.WriteString(escapeXML(s))
+	b.builder.WriteString("</textPath></text>")
+	b.closeElement(kindText)
+}
+
+// drawTextAtPathStart writes s as plain text at the start of path, for
+// profiles without <textPath>.
+func (b *Backend) drawTextAtPathStart(s string, path *gg.Path, face text.Face, brush recording.Brush) {
+	start := startPoint(path)
+	b.openElement("text")
+	b.writeTransform()
+	b.builder.WriteString(fmt.Sprintf(` x="%g" y="%g"`, start.X, start.Y))
+	b.writeFont(face)
+	b.writeFill(brush)
+	b.builder.WriteString(">")
+	b.builder.WriteString(escapeXML(s))
+	b.builder.WriteString("</text>")
+	b.closeElement(kindText)
+}
+
+var _ TextOnPathBackend = (*Backend)(nil)
@@ -0,0 +1,42 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestDrawTextOnPath(t *testing.T) {
+	backend := NewBackend()
+	if err := backend.Begin(400, 300); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	path := gg.NewPath()
+	path.MoveTo(50, 200)
+	path.QuadraticTo(200, 50, 350, 200)
+
+	brush := recording.NewSolidBrush(gg.RGBA{R: 0, G: 0, B: 0, A: 1})
+	backend.DrawTextOnPath("Curved & label", path, 10, nil, brush)
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `<path id="tp1" d="M50 200Q200 50 350 200"/>`) {
+		t.Error("Output should define the text path in defs")
+	}
+	if !strings.Contains(svg, `<textPath href="#tp1" startOffset="10">Curved &amp; label</textPath>`) {
+		t.Error("Output should reference the path from a textPath element")
+	}
+}
+
+func TestDrawTextOnPathNil(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(400, 300)
+
+	backend.DrawTextOnPath("ignored", nil, 0, nil, recording.NewSolidBrush(gg.RGBA{A: 1}))
+
+	if strings.Contains(writeSVG(t, backend), "<textPath") {
+		t.Error("Nil path should not emit text")
+	}
+}
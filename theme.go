@@ -0,0 +1,83 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogpu/gg"
+)
+
+// CurrentColor is the variable name that maps a color to the CSS
+// currentColor keyword in ThemeColor.
+const CurrentColor = "currentColor"
+
+// ThemeColor maps a solid fill or stroke color to a CSS custom property, so
+// one exported icon can adapt to light and dark themes purely via CSS.
+// Elements painted with c are written as var(--name, fallback), where the
+// fallback is the original color. Colors are matched by their RGB value;
+// opacity is kept in fill-opacity and stroke-opacity.
+//
+// variable is the custom property name, with or without the leading "--".
+// An empty variable assigns the next of --gg-color-1, --gg-color-2, ...
+// The name CurrentColor maps c to the currentColor keyword instead, which
+// inherits the color property of the embedding element. Gradient stops
+// are not themed.
+//
+// CSS variables are not resolved in presentation attributes, so themed
+// properties are written in a style attribute in StyleAttributes mode.
+// Like other hooks, mappings are not reset by Begin.
+func (b *Backend) ThemeColor(c gg.RGBA, variable string) {
+	if b.themeColors == nil {
+		b.themeColors = make(map[string]string)
+	}
+
+	key := colorToCSS(c)
+	switch {
+	case variable == CurrentColor:
+		b.themeColors[key] = CurrentColor
+		return
+	case variable == "":
+		b.themeAuto++
+		variable = fmt.Sprintf("gg-color-%d", b.themeAuto)
+	}
+	variable = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}, strings.TrimPrefix(variable, "--"))
+	b.themeColors[key] = variable
+	b.themeVars = true
+}
+
+// paintColor returns the fill or stroke value for a solid color,
+// applying any theme mapping.
+func (b *Backend) paintColor(c gg.RGBA) string {
+	var variable string
+	ok := false
+	if len(b.themeColors) > 0 {
+		variable, ok = b.themeColors[colorToCSS(c)]
+	}
+	switch {
+	case !ok || b.tiny():
+		return b.formatColor(c)
+	case variable == CurrentColor:
+		return CurrentColor
+	default:
+		// Alpha stays in the opacity attribute, which also applies to
+		// the theme's color.
+		c.A = 1
+		return fmt.Sprintf("var(--%s,%s)", variable, b.formatColor(c))
+	}
+}
+
+// paintsAlpha reports whether paintColor(c) carries the alpha of c, so no
+// opacity attribute is needed. Themed colors never do, since the theme
+// supplies the color.
+func (b *Backend) paintsAlpha(c gg.RGBA) bool {
+	if !b.alphaInColor(c) {
+		return false
+	}
+	_, themed := b.themeColors[colorToCSS(c)]
+	return !themed || b.tiny()
+}
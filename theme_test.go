@@ -0,0 +1,47 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestThemeColor(t *testing.T) {
+	backend := NewBackend()
+	backend.ThemeColor(gg.Red, "")
+	backend.ThemeColor(gg.Blue, "--accent")
+	backend.ThemeColor(gg.Black, CurrentColor)
+	_ = backend.Begin(100, 100)
+
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.RGBA{R: 1, A: 0.5}))
+	backend.StrokePath(line(0, 0, 10, 10), recording.NewSolidBrush(gg.Blue), recording.DefaultStroke())
+	backend.DrawText("Hi", 1, 2, nil, recording.NewSolidBrush(gg.Black))
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Green))
+
+	svg := writeSVG(t, backend)
+	expected := []string{
+		`<rect x="0" y="0" width="5" height="5" fill-opacity="0.5" stroke="none" style="fill:var(--gg-color-1,rgb(255,0,0))"/>`,
+		`stroke-width="1" stroke-linecap="butt" stroke-linejoin="miter" stroke-miterlimit="4" style="stroke:var(--accent,rgb(0,0,255))"/>`,
+		`<text x="1" y="2" font-size="12" fill="currentColor">Hi</text>`,
+		`fill="rgb(0,255,0)"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
+
+func TestThemeColorStyleClasses(t *testing.T) {
+	backend := NewBackend(WithStyleMode(StyleClasses))
+	backend.ThemeColor(gg.Red, "brand")
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), recording.NewSolidBrush(gg.Red))
+
+	svg := writeSVG(t, backend)
+	if !strings.Contains(svg, `.s1{fill:var(--brand,rgb(255,0,0));stroke:none}`) {
+		t.Errorf("Themed color should be written into the class, got:\n%s", svg)
+	}
+}
@@ -0,0 +1,146 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gogpu/gg"
+)
+
+// WithToolpathOrdering defers strokes to End and reorders them to
+// minimize pen-up travel, reversing single-subpath strokes where that
+// shortens the move. Ordering is greedy nearest-neighbor over stroke
+// endpoints in canvas space, starting at the origin.
+//
+// This is intended for stroke-only plotter exports: strokes are written
+// after all other content, so stacking order relative to fills and text
+// is not preserved, and strokes leave any Save/Restore groups, links and
+// layers they were drawn in. Transforms and clips are kept.
+//
+// Writing the document before End, with WriteTo or any method built on
+// it, writes the strokes deferred so far at that point. Strokes drawn
+// after such a write are ordered among themselves and follow them.
+func WithToolpathOrdering(enabled bool) Option {
+	return func(b *Backend) {
+		b.toolpathOrdering = enabled
+	}
+}
+
+// toolpath is a serialized stroke awaiting reordering.
+type toolpath struct {
+	// prefix and suffix surround the d attribute of the element.
+	prefix, suffix string
+	// d and reversedD are the d attributes for both directions.
+	// reversedD is empty if the path cannot be reversed as a whole.
+	d, reversedD string
+	// start and end are the pen-down and pen-up points in canvas space.
+	start, end gg.Point
+	// layer is the layer the element belongs to with layered structures.
+	layer int
+	// tagOffset is the offset of the start tag within prefix, past any
+	// link wrapper.
+	tagOffset int
+}
+
+// deferToolpath moves the element just written by StrokePath into the
+// toolpath list. dStart and dEnd delimit its d attribute in the builder.
+func (b *Backend) deferToolpath(path *gg.Path, dStart, dEnd int) {
+	layer := int(kindStroke)
+	if b.structure != StructureInterleaved {
+		b.closeElementLink()
+		if b.structure == StructureLaser {
+			layer = b.elementLayer
+		}
+	}
+
+	buf := b.builder.Bytes()
+	tp := toolpath{
+		prefix:    string(buf[b.elementStart:dStart]),
+		d:         string(buf[dStart:dEnd]),
+		suffix:    string(buf[dEnd:]),
+		layer:     layer,
+		tagOffset: b.tagStart - b.elementStart,
+	}
+	b.builder.Truncate(b.elementStart)
+	if layer < 0 {
+		return
+	}
+
+	elements := path.Elements()
+	if len(elements) == 0 {
+		return
+	}
+	subpaths := 0
+	for _, el := range elements {
+		if m, ok := el.(gg.MoveTo); ok {
+			if subpaths == 0 {
+				tp.start = m.Point
+			}
+			subpaths++
+		}
+	}
+	tp.end = path.CurrentPoint()
+	if subpaths == 1 && tp.start != tp.end {
+		tp.reversedD = fmt.Sprintf(` d="%s"`, b.pathToD(path.Reversed()))
+	}
+
+	m := b.currentTransform
+	tp.start.X, tp.start.Y = m.TransformPoint(tp.start.X, tp.start.Y)
+	tp.end.X, tp.end.Y = m.TransformPoint(tp.end.X, tp.end.Y)
+	b.toolpaths = append(b.toolpaths, tp)
+}
+
+// flushToolpaths writes the deferred strokes in travel-optimized order.
+func (b *Backend) flushToolpaths() {
+	for _, tp := range orderToolpaths(b.toolpaths) {
+		out := &b.builder
+		if b.structure != StructureInterleaved {
+			out = &b.layers[tp.layer]
+		}
+		elem := tp.prefix + tp.d + tp.suffix
+		if b.rewritesElements() {
+			rewritten, ok := b.rewriteElement(elem[tp.tagOffset:])
+			if !ok {
+				continue
+			}
+			elem = elem[:tp.tagOffset] + rewritten
+		}
+		out.WriteString(elem)
+	}
+	b.toolpaths = b.toolpaths[:0]
+}
+
+// orderToolpaths orders toolpaths greedily by nearest pen-down point,
+// swapping d for reversedD where the path is drawn backwards.
+func orderToolpaths(paths []toolpath) []toolpath {
+	remaining := append([]toolpath(nil), paths...)
+	ordered := make([]toolpath, 0, len(paths))
+	pen := gg.Point{}
+
+	for len(remaining) > 0 {
+		best, reverse := 0, false
+		bestDist := math.Inf(1)
+		for i, tp := range remaining {
+			if d := pen.Distance(tp.start); d < bestDist {
+				best, reverse, bestDist = i, false, d
+			}
+			if tp.reversedD != "" {
+				if d := pen.Distance(tp.end); d < bestDist {
+					best, reverse, bestDist = i, true, d
+				}
+			}
+		}
+
+		tp := remaining[best]
+		remaining[best] = remaining[len(remaining)-1]
+		remaining = remaining[:len(remaining)-1]
+
+		if reverse {
+			tp.d, tp.reversedD = tp.reversedD, tp.d
+			tp.start, tp.end = tp.end, tp.start
+		}
+		ordered = append(ordered, tp)
+		pen = tp.end
+	}
+	return ordered
+}
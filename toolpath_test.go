@@ -0,0 +1,87 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func line(x0, y0, x1, y1 float64) *gg.Path {
+	p := gg.NewPath()
+	p.MoveTo(x0, y0)
+	p.LineTo(x1, y1)
+	return p
+}
+
+func TestToolpathOrdering(t *testing.T) {
+	backend := NewBackend(WithToolpathOrdering(true))
+	if err := backend.Begin(200, 100); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	stroke := recording.DefaultStroke()
+	backend.StrokePath(line(100, 0, 20, 0), brush, stroke)
+	backend.StrokePath(line(0, 0, 10, 0), brush, stroke)
+	backend.StrokePath(line(11, 0, 15, 0), brush, stroke)
+	backend.FillRect(recording.NewRect(0, 0, 5, 5), brush)
+
+	svg := writeSVG(t, backend)
+	first := strings.Index(svg, `d="M0 0L10 0"`)
+	second := strings.Index(svg, `d="M11 0L15 0"`)
+	third := strings.Index(svg, `d="M20 0L100 0"`)
+	if first < 0 || second < 0 || third < 0 {
+		t.Fatalf("Output should contain all strokes, reversing the far one:\n%s", svg)
+	}
+	if first >= second || second >= third {
+		t.Error("Strokes should be ordered by nearest endpoint")
+	}
+	if strings.Index(svg, "<rect") > first {
+		t.Error("Strokes should be written after other content")
+	}
+}
+
+func TestToolpathOrderingTransform(t *testing.T) {
+	backend := NewBackend(WithToolpathOrdering(true))
+	_ = backend.Begin(200, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	stroke := recording.DefaultStroke()
+	backend.SetTransform(recording.Translate(100, 0))
+	backend.StrokePath(line(0, 0, 10, 0), brush, stroke)
+	backend.SetTransform(recording.Identity())
+	backend.StrokePath(line(50, 0, 60, 0), brush, stroke)
+
+	svg := writeSVG(t, backend)
+	if strings.Index(svg, `d="M50 0L60 0"`) > strings.Index(svg, `d="M0 0L10 0"`) {
+		t.Error("Endpoints should be compared in canvas space")
+	}
+	if !strings.Contains(svg, `transform="matrix(`) {
+		t.Error("Deferred strokes should keep their transform")
+	}
+}
+
+func TestToolpathOrderingWithoutEnd(t *testing.T) {
+	backend := NewBackend(WithToolpathOrdering(true))
+	_ = backend.Begin(200, 100)
+
+	brush := recording.NewSolidBrush(gg.RGBA{A: 1})
+	stroke := recording.DefaultStroke()
+	backend.StrokePath(line(50, 0, 60, 0), brush, stroke)
+	backend.StrokePath(line(0, 0, 10, 0), brush, stroke)
+
+	svg := backend.String()
+	first := strings.Index(svg, `d="M0 0L10 0"`)
+	second := strings.Index(svg, `d="M50 0L60 0"`)
+	if first < 0 || second < 0 {
+		t.Fatalf("WriteTo without End should write the deferred strokes:\n%s", svg)
+	}
+	if first > second {
+		t.Error("Strokes written without End should still be ordered")
+	}
+	if again := backend.String(); again != svg {
+		t.Errorf("Writing again should not duplicate strokes:\n%s", again)
+	}
+}
@@ -0,0 +1,43 @@
+package svg
+
+// WithUntrusted hardens the backend for exporting user-controlled
+// drawings. The written document is parsed after optimizer passes and
+// post-processors and only known SVG content is kept, so it contains no
+// scripts, event handler attributes or references to external resources,
+// wherever they came from (WriteRaw, OnElement or a post-processor):
+//
+//   - elements and attributes are kept only if they are known SVG ones,
+//     or belong to the metadata namespaces the backend writes itself;
+//     script, foreignObject and everything in other namespaces, such as
+//     XHTML iframes and objects, is dropped, and AddScript fails;
+//   - WriteRaw sanitizes its fragments, as with WithSanitizedRaw, and
+//     DrawForeignObject fails;
+//   - href attributes are removed unless they point into the document
+//     (#id), or hold a PNG, JPEG, GIF or WebP data URI on an <image>,
+//     which drops link targets as well;
+//   - in style sheets and style and presentation attributes, url() values
+//     that point elsewhere and CSS functions that may load resources,
+//     such as image-set(), are replaced with none, @import rules are
+//     removed, and CSS containing escapes is dropped.
+//
+// The document is buffered in memory before being written.
+func WithUntrusted(enabled bool) Option {
+	return func(b *Backend) {
+		b.untrusted = enabled
+	}
+}
+
+// scriptless reports whether the document must not contain scripts.
+func (b *Backend) scriptless() bool {
+	return b.noScript || b.untrusted
+}
+
+// sanitizer returns the sanitizer for the backend's options.
+func (b *Backend) sanitizer() sanitizer {
+	return sanitizer{external: !b.untrusted}
+}
+
+// sanitizesFragments reports whether raw fragments are sanitized.
+func (b *Backend) sanitizesFragments() bool {
+	return b.sanitizeRaw || b.untrusted
+}
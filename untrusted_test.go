@@ -0,0 +1,103 @@
+package svg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestUntrusted(t *testing.T) {
+	backend := NewBackend(WithScripts(true), WithUntrusted(true),
+		WithPostProcessor(func(data []byte) ([]byte, error) {
+			return []byte(strings.Replace(string(data), "</svg>",
+				`<style>@import url(https://evil.example/a.css); rect{fill:url('https://evil.example/p.svg#p')}</style></svg>`, 1)), nil
+		}))
+	if err := backend.AddScript("alert(1)"); !errors.Is(err, ErrScriptsDisabled) {
+		t.Errorf("AddScript in untrusted mode: err = %v", err)
+	}
+	_ = backend.Begin(100, 100)
+	if err := backend.WriteRaw(`<g>`); err == nil {
+		t.Error("malformed fragment should be rejected")
+	}
+	_ = backend.WriteRaw(`<g onload="x()"><script>alert(2)</script>` +
+		`<a href="https://evil.example/"><rect width="1" height="1"/></a>` +
+		`<use xlink:href="https://evil.example/s.svg#x"/><use href="#local"/>` +
+		`<image href="data:image/png;base64,AAAA"/><image href="file:///etc/passwd"/></g>`)
+	_ = backend.DrawForeignObject(0, 0, 50, 50, `<img src="https://evil.example/t.png" style="background:url(//evil.example/b)"/>`)
+	backend.OnElement(func(tag string, attrs map[string]string) map[string]string {
+		attrs["filter"] = "url(https://evil.example/f.svg#f)"
+		return attrs
+	})
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	_ = backend.End()
+
+	svg := writeSVG(t, backend)
+	for _, unwanted := range []string{"<script", "onload", "evil.example", "file:", "@import"} {
+		if strings.Contains(svg, unwanted) {
+			t.Errorf("untrusted output contains %s:\n%s", unwanted, svg)
+		}
+	}
+	for _, want := range []string{`<use href="#local"/>`, `href="data:image/png;base64,AAAA"`,
+		`<rect width="1" height="1"/>`, `filter="none"`, "fill:none"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG missing %q:\n%s", want, svg)
+		}
+	}
+}
+
+func TestUntrustedVectors(t *testing.T) {
+	tests := []struct {
+		name, fragment string
+		unwanted       []string
+	}{
+		{"iframe srcdoc",
+			`<foreignObject><iframe xmlns="http://www.w3.org/1999/xhtml" srcdoc="&lt;script&gt;alert(1)&lt;/script&gt;"/></foreignObject>`,
+			[]string{"iframe", "srcdoc", "foreignObject"}},
+		{"iframe data URI",
+			`<g><iframe xmlns="http://www.w3.org/1999/xhtml" src="data:image/svg+xml,&lt;svg onload='alert(1)'/&gt;"/></g>`,
+			[]string{"iframe", "data:"}},
+		{"object data",
+			`<object data="https://evil.example/x.svg"/><h:object xmlns:h="http://www.w3.org/1999/xhtml" data="x.svg"/>`,
+			[]string{"object", "data="}},
+		{"embed",
+			`<embed src="https://evil.example/x.svg"/>`,
+			[]string{"embed", "evil.example"}},
+		{"image-set",
+			`<rect style="fill:red;background:image-set('https://evil.example/i.png' 1x)"/>`,
+			[]string{"image-set", "evil.example"}},
+		{"CSS escape",
+			`<style>rect{fill:\75 rl(https://evil.example/p)}</style>`,
+			[]string{`\75`, "evil.example"}},
+		{"data URI outside image",
+			`<use href="data:image/png;base64,AAAA"/><image href="data:image/svg+xml;base64,AAAA"/>`,
+			[]string{"data:"}},
+		{"animated link",
+			`<a><set attributeName="xlink:href" to="javascript:alert(1)"/></a>`,
+			[]string{"<set", "javascript"}},
+	}
+
+	for _, tt := range tests {
+		// Fragments are sanitized by WriteRaw, and again with the
+		// document when a post-processor inserts them.
+		inserted := NewBackend(WithUntrusted(true), WithPostProcessor(func(data []byte) ([]byte, error) {
+			return []byte(strings.Replace(string(data), "</svg>", tt.fragment+"</svg>", 1)), nil
+		}))
+		_ = inserted.Begin(10, 10)
+		raw := NewBackend(WithUntrusted(true))
+		_ = raw.Begin(10, 10)
+		if err := raw.WriteRaw(tt.fragment); err != nil {
+			t.Errorf("%s: WriteRaw failed: %v", tt.name, err)
+		}
+		for _, backend := range []*Backend{inserted, raw} {
+			svg := writeSVG(t, backend)
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(svg, unwanted) {
+					t.Errorf("%s: untrusted output contains %s:\n%s", tt.name, unwanted, svg)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidDocument reports a generated document that failed
+// validation. Use errors.As with *ValidationError for the problems found.
+var ErrInvalidDocument = errors.New("svg: invalid document")
+
+// WithValidation makes End validate the document it would write: End
+// returns a *ValidationError if it is not well-formed XML, repeats an
+// attribute on an element, leaves groups unbalanced, references an id it
+// does not define, defines an id twice or has NaN or infinite numbers in
+// geometry and other numeric attributes. Validation serializes the whole
+// document, so it roughly doubles the cost of an export; enable it in
+// tests and development builds.
+func WithValidation(enabled bool) Option {
+	return func(b *Backend) {
+		b.validate = enabled
+	}
+}
+
+// ValidationError lists the problems found by Validate.
+type ValidationError struct {
+	// Problems describe each problem with its line in the document.
+	Problems []string
+}
+
+// Error formats the problems, one per line.
+func (e *ValidationError) Error() string {
+	return "svg: invalid document:\n\t" + strings.Join(e.Problems, "\n\t")
+}
+
+// Unwrap returns ErrInvalidDocument.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidDocument
+}
+
+// Validate checks the document as WriteTo would write it, after
+// optimizer passes and post-processors, and returns a *ValidationError
+// listing the problems found, or nil. See WithValidation for the checks.
+func (b *Backend) Validate() error {
+	data, err := b.postProcess()
+	if err != nil {
+		return err
+	}
+	if problems := validateDocument(data); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// nonFinite matches NaN and infinities as Go formats them.
+var nonFinite = regexp.MustCompile(`\b(?:NaN|[-+]?Inf)\b`)
+
+// numericAttributes are the attributes checked for non-finite numbers.
+// Others, such as aria-label or class, may hold words like "NaN".
+var numericAttributes = nameSet(`
+	d points x y x1 y1 x2 y2 cx cy r rx ry fx fy fr dx dy width height
+	transform gradientTransform patternTransform viewBox offset rotate
+	opacity fill-opacity stroke-opacity stop-opacity stroke-width
+	stroke-dasharray stroke-dashoffset stroke-miterlimit font-size
+	textLength startOffset pathLength refX refY markerWidth markerHeight
+`)
+
+// validateDocument returns the problems found in a serialized document,
+// at most maxFailures of them.
+func validateDocument(data []byte) []string {
+	var problems []string
+	report := func(line int, format string, args ...any) {
+		if len(problems) < maxFailures {
+			problems = append(problems, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+		}
+	}
+
+	type reference struct {
+		id   string
+		line int
+	}
+	ids := make(map[string]int)
+	var refs []reference
+	var open []string
+	roots := 0
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.RawToken()
+		line, _ := d.InputPos()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report(line, "%v", err)
+			return problems
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := qualifiedName(t.Name)
+			if len(open) == 0 {
+				if roots++; roots > 1 {
+					report(line, "second root element <%s>", name)
+				}
+			}
+			open = append(open, name)
+			seen := make(map[string]bool, len(t.Attr))
+			for _, a := range t.Attr {
+				attr := qualifiedName(a.Name)
+				if seen[attr] {
+					report(line, "attribute %s repeated on <%s>", attr, name)
+				}
+				seen[attr] = true
+				switch {
+				case attr == "id":
+					if first, ok := ids[a.Value]; ok {
+						report(line, "id %q already defined on line %d", a.Value, first)
+					} else {
+						ids[a.Value] = line
+					}
+				case (attr == "href" || strings.HasSuffix(attr, ":href")) && strings.HasPrefix(a.Value, "#"):
+					refs = append(refs, reference{a.Value[1:], line})
+				}
+				for _, m := range urlRef.FindAllStringSubmatch(a.Value, -1) {
+					refs = append(refs, reference{m[1], line})
+				}
+				if numericAttributes[attr] && nonFinite.MatchString(a.Value) {
+					report(line, "non-finite number in <%s %s=%q>", name, attr, a.Value)
+				}
+			}
+		case xml.EndElement:
+			name := qualifiedName(t.Name)
+			if len(open) == 0 || open[len(open)-1] != name {
+				report(line, "unexpected </%s>", name)
+				return problems
+			}
+			open = open[:len(open)-1]
+		case xml.CharData:
+			if len(open) > 0 {
+				for _, m := range urlRef.FindAllStringSubmatch(string(t), -1) {
+					refs = append(refs, reference{m[1], line})
+				}
+			}
+		}
+	}
+	if len(open) > 0 {
+		line, _ := d.InputPos()
+		report(line, "unclosed <%s>", open[len(open)-1])
+	}
+	if roots == 0 {
+		report(1, "no root element")
+	}
+	for _, r := range refs {
+		if _, ok := ids[r.id]; !ok {
+			report(r.line, "reference to undefined id %q", r.id)
+		}
+	}
+	return problems
+}
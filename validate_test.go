@@ -0,0 +1,69 @@
+package svg
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestValidation(t *testing.T) {
+	backend := NewBackend(WithValidation(true))
+	_ = backend.Begin(100, 100)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	backend.SetClip(rectPath(recording.NewRect(0, 0, 50, 50)), recording.FillRuleNonZero)
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	if err := backend.End(); err != nil {
+		t.Fatalf("valid document: %v", err)
+	}
+
+	_ = backend.Begin(100, 100)
+	_ = backend.WriteRaw(`<use href="#missing"/><rect id="a"/><rect id="a" fill="url(#nowhere)"/>`)
+	backend.FillRect(recording.NewRect(0, 0, math.Inf(1), 5), recording.NewSolidBrush(gg.Red))
+	err := backend.End()
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidDocument) {
+		t.Fatalf("End should return a ValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		`reference to undefined id "missing"`,
+		`reference to undefined id "nowhere"`,
+		`id "a" already defined on line 3`,
+		`non-finite number in <rect width="+Inf">`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %s:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	backend := NewBackend(WithValidation(true))
+	_ = backend.Begin(100, 100)
+	backend.SetNextAria(Aria{Label: "Inf"})
+	backend.SetNextAttrs("", "NaN-row")
+	backend.FillRect(recording.NewRect(0, 0, 10, 10), recording.NewSolidBrush(gg.Red))
+	if err := backend.End(); err != nil {
+		t.Errorf("words in text attributes are not numbers: %v", err)
+	}
+
+	_ = backend.Begin(100, 100)
+	_ = backend.WriteRaw(`<rect x="0" x="1"/>`)
+	if err := backend.End(); err == nil || !strings.Contains(err.Error(), "attribute x repeated on <rect>") {
+		t.Errorf("repeated attribute should be reported, got %v", err)
+	}
+}
+
+func TestValidateMalformed(t *testing.T) {
+	backend := NewBackend()
+	_ = backend.Begin(100, 100)
+	_ = backend.WriteRaw(`<g>`)
+	_ = backend.End()
+	err := backend.Validate()
+	if err == nil || !strings.Contains(err.Error(), "line 4: ") {
+		t.Errorf("unbalanced group should be reported with its line, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package svg
+
+import "github.com/gogpu/gg"
+
+// WithWelding enables a geometry cleanup pass for generative geometry.
+// An open subpath whose end lies within tolerance of the next subpath's
+// start is joined with it, removing hairline gaps that show up as
+// rendering artifacts and break cutters. If closePaths is true, open
+// subpaths whose end lies within tolerance of their own start are closed.
+// A tolerance of 0 or less disables the pass.
+func WithWelding(tolerance float64, closePaths bool) Option {
+	return func(b *Backend) {
+		b.weldTolerance = tolerance
+		b.weldClose = closePaths
+	}
+}
+
+// weldPath joins nearly-touching subpaths and closes nearly-closed ones.
+func (b *Backend) weldPath(path *gg.Path) *gg.Path {
+	if b.weldTolerance <= 0 {
+		return path
+	}
+
+	subpaths := splitSubpaths(path)
+	if len(subpaths) == 0 {
+		return path
+	}
+
+	result := gg.NewPath()
+	var current *gg.Path
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if b.weldClose && !isClosed(current) && len(current.Elements()) > 1 &&
+			current.CurrentPoint().Distance(startPoint(current)) <= b.weldTolerance {
+			current.Close()
+		}
+		appendPath(result, current)
+	}
+
+	for _, sp := range subpaths {
+		if current != nil && !isClosed(current) &&
+			current.CurrentPoint().Distance(startPoint(sp)) <= b.weldTolerance {
+			// Continue the current subpath, dropping the MoveTo.
+			for _, elem := range sp.Elements()[1:] {
+				appendElement(current, elem)
+			}
+			continue
+		}
+		flush()
+		current = sp
+	}
+	flush()
+	return result
+}
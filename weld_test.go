@@ -0,0 +1,40 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithWelding(t *testing.T) {
+	path := gg.NewPath()
+	path.MoveTo(0, 0)
+	path.LineTo(50, 0)
+	path.MoveTo(50.01, 0) // hairline gap
+	path.LineTo(50, 50)
+	path.LineTo(0.005, 0.005) // nearly closed
+	path.MoveTo(80, 80)       // separate subpath
+	path.LineTo(90, 90)
+
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, `d="M0 0L50 0M50.01 0L50 50L0.005 0.005M80 80L90 90"`},
+		{[]Option{WithWelding(0.1, false)}, `d="M0 0L50 0L50 50L0.005 0.005M80 80L90 90"`},
+		{[]Option{WithWelding(0.1, true)}, `d="M0 0L50 0L50 50L0.005 0.005ZM80 80L90 90"`},
+	}
+
+	for i, tt := range tests {
+		backend := NewBackend(tt.opts...)
+		_ = backend.Begin(100, 100)
+		backend.StrokePath(path, recording.NewSolidBrush(gg.RGBA{A: 1}), recording.DefaultStroke())
+
+		svg := writeSVG(t, backend)
+		if !strings.Contains(svg, tt.expected) {
+			t.Errorf("Case %d: output should contain %s, got:\n%s", i, tt.expected, svg)
+		}
+	}
+}
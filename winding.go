@@ -0,0 +1,87 @@
+package svg
+
+import "github.com/gogpu/gg"
+
+// Winding selects the subpath direction written for filled, stroked and
+// clip paths. Directions are as seen on screen, with the y axis pointing
+// down.
+type Winding int
+
+const (
+	// WindingAsRecorded writes subpaths in their recorded direction.
+	// This is the default.
+	WindingAsRecorded Winding = iota
+	// WindingOuterCCW writes outer contours counter-clockwise and holes
+	// clockwise.
+	WindingOuterCCW
+	// WindingOuterCW writes outer contours clockwise and holes
+	// counter-clockwise.
+	WindingOuterCW
+)
+
+// String returns the name of the winding convention.
+func (w Winding) String() string {
+	switch w {
+	case WindingOuterCCW:
+		return "outer-ccw"
+	case WindingOuterCW:
+		return "outer-cw"
+	default:
+		return "as-recorded"
+	}
+}
+
+// WithWinding normalizes subpath winding direction. Several CAM and
+// fill-rule-sensitive tools require a consistent convention, which gg
+// recordings don't guarantee.
+//
+// A subpath is a hole if its start point lies inside an odd number of the
+// path's other subpaths. Open subpaths are treated as implicitly closed.
+func WithWinding(w Winding) Option {
+	return func(b *Backend) {
+		b.winding = w
+	}
+}
+
+// normalizeWinding returns the path with subpaths reoriented according to
+// the winding option. The path is returned unchanged if no subpath needs
+// reversing.
+func (b *Backend) normalizeWinding(path *gg.Path) *gg.Path {
+	if b.winding == WindingAsRecorded {
+		return path
+	}
+
+	subpaths := splitSubpaths(path)
+	closed := make([]*gg.Path, len(subpaths))
+	for i, sp := range subpaths {
+		closed[i] = closedCopy(sp)
+	}
+
+	changed := false
+	for i, sp := range subpaths {
+		depth := 0
+		pt := startPoint(sp)
+		for j, other := range closed {
+			if i != j && other.Contains(pt) {
+				depth++
+			}
+		}
+
+		// gg reports positive area for clockwise paths in y-down space.
+		clockwise := closed[i].Area() > 0
+		wantClockwise := (depth%2 == 0) == (b.winding == WindingOuterCW)
+		if clockwise != wantClockwise {
+			subpaths[i] = sp.Reversed()
+			changed = true
+		}
+	}
+	if !changed {
+		return path
+	}
+
+	result := gg.NewPath()
+	for _, sp := range subpaths {
+		appendPath(result, sp)
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+// donut returns two clockwise squares, the second nested in the first.
+func donut() *gg.Path {
+	p := gg.NewPath()
+	p.Rectangle(0, 0, 100, 100)
+	p.Rectangle(25, 25, 50, 50)
+	return p
+}
+
+func TestWithWinding(t *testing.T) {
+	tests := []struct {
+		winding  Winding
+		expected string
+	}{
+		{WindingAsRecorded, `d="M0 0L100 0L100 100L0 100ZM25 25L75 25L75 75L25 75Z"`},
+		{WindingOuterCW, `d="M0 0L100 0L100 100L0 100ZM25 75L75 75L75 25L25 25Z"`},
+		{WindingOuterCCW, `d="M0 100L100 100L100 0L0 0ZM25 25L75 25L75 75L25 75Z"`},
+	}
+
+	for _, tt := range tests {
+		backend := NewBackend(WithWinding(tt.winding))
+		_ = backend.Begin(100, 100)
+		backend.FillPath(donut(), recording.NewSolidBrush(gg.RGBA{A: 1}), recording.FillRuleNonZero)
+
+		svg := writeSVG(t, backend)
+		if !strings.Contains(svg, tt.expected) {
+			t.Errorf("Winding %d: output should contain %s, got:\n%s", tt.winding, tt.expected, svg)
+		}
+	}
+}
+
+func TestSplitSubpaths(t *testing.T) {
+	subpaths := splitSubpaths(donut())
+	if len(subpaths) != 2 {
+		t.Fatalf("Got %d subpaths, expected 2", len(subpaths))
+	}
+	for i, sp := range subpaths {
+		if !isClosed(sp) {
+			t.Errorf("Subpath %d should keep its Close", i)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogpu/gg/text"
+)
+
+// WithTextWrap wraps DrawText strings wider than maxWidth onto several
+// lines, measured with the text's face, and writes each line as a
+// <tspan>. Lines break at spaces; a word wider than maxWidth gets a line
+// of its own. Newlines in the string always start a new line. Text drawn
+// without a face cannot be measured and is only broken at newlines.
+// A maxWidth of 0 or less disables wrapping.
+//
+// Lines are spaced by the face's line height. With WithTextLength each
+// line is pinned to its own measured width.
+func WithTextWrap(maxWidth float64) Option {
+	return func(b *Backend) {
+		b.wrapWidth = maxWidth
+	}
+}
+
+// wrapLines splits s into lines for WithTextWrap. It returns s as the only
+// line when wrapping is disabled.
+func (b *Backend) wrapLines(s string, face text.Face) []string {
+	if b.wrapWidth <= 0 {
+		return []string{s}
+	}
+
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		if face == nil {
+			lines = append(lines, para)
+			continue
+		}
+		line := ""
+		for _, word := range strings.Split(para, " ") {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && face.Advance(candidate) > b.wrapWidth {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// lineHeight returns the distance between wrapped lines.
+func (b *Backend) lineHeight(face text.Face) float64 {
+	if face != nil {
+		if h := face.Metrics().LineHeight(); h > 0 {
+			return h
+		}
+	}
+	return b.fontSize(face) * 1.2
+}
+
+// writeTextLines writes wrapped lines as <tspan> elements starting at x.
+func (b *Backend) writeTextLines(lines []string, x float64, face text.Face) {
+	dy := b.lineHeight(face)
+	for i, line := range lines {
+		b.builder.WriteString(fmt.Sprintf(`<tspan x="%g"`, x))
+		if i > 0 {
+			b.builder.WriteString(fmt.Sprintf(` dy="%g"`, dy))
+		}
+		b.writeTextLength(line, face)
+		b.builder.WriteString(">")
+		b.builder.WriteString(escapeXML(line))
+		b.builder.WriteString("</tspan>")
+	}
+}
@@ -0,0 +1,49 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gogpu/gg"
+	"github.com/gogpu/gg/recording"
+)
+
+func TestWithTextWrap(t *testing.T) {
+	face := testFace(t, 10)
+	maxWidth := face.Advance("alpha beta") + 0.1
+
+	backend := NewBackend(WithTextWrap(maxWidth))
+	_ = backend.Begin(200, 100)
+	backend.DrawText("alpha beta gamma\nde", 10, 20, face, recording.NewSolidBrush(gg.Black))
+	backend.DrawText("short", 10, 20, face, recording.NewSolidBrush(gg.Black))
+	backend.DrawText("no face here", 10, 20, nil, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	dy := face.Metrics().LineHeight()
+	expected := []string{
+		fmt.Sprintf(`<tspan x="10">alpha beta</tspan><tspan x="10" dy="%g">gamma</tspan><tspan x="10" dy="%g">de</tspan>`, dy, dy),
+		`fill="rgb(0,0,0)">short</text>`,
+		`fill="rgb(0,0,0)">no face here</text>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Errorf("Output should contain %s, got:\n%s", e, svg)
+		}
+	}
+}
+
+func TestWithTextWrapTextLength(t *testing.T) {
+	face := testFace(t, 10)
+	backend := NewBackend(WithTextWrap(1), WithTextLength(true))
+	_ = backend.Begin(200, 100)
+	backend.DrawText("ab cd", 0, 20, face, recording.NewSolidBrush(gg.Black))
+
+	svg := writeSVG(t, backend)
+	if n := strings.Count(svg, "textLength="); n != 2 {
+		t.Errorf("Each wrapped line should carry its own textLength, got %d:\n%s", n, svg)
+	}
+	if strings.Contains(svg, `<text x="0" y="20" font-size="10" textLength`) {
+		t.Error("The wrapped text element should not carry a textLength")
+	}
+}